@@ -95,6 +95,20 @@ spec:
           value: "nats://nats-service:4222"
         - name: REDIS_URL
           value: "redis://redis-service:6379"
+        {{- if $svc.probes }}
+        livenessProbe:
+          httpGet:
+            path: /livez
+            port: {{ .port }}
+          periodSeconds: 10
+          failureThreshold: 3
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: {{ .port }}
+          periodSeconds: 5
+          failureThreshold: 2
+        {{- end }}
 {{- end }}
 {{- end }}
 
@@ -0,0 +1,8 @@
+-- Remove audit trail
+DROP TABLE IF EXISTS tenant_audit_log;
+
+-- Remove soft-delete tracking
+DROP INDEX IF EXISTS idx_tenants_purge_after;
+ALTER TABLE tenants
+    DROP COLUMN IF EXISTS purge_after,
+    DROP COLUMN IF EXISTS deleted_at;
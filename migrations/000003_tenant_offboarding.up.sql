@@ -0,0 +1,19 @@
+-- Soft-delete and scheduled purge tracking for tenant off-boarding
+ALTER TABLE tenants
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN purge_after TIMESTAMPTZ;
+
+CREATE INDEX idx_tenants_purge_after ON tenants(purge_after) WHERE deleted_at IS NOT NULL;
+
+-- Audit trail for tenant lifecycle changes
+-- No foreign key: entries must outlive the tenant rows they describe
+CREATE TABLE tenant_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    details JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tenant_audit_log_tenant_id ON tenant_audit_log(tenant_id, created_at);
@@ -0,0 +1,2 @@
+-- Drop the shared tenant schema (cascades to tables, policies and indexes)
+DROP SCHEMA IF EXISTS tenant_shared CASCADE;
@@ -0,0 +1,181 @@
+-- Shared tenant tables for row-level-security isolation (TENANT_ISOLATION_MODE=rls)
+-- Same structure as tenant_template plus a tenant_id column. Every row is
+-- filtered by the app.tenant_id setting, which pkg/tenant sets per transaction.
+-- Policies do not apply to superusers: services must connect as a normal role.
+CREATE SCHEMA tenant_shared;
+
+SET search_path TO tenant_shared;
+
+-- Users table
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id),
+    email VARCHAR(254) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) CHECK (role IN ('admin', 'manager', 'sales_rep', 'viewer')) NOT NULL DEFAULT 'sales_rep',
+    status VARCHAR(20) CHECK (status IN ('active', 'inactive', 'pending')) NOT NULL DEFAULT 'pending',
+    email_verified BOOLEAN DEFAULT FALSE,
+    last_login_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+
+    -- Uniqueness is per tenant, and references never cross tenants
+    UNIQUE (tenant_id, email),
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id)
+);
+
+-- Companies table
+CREATE TABLE companies (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id),
+    name VARCHAR(255) NOT NULL,
+    domain VARCHAR(253),
+    industry VARCHAR(100),
+    size_category VARCHAR(50) CHECK (size_category IN ('startup', 'small', 'medium', 'large', 'enterprise')),
+    parent_company_id INTEGER,
+    street_address VARCHAR(255),
+    city VARCHAR(100),
+    state VARCHAR(100),
+    country VARCHAR(100),
+    postal_code VARCHAR(20),
+    phone VARCHAR(50),
+    website VARCHAR(255),
+    custom_fields JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, parent_company_id) REFERENCES companies(tenant_id, id),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id)
+);
+
+-- Contacts table
+CREATE TABLE contacts (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id),
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(254),
+    phone VARCHAR(50),
+    job_title VARCHAR(100),
+    company_id INTEGER,
+    owner_id INTEGER,
+    status VARCHAR(50) CHECK (status IN ('lead', 'prospect', 'customer', 'inactive')) DEFAULT 'lead',
+    source VARCHAR(100),
+    street_address VARCHAR(255),
+    city VARCHAR(100),
+    state VARCHAR(100),
+    country VARCHAR(100),
+    postal_code VARCHAR(20),
+    custom_fields JSONB DEFAULT '{}',
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, company_id) REFERENCES companies(tenant_id, id),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id)
+);
+
+-- Deals table
+CREATE TABLE deals (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    value DECIMAL(15,2),
+    currency VARCHAR(3) DEFAULT 'USD',
+    stage VARCHAR(100) NOT NULL,
+    probability INTEGER CHECK (probability >= 0 AND probability <= 100) DEFAULT 0,
+    expected_close_date DATE,
+    actual_close_date DATE,
+    owner_id INTEGER,
+    company_id INTEGER,
+    primary_contact_id INTEGER,
+    source VARCHAR(100),
+    close_reason VARCHAR(255),
+    custom_fields JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id),
+    FOREIGN KEY (tenant_id, company_id) REFERENCES companies(tenant_id, id),
+    FOREIGN KEY (tenant_id, primary_contact_id) REFERENCES contacts(tenant_id, id),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id)
+);
+
+-- Activities table
+CREATE TABLE activities (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id),
+    type VARCHAR(50) CHECK (type IN ('email', 'call', 'meeting', 'note', 'task', 'proposal')) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    description TEXT,
+    due_date TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    duration_minutes INTEGER,
+    contact_id INTEGER,
+    company_id INTEGER,
+    deal_id INTEGER,
+    owner_id INTEGER,
+    custom_fields JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, contact_id) REFERENCES contacts(tenant_id, id),
+    FOREIGN KEY (tenant_id, company_id) REFERENCES companies(tenant_id, id),
+    FOREIGN KEY (tenant_id, deal_id) REFERENCES deals(tenant_id, id),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id)
+);
+
+-- Row-level security: one policy per table, enforced for the table owner too
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['users', 'companies', 'contacts', 'deals', 'activities'] LOOP
+        EXECUTE format('ALTER TABLE tenant_shared.%I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE tenant_shared.%I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON tenant_shared.%I
+                USING (tenant_id = current_setting(''app.tenant_id'', true))
+                WITH CHECK (tenant_id = current_setting(''app.tenant_id'', true))', t);
+    END LOOP;
+END $$;
+
+-- Indexes lead with tenant_id so every filtered scan stays within one tenant
+CREATE INDEX idx_shared_users_role ON users(tenant_id, role);
+CREATE INDEX idx_shared_users_status ON users(tenant_id, status);
+
+CREATE INDEX idx_shared_companies_name ON companies(tenant_id, name);
+CREATE INDEX idx_shared_companies_domain ON companies(tenant_id, domain);
+
+CREATE INDEX idx_shared_contacts_email ON contacts(tenant_id, email);
+CREATE INDEX idx_shared_contacts_company_id ON contacts(tenant_id, company_id);
+CREATE INDEX idx_shared_contacts_owner_id ON contacts(tenant_id, owner_id);
+CREATE INDEX idx_shared_contacts_status ON contacts(tenant_id, status);
+
+CREATE INDEX idx_shared_deals_stage ON deals(tenant_id, stage);
+CREATE INDEX idx_shared_deals_owner_id ON deals(tenant_id, owner_id);
+CREATE INDEX idx_shared_deals_company_id ON deals(tenant_id, company_id);
+CREATE INDEX idx_shared_deals_expected_close_date ON deals(tenant_id, expected_close_date);
+
+CREATE INDEX idx_shared_activities_contact_id ON activities(tenant_id, contact_id);
+CREATE INDEX idx_shared_activities_deal_id ON activities(tenant_id, deal_id);
+CREATE INDEX idx_shared_activities_owner_id ON activities(tenant_id, owner_id);
+CREATE INDEX idx_shared_activities_due_date ON activities(tenant_id, due_date);
+
+-- Reset search path
+RESET search_path;
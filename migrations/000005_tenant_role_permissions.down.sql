@@ -0,0 +1,4 @@
+-- Remove per-tenant role permission overrides
+ALTER TABLE tenants
+    DROP CONSTRAINT IF EXISTS tenants_role_permissions_object,
+    DROP COLUMN IF EXISTS role_permissions;
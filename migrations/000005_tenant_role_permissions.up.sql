@@ -0,0 +1,5 @@
+-- Per-tenant role permission overrides: {"<role>": ["<permission>", ...]}
+-- A role listed here replaces the built-in permissions for that role (see pkg/middleware/permissions.go)
+ALTER TABLE tenants
+    ADD COLUMN role_permissions JSONB NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT tenants_role_permissions_object CHECK (jsonb_typeof(role_permissions) = 'object');
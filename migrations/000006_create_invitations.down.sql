@@ -0,0 +1,2 @@
+-- Remove user invitations
+DROP TABLE IF EXISTS invitations;
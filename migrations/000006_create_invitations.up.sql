@@ -0,0 +1,22 @@
+-- User invitations (global table: the invitee has no tenant context until they accept)
+-- Only the SHA-256 of the invitation token is stored; the token itself is returned once on creation.
+CREATE TABLE invitations (
+    id TEXT PRIMARY KEY, -- ULID format (26 chars)
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email VARCHAR(254) NOT NULL,
+    role VARCHAR(20) CHECK (role IN ('admin', 'manager', 'sales_rep', 'viewer')) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    invited_by INTEGER, -- References users table in tenant schema
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invitations_tenant_id ON invitations(tenant_id, created_at);
+CREATE INDEX idx_invitations_email ON invitations(email);
+CREATE INDEX idx_invitations_expires_at ON invitations(expires_at) WHERE accepted_at IS NULL;
+
+-- At most one open invitation per tenant and email
+-- Expired rows still count until cleanup, so the service clears them before inviting again.
+CREATE UNIQUE INDEX idx_invitations_tenant_email_pending ON invitations(tenant_id, email) WHERE accepted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Remove per-tenant MFA requirements
+ALTER TABLE tenants
+    DROP CONSTRAINT IF EXISTS tenants_mfa_required_roles_known,
+    DROP COLUMN IF EXISTS mfa_required_roles;
@@ -0,0 +1,5 @@
+-- Roles whose users must sign in with a second factor (auth-service TOTP)
+ALTER TABLE tenants
+    ADD COLUMN mfa_required_roles TEXT[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT tenants_mfa_required_roles_known
+        CHECK (mfa_required_roles <@ ARRAY['admin', 'manager', 'sales_rep', 'viewer']::TEXT[]);
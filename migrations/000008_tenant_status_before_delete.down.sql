@@ -0,0 +1,3 @@
+-- Remove the pre-delete status
+ALTER TABLE tenants
+    DROP COLUMN IF EXISTS status_before_delete;
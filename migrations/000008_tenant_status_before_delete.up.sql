@@ -0,0 +1,14 @@
+-- Status a soft-deleted tenant had, so a restore returns it there instead of to active
+ALTER TABLE tenants
+    ADD COLUMN status_before_delete VARCHAR(50);
+
+-- Tenants already deleted: take the status from the audit trail of their soft delete
+UPDATE tenants t
+SET status_before_delete = (
+    SELECT a.details->>'previous_status'
+    FROM tenant_audit_log a
+    WHERE a.tenant_id = t.id AND a.action = 'soft_delete'
+    ORDER BY a.id DESC
+    LIMIT 1
+)
+WHERE t.deleted_at IS NOT NULL;
@@ -0,0 +1,215 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"crm-platform/pkg/errors"
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTeamUnavailable is returned when a manager's team cannot be read
+var ErrTeamUnavailable = fmt.Errorf("failed to load team members")
+
+const (
+	// DefaultTeamCacheTTL bounds how long a reporting line change takes to affect scopes
+	DefaultTeamCacheTTL = 30 * time.Second
+
+	// DefaultTeamCacheSize caps how many managers' teams are cached
+	DefaultTeamCacheSize = 10000
+)
+
+// TeamLookup returns the users reporting to a manager, directly or indirectly
+type TeamLookup interface {
+	TeamMemberIDs(ctx context.Context, managerID int32) ([]int32, error)
+}
+
+// UserTeamLookup reads reporting lines from users.manager_id in the request's tenant
+type UserTeamLookup struct {
+	db tenant.Querier
+}
+
+// NewUserTeamLookup creates a team lookup; db must apply tenant isolation (e.g. *tenant.TenantPool)
+func NewUserTeamLookup(db tenant.Querier) *UserTeamLookup {
+	return &UserTeamLookup{db: db}
+}
+
+// TeamMemberIDs walks the reporting tree below managerID
+func (l *UserTeamLookup) TeamMemberIDs(ctx context.Context, managerID int32) ([]int32, error) {
+	sql := `WITH RECURSIVE team AS (
+                SELECT id FROM users WHERE manager_id = $1
+                UNION
+                SELECT u.id FROM users u JOIN team t ON u.manager_id = t.id
+            )
+            SELECT id FROM team ORDER BY id`
+
+	rows, err := l.db.Query(ctx, sql, managerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTeamUnavailable, err)
+	}
+	defer rows.Close()
+
+	var ids []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTeamUnavailable, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTeamUnavailable, err)
+	}
+	return ids, nil
+}
+
+// CachedTeamLookup wraps a team lookup with an in-process TTL cache per tenant and manager
+// Without it every request by a manager walks the reporting tree in the database.
+type CachedTeamLookup struct {
+	source TeamLookup
+	ttl    time.Duration
+	size   int
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[teamKey]teamEntry
+}
+
+type teamKey struct {
+	tenantID  string
+	managerID int32
+}
+
+type teamEntry struct {
+	ids       []int32
+	expiresAt time.Time
+}
+
+// NewCachedTeamLookup caches source lookups for ttl (DefaultTeamCacheTTL if <= 0)
+func NewCachedTeamLookup(source TeamLookup, ttl time.Duration) *CachedTeamLookup {
+	if ttl <= 0 {
+		ttl = DefaultTeamCacheTTL
+	}
+	return &CachedTeamLookup{
+		source:  source,
+		ttl:     ttl,
+		size:    DefaultTeamCacheSize,
+		now:     time.Now,
+		entries: make(map[teamKey]teamEntry),
+	}
+}
+
+// TeamMemberIDs returns the cached team, refreshing it from the source once expired
+// Lookups outside a tenant context and failed lookups are not cached.
+func (c *CachedTeamLookup) TeamMemberIDs(ctx context.Context, managerID int32) ([]int32, error) {
+	tenantID, err := tenant.FromContext(ctx)
+	if err != nil {
+		return c.source.TeamMemberIDs(ctx, managerID)
+	}
+	key := teamKey{tenantID: tenantID, managerID: managerID}
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return slices.Clone(entry.ids), nil
+	}
+
+	ids, err := c.source.TeamMemberIDs(ctx, managerID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = teamEntry{ids: slices.Clone(ids), expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return ids, nil
+}
+
+// evict drops expired entries, or any one entry if none has expired; caller holds mu
+func (c *CachedTeamLookup) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
+
+// Policy decides which records a user may access from their role
+//   - admin and viewer: every record in the tenant (viewers cannot write anyway)
+//   - manager: their own records and those of everyone reporting to them
+//   - sales_rep and any other role: only their own records
+type Policy struct {
+	team TeamLookup
+}
+
+// NewPolicy creates a record policy; a nil team lookup limits managers to their own records
+func NewPolicy(team TeamLookup) *Policy {
+	return &Policy{team: team}
+}
+
+// Scope returns the records claims may access
+// A user ID that is not a users.id (e.g. a development header) owns nothing, so
+// restricted roles get an empty scope.
+func (p *Policy) Scope(ctx context.Context, claims *middleware.Claims) (Scope, error) {
+	switch claims.Role {
+	case middleware.RoleAdmin, middleware.RoleViewer:
+		return TenantScope(), nil
+	}
+
+	userID, err := strconv.ParseInt(claims.UserID, 10, 32)
+	if err != nil {
+		return OwnerScope(), nil
+	}
+	owners := []int32{int32(userID)}
+
+	if claims.Role == middleware.RoleManager && p.team != nil {
+		team, err := p.team.TeamMemberIDs(ctx, int32(userID))
+		if err != nil {
+			return Scope{}, err
+		}
+		owners = append(owners, team...)
+	}
+	return OwnerScope(owners...), nil
+}
+
+// ScopeMiddleware stores the request's record scope on its context
+// Must run after tenant resolution so team lookups read the right tenant.
+func ScopeMiddleware(policy *Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := middleware.GetClaims(c)
+		if !ok {
+			c.JSON(401, gin.H{"error": errors.ErrAuth("authentication required").Error()})
+			c.Abort()
+			return
+		}
+
+		scope, err := policy.Scope(c.Request.Context(), claims)
+		if err != nil {
+			c.JSON(503, gin.H{"error": errors.ErrPermission("record scope unavailable").Error()})
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithScope(c.Request.Context(), scope))
+		c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTeams is a TeamLookup over a map that counts its calls
+type countingTeams struct {
+	teams map[int32][]int32
+	err   error
+	calls int
+}
+
+func (c *countingTeams) TeamMemberIDs(ctx context.Context, managerID int32) ([]int32, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.teams[managerID], nil
+}
+
+func tenantContext(t *testing.T, tenantID string) context.Context {
+	t.Helper()
+	ctx, err := tenant.NewContext(context.Background(), tenantID)
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestCachedTeamLookup_Hit(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{10: {11, 12}}}
+	cache := NewCachedTeamLookup(source, time.Minute)
+	ctx := tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T")
+
+	for i := 0; i < 3; i++ {
+		ids, err := cache.TeamMemberIDs(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int32{11, 12}, ids)
+	}
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestCachedTeamLookup_KeyedByTenant(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{10: {11}}}
+	cache := NewCachedTeamLookup(source, time.Minute)
+
+	_, err := cache.TeamMemberIDs(tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T"), 10)
+	require.NoError(t, err)
+	_, err = cache.TeamMemberIDs(tenantContext(t, "01HK153X003BMPJNJB6JHKXK8V"), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedTeamLookup_Expiry(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{10: {11}}}
+	cache := NewCachedTeamLookup(source, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T")
+
+	cache.TeamMemberIDs(ctx, 10)
+	now = now.Add(30 * time.Second)
+	cache.TeamMemberIDs(ctx, 10)
+	assert.Equal(t, 1, source.calls)
+
+	now = now.Add(31 * time.Second)
+	cache.TeamMemberIDs(ctx, 10)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedTeamLookup_ErrorsNotCached(t *testing.T) {
+	source := &countingTeams{err: ErrTeamUnavailable}
+	cache := NewCachedTeamLookup(source, time.Minute)
+	ctx := tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T")
+
+	_, err := cache.TeamMemberIDs(ctx, 10)
+	assert.ErrorIs(t, err, ErrTeamUnavailable)
+
+	source.err = nil
+	source.teams = map[int32][]int32{10: {11}}
+	ids, err := cache.TeamMemberIDs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{11}, ids)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedTeamLookup_WithoutTenantNotCached(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{10: {11}}}
+	cache := NewCachedTeamLookup(source, time.Minute)
+
+	cache.TeamMemberIDs(context.Background(), 10)
+	cache.TeamMemberIDs(context.Background(), 10)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedTeamLookup_SizeBounded(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{}}
+	cache := NewCachedTeamLookup(source, time.Minute)
+	cache.size = 3
+	ctx := tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T")
+
+	for managerID := int32(1); managerID <= 10; managerID++ {
+		_, err := cache.TeamMemberIDs(ctx, managerID)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(cache.entries), 3)
+	}
+}
+
+func TestPolicy_ScopeUsesTeam(t *testing.T) {
+	source := &countingTeams{teams: map[int32][]int32{10: {11, 12}}}
+	policy := NewPolicy(NewCachedTeamLookup(source, time.Minute))
+	ctx := tenantContext(t, "01HK153X003BMPJNJB6JHKXK8T")
+
+	tests := []struct {
+		role string
+		want Scope
+	}{
+		{middleware.RoleAdmin, TenantScope()},
+		{middleware.RoleViewer, TenantScope()},
+		{middleware.RoleManager, OwnerScope(10, 11, 12)},
+		{middleware.RoleSalesRep, OwnerScope(10)},
+	}
+	for _, tt := range tests {
+		scope, err := policy.Scope(ctx, &middleware.Claims{UserID: "10", Role: tt.role})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, scope, tt.role)
+	}
+
+	// A second manager request is served from the cache
+	_, err := policy.Scope(ctx, &middleware.Claims{UserID: "10", Role: middleware.RoleManager})
+	require.NoError(t, err)
+	assert.Equal(t, 1, source.calls)
+
+	source.err = errors.New("database down")
+	_, err = policy.Scope(ctx, &middleware.Claims{UserID: "20", Role: middleware.RoleManager})
+	assert.Error(t, err)
+}
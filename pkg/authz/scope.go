@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"context"
+	"slices"
+)
+
+type scopeContextKey struct{}
+
+// Scope is the set of record owners a request may read and modify
+// Records outside the scope are reported as not found, never as forbidden, so
+// their existence is not disclosed.
+type Scope struct {
+	Unrestricted bool    // Every record in the tenant
+	OwnerIDs     []int32 // Otherwise only records owned by these users
+}
+
+// TenantScope returns the scope covering every record in the tenant
+func TenantScope() Scope {
+	return Scope{Unrestricted: true}
+}
+
+// OwnerScope returns the scope covering records owned by ownerIDs
+func OwnerScope(ownerIDs ...int32) Scope {
+	return Scope{OwnerIDs: ownerIDs}
+}
+
+// Allows reports whether a record owned by ownerID is in scope (unowned records only when unrestricted)
+func (s Scope) Allows(ownerID *int32) bool {
+	if s.Unrestricted {
+		return true
+	}
+	return ownerID != nil && slices.Contains(s.OwnerIDs, *ownerID)
+}
+
+// Owners returns the owner IDs for query parameters, never nil so it encodes as an empty array
+func (s Scope) Owners() []int32 {
+	if s.OwnerIDs == nil {
+		return []int32{}
+	}
+	return s.OwnerIDs
+}
+
+// WithScope returns a copy of ctx carrying scope
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+// ScopeFromContext returns the scope stored by WithScope
+// Callers must treat a missing scope as an error rather than as unrestricted.
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	if ctx == nil {
+		return Scope{}, false
+	}
+	scope, ok := ctx.Value(scopeContextKey{}).(Scope)
+	return scope, ok
+}
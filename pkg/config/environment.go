@@ -3,43 +3,39 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 )
 
-// IsDevelopmentMode checks if running in development environment
-func IsDevelopmentMode() bool {
-	env := strings.ToLower(os.Getenv("ENVIRONMENT"))		
-	return env == "dev" || env == "development" || env == ""
-}
-
-// IsProductionMode checks if running in production environment
-func IsProductionMode() bool {
-	env := strings.ToLower(os.Getenv("ENVIRONMENT"))
-	return env == "prod" || env == "production"
-}
+// DefaultJWTClockSkew is the leeway allowed on token exp, nbf and iat
+const DefaultJWTClockSkew = 30 * time.Second
 
-// IsStagingMode checks if running in staging environment
-func IsStagingMode() bool {
-	env := strings.ToLower(os.Getenv("ENVIRONMENT"))
-	return env == "stage" || env == "staging"
+// GetTenantBaseDomain returns the domain tenant subdomains live under (e.g. ourcrm.com)
+func GetTenantBaseDomain() string {
+	return os.Getenv("TENANT_BASE_DOMAIN")
 }
 
-// GetEnvironment returns the current environment name
-func GetEnvironment() string {
-	env := os.Getenv("ENVIRONMENT")
-	if env == "" {
-		return "development"
+// GetTenantCustomDomains parses TENANT_CUSTOM_DOMAINS ("crm.acme.com=<tenant id>,...")
+func GetTenantCustomDomains() map[string]string {
+	domains := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("TENANT_CUSTOM_DOMAINS"), ",") {
+		domain, tenantID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || domain == "" || tenantID == "" {
+			continue
+		}
+		domains[strings.TrimSpace(domain)] = strings.TrimSpace(tenantID)
 	}
-	return strings.ToLower(env)
+	return domains
 }
 
-// GetDatabaseURL returns the database connection URL
-func GetDatabaseURL() string {
-	return os.Getenv("DATABASE_URL")
+// GetTenantIsolationMode returns the tenant isolation strategy ("schema" or "rls")
+func GetTenantIsolationMode() string {
+	return os.Getenv("TENANT_ISOLATION_MODE")
 }
 
-// GetJWTSecret returns the shared JWT secret for authentication
-func GetJWTSecret() string {
-	return os.Getenv("SHARED_JWT_SECRET")
+// GetMetricsTenantLabels reports whether exported metrics carry a tenant label
+// Off by default: every tenant adds its own series.
+func GetMetricsTenantLabels() bool {
+	return strings.ToLower(os.Getenv("METRICS_TENANT_LABELS")) == "true"
 }
 
 // GetServicePort returns the port for the service with a fallback
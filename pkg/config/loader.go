@@ -0,0 +1,644 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crm-platform/pkg/database"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is wrapped by every error Load returns for bad settings
+var ErrInvalidConfig = fmt.Errorf("invalid configuration")
+
+// Config is the typed service configuration
+// Sources are layered, later ones winning: Defaults, the YAML file named by
+// CONFIG_FILE, environment variables, then secret files (<VAR>_FILE or SECRETS_DIR/<VAR>).
+type Config struct {
+	Environment string         `yaml:"environment"`
+	Database    DatabaseConfig `yaml:"database"`
+	Auth        AuthConfig     `yaml:"auth"`
+	Tenant      TenantConfig   `yaml:"tenant"`
+	NATSURL     string         `yaml:"nats_url"`  // NATS_URL (readiness checks it when set)
+	RedisURL    string         `yaml:"redis_url"` // REDIS_URL (secret; readiness checks it when set)
+}
+
+// DatabaseConfig holds connection and pool settings for database.NewPool
+type DatabaseConfig struct {
+	URL                  string        `yaml:"url"`                    // DATABASE_URL (secret)
+	ReplicaURLs          []string      `yaml:"replica_urls"`           // DATABASE_REPLICA_URLS (secret)
+	MaxConns             int32         `yaml:"max_conns"`              // DB_MAX_CONNS
+	MinConns             int32         `yaml:"min_conns"`              // DB_MIN_CONNS
+	MaxConnLifetime      time.Duration `yaml:"max_conn_lifetime"`      // DB_MAX_CONN_LIFETIME
+	MaxConnIdleTime      time.Duration `yaml:"max_conn_idle_time"`     // DB_MAX_CONN_IDLE_TIME
+	ConnectTimeout       time.Duration `yaml:"connect_timeout"`        // DB_CONNECT_TIMEOUT
+	QueryTimeout         time.Duration `yaml:"query_timeout"`          // DB_QUERY_TIMEOUT (0 disables)
+	MaxRetries           int           `yaml:"max_retries"`            // DB_MAX_RETRIES
+	RetryInterval        time.Duration `yaml:"retry_interval"`         // DB_RETRY_INTERVAL (first retry delay, doubling)
+	MaxRetryInterval     time.Duration `yaml:"max_retry_interval"`     // DB_MAX_RETRY_INTERVAL
+	MaxRetryElapsed      time.Duration `yaml:"max_retry_elapsed"`      // DB_MAX_RETRY_ELAPSED (0 = attempts only)
+	BackgroundConnect    bool          `yaml:"background_connect"`     // DB_BACKGROUND_CONNECT
+	SlowQueryThreshold   time.Duration `yaml:"slow_query_threshold"`   // DB_SLOW_QUERY_THRESHOLD (0 disables)
+	MaxReplicaLag        time.Duration `yaml:"max_replica_lag"`        // DB_MAX_REPLICA_LAG
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval"` // DB_REPLICA_CHECK_INTERVAL
+}
+
+// AuthConfig holds authentication settings
+type AuthConfig struct {
+	JWTSecret           string        `yaml:"jwt_secret"`            // SHARED_JWT_SECRET (secret)
+	JWKSURL             string        `yaml:"jwks_url"`              // JWT_JWKS_URL (replaces the shared secret when set)
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"` // JWT_JWKS_REFRESH_INTERVAL (0 = 10m)
+	Issuer              string        `yaml:"issuer"`                // JWT_ISSUER
+	Audience            string        `yaml:"audience"`              // JWT_AUDIENCE
+	ClockSkew           time.Duration `yaml:"clock_skew"`            // JWT_CLOCK_SKEW
+}
+
+const (
+	// DefaultTenantMaxQueued is how many operations one tenant may have waiting by default
+	DefaultTenantMaxQueued = 50
+
+	// DefaultTenantQueueTimeout bounds how long an operation waits for a tenant slot
+	DefaultTenantQueueTimeout = 5 * time.Second
+)
+
+// TenantConfig holds the per-tenant limits tenant.TenantPool applies
+type TenantConfig struct {
+	MaxConcurrent             int                      `yaml:"max_concurrent"`              // TENANT_MAX_CONCURRENT (0 disables quotas)
+	MaxQueued                 int                      `yaml:"max_queued"`                  // TENANT_MAX_QUEUED (0 = no queue)
+	QueueTimeout              time.Duration            `yaml:"queue_timeout"`               // TENANT_QUEUE_TIMEOUT
+	QuotaOverrides            map[string]int           `yaml:"quota_overrides"`             // TENANT_QUOTA_OVERRIDES ("<tenant id>=<n>,...")
+	StatementTimeout          time.Duration            `yaml:"statement_timeout"`           // TENANT_STATEMENT_TIMEOUT (0 leaves the server default)
+	StatementTimeoutOverrides map[string]time.Duration `yaml:"statement_timeout_overrides"` // TENANT_STATEMENT_TIMEOUT_OVERRIDES ("<tenant id>=<duration>,...")
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error lists the problems one per line
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v:\n  - %s", ErrInvalidConfig, strings.Join(e.Problems, "\n  - "))
+}
+
+// Unwrap lets errors.Is match ErrInvalidConfig
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Defaults returns the built-in configuration
+func Defaults() *Config {
+	return &Config{
+		Environment: "development",
+		Database: DatabaseConfig{
+			MaxConns:             20,
+			MinConns:             5,
+			MaxConnLifetime:      time.Minute * 60,
+			MaxConnIdleTime:      time.Minute * 5,
+			ConnectTimeout:       time.Second * 30,
+			QueryTimeout:         time.Second * 30,
+			MaxRetries:           5,
+			RetryInterval:        database.DefaultRetryInterval,
+			MaxRetryInterval:     database.DefaultMaxRetryInterval,
+			MaxReplicaLag:        database.DefaultMaxReplicaLag,
+			ReplicaCheckInterval: database.DefaultReplicaCheckInterval,
+		},
+		Auth: AuthConfig{
+			ClockSkew: DefaultJWTClockSkew,
+		},
+		Tenant: TenantConfig{
+			MaxQueued:    DefaultTenantMaxQueued,
+			QueueTimeout: DefaultTenantQueueTimeout,
+		},
+	}
+}
+
+// ServiceSettings are settings only one service reads, loaded in the same layers
+// A service passes its defaults to LoadService; the file's "service" section is decoded
+// into them (unknown keys rejected), ApplyEnv then overlays environment variables and
+// Validate returns one problem per invalid setting.
+type ServiceSettings interface {
+	ApplyEnv(env *EnvParser)
+	Validate() []string
+}
+
+// Load builds the configuration from every source and validates it
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile is Load with an explicit YAML file; an empty path skips the file layer
+func LoadFile(path string) (*Config, error) {
+	return LoadServiceFile(path, nil)
+}
+
+// LoadService is Load that also fills service; problems with either are reported together
+func LoadService(service ServiceSettings) (*Config, error) {
+	return LoadServiceFile(os.Getenv("CONFIG_FILE"), service)
+}
+
+// LoadServiceFile is LoadService with an explicit YAML file; service may be nil
+func LoadServiceFile(path string, service ServiceSettings) (*Config, error) {
+	cfg := Defaults()
+
+	if path != "" {
+		if err := cfg.applyFile(path, service); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []string
+	problems = append(problems, cfg.applyEnv()...)
+	problems = append(problems, cfg.applySecrets()...)
+
+	var invalid *ValidationError
+	if err := cfg.Validate(); errors.As(err, &invalid) {
+		problems = append(problems, invalid.Problems...)
+	}
+
+	if service != nil {
+		var env EnvParser
+		service.ApplyEnv(&env)
+		problems = append(problems, env.problems...)
+		problems = append(problems, service.Validate()...)
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// applyFile overlays a YAML file; unknown keys are rejected so typos are not silently ignored
+// The "service" section goes to service, and is ignored by services that pass none.
+func (c *Config) applyFile(path string, service ServiceSettings) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: reading %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	file := struct {
+		*Config `yaml:",inline"`
+		Service yaml.Node `yaml:"service"`
+	}{Config: c}
+	if err := decodeStrict(data, &file); err != nil {
+		return fmt.Errorf("%w: parsing %s: %v", ErrInvalidConfig, path, err)
+	}
+	if service == nil || file.Service.IsZero() {
+		return nil
+	}
+
+	section, err := yaml.Marshal(&file.Service)
+	if err != nil {
+		return fmt.Errorf("%w: parsing %s: %v", ErrInvalidConfig, path, err)
+	}
+	if err := decodeStrict(section, service); err != nil {
+		return fmt.Errorf("%w: parsing service section of %s: %v", ErrInvalidConfig, path, err)
+	}
+	return nil
+}
+
+// decodeStrict decodes YAML into dst, rejecting keys dst has no field for
+func decodeStrict(data []byte, dst any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// applyEnv overlays environment variables, returning one problem per unparsable value
+func (c *Config) applyEnv() []string {
+	var p EnvParser
+	db := &c.Database
+
+	p.str("ENVIRONMENT", &c.Environment)
+	p.str("DATABASE_URL", &db.URL)
+	p.list("DATABASE_REPLICA_URLS", &db.ReplicaURLs)
+	p.int32("DB_MAX_CONNS", &db.MaxConns)
+	p.int32("DB_MIN_CONNS", &db.MinConns)
+	p.duration("DB_MAX_CONN_LIFETIME", &db.MaxConnLifetime)
+	p.duration("DB_MAX_CONN_IDLE_TIME", &db.MaxConnIdleTime)
+	p.duration("DB_CONNECT_TIMEOUT", &db.ConnectTimeout)
+	p.duration("DB_QUERY_TIMEOUT", &db.QueryTimeout)
+	p.int("DB_MAX_RETRIES", &db.MaxRetries)
+	p.duration("DB_RETRY_INTERVAL", &db.RetryInterval)
+	p.duration("DB_MAX_RETRY_INTERVAL", &db.MaxRetryInterval)
+	p.duration("DB_MAX_RETRY_ELAPSED", &db.MaxRetryElapsed)
+	p.bool("DB_BACKGROUND_CONNECT", &db.BackgroundConnect)
+	p.duration("DB_SLOW_QUERY_THRESHOLD", &db.SlowQueryThreshold)
+	p.duration("DB_MAX_REPLICA_LAG", &db.MaxReplicaLag)
+	p.duration("DB_REPLICA_CHECK_INTERVAL", &db.ReplicaCheckInterval)
+	p.str("SHARED_JWT_SECRET", &c.Auth.JWTSecret)
+	p.str("JWT_JWKS_URL", &c.Auth.JWKSURL)
+	p.duration("JWT_JWKS_REFRESH_INTERVAL", &c.Auth.JWKSRefreshInterval)
+	p.str("JWT_ISSUER", &c.Auth.Issuer)
+	p.str("JWT_AUDIENCE", &c.Auth.Audience)
+	p.duration("JWT_CLOCK_SKEW", &c.Auth.ClockSkew)
+	p.str("NATS_URL", &c.NATSURL)
+	p.str("REDIS_URL", &c.RedisURL)
+	p.int("TENANT_MAX_CONCURRENT", &c.Tenant.MaxConcurrent)
+	p.int("TENANT_MAX_QUEUED", &c.Tenant.MaxQueued)
+	p.duration("TENANT_QUEUE_TIMEOUT", &c.Tenant.QueueTimeout)
+	p.intMap("TENANT_QUOTA_OVERRIDES", &c.Tenant.QuotaOverrides)
+	p.duration("TENANT_STATEMENT_TIMEOUT", &c.Tenant.StatementTimeout)
+	p.durationMap("TENANT_STATEMENT_TIMEOUT_OVERRIDES", &c.Tenant.StatementTimeoutOverrides)
+
+	c.Environment = strings.ToLower(c.Environment)
+	return p.problems
+}
+
+// applySecrets overlays values read from mounted secret files
+func (c *Config) applySecrets() []string {
+	var problems []string
+	secret := func(name string, apply func(string)) {
+		value, ok, err := readSecretFile(name)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if ok {
+			apply(value)
+		}
+	}
+
+	secret("DATABASE_URL", func(v string) { c.Database.URL = v })
+	secret("DATABASE_REPLICA_URLS", func(v string) { c.Database.ReplicaURLs = splitList(v) })
+	secret("SHARED_JWT_SECRET", func(v string) { c.Auth.JWTSecret = v })
+	secret("REDIS_URL", func(v string) { c.RedisURL = v })
+	return problems
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var problems []string
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.Environment {
+	case "dev", "development", "stage", "staging", "prod", "production", "test":
+	default:
+		problem("environment %q must be one of development, staging, production, test", c.Environment)
+	}
+
+	db := c.Database
+	if db.URL == "" {
+		problem("database.url is required (DATABASE_URL or DATABASE_URL_FILE)")
+	} else if err := checkDatabaseURL(db.URL); err != nil {
+		problem("database.url: %v", err)
+	}
+	for i, replicaURL := range db.ReplicaURLs {
+		if err := checkDatabaseURL(replicaURL); err != nil {
+			problem("database.replica_urls[%d]: %v", i, err)
+		}
+	}
+
+	if db.MaxConns <= 0 {
+		problem("database.max_conns must be positive, got %d", db.MaxConns)
+	}
+	if db.MinConns < 0 || db.MinConns > db.MaxConns {
+		problem("database.min_conns must be between 0 and max_conns (%d), got %d", db.MaxConns, db.MinConns)
+	}
+	if db.MaxRetries < 0 {
+		problem("database.max_retries must not be negative, got %d", db.MaxRetries)
+	}
+	if db.ConnectTimeout <= 0 {
+		problem("database.connect_timeout must be positive, got %v", db.ConnectTimeout)
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"database.max_conn_lifetime", db.MaxConnLifetime},
+		{"database.max_conn_idle_time", db.MaxConnIdleTime},
+		{"database.query_timeout", db.QueryTimeout},
+		{"database.retry_interval", db.RetryInterval},
+		{"database.max_retry_interval", db.MaxRetryInterval},
+		{"database.max_retry_elapsed", db.MaxRetryElapsed},
+		{"database.slow_query_threshold", db.SlowQueryThreshold},
+		{"database.max_replica_lag", db.MaxReplicaLag},
+		{"database.replica_check_interval", db.ReplicaCheckInterval},
+		{"auth.jwks_refresh_interval", c.Auth.JWKSRefreshInterval},
+		{"auth.clock_skew", c.Auth.ClockSkew},
+		{"tenant.queue_timeout", c.Tenant.QueueTimeout},
+		{"tenant.statement_timeout", c.Tenant.StatementTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			problem("%s must not be negative, got %v", d.name, d.value)
+		}
+	}
+
+	if c.Tenant.MaxConcurrent < 0 {
+		problem("tenant.max_concurrent must not be negative, got %d", c.Tenant.MaxConcurrent)
+	}
+	if c.Tenant.MaxQueued < 0 {
+		problem("tenant.max_queued must not be negative, got %d", c.Tenant.MaxQueued)
+	}
+	for _, tenantID := range sortedKeys(c.Tenant.QuotaOverrides) {
+		if n := c.Tenant.QuotaOverrides[tenantID]; tenantID == "" || n <= 0 {
+			problem("tenant.quota_overrides[%q] must be a positive limit for a tenant ID, got %d", tenantID, n)
+		}
+	}
+	for _, tenantID := range sortedKeys(c.Tenant.StatementTimeoutOverrides) {
+		if d := c.Tenant.StatementTimeoutOverrides[tenantID]; tenantID == "" || d <= 0 {
+			problem("tenant.statement_timeout_overrides[%q] must be a positive duration for a tenant ID, got %v", tenantID, d)
+		}
+	}
+
+	if c.NATSURL != "" && !hasHost(c.NATSURL) {
+		problem("nats_url must be a URL with a host (e.g. nats://nats-service:4222)")
+	}
+	if c.RedisURL != "" && !hasHost(c.RedisURL) {
+		problem("redis_url must be a URL with a host (e.g. redis://redis-service:6379)")
+	}
+
+	if c.Auth.JWKSURL != "" && !isJWKSSource(c.Auth.JWKSURL) {
+		problem("auth.jwks_url must be an http(s) URL with a host, a file:// URL or a file path")
+	}
+	if c.Auth.JWTSecret == "" && c.Auth.JWKSURL == "" && !c.IsDevelopment() {
+		problem("auth.jwks_url or auth.jwt_secret is required outside development (JWT_JWKS_URL or SHARED_JWT_SECRET)")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkDatabaseURL validates a connection URL without echoing it (it carries a password)
+func checkDatabaseURL(dbURL string) error {
+	_, err := database.ConfigFromURL(dbURL)
+	if errors.Is(err, database.ErrInvalidDatabaseURL) {
+		return database.ErrInvalidDatabaseURL
+	}
+	return err
+}
+
+// sortedKeys returns the keys of m in order, so problems are listed deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasHost reports whether rawURL parses and names a host
+func hasHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Hostname() != ""
+}
+
+// isJWKSSource reports whether source is something middleware.NewKeySet can read
+func isJWKSSource(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Hostname() != ""
+	case "file":
+		return u.Path != ""
+	case "":
+		return true
+	}
+	return false
+}
+
+// IsDevelopment reports whether the configuration is for local development
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == "dev" || c.Environment == "development"
+}
+
+// PoolConfig converts the database settings into a database.Config
+// A URL without sslmode gets "disable" in development and "prefer" elsewhere.
+func (c *Config) PoolConfig() (*database.Config, error) {
+	d := c.Database
+	cfg, err := database.ConfigFromURL(d.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(d.URL); err == nil && u.Query().Get("sslmode") == "" {
+		cfg.SSLMode = "prefer"
+		if c.IsDevelopment() {
+			cfg.SSLMode = "disable"
+		}
+	}
+
+	cfg.ReplicaURLs = d.ReplicaURLs
+	cfg.MaxConns = d.MaxConns
+	cfg.MinConns = d.MinConns
+	cfg.MaxConnLifetime = d.MaxConnLifetime
+	cfg.MaxConnIdleTime = d.MaxConnIdleTime
+	cfg.ConnectTimeout = d.ConnectTimeout
+	cfg.QueryTimeout = d.QueryTimeout
+	cfg.MaxRetries = d.MaxRetries
+	cfg.RetryInterval = d.RetryInterval
+	cfg.MaxRetryInterval = d.MaxRetryInterval
+	cfg.MaxRetryElapsed = d.MaxRetryElapsed
+	cfg.BackgroundConnect = d.BackgroundConnect
+	cfg.SlowQueryThreshold = d.SlowQueryThreshold
+	cfg.MaxReplicaLag = d.MaxReplicaLag
+	cfg.ReplicaCheckInterval = d.ReplicaCheckInterval
+	return cfg, nil
+}
+
+// EnvParser overlays set environment variables and collects parse problems
+// Unset or empty variables leave dst unchanged.
+type EnvParser struct {
+	problems []string
+}
+
+// String overlays a string variable
+func (p *EnvParser) String(name string, dst *string) {
+	p.str(name, dst)
+}
+
+// Secret overlays a secret from <name>_FILE or SECRETS_DIR/<name>, else the variable
+func (p *EnvParser) Secret(name string, dst *string) {
+	p.str(name, dst)
+	value, ok, err := readSecretFile(name)
+	if err != nil {
+		p.problems = append(p.problems, err.Error())
+	} else if ok {
+		*dst = value
+	}
+}
+
+// Int overlays an integer variable
+func (p *EnvParser) Int(name string, dst *int) {
+	p.int(name, dst)
+}
+
+// Bool overlays a boolean variable
+func (p *EnvParser) Bool(name string, dst *bool) {
+	p.bool(name, dst)
+}
+
+// Duration overlays a duration variable (e.g. 30s, 5m)
+func (p *EnvParser) Duration(name string, dst *time.Duration) {
+	p.duration(name, dst)
+}
+
+// List overlays a comma-separated variable
+func (p *EnvParser) List(name string, dst *[]string) {
+	p.list(name, dst)
+}
+
+func (p *EnvParser) str(name string, dst *string) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		*dst = value
+	}
+}
+
+func (p *EnvParser) list(name string, dst *[]string) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		*dst = splitList(value)
+	}
+}
+
+func (p *EnvParser) int(name string, dst *int) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q is not an integer", name, value))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (p *EnvParser) int32(name string, dst *int32) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q is not an integer", name, value))
+			return
+		}
+		*dst = int32(n)
+	}
+}
+
+// intMap parses "<key>=<n>,..." into dst, replacing any earlier map
+func (p *EnvParser) intMap(name string, dst *map[string]int) {
+	pairs, ok := p.pairs(name)
+	if !ok {
+		return
+	}
+	parsed := make(map[string]int, len(pairs))
+	for key, value := range pairs {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q for %s is not an integer", name, value, key))
+			continue
+		}
+		parsed[key] = n
+	}
+	*dst = parsed
+}
+
+// durationMap parses "<key>=<duration>,..." into dst, replacing any earlier map
+func (p *EnvParser) durationMap(name string, dst *map[string]time.Duration) {
+	pairs, ok := p.pairs(name)
+	if !ok {
+		return
+	}
+	parsed := make(map[string]time.Duration, len(pairs))
+	for key, value := range pairs {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q for %s is not a duration (e.g. 30s, 5m)", name, value, key))
+			continue
+		}
+		parsed[key] = d
+	}
+	*dst = parsed
+}
+
+// pairs splits a set "<key>=<value>,..." variable, reporting entries without a key
+func (p *EnvParser) pairs(name string) (map[string]string, bool) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return nil, false
+	}
+	pairs := make(map[string]string)
+	for _, item := range splitList(value) {
+		key, val, found := strings.Cut(item, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found || key == "" {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q is not <key>=<value>", name, item))
+			continue
+		}
+		pairs[key] = val
+	}
+	return pairs, true
+}
+
+func (p *EnvParser) bool(name string, dst *bool) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q is not a boolean (true or false)", name, value))
+			return
+		}
+		*dst = b
+	}
+}
+
+func (p *EnvParser) duration(name string, dst *time.Duration) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			p.problems = append(p.problems, fmt.Sprintf("%s: %q is not a duration (e.g. 30s, 5m)", name, value))
+			return
+		}
+		*dst = d
+	}
+}
+
+// readSecretFile reads the secret for name from <name>_FILE or SECRETS_DIR/<name>
+// Kubernetes secret volumes end values with a newline, which is trimmed.
+func readSecretFile(name string) (string, bool, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		dir := os.Getenv("SECRETS_DIR")
+		if dir == "" {
+			return "", false, nil
+		}
+		path = filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: reading secret file: %v", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
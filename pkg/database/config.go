@@ -33,14 +33,70 @@ type Config struct {
 	ConnectTimeout time.Duration 	// Timeout for establishing connections
 	QueryTimeout time.Duration		// Timeout for individual queries
 	MaxRetries int					// Maximum number of connection retry attempts
-	RetryInterval time.Duration		// Duration between retry attempts
+	RetryInterval time.Duration		// Delay before the first retry; doubles with each retry
+	MaxRetryInterval time.Duration	// Cap on the delay between retries
+	MaxRetryElapsed time.Duration	// Stop retrying after this long (0 = attempts only)
+	BackgroundConnect bool			// Start degraded and reconnect in the background if the database is down
 	SSLMode string 					// SSL mode (disable, prefer, require)
+	SlowQueryThreshold time.Duration	// Log statements slower than this (0 disables)
+	ReplicaURLs []string			// Read replica connection strings (empty disables routing)
+	MaxReplicaLag time.Duration		// Replicas lagging further behind leave rotation
+	ReplicaCheckInterval time.Duration	// How often replica health and lag are checked
 }
 
 // LoadConfigFromEnv loads database configuration from environment variables
+// Services load it through config.Load, which also reads config files and mounted secrets.
 func LoadConfigFromEnv() (*Config, error) {
 	// Get database URL from environment
-	dbConnString := os.Getenv("DATABASE_URL")
+	config, err := ConfigFromURL(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional query timeout override (e.g. DB_QUERY_TIMEOUT=10s, 0 disables)
+	if timeout := os.Getenv("DB_QUERY_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
+		}
+		config.QueryTimeout = d
+	}
+
+	// Optional slow query logging (e.g. DB_SLOW_QUERY_THRESHOLD=500ms)
+	if threshold := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); threshold != "" {
+		d, err := time.ParseDuration(threshold)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_SLOW_QUERY_THRESHOLD: %w", err)
+		}
+		config.SlowQueryThreshold = d
+	}
+
+	// Optional read replicas (comma-separated URLs, same credentials format as DATABASE_URL)
+	for _, replicaURL := range strings.Split(os.Getenv("DATABASE_REPLICA_URLS"), ",") {
+		if replicaURL = strings.TrimSpace(replicaURL); replicaURL != "" {
+			config.ReplicaURLs = append(config.ReplicaURLs, replicaURL)
+		}
+	}
+	if lag := os.Getenv("DB_MAX_REPLICA_LAG"); lag != "" {
+		d, err := time.ParseDuration(lag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_MAX_REPLICA_LAG: %w", err)
+		}
+		config.MaxReplicaLag = d
+	}
+	if interval := os.Getenv("DB_REPLICA_CHECK_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_REPLICA_CHECK_INTERVAL: %w", err)
+		}
+		config.ReplicaCheckInterval = d
+	}
+
+	return config, nil
+}
+
+// ConfigFromURL parses a connection URL into a Config with default pool settings
+func ConfigFromURL(dbConnString string) (*Config, error) {
 	if dbConnString == "" {
 		return nil, ErrDatabaseURLRequired
 	}
@@ -89,7 +145,7 @@ func LoadConfigFromEnv() (*Config, error) {
 	}
 
 	// Create configuration with parsed values and reasonable defaults
-	config := Config{
+	return &Config{
 		Host:     			host,
 		Port:     			portInt,
 		Database: 			db,
@@ -102,11 +158,12 @@ func LoadConfigFromEnv() (*Config, error) {
 		ConnectTimeout: 	time.Second * 30,
 		QueryTimeout: 		time.Second * 30,
 		MaxRetries: 		5,
-		RetryInterval: 		time.Second * 10,
+		RetryInterval: 		DefaultRetryInterval,
+		MaxRetryInterval: 	DefaultMaxRetryInterval,
 		SSLMode: 			sslMode,
-	}
-
-	return &config, nil
+		MaxReplicaLag: 		DefaultMaxReplicaLag,
+		ReplicaCheckInterval: DefaultReplicaCheckInterval,
+	}, nil
 }
 
 // ConnectionString returns the connection string for pgx
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // HealthStatus represents the health status of the database
 type HealthStatus struct {
-	Healthy      bool          `json:"healthy"`
-	ResponseTime time.Duration `json:"response_time"`
-	Error        string        `json:"error,omitempty"`
-	Stats        *PoolStats    `json:"stats"`
+	Healthy      bool            `json:"healthy"`
+	ResponseTime time.Duration   `json:"response_time"`
+	Error        string          `json:"error,omitempty"`
+	Degraded     bool            `json:"degraded,omitempty"` // Reconnecting in the background
+	Stats        *PoolStats      `json:"stats"`
+	Replicas     []ReplicaStatus `json:"replicas,omitempty"`
 }
 
 // PoolStats represents connection pool statistics
@@ -38,14 +42,20 @@ func (p *Pool) HealthCheck(ctx context.Context) *HealthStatus {
 	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	// Replicas are reported but do not affect health; reads fall back to the primary
 	status := &HealthStatus{
-		Stats: p.getPoolStats(),
+		Stats:    p.getPoolStats(),
+		Replicas: p.Replicas(),
 	}
 
-	// Perform ping test
+	// Perform ping test; a lost database starts the background reconnect loop
 	if err := p.Ping(healthCtx); err != nil {
 		p.metrics.IncrementFailedHealthChecks()
+		if ctx.Err() == nil {
+			p.reconnect(err)
+		}
 		status.Healthy = false
+		status.Degraded = p.Degraded()
 		status.Error = fmt.Sprintf("ping failed: %v", err)
 		status.ResponseTime = time.Since(start)
 		p.metrics.UpdateLastHealthCheck()
@@ -85,7 +95,11 @@ func (p *Pool) HealthCheck(ctx context.Context) *HealthStatus {
 
 // getPoolStats converts pgxpool.Stat to our PoolStats structure
 func (p *Pool) getPoolStats() *PoolStats {
-	stats := p.Stat()
+	return poolStats(p.Stat())
+}
+
+// poolStats converts one pgxpool.Stat snapshot
+func poolStats(stats *pgxpool.Stat) *PoolStats {
 	return &PoolStats{
 		AcquireCount:         stats.AcquireCount(),
 		AcquireDuration:      stats.AcquireDuration().Nanoseconds(),
@@ -1,10 +1,16 @@
 package database
 
 import (
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// DefaultLatencyBuckets are the query latency histogram upper bounds, in seconds
+var DefaultLatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
 // Metrics holds database performance and usage metrics
 type Metrics struct {
 	// Connection metrics
@@ -15,17 +21,73 @@ type Metrics struct {
 	// Query metrics
 	TotalQueries        int64
 	FailedQueries       int64
+	TimedOutQueries     int64 // subset of FailedQueries
 	QueryDuration       int64 // nanoseconds
 	
 	// Health check metrics
 	HealthChecks        int64
 	FailedHealthChecks  int64
 	LastHealthCheck     int64 // unix timestamp
+
+	// Per-tenant scheduling metrics (snapshot, filled by GetMetrics)
+	Tenants map[string]TenantMetrics
+
+	tenants *tenantMetricsStore
+	latency *latencyStore
+}
+
+// TenantMetrics holds connection scheduling metrics for one tenant
+type TenantMetrics struct {
+	Acquired     int64 // Connection slots granted
+	Queued       int64 // Grants that had to wait in the tenant queue
+	Rejected     int64 // Operations refused (queue full, timeout or cancellation)
+	WaitDuration int64 // Total time spent queued, nanoseconds
+}
+
+// tenantMetricsStore guards the per-tenant counters
+type tenantMetricsStore struct {
+	mu      sync.Mutex
+	tenants map[string]*TenantMetrics
+}
+
+// QueryLatencyKey identifies one query latency histogram
+type QueryLatencyKey struct {
+	Query  string // sqlc query name ("" for ad-hoc SQL)
+	Tenant string // tenant ID ("" outside tenant context)
+}
+
+// LatencyHistogram is a snapshot of one query latency histogram
+type LatencyHistogram struct {
+	Buckets []float64 // Upper bounds in seconds
+	Counts  []int64   // Cumulative observations per bucket
+	Count   int64     // Total observations
+	Sum     float64   // Total latency in seconds
+}
+
+// latencyStore guards the per-query histograms
+// Tenants are only part of the key while byTenant is set, so without tenant labels
+// memory grows with the number of queries, not queries times tenants.
+type latencyStore struct {
+	mu       sync.Mutex
+	buckets  []float64
+	hists    map[QueryLatencyKey]*latencyHistogram
+	byTenant atomic.Bool
+}
+
+type latencyHistogram struct {
+	counts []int64 // per bucket, not cumulative; last entry is +Inf
+	sum    time.Duration
 }
 
 // NewMetrics creates a new Metrics instance
 func NewMetrics() *Metrics {
-	return &Metrics{}
+	return &Metrics{
+		tenants: &tenantMetricsStore{tenants: make(map[string]*TenantMetrics)},
+		latency: &latencyStore{
+			buckets: DefaultLatencyBuckets,
+			hists:   make(map[QueryLatencyKey]*latencyHistogram),
+		},
+	}
 }
 
 // IncrementConnections increments the total connections counter
@@ -53,11 +115,96 @@ func (m *Metrics) IncrementFailedQueries() {
 	atomic.AddInt64(&m.FailedQueries, 1)
 }
 
+// IncrementTimedOutQueries increments the timed out queries counter
+func (m *Metrics) IncrementTimedOutQueries() {
+	atomic.AddInt64(&m.TimedOutQueries, 1)
+}
+
 // AddQueryDuration adds to the total query duration
 func (m *Metrics) AddQueryDuration(duration time.Duration) {
 	atomic.AddInt64(&m.QueryDuration, duration.Nanoseconds())
 }
 
+// ObserveQueryLatency adds one query to the latency histogram for its query name and tenant
+func (m *Metrics) ObserveQueryLatency(key QueryLatencyKey, duration time.Duration) {
+	if m == nil || m.latency == nil {
+		return
+	}
+	m.latency.observe(key, duration)
+}
+
+// SetTenantLatency keys later latency observations by tenant (off by default)
+// NewExporter turns it on for ExporterOptions.TenantLabels.
+func (m *Metrics) SetTenantLatency(enabled bool) {
+	if m == nil || m.latency == nil {
+		return
+	}
+	m.latency.byTenant.Store(enabled)
+}
+
+// QueryLatency returns a snapshot of every query latency histogram
+func (m *Metrics) QueryLatency() map[QueryLatencyKey]LatencyHistogram {
+	if m == nil || m.latency == nil {
+		return map[QueryLatencyKey]LatencyHistogram{}
+	}
+	return m.latency.snapshot()
+}
+
+func (s *latencyStore) observe(key QueryLatencyKey, duration time.Duration) {
+	// First bucket whose bound holds the duration; len(buckets) is +Inf
+	idx := sort.SearchFloat64s(s.buckets, duration.Seconds())
+	if !s.byTenant.Load() {
+		key.Tenant = ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.hists[key]
+	if !ok {
+		h = &latencyHistogram{counts: make([]int64, len(s.buckets)+1)}
+		s.hists[key] = h
+	}
+	h.counts[idx]++
+	h.sum += duration
+}
+
+func (s *latencyStore) snapshot() map[QueryLatencyKey]LatencyHistogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[QueryLatencyKey]LatencyHistogram, len(s.hists))
+	for key, h := range s.hists {
+		snap := LatencyHistogram{
+			Buckets: s.buckets,
+			Counts:  make([]int64, len(s.buckets)),
+			Sum:     h.sum.Seconds(),
+		}
+		for i, n := range h.counts {
+			snap.Count += n
+			if i < len(s.buckets) {
+				snap.Counts[i] = snap.Count
+			}
+		}
+		result[key] = snap
+	}
+	return result
+}
+
+// QueryName extracts the sqlc query name from a "-- name: GetDeal :one" header
+// Returns "" for SQL without one.
+func QueryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if !strings.HasPrefix(sql, "-- name:") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(sql, "-- name:"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
 // IncrementHealthChecks increments the health checks counter
 func (m *Metrics) IncrementHealthChecks() {
 	atomic.AddInt64(&m.HealthChecks, 1)
@@ -73,6 +220,53 @@ func (m *Metrics) UpdateLastHealthCheck() {
 	atomic.StoreInt64(&m.LastHealthCheck, time.Now().Unix())
 }
 
+// RecordTenantAcquire counts a granted slot and the time the tenant waited for it
+func (m *Metrics) RecordTenantAcquire(tenantID string, wait time.Duration) {
+	if m == nil || m.tenants == nil {
+		return
+	}
+	m.tenants.update(tenantID, func(t *TenantMetrics) {
+		t.Acquired++
+		if wait > 0 {
+			t.Queued++
+			t.WaitDuration += wait.Nanoseconds()
+		}
+	})
+}
+
+// IncrementTenantRejections counts an operation refused by the tenant quota
+func (m *Metrics) IncrementTenantRejections(tenantID string) {
+	if m == nil || m.tenants == nil {
+		return
+	}
+	m.tenants.update(tenantID, func(t *TenantMetrics) {
+		t.Rejected++
+	})
+}
+
+func (s *tenantMetricsStore) update(tenantID string, fn func(*TenantMetrics)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenants[tenantID]
+	if !ok {
+		t = &TenantMetrics{}
+		s.tenants[tenantID] = t
+	}
+	fn(t)
+}
+
+func (s *tenantMetricsStore) snapshot() map[string]TenantMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]TenantMetrics, len(s.tenants))
+	for id, t := range s.tenants {
+		result[id] = *t
+	}
+	return result
+}
+
 // GetMetrics returns a copy of the current metrics
 func (m *Metrics) GetMetrics() Metrics {
 	return Metrics{
@@ -81,9 +275,19 @@ func (m *Metrics) GetMetrics() Metrics {
 		ActiveConnections:   atomic.LoadInt64(&m.ActiveConnections),
 		TotalQueries:        atomic.LoadInt64(&m.TotalQueries),
 		FailedQueries:       atomic.LoadInt64(&m.FailedQueries),
+		TimedOutQueries:     atomic.LoadInt64(&m.TimedOutQueries),
 		QueryDuration:       atomic.LoadInt64(&m.QueryDuration),
 		HealthChecks:        atomic.LoadInt64(&m.HealthChecks),
 		FailedHealthChecks:  atomic.LoadInt64(&m.FailedHealthChecks),
 		LastHealthCheck:     atomic.LoadInt64(&m.LastHealthCheck),
+		Tenants:             m.tenantSnapshot(),
+	}
+}
+
+// tenantSnapshot copies the per-tenant counters
+func (m *Metrics) tenantSnapshot() map[string]TenantMetrics {
+	if m.tenants == nil {
+		return map[string]TenantMetrics{}
 	}
+	return m.tenants.snapshot()
 }
\ No newline at end of file
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryEvent describes one statement run through the pool
+type QueryEvent struct {
+	SQL             string        // Statement text
+	Query           string        // sqlc query name ("" for ad-hoc SQL)
+	ArgsFingerprint string        // Keyed hash of the arguments; equal values give equal fingerprints in one process
+	ArgCount        int           // Number of arguments
+	TenantID        string        // Tenant the statement ran for ("" outside tenant context)
+	Start           time.Time     // When the statement was issued
+	Duration        time.Duration // Until the result was consumed (set before QueryEnd)
+	Err             error         // Error from the call, Scan or row iteration (set before QueryEnd)
+}
+
+// QueryObserver is notified about every statement the pool runs
+// QueryStart may return a derived context (e.g. carrying a span); the statement runs
+// with it and QueryEnd receives it. QueryEnd runs once the result is consumed:
+// after Scan for QueryRow and when the rows are closed for Query.
+type QueryObserver interface {
+	QueryStart(ctx context.Context, event *QueryEvent) context.Context
+	QueryEnd(ctx context.Context, event *QueryEvent)
+}
+
+// AddQueryObserver registers an observer for every subsequent statement
+func (p *Pool) AddQueryObserver(observer QueryObserver) {
+	p.observerMu.Lock()
+	defer p.observerMu.Unlock()
+
+	current := p.loadObservers()
+	next := make([]QueryObserver, 0, len(current)+1)
+	next = append(next, current...)
+	next = append(next, observer)
+	p.observers.Store(&next)
+}
+
+func (p *Pool) loadObservers() []QueryObserver {
+	if observers := p.observers.Load(); observers != nil {
+		return *observers
+	}
+	return nil
+}
+
+// QueryTrace follows one statement from the call until its result is consumed
+type QueryTrace struct {
+	pool      *Pool
+	ctx       context.Context
+	event     QueryEvent
+	observers []QueryObserver
+	cancel    context.CancelFunc
+	once      sync.Once
+}
+
+// TraceQuery starts tracking a statement and returns the context to run it with
+// The context carries the statement deadline (Config.QueryTimeout or WithQueryTimeout),
+// released when the trace ends. Query/QueryRow/Exec trace themselves; use this for
+// statements run on an acquired connection or transaction, and always End the trace
+// (or wrap its Rows/Row).
+func (p *Pool) TraceQuery(ctx context.Context, sql string, args []any, tenantID string) (context.Context, *QueryTrace) {
+	ctx, cancel := p.withStatementDeadline(ctx)
+
+	t := &QueryTrace{
+		cancel:    cancel,
+		pool:      p,
+		observers: p.loadObservers(),
+		event: QueryEvent{
+			SQL:             sql,
+			Query:           QueryName(sql),
+			ArgsFingerprint: ArgsFingerprint(args),
+			ArgCount:        len(args),
+			TenantID:        tenantID,
+			Start:           time.Now(),
+		},
+	}
+
+	for _, observer := range t.observers {
+		ctx = observer.QueryStart(ctx, &t.event)
+	}
+	t.ctx = ctx
+	return ctx, t
+}
+
+// End records the outcome; only the first call counts
+// pgx.ErrNoRows is a normal result, not a failure.
+func (t *QueryTrace) End(err error) {
+	t.once.Do(func() {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+		t.event.Duration = time.Since(t.event.Start)
+		t.event.Err = err
+
+		m := t.pool.metrics
+		m.IncrementQueries()
+		m.AddQueryDuration(t.event.Duration)
+		m.ObserveQueryLatency(QueryLatencyKey{Query: t.event.Query, Tenant: t.event.TenantID}, t.event.Duration)
+		if err != nil {
+			m.IncrementFailedQueries()
+		}
+		if IsQueryTimeoutCtx(t.ctx, err) {
+			m.IncrementTimedOutQueries()
+		}
+
+		for _, observer := range t.observers {
+			observer.QueryEnd(t.ctx, &t.event)
+		}
+		t.cancel()
+	})
+}
+
+// Rows wraps rows so the trace ends when iteration finishes or the rows are closed
+func (t *QueryTrace) Rows(rows pgx.Rows) pgx.Rows {
+	return &tracedRows{Rows: rows, trace: t}
+}
+
+// Row wraps a row so the trace ends after Scan, including errors only Scan reports
+func (t *QueryTrace) Row(row pgx.Row) pgx.Row {
+	return &tracedRow{Row: row, trace: t}
+}
+
+type tracedRows struct {
+	pgx.Rows
+	trace *QueryTrace
+}
+
+// Next advances the rows and ends the trace after the last row
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.trace.End(r.Rows.Err())
+	return false
+}
+
+// Close closes the rows and ends the trace
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.trace.End(r.Rows.Err())
+}
+
+type tracedRow struct {
+	pgx.Row
+	trace *QueryTrace
+}
+
+// Scan reads the row and ends the trace
+func (r *tracedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.trace.End(err)
+	return err
+}
+
+// fingerprintKey keys ArgsFingerprint; it is random per process so a fingerprint
+// found in a trace or log cannot be matched against guessed values offline
+var fingerprintKey = newFingerprintKey()
+
+func newFingerprintKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("database: failed to generate fingerprint key: %v", err))
+	}
+	return key
+}
+
+// ArgsFingerprint hashes query arguments so calls can be correlated without logging values
+// Fingerprints are only comparable within one process.
+func ArgsFingerprint(args []any) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	h := hmac.New(sha256.New, fingerprintKey)
+	for _, arg := range args {
+		fmt.Fprintf(h, "%T=%v;", arg, arg)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observerKey struct{}
+
+// recordingObserver keeps a copy of every event it sees
+// QueryStart tags the context so tests can check QueryEnd receives it.
+type recordingObserver struct {
+	mu      sync.Mutex
+	started []QueryEvent
+	ended   []QueryEvent
+	endCtx  []context.Context
+}
+
+func (o *recordingObserver) QueryStart(ctx context.Context, event *QueryEvent) context.Context {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.started = append(o.started, *event)
+	return context.WithValue(ctx, observerKey{}, len(o.started))
+}
+
+func (o *recordingObserver) QueryEnd(ctx context.Context, event *QueryEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ended = append(o.ended, *event)
+	o.endCtx = append(o.endCtx, ctx)
+}
+
+func TestArgsFingerprint(t *testing.T) {
+	assert.Empty(t, ArgsFingerprint(nil))
+
+	fingerprint := ArgsFingerprint([]any{"alice@example.com", 42})
+	assert.Len(t, fingerprint, 16)
+	assert.Equal(t, fingerprint, ArgsFingerprint([]any{"alice@example.com", 42}))
+	assert.NotEqual(t, fingerprint, ArgsFingerprint([]any{"bob@example.com", 42}))
+	assert.NotEqual(t, fingerprint, ArgsFingerprint([]any{"alice@example.com", "42"}))
+}
+
+func TestTraceQuery_NotifiesObservers(t *testing.T) {
+	pool := newUnreachablePool(t)
+	observer := &recordingObserver{}
+	pool.AddQueryObserver(observer)
+
+	sql := "-- name: GetDeal :one\nSELECT * FROM deals WHERE id = $1"
+	ctx, trace := pool.TraceQuery(context.Background(), sql, []any{int64(7)}, "tenant-a")
+	assert.Equal(t, 1, ctx.Value(observerKey{}), "the statement runs with the observer's context")
+	require.Len(t, observer.started, 1)
+	assert.Equal(t, "GetDeal", observer.started[0].Query)
+	assert.Equal(t, "tenant-a", observer.started[0].TenantID)
+	assert.Equal(t, 1, observer.started[0].ArgCount)
+	assert.Equal(t, ArgsFingerprint([]any{int64(7)}), observer.started[0].ArgsFingerprint)
+	assert.Empty(t, observer.ended)
+
+	trace.End(nil)
+	trace.End(errors.New("ignored"))
+
+	require.Len(t, observer.ended, 1, "only the first End counts")
+	assert.NoError(t, observer.ended[0].Err)
+	assert.Positive(t, observer.ended[0].Duration)
+	assert.Equal(t, 1, observer.endCtx[0].Value(observerKey{}))
+
+	metrics := pool.metrics.GetMetrics()
+	assert.Equal(t, int64(1), metrics.TotalQueries)
+	assert.Zero(t, metrics.FailedQueries)
+}
+
+func TestQueryTrace_End(t *testing.T) {
+	deadline := func() context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	cancelled := func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+	queryCanceled := &pgconn.PgError{Code: pgQueryCanceled, Message: "canceling statement due to statement timeout"}
+
+	tests := []struct {
+		name         string
+		ctx          func() context.Context
+		err          error
+		wantErr      bool
+		wantFailed   int64
+		wantTimedOut int64
+	}{
+		{name: "success", ctx: context.Background},
+		{name: "no rows is a result", ctx: context.Background, err: pgx.ErrNoRows},
+		{name: "wrapped no rows", ctx: context.Background, err: errors.Join(errors.New("get deal"), pgx.ErrNoRows)},
+		{name: "failure", ctx: context.Background, err: errors.New("syntax error"), wantErr: true, wantFailed: 1},
+		{name: "client deadline", ctx: deadline, err: context.DeadlineExceeded, wantErr: true, wantFailed: 1, wantTimedOut: 1},
+		{name: "statement_timeout", ctx: context.Background, err: queryCanceled, wantErr: true, wantFailed: 1, wantTimedOut: 1},
+		{name: "caller cancelled", ctx: cancelled, err: context.Canceled, wantErr: true, wantFailed: 1},
+		{name: "cancel request after caller cancelled", ctx: cancelled, err: queryCanceled, wantErr: true, wantFailed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newUnreachablePool(t)
+			observer := &recordingObserver{}
+			pool.AddQueryObserver(observer)
+
+			_, trace := pool.TraceQuery(tt.ctx(), "SELECT 1", nil, "")
+			trace.End(tt.err)
+
+			require.Len(t, observer.ended, 1)
+			if tt.wantErr {
+				assert.Equal(t, tt.err, observer.ended[0].Err)
+			} else {
+				assert.NoError(t, observer.ended[0].Err)
+			}
+
+			metrics := pool.metrics.GetMetrics()
+			assert.Equal(t, int64(1), metrics.TotalQueries)
+			assert.Equal(t, tt.wantFailed, metrics.FailedQueries)
+			assert.Equal(t, tt.wantTimedOut, metrics.TimedOutQueries)
+		})
+	}
+}
+
+func TestQueryTrace_AppliesQueryTimeout(t *testing.T) {
+	pool := newUnreachablePool(t)
+	pool.config.QueryTimeout = time.Minute
+
+	ctx, trace := pool.TraceQuery(context.Background(), "SELECT 1", nil, "")
+	_, ok := ctx.Deadline()
+	assert.True(t, ok, "statements run with the pool's query timeout")
+
+	trace.End(nil)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "ending the trace releases the deadline")
+}
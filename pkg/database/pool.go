@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -16,6 +18,7 @@ var (
 	ErrNilConfig          = fmt.Errorf("config cannot be nil")
 	ErrPoolConfigParse    = fmt.Errorf("unable to parse pool config")
 	ErrConnectionFailed   = fmt.Errorf("failed to create connection pool")
+	ErrReadOnly           = fmt.Errorf("commands cannot run through a read replica reader")
 )
 
 // Pool wraps pgxpool.Pool with additional functionality
@@ -23,6 +26,18 @@ type Pool struct {
 	*pgxpool.Pool
 	config  *Config
 	metrics *Metrics
+
+	observers  atomic.Pointer[[]QueryObserver]
+	observerMu sync.Mutex // serializes AddQueryObserver
+
+	replicas    []*replica
+	nextReplica atomic.Uint64 // round-robin cursor
+
+	reconnecting   atomic.Bool     // set while the database is unreachable
+	background     context.Context // lives until Close
+	stopBackground context.CancelFunc
+	workersMu      sync.Mutex     // orders goBackground against Close
+	workers        sync.WaitGroup // replica monitor and reconnect loop
 }
 
 // NewPool creates a new database connection pool
@@ -45,46 +60,90 @@ func NewPool(ctx context.Context, config *Config) (*Pool, error) {
 	pgxConfig.MaxConnIdleTime = config.MaxConnIdleTime
 	pgxConfig.ConnConfig.ConnectTimeout = config.ConnectTimeout
 
+	// Deadlines cancel the statement on the server, not just the client wait
+	pgxConfig.ConnConfig.BuildContextWatcherHandler = cancelRequestHandler
+
 	// Initialize metrics before attempting connection
 	metrics := NewMetrics()
 
-	// Create pool with retry logic
+	// Connect with exponential backoff; waits end as soon as ctx is canceled
 	var pool *pgxpool.Pool
-	for i := 0; i <= config.MaxRetries; i++ {
+	connect := func(ctx context.Context) error {
 		metrics.IncrementConnections()
-		pool, err = pgxpool.NewWithConfig(ctx, pgxConfig)
+		candidate, err := pgxpool.NewWithConfig(ctx, pgxConfig)
 		if err == nil {
-			err = pool.Ping(ctx)
+			err = candidate.Ping(ctx)
 			if err != nil {
-				pool.Close()
-				metrics.IncrementFailedConnections()
-			} else {
-				break
+				candidate.Close()
 			}
-		} else {
-			metrics.IncrementFailedConnections()
 		}
-		if i < config.MaxRetries { // Don't sleep after last attempt
-			log.Printf("Connection attempt %d failed: %v. Retrying in %v...\n",
-				i+1, err, config.RetryInterval)
-			time.Sleep(config.RetryInterval)
-		} else {
-			break
+		if err != nil {
+			metrics.IncrementFailedConnections()
+			return err
 		}
+		pool = candidate
+		return nil
 	}
-	if err != nil {
-		return nil, fmt.Errorf("%w after %d attempts: %v", ErrConnectionFailed, config.MaxRetries+1, err)
+
+	// In background mode one failed attempt is enough to start degraded
+	policy := config.RetryPolicy()
+	if config.BackgroundConnect {
+		policy.MaxAttempts = 1
+	}
+	connectErr := policy.Do(ctx, connect, func(attempt int, err error, wait time.Duration) {
+		log.Printf("Connection attempt %d failed: %v. Retrying in %v...\n",
+			attempt, err, wait.Round(time.Millisecond))
+	})
+	if connectErr != nil {
+		if !config.BackgroundConnect {
+			return nil, fmt.Errorf("%w: %w", ErrConnectionFailed, connectErr)
+		}
+
+		// pgxpool dials lazily, so the pool serves statements once the database is back
+		pool, err = pgxpool.NewWithConfig(context.Background(), pgxConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
 	}
 
+	background, stopBackground := context.WithCancel(context.Background())
 	customPool := &Pool{
-		Pool:    pool,           // Embed the pgxpool.Pool
-		config:  config,         // Store the config
-		metrics: metrics,	 	 // Initialize pool metrics
+		Pool:           pool,           // Embed the pgxpool.Pool
+		config:         config,         // Store the config
+		metrics:        metrics,        // Initialize pool metrics
+		background:     background,     // Canceled by Close to stop monitors and reconnects
+		stopBackground: stopBackground,
+	}
+
+	if connectErr != nil {
+		customPool.reconnect(connectErr)
 	}
 
 	// Update active connections count
 	customPool.updateActiveConnections()
 
+	// Read replicas join rotation once their first health check passes
+	if len(config.ReplicaURLs) > 0 {
+		replicas, err := connectReplicas(config)
+		if err != nil {
+			customPool.Close()
+			return nil, err
+		}
+		customPool.replicas = replicas
+		customPool.checkReplicas(ctx)
+
+		interval := config.ReplicaCheckInterval
+		if interval <= 0 {
+			interval = DefaultReplicaCheckInterval
+		}
+		customPool.goBackground(func() { customPool.monitorReplicas(interval) })
+	}
+
+	// Log statements slower than the configured threshold
+	if config.SlowQueryThreshold > 0 {
+		customPool.AddQueryObserver(NewSlowQueryLogger(config.SlowQueryThreshold, nil))
+	}
+
 	return customPool, nil
 }
 
@@ -92,10 +151,32 @@ func NewPool(ctx context.Context, config *Config) (*Pool, error) {
 func (p *Pool) Close() {
 	// Close the underlying pgxpool
 	log.Println("Closing connection pool...")
+	p.workersMu.Lock()
+	p.stopBackground()
+	p.workersMu.Unlock()
+	p.workers.Wait()
+	closeReplicas(p.replicas)
 	p.Pool.Close()
 	log.Println("Successfully closed connection pool.")
 }
 
+// goBackground runs fn in a worker Close waits for; it returns false once the pool is closing
+// Adding to the WaitGroup under workersMu keeps it from racing with Close's Wait.
+func (p *Pool) goBackground(fn func()) bool {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	if p.background.Err() != nil {
+		return false
+	}
+
+	p.workers.Add(1)
+	go func() {
+		defer p.workers.Done()
+		fn()
+	}()
+	return true
+}
+
 // Stats returns connection pool statistics
 func (p *Pool) Stats() *pgxpool.Stat {
 	return p.Stat()
@@ -106,56 +187,46 @@ func (p *Pool) GetMetrics() Metrics {
 	return p.metrics.GetMetrics()
 }
 
+// Metrics returns the live metrics collector for callers that record their own counters
+func (p *Pool) Metrics() *Metrics {
+	return p.metrics
+}
+
 // updateActiveConnections updates the active connections metric from pool stats
 func (p *Pool) updateActiveConnections() {
 	stats := p.Stat()
 	p.metrics.SetActiveConnections(int64(stats.AcquiredConns()))
 }
 
-// Query wraps pgxpool.Pool.Query with metrics tracking
+// Query wraps pgxpool.Pool.Query with metrics tracking and query observers
+// The query is observed until the rows are closed. It runs on the primary; use
+// Reader to serve reads from a replica.
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	start := time.Now()
-	p.metrics.IncrementQueries()
+	ctx, trace := p.TraceQuery(ctx, sql, args, "")
 
 	rows, err := p.Pool.Query(ctx, sql, args...)
-	duration := time.Since(start)
-	p.metrics.AddQueryDuration(duration)
-
 	if err != nil {
-		p.metrics.IncrementFailedQueries()
+		trace.End(err)
 		return nil, err
 	}
 
-	return rows, nil
+	return trace.Rows(rows), nil
 }
 
-// QueryRow wraps pgxpool.Pool.QueryRow with metrics tracking
+// QueryRow wraps pgxpool.Pool.QueryRow with metrics tracking and query observers
+// The query is observed until Scan, so errors reported by Scan count as failures.
+// It runs on the primary, like Query.
 func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	start := time.Now()
-	p.metrics.IncrementQueries()
-
-	row := p.Pool.QueryRow(ctx, sql, args...)
-	duration := time.Since(start)
-	p.metrics.AddQueryDuration(duration)
-
-	// Note: pgx.Row doesn't return errors until Scan() is called
-	// We can't track failures here, but we track the query attempt
-	return row
+	ctx, trace := p.TraceQuery(ctx, sql, args, "")
+	return trace.Row(p.Pool.QueryRow(ctx, sql, args...))
 }
 
-// Exec wraps pgxpool.Pool.Exec with metrics tracking
+// Exec wraps pgxpool.Pool.Exec with metrics tracking and query observers
+// Exec always runs on the primary.
 func (p *Pool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
-	start := time.Now()
-	p.metrics.IncrementQueries()
+	ctx, trace := p.TraceQuery(ctx, sql, args, "")
 
 	tag, err := p.Pool.Exec(ctx, sql, args...)
-	duration := time.Since(start)
-	p.metrics.AddQueryDuration(duration)
-
-	if err != nil {
-		p.metrics.IncrementFailedQueries()
-		return tag, err
-	}
-
-	return tag, nil
-}
\ No newline at end of file
+	trace.End(err)
+	return tag, err
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the Prometheus text exposition format (v0.0.4)
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ExporterOptions controls the labels attached to exported series
+type ExporterOptions struct {
+	Service      string // service label on every series, e.g. "deal-service"
+	QueryLabels  bool   // label latency histograms with the sqlc query name
+	TenantLabels bool   // label latency and scheduling series with the tenant ID (one series per tenant)
+}
+
+// Exporter publishes pool metrics and stats in Prometheus text format
+type Exporter struct {
+	pool *Pool
+	opts ExporterOptions
+}
+
+// NewExporter creates a /metrics handler for pool
+// Query latency is only recorded per tenant when opts.TenantLabels is set.
+func NewExporter(pool *Pool, opts ExporterOptions) *Exporter {
+	pool.metrics.SetTenantLatency(opts.TenantLabels)
+	return &Exporter{pool: pool, opts: opts}
+}
+
+// ServeHTTP writes the current metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", PrometheusContentType)
+	buf := bufio.NewWriter(w)
+	e.write(buf)
+	buf.Flush()
+}
+
+// write renders every metric family
+func (e *Exporter) write(w *bufio.Writer) {
+	m := e.pool.metrics.GetMetrics()
+	service := label{"service", e.opts.Service}
+
+	// Connection and query counters
+	writeFamily(w, "crm_db_connection_attempts_total", "counter", "Connection attempts made while creating the pool.")
+	writeSample(w, "crm_db_connection_attempts_total", float64(m.TotalConnections), service)
+	writeFamily(w, "crm_db_connection_failures_total", "counter", "Connection attempts that failed.")
+	writeSample(w, "crm_db_connection_failures_total", float64(m.FailedConnections), service)
+	writeFamily(w, "crm_db_queries_total", "counter", "Queries executed.")
+	writeSample(w, "crm_db_queries_total", float64(m.TotalQueries), service)
+	writeFamily(w, "crm_db_query_failures_total", "counter", "Queries that returned an error.")
+	writeSample(w, "crm_db_query_failures_total", float64(m.FailedQueries), service)
+	writeFamily(w, "crm_db_query_timeouts_total", "counter", "Queries stopped by a deadline or statement_timeout.")
+	writeSample(w, "crm_db_query_timeouts_total", float64(m.TimedOutQueries), service)
+
+	// Health checks
+	writeFamily(w, "crm_db_health_checks_total", "counter", "Database health checks run.")
+	writeSample(w, "crm_db_health_checks_total", float64(m.HealthChecks), service)
+	writeFamily(w, "crm_db_health_check_failures_total", "counter", "Database health checks that failed.")
+	writeSample(w, "crm_db_health_check_failures_total", float64(m.FailedHealthChecks), service)
+	writeFamily(w, "crm_db_last_health_check_timestamp_seconds", "gauge", "Unix time of the last completed health check.")
+	writeSample(w, "crm_db_last_health_check_timestamp_seconds", float64(m.LastHealthCheck), service)
+
+	e.writePoolStats(w, service)
+	e.writeLatency(w, service)
+	e.writeTenants(w, service, m.Tenants)
+}
+
+// writePoolStats exports pgxpool statistics
+func (e *Exporter) writePoolStats(w *bufio.Writer, service label) {
+	stats := e.pool.getPoolStats()
+
+	gauges := []struct {
+		name, help string
+		value      int32
+	}{
+		{"crm_db_pool_acquired_conns", "Connections currently checked out.", stats.AcquiredConns},
+		{"crm_db_pool_idle_conns", "Idle connections in the pool.", stats.IdleConns},
+		{"crm_db_pool_constructing_conns", "Connections being established.", stats.ConstructingConns},
+		{"crm_db_pool_total_conns", "Connections open in the pool.", stats.TotalConns},
+		{"crm_db_pool_max_conns", "Configured pool size.", stats.MaxConns},
+	}
+	for _, g := range gauges {
+		writeFamily(w, g.name, "gauge", g.help)
+		writeSample(w, g.name, float64(g.value), service)
+	}
+
+	counters := []struct {
+		name, help string
+		value      float64
+	}{
+		{"crm_db_pool_acquires_total", "Successful connection acquires.", float64(stats.AcquireCount)},
+		{"crm_db_pool_empty_acquires_total", "Acquires that waited because the pool was empty.", float64(stats.EmptyAcquireCount)},
+		{"crm_db_pool_canceled_acquires_total", "Acquires canceled by their context.", float64(stats.CanceledAcquireCount)},
+		{"crm_db_pool_acquire_seconds_total", "Total time spent acquiring connections.", float64(stats.AcquireDuration) / 1e9},
+	}
+	for _, c := range counters {
+		writeFamily(w, c.name, "counter", c.help)
+		writeSample(w, c.name, c.value, service)
+	}
+}
+
+// writeLatency exports query latency histograms, merging series for disabled labels
+func (e *Exporter) writeLatency(w *bufio.Writer, service label) {
+	const name = "crm_db_query_duration_seconds"
+
+	merged := make(map[QueryLatencyKey]LatencyHistogram)
+	for key, h := range e.pool.metrics.QueryLatency() {
+		if !e.opts.QueryLabels {
+			key.Query = ""
+		}
+		if !e.opts.TenantLabels {
+			key.Tenant = ""
+		}
+		merged[key] = mergeHistograms(merged[key], h)
+	}
+
+	writeFamily(w, name, "histogram", "Query latency.")
+	for _, key := range sortedLatencyKeys(merged) {
+		h := merged[key]
+		labels := []label{service}
+		if e.opts.QueryLabels {
+			labels = append(labels, label{"query", key.Query})
+		}
+		if e.opts.TenantLabels {
+			labels = append(labels, label{"tenant", key.Tenant})
+		}
+
+		for i, bound := range h.Buckets {
+			le := label{"le", strconv.FormatFloat(bound, 'g', -1, 64)}
+			writeSample(w, name+"_bucket", float64(h.Counts[i]), append(labels, le)...)
+		}
+		writeSample(w, name+"_bucket", float64(h.Count), append(labels, label{"le", "+Inf"})...)
+		writeSample(w, name+"_sum", h.Sum, labels...)
+		writeSample(w, name+"_count", float64(h.Count), labels...)
+	}
+}
+
+// writeTenants exports quota scheduling counters, summed across tenants unless TenantLabels is set
+func (e *Exporter) writeTenants(w *bufio.Writer, service label, tenants map[string]TenantMetrics) {
+	merged := make(map[string]TenantMetrics)
+	for tenantID, t := range tenants {
+		if !e.opts.TenantLabels {
+			tenantID = ""
+		}
+		sum := merged[tenantID]
+		sum.Acquired += t.Acquired
+		sum.Queued += t.Queued
+		sum.Rejected += t.Rejected
+		sum.WaitDuration += t.WaitDuration
+		merged[tenantID] = sum
+	}
+
+	ids := make([]string, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	families := []struct {
+		name, help string
+		value      func(TenantMetrics) float64
+	}{
+		{"crm_db_tenant_slots_acquired_total", "Tenant connection slots granted.", func(t TenantMetrics) float64 { return float64(t.Acquired) }},
+		{"crm_db_tenant_slots_queued_total", "Tenant connection slots granted after queueing.", func(t TenantMetrics) float64 { return float64(t.Queued) }},
+		{"crm_db_tenant_slot_wait_seconds_total", "Time tenants spent queued for a connection slot.", func(t TenantMetrics) float64 { return float64(t.WaitDuration) / 1e9 }},
+		{"crm_db_tenant_slot_rejections_total", "Operations rejected by tenant connection quotas.", func(t TenantMetrics) float64 { return float64(t.Rejected) }},
+	}
+	for _, f := range families {
+		writeFamily(w, f.name, "counter", f.help)
+		for _, id := range ids {
+			labels := []label{service}
+			if e.opts.TenantLabels {
+				labels = append(labels, label{"tenant", id})
+			}
+			writeSample(w, f.name, f.value(merged[id]), labels...)
+		}
+	}
+}
+
+// mergeHistograms adds b into a; a may be the zero value
+func mergeHistograms(a, b LatencyHistogram) LatencyHistogram {
+	if a.Buckets == nil {
+		a.Buckets = b.Buckets
+		a.Counts = make([]int64, len(b.Counts))
+	}
+	for i := range b.Counts {
+		a.Counts[i] += b.Counts[i]
+	}
+	a.Count += b.Count
+	a.Sum += b.Sum
+	return a
+}
+
+func sortedLatencyKeys(hists map[QueryLatencyKey]LatencyHistogram) []QueryLatencyKey {
+	keys := make([]QueryLatencyKey, 0, len(hists))
+	for key := range hists {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Query != keys[j].Query {
+			return keys[i].Query < keys[j].Query
+		}
+		return keys[i].Tenant < keys[j].Tenant
+	})
+	return keys
+}
+
+// label is one name="value" pair
+type label struct {
+	name, value string
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeFamily(w *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w *bufio.Writer, name string, value float64, labels ...label) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l.name, labelEscaper.Replace(l.value))
+		}
+		w.WriteByte('}')
+	}
+	fmt.Fprintf(w, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample is one parsed exposition line
+type sample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// scrape serves the exporter and parses its text exposition output
+// Every sample must belong to a family declared with # HELP and # TYPE before it.
+func scrape(t *testing.T, exporter *Exporter) (map[string]string, []sample) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, PrometheusContentType, recorder.Header().Get("Content-Type"))
+
+	types := make(map[string]string)
+	var samples []sample
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, kind, found := strings.Cut(rest, " ")
+			require.True(t, found, line)
+			types[name] = kind
+			continue
+		}
+
+		s := parseSample(t, line)
+		family := s.name
+		if types[family] == "" {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				if base, ok := strings.CutSuffix(s.name, suffix); ok && types[base] == "histogram" {
+					family = base
+				}
+			}
+		}
+		require.NotEmpty(t, types[family], "sample %s has no # TYPE", s.name)
+		samples = append(samples, s)
+	}
+	require.NoError(t, scanner.Err())
+	return types, samples
+}
+
+// parseSample parses name{label="value",...} value, unescaping label values
+func parseSample(t *testing.T, line string) sample {
+	t.Helper()
+	s := sample{labels: make(map[string]string)}
+
+	end := strings.IndexAny(line, "{ ")
+	require.Positive(t, end, line)
+	s.name, line = line[:end], line[end:]
+
+	if strings.HasPrefix(line, "{") {
+		line = line[1:]
+		for !strings.HasPrefix(line, "}") {
+			name, rest, found := strings.Cut(line, `="`)
+			require.True(t, found, line)
+
+			var value strings.Builder
+			i := 0
+			for ; rest[i] != '"'; i++ {
+				if rest[i] == '\\' {
+					i++
+					switch rest[i] {
+					case 'n':
+						value.WriteByte('\n')
+					case '\\', '"':
+						value.WriteByte(rest[i])
+					default:
+						t.Fatalf("bad escape in %q", line)
+					}
+					continue
+				}
+				value.WriteByte(rest[i])
+			}
+			s.labels[strings.TrimPrefix(name, ",")] = value.String()
+			line = rest[i+1:]
+		}
+		line = line[1:]
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimPrefix(line, " "), 64)
+	require.NoError(t, err, line)
+	s.value = value
+	return s
+}
+
+// find returns the samples named name whose labels include want
+func find(samples []sample, name string, want map[string]string) []sample {
+	var found []sample
+	for _, s := range samples {
+		if s.name != name {
+			continue
+		}
+		matches := true
+		for k, v := range want {
+			if s.labels[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+func TestExporter_LatencyHistogram(t *testing.T) {
+	pool := newUnreachablePool(t)
+	exporter := NewExporter(pool, ExporterOptions{Service: "deal-service", QueryLabels: true})
+	for _, d := range []time.Duration{2 * time.Millisecond, 20 * time.Millisecond, 20 * time.Second} {
+		pool.metrics.ObserveQueryLatency(QueryLatencyKey{Query: "GetDeal", Tenant: "tenant-a"}, d)
+	}
+
+	types, samples := scrape(t, exporter)
+	assert.Equal(t, "histogram", types["crm_db_query_duration_seconds"])
+	assert.Equal(t, "counter", types["crm_db_queries_total"])
+	assert.Equal(t, "gauge", types["crm_db_pool_max_conns"])
+
+	query := map[string]string{"service": "deal-service", "query": "GetDeal"}
+	buckets := find(samples, "crm_db_query_duration_seconds_bucket", query)
+	require.Len(t, buckets, len(DefaultLatencyBuckets)+1)
+
+	counts := make(map[string]float64)
+	previous := 0.0
+	for _, b := range buckets {
+		assert.NotContains(t, b.labels, "tenant")
+		assert.GreaterOrEqual(t, b.value, previous, "buckets are cumulative")
+		previous = b.value
+		counts[b.labels["le"]] = b.value
+	}
+	assert.Equal(t, 0.0, counts["0.001"])
+	assert.Equal(t, 1.0, counts["0.0025"])
+	assert.Equal(t, 2.0, counts["0.025"])
+	assert.Equal(t, 2.0, counts["10"])
+	assert.Equal(t, 3.0, counts["+Inf"])
+	assert.Equal(t, "+Inf", buckets[len(buckets)-1].labels["le"])
+
+	sum := find(samples, "crm_db_query_duration_seconds_sum", query)
+	require.Len(t, sum, 1)
+	assert.InDelta(t, 20.022, sum[0].value, 1e-9)
+	count := find(samples, "crm_db_query_duration_seconds_count", query)
+	require.Len(t, count, 1)
+	assert.Equal(t, 3.0, count[0].value)
+}
+
+func TestExporter_TenantLabels(t *testing.T) {
+	tests := []struct {
+		name         string
+		tenantLabels bool
+		wantSeries   int
+	}{
+		{name: "merged without tenant labels", wantSeries: 1},
+		{name: "one series per tenant", tenantLabels: true, wantSeries: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newUnreachablePool(t)
+			exporter := NewExporter(pool, ExporterOptions{Service: "deal-service", TenantLabels: tt.tenantLabels})
+			for _, tenantID := range []string{"tenant-a", "tenant-b"} {
+				pool.metrics.ObserveQueryLatency(QueryLatencyKey{Query: "GetDeal", Tenant: tenantID}, time.Millisecond)
+				pool.metrics.RecordTenantAcquire(tenantID, time.Second)
+			}
+			assert.Len(t, pool.metrics.QueryLatency(), tt.wantSeries, "latency is only stored per tenant when labelled")
+
+			_, samples := scrape(t, exporter)
+			counts := find(samples, "crm_db_query_duration_seconds_count", nil)
+			require.Len(t, counts, tt.wantSeries)
+			waits := find(samples, "crm_db_tenant_slot_wait_seconds_total", nil)
+			require.Len(t, waits, tt.wantSeries)
+
+			if tt.tenantLabels {
+				assert.Equal(t, "tenant-a", counts[0].labels["tenant"])
+				assert.Equal(t, 1.0, counts[0].value)
+				assert.Equal(t, 1.0, waits[0].value)
+			} else {
+				assert.NotContains(t, counts[0].labels, "tenant")
+				assert.Equal(t, 2.0, counts[0].value)
+				assert.Equal(t, 2.0, waits[0].value)
+			}
+		})
+	}
+}
+
+func TestExporter_EscapesLabelValues(t *testing.T) {
+	pool := newUnreachablePool(t)
+	service := "deal \"blue\"\\green\nservice"
+	exporter := NewExporter(pool, ExporterOptions{Service: service})
+
+	_, samples := scrape(t, exporter)
+	queries := find(samples, "crm_db_queries_total", nil)
+	require.Len(t, queries, 1)
+	assert.Equal(t, service, queries[0].labels["service"])
+}
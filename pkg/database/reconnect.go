@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Degraded reports whether the pool is waiting for the database to come back
+// Statements still run (and fail fast) while degraded; pgxpool dials on demand.
+func (p *Pool) Degraded() bool {
+	return p.reconnecting.Load()
+}
+
+// reconnect pings the database with backoff until it answers or the pool closes
+// Only one reconnect loop runs at a time; later calls while it runs are no-ops.
+func (p *Pool) reconnect(cause error) {
+	if !p.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+	log.Printf("Database unavailable, reconnecting in background: %v", cause)
+
+	// Retry until the database answers; only Close stops the loop
+	policy := p.config.RetryPolicy()
+	policy.MaxAttempts = 0
+	policy.MaxElapsed = 0
+
+	started := p.goBackground(func() {
+		defer p.reconnecting.Store(false)
+
+		err := policy.Do(p.background, p.pingOnce, func(attempt int, err error, wait time.Duration) {
+			log.Printf("Reconnect attempt %d failed: %v. Retrying in %v...", attempt, err, wait.Round(time.Millisecond))
+		})
+		if err != nil {
+			return // pool closed
+		}
+
+		// Drop connections that broke during the outage
+		p.Pool.Reset()
+		log.Println("Database connection restored")
+	})
+	if !started {
+		p.reconnecting.Store(false) // pool closed
+	}
+}
+
+// pingOnce makes one connection attempt bounded by ConnectTimeout
+func (p *Pool) pingOnce(ctx context.Context) error {
+	if p.config.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.ConnectTimeout)
+		defer cancel()
+	}
+
+	p.metrics.IncrementConnections()
+	if err := p.Pool.Ping(ctx); err != nil {
+		p.metrics.IncrementFailedConnections()
+		return err
+	}
+	return nil
+}
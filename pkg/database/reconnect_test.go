@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnreachablePool returns a pool whose database never answers
+// pgxpool dials lazily, so creating it needs no server.
+func newUnreachablePool(t *testing.T) *Pool {
+	t.Helper()
+	pgxConfig, err := pgxpool.ParseConfig("postgres://crm@127.0.0.1:1/crm?sslmode=disable")
+	require.NoError(t, err)
+	pgxPool, err := pgxpool.NewWithConfig(context.Background(), pgxConfig)
+	require.NoError(t, err)
+
+	background, stopBackground := context.WithCancel(context.Background())
+	return &Pool{
+		Pool: pgxPool,
+		config: &Config{
+			RetryInterval:    time.Millisecond,
+			MaxRetryInterval: 5 * time.Millisecond,
+			ConnectTimeout:   100 * time.Millisecond,
+		},
+		metrics:        NewMetrics(),
+		background:     background,
+		stopBackground: stopBackground,
+	}
+}
+
+func TestReconnect_RetriesUntilClose(t *testing.T) {
+	pool := newUnreachablePool(t)
+
+	pool.reconnect(errors.New("connection refused"))
+	assert.True(t, pool.Degraded())
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&pool.metrics.FailedConnections) >= 3
+	}, 2*time.Second, time.Millisecond, "reconnect keeps pinging with backoff")
+
+	pool.reconnect(errors.New("still down")) // no second loop while one runs
+	pool.Close()
+	assert.False(t, pool.Degraded())
+}
+
+func TestReconnect_AfterCloseIsNoop(t *testing.T) {
+	pool := newUnreachablePool(t)
+	pool.Close()
+
+	pool.reconnect(errors.New("connection refused"))
+	assert.False(t, pool.Degraded())
+	assert.Zero(t, atomic.LoadInt64(&pool.metrics.TotalConnections))
+}
+
+func TestReconnect_ConcurrentWithClose(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pool := newUnreachablePool(t)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pool.reconnect(errors.New("connection refused"))
+			}()
+		}
+		pool.Close()
+		wg.Wait()
+
+		// A loop started before Close has exited; none can start after it
+		assert.False(t, pool.Degraded())
+	}
+}
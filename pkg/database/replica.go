@@ -0,0 +1,343 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Replica defaults
+const (
+	DefaultMaxReplicaLag        = 10 * time.Second
+	DefaultReplicaCheckInterval = 5 * time.Second
+)
+
+// replicaLagSQL reports whether the server is a standby, whether its WAL receiver is
+// running (pg_stat_wal_receiver only has a row while it is) and the replay lag in seconds.
+// Lag is 0 when everything received has been replayed, and NULL when nothing has been
+// replayed yet, so it is unknown.
+const replicaLagSQL = `SELECT
+	pg_is_in_recovery(),
+	EXISTS (SELECT 1 FROM pg_stat_wal_receiver),
+	CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END::float8`
+
+// ReplicaStatus reports the routing state of one read replica
+type ReplicaStatus struct {
+	Name      string        `json:"name"`
+	Healthy   bool          `json:"healthy"`
+	Lag       time.Duration `json:"lag"`
+	LastCheck time.Time     `json:"last_check"`
+	Error     string        `json:"error,omitempty"`
+	Stats     *PoolStats    `json:"stats"`
+}
+
+// replica is one read-only pool and its last observed health
+type replica struct {
+	name string
+	pool *pgxpool.Pool
+
+	healthy atomic.Bool
+	mu      sync.Mutex // guards the fields below
+	lag     time.Duration
+	checked time.Time
+	lastErr string
+}
+
+// Reader runs queries on a healthy read replica, falling back to the primary
+// Get one from Pool.Reader for reads that tolerate replication lag (MaxReplicaLag);
+// everything else the pool runs stays on the primary. Reader satisfies the sqlc
+// DBTX interface, but Exec always fails with ErrReadOnly.
+type Reader struct {
+	pool *Pool
+}
+
+// Reader returns the pool's read-replica view
+func (p *Pool) Reader() *Reader {
+	return &Reader{pool: p}
+}
+
+// Query runs a query on a healthy replica, retrying on the primary if the replica cannot be reached
+// The query is observed until the rows are closed, like Pool.Query.
+func (r *Reader) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, trace := r.pool.TraceQuery(ctx, sql, args, "")
+
+	reader, replica := r.pool.reader()
+	rows, err := reader.Query(ctx, sql, args...)
+	if isReplicaFailover(replica, err) {
+		r.pool.failReplica(replica, err)
+		rows, err = r.pool.Pool.Query(ctx, sql, args...)
+	}
+	if err != nil {
+		trace.End(err)
+		return nil, err
+	}
+
+	return trace.Rows(rows), nil
+}
+
+// QueryRow runs a single-row query on a healthy replica, like Query
+func (r *Reader) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, trace := r.pool.TraceQuery(ctx, sql, args, "")
+
+	if replica := r.pool.pickReplica(); replica != nil {
+		return trace.Row(&replicaRow{pool: r.pool, replica: replica, ctx: ctx, sql: sql, args: args})
+	}
+	return trace.Row(r.pool.Pool.QueryRow(ctx, sql, args...))
+}
+
+// Exec refuses to run commands; send them through Pool.Exec
+func (r *Reader) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, ErrReadOnly
+}
+
+// replicaRow runs a QueryRow on a replica at Scan time, retrying on the primary if the replica fails
+type replicaRow struct {
+	pool    *Pool
+	replica *replica
+	ctx     context.Context
+	sql     string
+	args    []any
+}
+
+// Scan reads the row from the replica or, after a connection failure, the primary
+func (r *replicaRow) Scan(dest ...any) error {
+	err := r.replica.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	if isReplicaFailover(r.replica, err) {
+		r.pool.failReplica(r.replica, err)
+		err = r.pool.Pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return err
+}
+
+// BeginTx starts a transaction; read-only transactions go to a healthy replica
+// Every other transaction, like Begin and Acquire, uses the primary.
+func (p *Pool) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	if opts.AccessMode == pgx.ReadOnly {
+		if r := p.pickReplica(); r != nil {
+			tx, err := r.pool.BeginTx(ctx, opts)
+			if err == nil {
+				return tx, nil
+			}
+			p.failReplica(r, err)
+		}
+	}
+	return p.Pool.BeginTx(ctx, opts)
+}
+
+// Replicas returns the routing state of every configured replica
+func (p *Pool) Replicas() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		r.mu.Lock()
+		status := ReplicaStatus{
+			Name:      r.name,
+			Healthy:   r.healthy.Load(),
+			Lag:       r.lag,
+			LastCheck: r.checked,
+			Error:     r.lastErr,
+		}
+		r.mu.Unlock()
+		status.Stats = poolStats(r.pool.Stat())
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// reader returns the pool that should serve a Reader statement
+func (p *Pool) reader() (*pgxpool.Pool, *replica) {
+	if r := p.pickReplica(); r != nil {
+		return r.pool, r
+	}
+	return p.Pool, nil
+}
+
+// pickReplica returns the next healthy replica round-robin, or nil for the primary
+// The cursor counts over the healthy replicas only, so an unhealthy one's share is
+// spread evenly instead of all landing on its neighbour.
+func (p *Pool) pickReplica() *replica {
+	healthy := 0
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return nil
+	}
+
+	target := int(p.nextReplica.Add(1) % uint64(healthy))
+	var last *replica
+	for _, r := range p.replicas {
+		if !r.healthy.Load() {
+			continue
+		}
+		if target == 0 {
+			return r
+		}
+		target--
+		last = r
+	}
+	// A replica left rotation between the two passes
+	return last
+}
+
+// failReplica takes a replica out of rotation after a connection-level error
+// Statement errors (a PgError) say nothing about replica health and are ignored.
+func (p *Pool) failReplica(r *replica, err error) {
+	if !isReplicaFailover(r, err) {
+		return
+	}
+
+	if r.healthy.Swap(false) {
+		log.Printf("Read replica %s failed, routing reads to primary: %v", r.name, err)
+	}
+	r.mu.Lock()
+	r.lastErr = err.Error()
+	r.mu.Unlock()
+}
+
+// isReplicaFailover reports whether a failed replica call should be retried on the primary
+func isReplicaFailover(r *replica, err error) bool {
+	var pgErr *pgconn.PgError
+	return r != nil && err != nil && !errors.As(err, &pgErr) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// connectReplicas opens a lazily connected pool per replica URL
+// Replicas that cannot be reached start unhealthy; reads use the primary until they recover.
+func connectReplicas(config *Config) ([]*replica, error) {
+	replicas := make([]*replica, 0, len(config.ReplicaURLs))
+	for _, replicaURL := range config.ReplicaURLs {
+		pgxConfig, err := pgxpool.ParseConfig(replicaURL)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("%w: replica: %v", ErrPoolConfigParse, err)
+		}
+
+		pgxConfig.MaxConns = config.MaxConns
+		pgxConfig.MinConns = 0
+		pgxConfig.MaxConnLifetime = config.MaxConnLifetime
+		pgxConfig.MaxConnIdleTime = config.MaxConnIdleTime
+		pgxConfig.ConnConfig.ConnectTimeout = config.ConnectTimeout
+		pgxConfig.ConnConfig.BuildContextWatcherHandler = cancelRequestHandler
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), pgxConfig)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("%w: replica: %v", ErrConnectionFailed, err)
+		}
+
+		replicas = append(replicas, &replica{name: replicaName(replicaURL), pool: pool})
+	}
+	return replicas, nil
+}
+
+func closeReplicas(replicas []*replica) {
+	for _, r := range replicas {
+		r.pool.Close()
+	}
+}
+
+// replicaName identifies a replica by host:port/database without credentials
+func replicaName(replicaURL string) string {
+	u, err := url.Parse(replicaURL)
+	if err != nil {
+		return "replica"
+	}
+	return u.Host + u.Path
+}
+
+// monitorReplicas checks replica health and lag until the pool closes
+func (p *Pool) monitorReplicas(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.background.Done():
+			return
+		case <-ticker.C:
+			p.checkReplicas(p.background)
+		}
+	}
+}
+
+// replicaLag measures how far a replica's replay is behind
+func replicaLag(ctx context.Context, pool *pgxpool.Pool) (time.Duration, error) {
+	var inRecovery, receiving bool
+	var lagSeconds *float64
+	if err := pool.QueryRow(ctx, replicaLagSQL).Scan(&inRecovery, &receiving, &lagSeconds); err != nil {
+		return 0, err
+	}
+	return standbyLag(inRecovery, receiving, lagSeconds)
+}
+
+// standbyLag interprets the replicaLagSQL result
+// A server that is not streaming from the primary (promoted, or with its WAL receiver
+// down) has caught up with nothing, so it reports an error instead of zero lag.
+func standbyLag(inRecovery, receiving bool, lagSeconds *float64) (time.Duration, error) {
+	switch {
+	case !inRecovery:
+		return 0, fmt.Errorf("server is not in recovery (promoted or not a standby)")
+	case !receiving:
+		return 0, fmt.Errorf("WAL receiver is not running, replica is not streaming from the primary")
+	case lagSeconds == nil:
+		return 0, fmt.Errorf("replication lag unknown, no transaction replayed yet")
+	}
+	return time.Duration(*lagSeconds * float64(time.Second)), nil
+}
+
+// checkReplicas updates every replica's health; lagging replicas leave rotation
+func (p *Pool) checkReplicas(ctx context.Context) {
+	maxLag := p.config.MaxReplicaLag
+	if maxLag <= 0 {
+		maxLag = DefaultMaxReplicaLag
+	}
+
+	for _, r := range p.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		lag, err := replicaLag(checkCtx, r.pool)
+		cancel()
+
+		r.record(lag, err, maxLag)
+	}
+}
+
+// record applies one lag check: the replica is healthy when it succeeded within maxLag
+func (r *replica) record(lag time.Duration, err error, maxLag time.Duration) {
+	healthy := err == nil && lag <= maxLag
+
+	r.mu.Lock()
+	r.checked = time.Now()
+	r.lag = lag
+	switch {
+	case err != nil:
+		r.lastErr = err.Error()
+	case !healthy:
+		r.lastErr = fmt.Sprintf("replication lag %v exceeds %v", lag.Round(time.Millisecond), maxLag)
+	default:
+		r.lastErr = ""
+	}
+	lastErr := r.lastErr
+	r.mu.Unlock()
+
+	if was := r.healthy.Swap(healthy); was != healthy {
+		if healthy {
+			log.Printf("Read replica %s healthy, routing reads to it", r.name)
+		} else {
+			log.Printf("Read replica %s unhealthy, routing reads to primary: %s", r.name, lastErr)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addReplica attaches a lazily connected replica nothing listens for
+func addReplica(t *testing.T, pool *Pool, name string, healthy bool) *replica {
+	t.Helper()
+	replicaPool, err := pgxpool.New(context.Background(), "postgres://replica@127.0.0.1:1/crm?sslmode=disable")
+	require.NoError(t, err)
+	t.Cleanup(replicaPool.Close)
+
+	r := &replica{name: name, pool: replicaPool}
+	r.healthy.Store(healthy)
+	pool.replicas = append(pool.replicas, r)
+	return r
+}
+
+func seconds(s float64) *float64 {
+	return &s
+}
+
+func TestStandbyLag(t *testing.T) {
+	tests := []struct {
+		name       string
+		inRecovery bool
+		receiving  bool
+		lagSeconds *float64
+		wantLag    time.Duration
+		wantErr    string
+	}{
+		{name: "streaming", inRecovery: true, receiving: true, lagSeconds: seconds(2.5), wantLag: 2500 * time.Millisecond},
+		{name: "caught up", inRecovery: true, receiving: true, lagSeconds: seconds(0)},
+		{name: "promoted", receiving: true, lagSeconds: seconds(0), wantErr: "not in recovery"},
+		{name: "WAL receiver down", inRecovery: true, lagSeconds: seconds(0), wantErr: "WAL receiver is not running"},
+		{name: "nothing replayed yet", inRecovery: true, receiving: true, wantErr: "lag unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lag, err := standbyLag(tt.inRecovery, tt.receiving, tt.lagSeconds)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLag, lag)
+		})
+	}
+}
+
+func TestReplicaRecord(t *testing.T) {
+	pool := newUnreachablePool(t)
+	r := addReplica(t, pool, "replica-1", false)
+	maxLag := 10 * time.Second
+
+	r.record(2*time.Second, nil, maxLag)
+	status := pool.Replicas()[0]
+	assert.True(t, status.Healthy, "a replica joins rotation once a check passes")
+	assert.Equal(t, 2*time.Second, status.Lag)
+	assert.Empty(t, status.Error)
+	assert.False(t, status.LastCheck.IsZero())
+
+	r.record(maxLag, nil, maxLag)
+	assert.True(t, r.healthy.Load(), "lag equal to the maximum is tolerated")
+
+	r.record(30*time.Second, nil, maxLag)
+	status = pool.Replicas()[0]
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "replication lag 30s exceeds 10s", status.Error)
+
+	r.record(0, nil, maxLag)
+	assert.True(t, r.healthy.Load(), "a lagging replica returns once it catches up")
+
+	_, promoted := standbyLag(false, true, seconds(0))
+	r.record(0, promoted, maxLag)
+	status = pool.Replicas()[0]
+	assert.False(t, status.Healthy, "a promoted replica leaves rotation")
+	assert.Contains(t, status.Error, "not in recovery")
+}
+
+func TestPickReplica(t *testing.T) {
+	pool := newUnreachablePool(t)
+	assert.Nil(t, pool.pickReplica(), "no replicas configured")
+
+	first := addReplica(t, pool, "replica-1", true)
+	addReplica(t, pool, "replica-2", false)
+	third := addReplica(t, pool, "replica-3", true)
+
+	picked := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		r := pool.pickReplica()
+		require.NotNil(t, r)
+		picked[r.name]++
+	}
+	assert.Equal(t, map[string]int{"replica-1": 5, "replica-3": 5}, picked, "healthy replicas are picked round-robin")
+
+	first.healthy.Store(false)
+	third.healthy.Store(false)
+	assert.Nil(t, pool.pickReplica(), "reads go to the primary with no healthy replica")
+}
+
+func TestFailReplica(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantHealthy bool
+	}{
+		{name: "connection failure", err: errors.New("dial tcp 127.0.0.1:1: connect: connection refused")},
+		{name: "wrapped connection failure", err: fmt.Errorf("acquire: %w", errors.New("connection reset by peer"))},
+		{name: "SQL error", err: &pgconn.PgError{Code: "42P01", Message: `relation "deals" does not exist`}, wantHealthy: true},
+		{name: "caller cancelled", err: context.Canceled, wantHealthy: true},
+		{name: "deadline", err: context.DeadlineExceeded, wantHealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newUnreachablePool(t)
+			r := addReplica(t, pool, "replica-1", true)
+
+			pool.failReplica(r, tt.err)
+
+			status := pool.Replicas()[0]
+			assert.Equal(t, tt.wantHealthy, status.Healthy)
+			if !tt.wantHealthy {
+				assert.Equal(t, tt.err.Error(), status.Error)
+			}
+		})
+	}
+}
+
+func TestBeginTx_OnlyReadOnlyTransactionsUseReplicas(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool := newUnreachablePool(t)
+	r := addReplica(t, pool, "replica-1", true)
+
+	_, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	require.Error(t, err)
+	_, err = pool.Begin(ctx)
+	require.Error(t, err)
+	_, err = pool.Acquire(ctx)
+	require.Error(t, err)
+	assert.True(t, r.healthy.Load(), "read-write work never touches a replica")
+
+	_, err = pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	require.Error(t, err, "the primary is unreachable too")
+	assert.False(t, r.healthy.Load(), "the failed replica leaves rotation before the primary is tried")
+}
+
+func TestReader_FailsOverToPrimary(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool := newUnreachablePool(t)
+	r := addReplica(t, pool, "replica-1", true)
+
+	var one int
+	_, err := pool.Query(ctx, "SELECT 1")
+	require.Error(t, err)
+	require.Error(t, pool.QueryRow(ctx, "SELECT 1").Scan(&one))
+	assert.True(t, r.healthy.Load(), "pool queries run on the primary")
+
+	_, err = pool.Reader().Query(ctx, "SELECT 1")
+	require.Error(t, err)
+	assert.False(t, r.healthy.Load())
+
+	r.healthy.Store(true)
+	require.Error(t, pool.Reader().QueryRow(ctx, "SELECT 1").Scan(&one))
+	assert.False(t, r.healthy.Load())
+
+	metrics := pool.metrics.GetMetrics()
+	assert.Equal(t, int64(4), metrics.TotalQueries, "a failed-over read is one query")
+}
+
+func TestReader_RejectsCommands(t *testing.T) {
+	pool := newUnreachablePool(t)
+	addReplica(t, pool, "replica-1", true)
+
+	_, err := pool.Reader().Exec(context.Background(), "DELETE FROM deals")
+	assert.ErrorIs(t, err, ErrReadOnly)
+}
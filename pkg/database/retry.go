@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrRetriesExhausted is returned when a RetryPolicy runs out of attempts or time
+var ErrRetriesExhausted = fmt.Errorf("retries exhausted")
+
+// Retry defaults
+const (
+	DefaultRetryInterval    = time.Second
+	DefaultMaxRetryInterval = 30 * time.Second
+)
+
+// RetryPolicy retries an operation with exponential backoff and jitter
+type RetryPolicy struct {
+	InitialInterval time.Duration // Delay before the first retry
+	MaxInterval     time.Duration // Cap on any single delay (0 = uncapped)
+	Multiplier      float64       // Growth per retry; 2 doubles the delay
+	Jitter          float64       // Random spread as a fraction of the delay (0.2 = ±20%)
+	MaxAttempts     int           // Attempts including the first (0 = unlimited)
+	MaxElapsed      time.Duration // Give up once the next delay would pass this (0 = unlimited)
+}
+
+// DefaultRetryPolicy returns 6 attempts starting at 1s, doubling up to 30s, ±20% jitter
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialInterval: DefaultRetryInterval,
+		MaxInterval:     DefaultMaxRetryInterval,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxAttempts:     6,
+	}
+}
+
+// Backoff returns the jittered delay before retry n (0-based)
+func (r RetryPolicy) Backoff(n int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(r.InitialInterval) * math.Pow(multiplier, float64(n))
+	if r.MaxInterval > 0 && delay > float64(r.MaxInterval) {
+		delay = float64(r.MaxInterval)
+	}
+	if r.Jitter > 0 {
+		delay += delay * r.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// Do runs op until it succeeds, the policy gives up or ctx is done
+// notify, if set, is called before each wait. Waiting stops as soon as ctx is
+// canceled; the returned error then wraps ctx.Err() and the last failure.
+func (r RetryPolicy) Do(ctx context.Context, op func(ctx context.Context) error, notify func(attempt int, err error, wait time.Duration)) error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w after %d attempts: %v", ctx.Err(), attempt, err)
+		}
+		if r.MaxAttempts > 0 && attempt >= r.MaxAttempts {
+			return fmt.Errorf("%w after %d attempts: %v", ErrRetriesExhausted, attempt, err)
+		}
+
+		wait := r.Backoff(attempt - 1)
+		if r.MaxElapsed > 0 && time.Since(start)+wait > r.MaxElapsed {
+			return fmt.Errorf("%w after %d attempts in %v: %v", ErrRetriesExhausted, attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+		if notify != nil {
+			notify(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w after %d attempts: %v", ctx.Err(), attempt, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// RetryPolicy builds the connection retry policy from the config
+// MaxRetries counts retries, so the policy allows MaxRetries+1 attempts.
+func (c *Config) RetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if c.RetryInterval > 0 {
+		policy.InitialInterval = c.RetryInterval
+	}
+	if c.MaxRetryInterval > 0 {
+		policy.MaxInterval = c.MaxRetryInterval
+	}
+	policy.MaxAttempts = max(c.MaxRetries, 0) + 1
+	policy.MaxElapsed = c.MaxRetryElapsed
+	return policy
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("connection refused")
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(0))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 800*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, time.Second, policy.Backoff(4), "capped at MaxInterval")
+	assert.Equal(t, time.Second, policy.Backoff(20))
+
+	policy.Multiplier = 0
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(3), "multiplier below 1 keeps the delay constant")
+}
+
+func TestRetryPolicy_BackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: time.Second, Multiplier: 2, Jitter: 0.2}
+
+	for i := 0; i < 100; i++ {
+		delay := policy.Backoff(1)
+		assert.GreaterOrEqual(t, delay, 1600*time.Millisecond)
+		assert.LessOrEqual(t, delay, 2400*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_DoSucceedsAfterRetries(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: time.Millisecond, Multiplier: 2, MaxAttempts: 5}
+
+	var attempts []int
+	var waits []time.Duration
+	calls := 0
+	err := policy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errUnavailable
+		}
+		return nil
+	}, func(attempt int, err error, wait time.Duration) {
+		assert.ErrorIs(t, err, errUnavailable)
+		attempts = append(attempts, attempt)
+		waits = append(waits, wait)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, waits)
+}
+
+func TestRetryPolicy_DoGivesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		wantCalls int
+	}{
+		{
+			name:      "max attempts",
+			policy:    RetryPolicy{InitialInterval: time.Millisecond, MaxAttempts: 3},
+			wantCalls: 3,
+		},
+		{
+			name:      "single attempt",
+			policy:    RetryPolicy{InitialInterval: time.Millisecond, MaxAttempts: 1},
+			wantCalls: 1,
+		},
+		{
+			name:      "max elapsed",
+			policy:    RetryPolicy{InitialInterval: 20 * time.Millisecond, Multiplier: 2, MaxElapsed: 50 * time.Millisecond},
+			wantCalls: 2, // waits 20ms, then the 40ms wait would pass 50ms
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := tt.policy.Do(context.Background(), func(ctx context.Context) error {
+				calls++
+				return errUnavailable
+			}, nil)
+
+			assert.ErrorIs(t, err, ErrRetriesExhausted)
+			assert.ErrorContains(t, err, errUnavailable.Error())
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRetryPolicy_DoStopsOnContext(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- policy.Do(ctx, func(ctx context.Context) error { return errUnavailable }, nil)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrRetriesExhausted)
+	case <-time.After(time.Second):
+		t.Fatal("Do kept waiting after the context was canceled")
+	}
+}
+
+func TestRetryPolicy_DoContextEndedDuringAttempt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := RetryPolicy{InitialInterval: time.Millisecond}.Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errUnavailable
+	}, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestConfig_RetryPolicy(t *testing.T) {
+	policy := (&Config{}).RetryPolicy()
+	assert.Equal(t, DefaultRetryInterval, policy.InitialInterval)
+	assert.Equal(t, DefaultMaxRetryInterval, policy.MaxInterval)
+	assert.Equal(t, 1, policy.MaxAttempts)
+
+	policy = (&Config{
+		MaxRetries:       3,
+		RetryInterval:    250 * time.Millisecond,
+		MaxRetryInterval: 5 * time.Second,
+		MaxRetryElapsed:  time.Minute,
+	}).RetryPolicy()
+	assert.Equal(t, 4, policy.MaxAttempts, "MaxRetries counts retries after the first attempt")
+	assert.Equal(t, 250*time.Millisecond, policy.InitialInterval)
+	assert.Equal(t, 5*time.Second, policy.MaxInterval)
+	assert.Equal(t, time.Minute, policy.MaxElapsed)
+}
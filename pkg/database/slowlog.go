@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxLoggedSQL caps how much statement text a slow query log line carries
+const maxLoggedSQL = 200
+
+// SlowQueryLogger logs statements that take at least Threshold
+// Argument values are never logged, only their fingerprint.
+type SlowQueryLogger struct {
+	Threshold time.Duration
+	Logger    *log.Logger
+}
+
+// NewSlowQueryLogger creates a slow query observer; a nil logger uses the standard logger
+func NewSlowQueryLogger(threshold time.Duration, logger *log.Logger) *SlowQueryLogger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &SlowQueryLogger{Threshold: threshold, Logger: logger}
+}
+
+// QueryStart implements QueryObserver
+func (l *SlowQueryLogger) QueryStart(ctx context.Context, event *QueryEvent) context.Context {
+	return ctx
+}
+
+// QueryEnd logs the statement when it ran for Threshold or longer
+func (l *SlowQueryLogger) QueryEnd(ctx context.Context, event *QueryEvent) {
+	if event.Duration < l.Threshold {
+		return
+	}
+
+	name := event.Query
+	if name == "" {
+		name = "-"
+	}
+	tenantID := event.TenantID
+	if tenantID == "" {
+		tenantID = "-"
+	}
+	fingerprint := event.ArgsFingerprint
+	if fingerprint == "" {
+		fingerprint = "-"
+	}
+	errText := "-"
+	if event.Err != nil {
+		errText = event.Err.Error()
+	}
+
+	l.Logger.Printf("Slow query: duration=%v query=%s tenant=%s args=%d/%s err=%q sql=%q",
+		event.Duration, name, tenantID, event.ArgCount, fingerprint, errText, compactSQL(event.SQL))
+}
+
+// compactSQL collapses whitespace and truncates statement text for logging
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedSQL {
+		return sql[:maxLoggedSQL] + "..."
+	}
+	return sql
+}
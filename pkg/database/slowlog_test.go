@@ -0,0 +1,78 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQueryLogger_Threshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		wantLog  bool
+	}{
+		{name: "below threshold", duration: 99 * time.Millisecond},
+		{name: "at threshold", duration: 100 * time.Millisecond, wantLog: true},
+		{name: "above threshold", duration: 2 * time.Second, wantLog: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := NewSlowQueryLogger(100*time.Millisecond, log.New(&out, "", 0))
+
+			event := &QueryEvent{SQL: "SELECT 1", Duration: tt.duration}
+			logger.QueryEnd(logger.QueryStart(context.Background(), event), event)
+
+			if tt.wantLog {
+				assert.Contains(t, out.String(), "Slow query: duration="+tt.duration.String())
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
+
+func TestSlowQueryLogger_LogsFingerprintNotArgs(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewSlowQueryLogger(0, log.New(&out, "", 0))
+
+	args := []any{"alice@example.com"}
+	logger.QueryEnd(context.Background(), &QueryEvent{
+		SQL:             "-- name: GetUserByEmail :one\nSELECT *\n  FROM users\n  WHERE email = $1",
+		Query:           "GetUserByEmail",
+		ArgsFingerprint: ArgsFingerprint(args),
+		ArgCount:        len(args),
+		TenantID:        "tenant-a",
+		Duration:        time.Second,
+		Err:             errors.New("canceling statement"),
+	})
+
+	line := out.String()
+	assert.Contains(t, line, "query=GetUserByEmail tenant=tenant-a args=1/"+ArgsFingerprint(args))
+	assert.Contains(t, line, `err="canceling statement"`)
+	assert.Contains(t, line, `SELECT * FROM users WHERE email = $1"`)
+	assert.NotContains(t, line, "alice@example.com")
+}
+
+func TestSlowQueryLogger_Placeholders(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewSlowQueryLogger(0, log.New(&out, "", 0))
+
+	logger.QueryEnd(context.Background(), &QueryEvent{SQL: "SELECT 1"})
+	assert.Contains(t, out.String(), `query=- tenant=- args=0/- err="-"`)
+}
+
+func TestCompactSQL_Truncates(t *testing.T) {
+	sql := "SELECT " + strings.Repeat("col, ", 100) + "1"
+	compact := compactSQL(sql)
+	assert.Len(t, compact, maxLoggedSQL+len("..."))
+	assert.True(t, strings.HasSuffix(compact, "..."))
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+)
+
+// cancelGracePeriod is how long a canceled statement may take to stop on the server
+// before the client gives up on the connection
+const cancelGracePeriod = 2 * time.Second
+
+// pgQueryCanceled is SQLSTATE query_canceled (statement_timeout or cancel request)
+const pgQueryCanceled = "57014"
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout overrides Config.QueryTimeout for statements run with ctx
+// A timeout of zero or less removes the pool deadline (a deadline already on ctx still applies).
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// withStatementDeadline applies the query timeout unless ctx already ends sooner
+func (p *Pool) withStatementDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := p.config.QueryTimeout
+	if override, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		timeout = override
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// IsQueryTimeout reports whether err means a statement ran out of time
+// Covers the client deadline and PostgreSQL statement_timeout cancellations. A caller
+// cancelling its context is not a timeout; for the cancel request pgx sends then, the
+// server's query_canceled error looks the same, so use IsQueryTimeoutCtx when ctx is known.
+func IsQueryTimeout(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled
+}
+
+// IsQueryTimeoutCtx is IsQueryTimeout for a statement run with ctx
+// It is false whenever ctx was cancelled rather than reaching its deadline.
+func IsQueryTimeoutCtx(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	return IsQueryTimeout(err)
+}
+
+// cancelRequestHandler sends a PostgreSQL cancel request when a statement's context ends
+// so the server stops the statement instead of running it to completion.
+func cancelRequestHandler(conn *pgconn.PgConn) ctxwatch.Handler {
+	return &pgconn.CancelRequestContextWatcherHandler{
+		Conn:          conn,
+		DeadlineDelay: cancelGracePeriod,
+	}
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsQueryTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "wrapped deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: true},
+		{name: "statement_timeout", err: &pgconn.PgError{Code: pgQueryCanceled}, want: true},
+		{name: "caller cancelled", err: context.Canceled, want: false},
+		{name: "wrapped cancel", err: fmt.Errorf("query: %w", context.Canceled), want: false},
+		{name: "other postgres error", err: &pgconn.PgError{Code: "23505"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsQueryTimeout(tt.err))
+		})
+	}
+}
+
+func TestIsQueryTimeoutCtx(t *testing.T) {
+	cancelErr := &pgconn.PgError{Code: pgQueryCanceled}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, IsQueryTimeoutCtx(cancelled, cancelErr), "cancel request after the caller gave up")
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), 0)
+	defer cancelExpired()
+	<-expired.Done()
+	assert.True(t, IsQueryTimeoutCtx(expired, cancelErr))
+
+	assert.True(t, IsQueryTimeoutCtx(context.Background(), cancelErr), "server statement_timeout")
+}
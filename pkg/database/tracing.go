@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifies spans created by this package
+const TracerName = "crm-platform/pkg/database"
+
+// TracingObserver creates an OpenTelemetry client span per statement
+// Spans are children of the span in the call context, e.g. the server span
+// started by middleware.TracingMiddleware on the gin request context.
+type TracingObserver struct {
+	tracer trace.Tracer
+}
+
+// NewTracingObserver creates a tracing observer; a nil provider uses the global one
+func NewTracingObserver(provider trace.TracerProvider) *TracingObserver {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &TracingObserver{tracer: provider.Tracer(TracerName)}
+}
+
+// QueryStart starts the statement span
+func (o *TracingObserver) QueryStart(ctx context.Context, event *QueryEvent) context.Context {
+	name := event.Query
+	if name == "" {
+		name = "query"
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", compactSQL(event.SQL)),
+		attribute.Int("db.args.count", event.ArgCount),
+	}
+	if event.Query != "" {
+		attrs = append(attrs, attribute.String("db.operation", event.Query))
+	}
+	if event.ArgsFingerprint != "" {
+		attrs = append(attrs, attribute.String("db.args.fingerprint", event.ArgsFingerprint))
+	}
+	if event.TenantID != "" {
+		attrs = append(attrs, attribute.String("tenant.id", event.TenantID))
+	}
+
+	ctx, _ = o.tracer.Start(ctx, "db "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(event.Start),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// QueryEnd ends the statement span, recording any error
+func (o *TracingObserver) QueryEnd(ctx context.Context, event *QueryEvent) {
+	span := trace.SpanFromContext(ctx)
+	if event.Err != nil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	span.End(trace.WithTimestamp(event.Start.Add(event.Duration)))
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// newTracedPool returns a pool whose statements are traced into an in-memory exporter
+func newTracedPool(t *testing.T) (*Pool, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	pool := newUnreachablePool(t)
+	pool.AddQueryObserver(NewTracingObserver(provider))
+	return pool, exporter, provider
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingObserver_SpanAttributes(t *testing.T) {
+	pool, exporter, provider := newTracedPool(t)
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "GET /deals/:id")
+
+	args := []any{int64(7), "alice@example.com"}
+	_, trace := pool.TraceQuery(parentCtx, "-- name: GetDeal :one\nSELECT *\n  FROM deals WHERE id = $1", args, "tenant-a")
+	trace.End(nil)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "db GetDeal", span.Name)
+	assert.Equal(t, oteltrace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID(), "statement spans are children of the request span")
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Empty(t, span.Events)
+
+	attrs := attributes(span)
+	assert.Equal(t, "postgresql", attrs["db.system"].AsString())
+	assert.Equal(t, "-- name: GetDeal :one SELECT * FROM deals WHERE id = $1", attrs["db.statement"].AsString())
+	assert.Equal(t, "GetDeal", attrs["db.operation"].AsString())
+	assert.Equal(t, int64(2), attrs["db.args.count"].AsInt64())
+	assert.Equal(t, ArgsFingerprint(args), attrs["db.args.fingerprint"].AsString())
+	assert.Equal(t, "tenant-a", attrs["tenant.id"].AsString())
+	for _, value := range attrs {
+		assert.NotContains(t, value.Emit(), "alice@example.com")
+	}
+}
+
+func TestTracingObserver_AdHocSQL(t *testing.T) {
+	pool, exporter, _ := newTracedPool(t)
+
+	_, trace := pool.TraceQuery(context.Background(), "SELECT 1", nil, "")
+	trace.End(nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "db query", spans[0].Name)
+	assert.False(t, spans[0].Parent.IsValid())
+
+	attrs := attributes(spans[0])
+	assert.Equal(t, int64(0), attrs["db.args.count"].AsInt64())
+	for _, key := range []attribute.Key{"db.operation", "db.args.fingerprint", "tenant.id"} {
+		assert.NotContains(t, attrs, key)
+	}
+}
+
+func TestTracingObserver_RecordsErrors(t *testing.T) {
+	pool, exporter, _ := newTracedPool(t)
+
+	_, trace := pool.TraceQuery(context.Background(), "SELECT 1", nil, "")
+	trace.End(errors.New("relation \"deals\" does not exist"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, `relation "deals" does not exist`, spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
+
+func TestTracingObserver_NoRowsIsNotAnError(t *testing.T) {
+	pool, exporter, _ := newTracedPool(t)
+
+	_, trace := pool.TraceQuery(context.Background(), "SELECT 1", nil, "")
+	trace.End(pgx.ErrNoRows)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Empty(t, spans[0].Events)
+}
+
+func TestTracingObserver_SpanTiming(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	observer := NewTracingObserver(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := &QueryEvent{SQL: "SELECT 1", Start: start, Duration: 250 * time.Millisecond}
+	observer.QueryEnd(observer.QueryStart(context.Background(), event), event)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, start, spans[0].StartTime)
+	assert.Equal(t, start.Add(250*time.Millisecond), spans[0].EndTime)
+}
@@ -19,6 +19,16 @@ var (
 		return fmt.Errorf("TENANT ERROR: %s", msg)
 	}
 
+	// Tenant access blocked by suspension
+	ErrTenantSuspended = func(msg string) error {
+		return fmt.Errorf("TENANT SUSPENDED: %s", msg)
+	}
+
+	// Tenant access blocked until provisioning completes
+	ErrTenantPending = func(msg string) error {
+		return fmt.Errorf("TENANT PENDING: %s", msg)
+	}
+
 	// Permission checking process errors
 	ErrPermission = func(msg string) error {
 		return fmt.Errorf("PERMISSION ERROR: %s", msg)
@@ -0,0 +1,148 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"crm-platform/pkg/config"
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/tenant"
+)
+
+// Check error definitions
+var (
+	ErrTemplateSchemaMissing = fmt.Errorf("tenant template schema is missing")
+	ErrMigrationsPending     = fmt.Errorf("tenant template has pending migrations")
+	ErrUnexpectedReply       = fmt.Errorf("unexpected reply")
+)
+
+// NewServiceChecker registers the standard readiness checks for a service
+// The database and tenant template are always checked; NATS and Redis only when
+// their URLs are configured. Migrations are not: only the service that applies
+// them registers MigrationsCheck.
+func NewServiceChecker(service string, pool *database.Pool, cfg *config.Config) (*Checker, error) {
+	checker := NewChecker(Options{Service: service})
+	checker.Register("database", DatabaseCheck(pool))
+	checker.Register("template_schema", TemplateSchemaCheck(pool))
+	if cfg.NATSURL != "" {
+		checker.Register("nats", NATSCheck(cfg.NATSURL))
+	}
+	if cfg.RedisURL != "" {
+		checker.Register("redis", RedisCheck(cfg.RedisURL))
+	}
+	return checker, nil
+}
+
+// DatabaseCheck fails when the primary database does not answer
+func DatabaseCheck(pool *database.Pool) Check {
+	return func(ctx context.Context) error {
+		status := pool.HealthCheck(ctx)
+		if !status.Healthy {
+			return errors.New(status.Error)
+		}
+		return nil
+	}
+}
+
+// TemplateSchemaCheck fails when the schema new tenants are cloned from is missing
+func TemplateSchemaCheck(pool *database.Pool) Check {
+	return func(ctx context.Context) error {
+		exists, err := tenant.SchemaExists(ctx, pool, tenant.TemplateSchemaName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrTemplateSchemaMissing, tenant.TemplateSchemaName)
+		}
+		return nil
+	}
+}
+
+// MigrationsCheck fails while the template schema is behind the migrations this build ships
+// Only the template is checked: tenant schemas are migrated from it and checking
+// every schema would cost one query per tenant on each probe. Register it only in a
+// service that migrates the template itself; elsewhere a new build would stay unready
+// until someone else applied its migrations, stalling the rollout.
+func MigrationsCheck(migrator *tenant.Migrator) Check {
+	return func(ctx context.Context) error {
+		version, err := migrator.SchemaVersion(ctx, tenant.TemplateSchemaName)
+		if err != nil {
+			return err
+		}
+		if latest := migrator.LatestVersion(); version < latest {
+			return fmt.Errorf("%w: at version %d, latest is %d", ErrMigrationsPending, version, latest)
+		}
+		return nil
+	}
+}
+
+// NATSCheck fails when the NATS server at natsURL does not send its INFO banner
+func NATSCheck(natsURL string) Check {
+	return func(ctx context.Context) error {
+		conn, err := dialURL(ctx, natsURL, "4222")
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading NATS banner: %w", err)
+		}
+		if !strings.HasPrefix(line, "INFO ") {
+			return fmt.Errorf("%w from NATS: %q", ErrUnexpectedReply, strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+// RedisCheck fails when the Redis server at redisURL does not answer PING
+// A NOAUTH reply still proves the server is up, so no credentials are needed.
+func RedisCheck(redisURL string) Check {
+	return func(ctx context.Context) error {
+		conn, err := dialURL(ctx, redisURL, "6379")
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+			return fmt.Errorf("sending Redis PING: %w", err)
+		}
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading Redis reply: %w", err)
+		}
+		if line = strings.TrimSpace(line); line != "+PONG" && !strings.HasPrefix(line, "-NOAUTH") {
+			return fmt.Errorf("%w from Redis: %q", ErrUnexpectedReply, line)
+		}
+		return nil
+	}
+}
+
+// dialURL opens a TCP connection to the host in rawURL, honoring ctx's deadline
+func dialURL(ctx context.Context, rawURL, defaultPort string) (net.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid URL %q", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
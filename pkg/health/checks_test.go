@@ -0,0 +1,128 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer accepts connections on a local port and answers each with reply
+// If expect is set, the reply is only sent after that line is read.
+func fakeServer(t *testing.T, expect, reply string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if expect != "" {
+					line, err := bufio.NewReader(conn).ReadString('\n')
+					if err != nil || line != expect {
+						return
+					}
+				}
+				conn.Write([]byte(reply))
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func checkCtx(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestNATSCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr error
+	}{
+		{name: "info banner", reply: "INFO {\"server_id\":\"test\"}\r\n"},
+		{name: "unexpected banner", reply: "HTTP/1.1 400 Bad Request\r\n", wantErr: ErrUnexpectedReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeServer(t, "", tt.reply)
+			err := NATSCheck("nats://" + addr)(checkCtx(t))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRedisCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr error
+	}{
+		{name: "pong", reply: "+PONG\r\n"},
+		{name: "auth required still proves the server is up", reply: "-NOAUTH Authentication required.\r\n"},
+		{name: "unexpected reply", reply: "-ERR unknown command\r\n", wantErr: ErrUnexpectedReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeServer(t, "PING\r\n", tt.reply)
+			err := RedisCheck("redis://" + addr)(checkCtx(t))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChecks_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	assert.Error(t, NATSCheck("nats://"+addr)(checkCtx(t)))
+	assert.Error(t, RedisCheck("redis://"+addr)(checkCtx(t)))
+}
+
+func TestChecks_InvalidURL(t *testing.T) {
+	assert.ErrorContains(t, RedisCheck("redis-service")(checkCtx(t)), "invalid URL")
+	assert.ErrorContains(t, NATSCheck("://nats")(checkCtx(t)), "invalid URL")
+}
+
+func TestChecks_SilentServerTimesOut(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, NATSCheck("nats://"+listener.Addr().String())(ctx))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,193 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Health defaults
+const (
+	DefaultCacheTTL     = 5 * time.Second
+	DefaultCheckTimeout = 3 * time.Second
+)
+
+// Report statuses
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check reports whether one dependency is usable; a nil error means healthy
+type Check func(ctx context.Context) error
+
+// Options controls how readiness checks run
+type Options struct {
+	Service  string        // Reported in every response
+	CacheTTL time.Duration // How long a readiness result is reused (protects the database from probe storms)
+	Timeout  time.Duration // Deadline for each check
+}
+
+// CheckResult is the outcome of one named check
+type CheckResult struct {
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+	Optional bool          `json:"optional,omitempty"` // Reported, but a failure does not fail readiness
+}
+
+// Report is the body served by /livez and /readyz
+type Report struct {
+	Status    string                 `json:"status"`
+	Service   string                 `json:"service,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Healthy reports whether every check passed
+func (r *Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs named readiness checks and caches the result
+type Checker struct {
+	opts Options
+
+	mu       sync.Mutex // guards checks; also serializes check runs so concurrent probes share one
+	checks   map[string]Check
+	optional map[string]bool
+	last     *Report
+}
+
+// NewChecker creates a checker with no checks registered
+func NewChecker(opts Options) *Checker {
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultCheckTimeout
+	}
+	return &Checker{opts: opts, checks: make(map[string]Check), optional: make(map[string]bool)}
+}
+
+// Register adds a readiness check; registering a name again replaces it
+func (c *Checker) Register(name string, check Check) {
+	c.register(name, check, false)
+}
+
+// RegisterOptional adds a check that is reported but never fails readiness
+// Use it for dependencies the service degrades gracefully without; taking every
+// replica out of rotation would turn their outage into a full one.
+func (c *Checker) RegisterOptional(name string, check Check) {
+	c.register(name, check, true)
+}
+
+// register adds or replaces a named check
+func (c *Checker) register(name string, check Check, optional bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+	c.optional[name] = optional
+	c.last = nil
+}
+
+// Ready runs every check (or returns the cached report) and reports the result
+// Checks run in parallel, each bounded by Options.Timeout.
+func (c *Checker) Ready(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.opts.CacheTTL {
+		return c.last
+	}
+
+	report := &Report{
+		Status:    StatusOK,
+		Service:   c.opts.Service,
+		CheckedAt: time.Now(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := runCheck(ctx, check, c.opts.Timeout)
+			result.Optional = c.optional[name]
+
+			resultsMu.Lock()
+			report.Checks[name] = result
+			resultsMu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK && !result.Optional {
+			report.Status = StatusFail
+		}
+	}
+
+	// A probe that gave up says nothing about the dependencies; don't cache it
+	if ctx.Err() == nil {
+		c.last = report
+	}
+	return report
+}
+
+// Live reports that the process is up and serving; it never touches dependencies
+// Restarting a pod cannot fix a database outage, so liveness stays independent of it.
+func (c *Checker) Live() *Report {
+	return &Report{Status: StatusOK, Service: c.opts.Service, CheckedAt: time.Now()}
+}
+
+// LivenessHandler serves /livez: 200 while the process can answer HTTP
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Live())
+	})
+}
+
+// ReadinessHandler serves /readyz: 200 when every check passes, 503 otherwise
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Ready(r.Context()))
+	})
+}
+
+// runCheck runs one check with a deadline, recovering from panics
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = CheckResult{Status: StatusFail, Error: "check panicked", Duration: time.Since(start)}
+		}
+	}()
+
+	err := check(ctx)
+	result = CheckResult{Status: StatusOK, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeReport encodes a report with the matching probe status code
+func writeReport(w http.ResponseWriter, report *Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Healthy() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
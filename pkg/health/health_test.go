@@ -0,0 +1,170 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passing(ctx context.Context) error { return nil }
+
+func TestChecker_Ready(t *testing.T) {
+	checker := NewChecker(Options{Service: "test-service"})
+	checker.Register("database", passing)
+	checker.Register("nats", passing)
+
+	report := checker.Ready(context.Background())
+	assert.True(t, report.Healthy())
+	assert.Equal(t, "test-service", report.Service)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+}
+
+func TestChecker_ReadyFailure(t *testing.T) {
+	checker := NewChecker(Options{})
+	checker.Register("database", passing)
+	checker.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	checker.Register("nats", func(ctx context.Context) error { panic("boom") })
+
+	report := checker.Ready(context.Background())
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, CheckResult{Status: StatusFail, Error: "connection refused", Duration: report.Checks["redis"].Duration}, report.Checks["redis"])
+	assert.Equal(t, "check panicked", report.Checks["nats"].Error)
+}
+
+func TestChecker_ReadyOptionalFailure(t *testing.T) {
+	checker := NewChecker(Options{CacheTTL: time.Nanosecond})
+	checker.Register("database", passing)
+	checker.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	// Re-registering as optional keeps the failure visible without failing readiness
+	checker.RegisterOptional("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	report := checker.Ready(context.Background())
+	assert.True(t, report.Healthy())
+	assert.Equal(t, StatusFail, report.Checks["redis"].Status)
+	assert.True(t, report.Checks["redis"].Optional)
+	assert.False(t, report.Checks["database"].Optional)
+
+	// Registering it again as required makes it count
+	checker.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	assert.False(t, checker.Ready(context.Background()).Healthy())
+}
+
+func TestChecker_ReadyTimeout(t *testing.T) {
+	checker := NewChecker(Options{Timeout: 20 * time.Millisecond})
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := checker.Ready(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusFail, report.Checks["slow"].Status)
+	assert.Contains(t, report.Checks["slow"].Error, "deadline exceeded")
+}
+
+func TestChecker_ReadyRunsChecksInParallel(t *testing.T) {
+	checker := NewChecker(Options{})
+	for _, name := range []string{"a", "b", "c"} {
+		checker.Register(name, func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+	}
+
+	start := time.Now()
+	require.True(t, checker.Ready(context.Background()).Healthy())
+	assert.Less(t, time.Since(start), 140*time.Millisecond)
+}
+
+func TestChecker_ReadyCachesReport(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(Options{CacheTTL: time.Hour})
+	checker.Register("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	first := checker.Ready(context.Background())
+	second := checker.Ready(context.Background())
+	assert.Same(t, first, second)
+	assert.EqualValues(t, 1, runs.Load())
+
+	// Registering a check drops the cached report
+	checker.Register("nats", passing)
+	third := checker.Ready(context.Background())
+	assert.Len(t, third.Checks, 2)
+	assert.EqualValues(t, 2, runs.Load())
+}
+
+func TestChecker_ReadyCacheExpires(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(Options{CacheTTL: 10 * time.Millisecond})
+	checker.Register("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	checker.Ready(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	checker.Ready(context.Background())
+	assert.EqualValues(t, 2, runs.Load())
+}
+
+func TestChecker_ReadyDoesNotCacheAbandonedProbe(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(Options{CacheTTL: time.Hour})
+	checker.Register("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, checker.Ready(ctx).Healthy())
+
+	assert.True(t, checker.Ready(context.Background()).Healthy())
+	assert.EqualValues(t, 2, runs.Load())
+}
+
+func TestHandlers(t *testing.T) {
+	checker := NewChecker(Options{Service: "test-service"})
+	checker.Register("database", func(ctx context.Context) error { return errors.New("down") })
+
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		wantStatus int
+		wantBody   string
+		wantChecks bool
+	}{
+		{name: "liveness ignores dependencies", handler: checker.LivenessHandler(), wantStatus: http.StatusOK, wantBody: StatusOK},
+		{name: "readiness reports failure", handler: checker.ReadinessHandler(), wantStatus: http.StatusServiceUnavailable, wantBody: StatusFail, wantChecks: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+			var report Report
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantBody, report.Status)
+			assert.Equal(t, "test-service", report.Service)
+			assert.Equal(t, tt.wantChecks, len(report.Checks) > 0)
+		})
+	}
+}
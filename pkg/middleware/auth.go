@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"crm-platform/pkg/config"
 	"crm-platform/pkg/errors"
@@ -23,23 +25,92 @@ func extractTokenFromHeader(c *gin.Context) string {
 	return ""
 }
 
-// Validate JWT token signature and extract claims
-func validateJWT(token string) (map[string]interface{}, error) {
-	parsedToken, err := jwt.Parse(token, func (token *jwt.Token)(interface{}, error) {
-		// Ensure HMAC signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.ErrJWT("invalid signing method") 
-		}
+// JWTOptions controls how access tokens are verified
+// With JWKSURL set only RS256/ES256 tokens signed by a published key are
+// accepted and Secret is ignored; otherwise tokens are HMAC-signed with Secret.
+type JWTOptions struct {
+	Secret      string        // Shared HMAC secret (legacy; unused once JWKSURL is set)
+	JWKSURL     string        // Auth service public keys: http(s) URL, file:// URL or path
+	JWKSRefresh time.Duration // How often the key set is refetched
+	Issuer      string        // Required iss claim (empty skips the check)
+	Audience    string        // Required aud entry (empty skips the check)
+	ClockSkew   time.Duration // Leeway for exp, nbf and iat
+}
 
-		// Get shared secret from environment via config package
-		secret := config.GetJWTSecret()
-		if secret == "" {
-			return nil, errors.ErrJWT("JWT secret not configured in environment")
-		}
+// JWTOptionsFromConfig takes the verification settings from the loaded configuration
+func JWTOptionsFromConfig(cfg config.AuthConfig) JWTOptions {
+	return JWTOptions{
+		Secret:      cfg.JWTSecret,
+		JWKSURL:     cfg.JWKSURL,
+		JWKSRefresh: cfg.JWKSRefreshInterval,
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		ClockSkew:   cfg.ClockSkew,
+	}
+}
+
+// JWTVerifier checks token signatures and registered claims
+type JWTVerifier struct {
+	opts JWTOptions
+	keys *KeySet // nil in HMAC mode
+}
 
-		return []byte(secret), nil
-	})
+// NewJWTVerifier creates a verifier; the JWKS is fetched on first use
+func NewJWTVerifier(opts JWTOptions) *JWTVerifier {
+	v := &JWTVerifier{opts: opts}
+	if opts.JWKSURL != "" {
+		v.keys = NewKeySet(opts.JWKSURL, opts.JWKSRefresh)
+	}
+	return v
+}
+
+// KeySet returns the verifier's JWKS cache, or nil when verifying with a shared secret
+func (v *JWTVerifier) KeySet() *KeySet {
+	return v.keys
+}
+
+// Verify checks the token signature, exp (required), nbf, iat, iss and aud, and returns its claims
+// Tokens without user_id (or sub) and tenant_id are rejected.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(max(v.opts.ClockSkew, 0)),
+	}
+	if v.opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(v.opts.Issuer))
+	}
+	if v.opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(v.opts.Audience))
+	}
 
+	var keyFunc jwt.Keyfunc
+	if v.keys != nil {
+		parserOpts = append(parserOpts, jwt.WithValidMethods([]string{"RS256", "ES256"}))
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, err := v.keys.key(ctx, kid)
+			if err != nil {
+				return nil, err
+			}
+			// An RSA kid must not verify an ES256 token and vice versa
+			if key.alg != token.Method.Alg() {
+				return nil, errors.ErrJWT("signing method does not match key " + kid)
+			}
+			return key.key, nil
+		}
+	} else {
+		parserOpts = append(parserOpts, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			if v.opts.Secret == "" {
+				return nil, errors.ErrJWT("JWT secret not configured")
+			}
+			return []byte(v.opts.Secret), nil
+		}
+	}
+
+	claims := &Claims{}
+	parsedToken, err := jwt.ParseWithClaims(token, claims, keyFunc, parserOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,40 +120,55 @@ func validateJWT(token string) (map[string]interface{}, error) {
 		return nil, errors.ErrJWT("token is invalid")
 	}
 
-	// Extract claims
-	claims, ok := parsedToken.Claims.(jwt.MapClaims) 
-	if !ok {
-		return nil, errors.ErrJWT("could not parse claims")
-	}
-	
 	return claims, nil
 }
 
 // MIDDLEWARE
 
 // Handle user authentication and set context
-func AuthMiddleware() gin.HandlerFunc {
+// Tokens are verified with cfg.Auth; only in development (cfg.IsDevelopment) is
+// the identity taken from request headers instead, see DevelopmentAuthMiddleware.
+func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	if cfg.IsDevelopment() {
+		return DevelopmentAuthMiddleware()
+	}
+	return AuthMiddlewareWithVerifier(NewJWTVerifier(JWTOptionsFromConfig(cfg.Auth)))
+}
+
+// DevelopmentAuthMiddleware trusts the X-Tenant-ID, X-User-ID, X-User-Role and
+// X-User-Permissions headers without a token; never use it outside development
+func DevelopmentAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Since no auth server yet, use headers in development mode
-		if config.IsDevelopmentMode() {
-			// Get tenant from header (allows dynamic tenant in dev/test)
-			tenantID := c.GetHeader("X-Tenant-ID")
-			if tenantID == "" {
-				tenantID = "01HK153X003BMPJNJB6JHKXK8T" // fallback
-			}
-			
-			userID := c.GetHeader("X-User-ID")
-			if userID == "" {
-				userID = "dev-user"
-			}
-			
-			c.Set("user_id", userID)
-			c.Set("user_permissions", []string{"deals:read", "deals:write"})
-			c.Set("tenant_id", tenantID)
-			c.Next()
-			return
+		// Get tenant from header (allows dynamic tenant in dev/test)
+		tenantID := c.GetHeader("X-Tenant-ID")
+		if tenantID == "" {
+			tenantID = "01HK153X003BMPJNJB6JHKXK8T" // fallback
+		}
+		
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			userID = "dev-user"
 		}
+		
+		// Role defaults to admin; RolePermissionsMiddleware expands it
+		role := c.GetHeader("X-User-Role")
+		if role == "" {
+			role = RoleAdmin
+		}
+
+		setClaims(c, &Claims{
+			UserID:      userID,
+			TenantID:    tenantID,
+			Role:        role,
+			Permissions: splitPermissions(c.GetHeader("X-User-Permissions")),
+		})
+		c.Next()
+	}
+}
 
+// AuthMiddlewareWithVerifier requires a bearer token the verifier accepts
+func AuthMiddlewareWithVerifier(verifier *JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
 		// Get JWT from header
 		token := extractTokenFromHeader(c)
 		if token == "" {
@@ -92,7 +178,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Validate and sign token, receive claims
-		claims, err := validateJWT(token)
+		claims, err := verifier.Verify(c.Request.Context(), token)
 		if err != nil {
 			c.JSON(401, gin.H{"error": errors.ErrAuth("token validation failed").Error()})
 			c.Abort()
@@ -100,54 +186,42 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 		
 		// Set claims in context for handlers to use
-		c.Set("user_id", claims["user_id"])
-		c.Set("user_permissions", claims["user_permissions"])
-		c.Set("tenant_id", claims["tenant_id"])
+		setClaims(c, claims)
 		c.Next()
 	}
 }
 
+// splitPermissions parses a comma separated permission list
+func splitPermissions(header string) []string {
+	var permissions []string
+	for _, permission := range strings.Split(header, ",") {
+		if permission = strings.TrimSpace(permission); permission != "" {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
 // CONTEXT EXTRACTION
 
 // Get authenticated user ID from request context
 func ExtractUserId(c *gin.Context) string {
-	userId, exists := c.Get("user_id")
-
-	if !exists {
-		return ""
-	}
-
-	if id, ok := userId.(string); ok {
-		return id
+	if claims, ok := GetClaims(c); ok {
+		return claims.UserID
 	}
-
-	return ""
+	return c.GetString("user_id")
 }
 
 // Get user permissions from request context
 func ExtractPermissions(c *gin.Context) []string {
-	perms, exists := c.Get("user_permissions")
-
-	if !exists {
-		return []string{}
-	}
-
-	if permissions, ok := perms.([]string); ok {
-		return permissions
+	if claims, ok := GetClaims(c); ok && claims.Permissions != nil {
+		return claims.Permissions
 	}
-	
 	return []string{}
 }
 
 // Check if user has specific permission
 func HasPermission(c *gin.Context, permission string) bool {
-	permissions := ExtractPermissions(c)
-
-	for _, perm := range permissions {
-		if perm == permission {
-			return true
-		}
-	}
-
-	return false
-}
\ No newline at end of file
+	claims, ok := GetClaims(c)
+	return ok && claims.HasPermission(permission)
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"crm-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claimsKey is the gin context key AuthMiddleware stores *Claims under
+const claimsKey = "claims"
+
+type claimsContextKey struct{}
+
+// Claims is the identity carried by a verified access token
+type Claims struct {
+	UserID      string   `json:"user_id"`
+	TenantID    string   `json:"tenant_id"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"user_permissions,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
+	// EmailVerified is false only when the issuer limits unverified users; absent means no restriction
+	EmailVerified *bool `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UnmarshalJSON accepts user_id as a string or an integer (users.id is a SERIAL)
+// A token without user_id falls back to sub.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	var raw struct {
+		plain
+		UserID json.RawMessage `json:"user_id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = Claims(raw.plain)
+	userID, err := parseUserID(raw.UserID)
+	if err != nil {
+		return err
+	}
+	c.UserID = userID
+	if c.UserID == "" {
+		c.UserID = c.Subject
+	}
+	return nil
+}
+
+// parseUserID decodes a user_id claim value, formatting integers without exponent or fraction
+func parseUserID(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	if raw[0] == '"' {
+		var id string
+		err := json.Unmarshal(raw, &id)
+		return id, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var number json.Number
+	if err := decoder.Decode(&number); err != nil {
+		return "", fmt.Errorf("user_id must be a string or integer")
+	}
+	id, err := number.Int64()
+	if err != nil {
+		return "", fmt.Errorf("user_id must be a string or integer, got %s", number)
+	}
+	return fmt.Sprint(id), nil
+}
+
+// Validate rejects tokens that do not identify a user and tenant; called by the jwt parser
+func (c *Claims) Validate() error {
+	if c.UserID == "" {
+		return errors.ErrJWT("token has no user_id")
+	}
+	if c.TenantID == "" {
+		return errors.ErrJWT("token has no tenant_id")
+	}
+	return nil
+}
+
+// Unverified reports whether the token marks the user's email as not yet verified
+func (c *Claims) Unverified() bool {
+	return c.EmailVerified != nil && !*c.EmailVerified
+}
+
+// HasPermission reports whether the claims grant permission, directly or by wildcard
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.ContainsFunc(c.Permissions, func(granted string) bool {
+		return MatchPermission(granted, permission)
+	})
+}
+
+// WithClaims returns a copy of ctx carrying claims
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored by WithClaims (AuthMiddleware stores them on the request context)
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// GetClaims returns the claims AuthMiddleware set for this request
+func GetClaims(c *gin.Context) (*Claims, bool) {
+	value, exists := c.Get(claimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*Claims)
+	return claims, ok && claims != nil
+}
+
+// setClaims exposes claims on the gin context and the request context
+// The flat user_id, user_role, user_permissions and tenant_id keys are kept for
+// handlers and resolvers that read them directly.
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set(claimsKey, claims)
+	c.Set("user_id", claims.UserID)
+	c.Set("user_role", claims.Role)
+	c.Set("user_permissions", claims.Permissions)
+	c.Set("tenant_id", claims.TenantID)
+	c.Request = c.Request.WithContext(WithClaims(c.Request.Context(), claims))
+}
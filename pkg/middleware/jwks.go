@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWKS defaults
+const (
+	DefaultJWKSRefreshInterval = 10 * time.Minute
+
+	// jwksMinRefreshInterval throttles refetches (or the refresh interval, if
+	// shorter), so tokens carrying made-up kids cannot turn every request into
+	// a JWKS download
+	jwksMinRefreshInterval = 10 * time.Second
+	jwksMaxBytes           = 1 << 20
+)
+
+// JWKS error definitions
+var (
+	ErrUnknownKey  = fmt.Errorf("no signing key for kid")
+	ErrInvalidJWKS = fmt.Errorf("invalid JWKS")
+)
+
+// signingKey is one usable public key from a JWKS document
+type signingKey struct {
+	alg string // "RS256" or "ES256"; fixed by the key type so a key cannot verify another algorithm
+	key any    // *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// KeySet caches the public keys published by the auth service
+// Keys are refetched every refresh interval, and early when a token names a kid
+// the cache does not know (the auth service rotated its signing key).
+type KeySet struct {
+	source   string
+	interval time.Duration
+	client   *http.Client
+
+	refreshMu sync.Mutex // serializes fetches so concurrent misses share one
+
+	mu          sync.RWMutex
+	keys        map[string]signingKey
+	fetchedAt   time.Time // last successful fetch
+	attemptedAt time.Time // last fetch, successful or not
+}
+
+// NewKeySet creates a key set for source: an http(s) URL, a file:// URL or a file path
+// Nothing is fetched until the first token is verified or Refresh is called.
+func NewKeySet(source string, refreshInterval time.Duration) *KeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+	return &KeySet{
+		source:   source,
+		interval: refreshInterval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// key returns the public key for kid, refetching the set when it is stale or kid is unknown
+// An empty kid is accepted only while the set holds exactly one key.
+func (k *KeySet) key(ctx context.Context, kid string) (signingKey, error) {
+	if k.stale() {
+		if err := k.refresh(ctx, false); err != nil && !k.loaded() {
+			return signingKey{}, err
+		}
+	}
+
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	// Unknown kid: the signing key may have rotated since the last fetch
+	if err := k.refresh(ctx, false); err != nil && !k.loaded() {
+		return signingKey{}, err
+	}
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+	return signingKey{}, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// Refresh fetches the key set now, ignoring the refresh throttle
+func (k *KeySet) Refresh(ctx context.Context) error {
+	return k.refresh(ctx, true)
+}
+
+// refresh fetches and replaces the keys; a failed fetch keeps the previous keys
+func (k *KeySet) refresh(ctx context.Context, force bool) error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+
+	k.mu.RLock()
+	throttled := time.Since(k.attemptedAt) < min(jwksMinRefreshInterval, k.interval)
+	k.mu.RUnlock()
+	if throttled && !force {
+		return nil
+	}
+
+	keys, err := k.fetch(ctx)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.attemptedAt = time.Now()
+	if err != nil {
+		if k.keys != nil {
+			log.Printf("JWKS refresh from %s failed, keeping %d cached keys: %v", k.source, len(k.keys), err)
+		}
+		return err
+	}
+	k.keys = keys
+	k.fetchedAt = k.attemptedAt
+	return nil
+}
+
+// fetch reads and parses the JWKS document from the source
+func (k *KeySet) fetch(ctx context.Context) (map[string]signingKey, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(k.source, "http://") || strings.HasPrefix(k.source, "https://") {
+		data, err = k.fetchURL(ctx)
+	} else {
+		path := k.source
+		if u, perr := url.Parse(k.source); perr == nil && u.Scheme == "file" {
+			path = u.Path
+		}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	return parseJWKS(data)
+}
+
+// fetchURL downloads the JWKS document over HTTP
+func (k *KeySet) fetchURL(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, jwksMaxBytes))
+}
+
+// stale reports whether the keys were never loaded or are older than the refresh interval
+func (k *KeySet) stale() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys == nil || time.Since(k.fetchedAt) > k.interval
+}
+
+// loaded reports whether any fetch has succeeded
+func (k *KeySet) loaded() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys != nil
+}
+
+// lookup finds kid in the cached keys
+func (k *KeySet) lookup(kid string) (signingKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// JWKS PARSING
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and EC public keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS extracts the RS256 and ES256 signing keys from a JWKS document
+// Encryption keys and unsupported key types are skipped, so the auth service can
+// publish keys this package does not use.
+func parseJWKS(data []byte) (map[string]signingKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	keys := make(map[string]signingKey, len(doc.Keys))
+	for i, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key signingKey
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaKey()
+		case "EC":
+			key, err = jwk.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %d (kid %q): %v", ErrInvalidJWKS, i, jwk.Kid, err)
+		}
+		if key.alg == "" {
+			continue // e.g. an RS512 or P-384 key
+		}
+		if _, dup := keys[jwk.Kid]; dup {
+			return nil, fmt.Errorf("%w: duplicate kid %q", ErrInvalidJWKS, jwk.Kid)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no RS256 or ES256 signing keys", ErrInvalidJWKS)
+	}
+	return keys, nil
+}
+
+// rsaKey decodes an RSA public key; alg is left empty when the JWK is pinned to another algorithm
+func (jwk jsonWebKey) rsaKey() (signingKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("modulus: %v", err)
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("exponent: %v", err)
+	}
+	if n.BitLen() < 2048 {
+		return signingKey{}, fmt.Errorf("RSA key is %d bits, need at least 2048", n.BitLen())
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return signingKey{}, fmt.Errorf("unsupported exponent")
+	}
+
+	key := signingKey{key: &rsa.PublicKey{N: n, E: int(e.Int64())}}
+	if jwk.Alg == "" || jwk.Alg == "RS256" {
+		key.alg = "RS256"
+	}
+	return key, nil
+}
+
+// ecKey decodes a P-256 public key; other curves are left without an alg
+func (jwk jsonWebKey) ecKey() (signingKey, error) {
+	if jwk.Crv != "P-256" {
+		return signingKey{}, nil
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("x: %v", err)
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("y: %v", err)
+	}
+
+	curve := elliptic.P256()
+	if !curve.IsOnCurve(x, y) {
+		return signingKey{}, fmt.Errorf("point is not on P-256")
+	}
+
+	key := signingKey{key: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}}
+	if jwk.Alg == "" || jwk.Alg == "ES256" {
+		key.alg = "ES256"
+	}
+	return key, nil
+}
+
+// decodeBigInt decodes an unpadded base64url big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"strings"
+
+	"crm-platform/pkg/errors"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles allowed by the users.role CHECK constraint in the tenant template
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleSalesRep = "sales_rep"
+	RoleViewer   = "viewer"
+)
+
+// DefaultRolePermissions returns the built-in permissions of each role
+// Permissions are "<resource>:<action>"; "*" grants everything and "<resource>:*"
+// every action on one resource.
+func DefaultRolePermissions() map[string][]string {
+	return map[string][]string{
+		RoleAdmin:    {"*"},
+		RoleManager:  {"deals:*", "contacts:*", "communications:*", "reports:read", "users:read"},
+		RoleSalesRep: {"deals:read", "deals:write", "contacts:read", "contacts:write", "communications:read", "communications:write"},
+		RoleViewer:   {"deals:read", "contacts:read", "communications:read", "reports:read"},
+	}
+}
+
+// UnverifiedPermissions are the most a user with an unverified email is granted
+// (Claims.Unverified): read access, and only where the role allows it.
+var UnverifiedPermissions = []string{"deals:read", "contacts:read", "communications:read", "reports:read", "users:read"}
+
+// MatchPermission reports whether a granted permission (possibly a wildcard) covers required
+func MatchPermission(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	resource, ok := strings.CutSuffix(granted, ":*")
+	return ok && strings.HasPrefix(required, resource+":")
+}
+
+// PermissionPolicy expands a user's role into permissions, applying per-tenant overrides
+type PermissionPolicy struct {
+	roles     map[string][]string
+	overrides tenant.RoleOverrideSource
+}
+
+// NewPermissionPolicy creates a policy over roles (DefaultRolePermissions if nil)
+// A nil overrides source applies the same roles to every tenant.
+func NewPermissionPolicy(roles map[string][]string, overrides tenant.RoleOverrideSource) *PermissionPolicy {
+	if roles == nil {
+		roles = DefaultRolePermissions()
+	}
+	return &PermissionPolicy{roles: roles, overrides: overrides}
+}
+
+// Permissions returns the permissions of the claims' role in tenantID
+// Only the role and the tenant's override for it count: permissions listed in the token
+// are ignored, so they cannot restore what an override removed. Tokens without a role
+// get no permissions, since otherwise the token alone would decide what they may do.
+// Unverified users are cut down to the UnverifiedPermissions their role covers.
+func (p *PermissionPolicy) Permissions(ctx context.Context, tenantID string, claims *Claims) ([]string, error) {
+	if claims.Role == "" {
+		return []string{}, nil
+	}
+
+	rolePermissions := p.roles[claims.Role]
+	if p.overrides != nil {
+		overrides, err := p.overrides.RolePermissions(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if override, ok := overrides[claims.Role]; ok {
+			rolePermissions = override
+		}
+	}
+	return p.limit(claims, slices.Clone(rolePermissions)), nil
+}
+
+// limit applies the unverified-email cut and returns permissions sorted without duplicates
+func (p *PermissionPolicy) limit(claims *Claims, permissions []string) []string {
+	if claims.Unverified() {
+		permissions = limitPermissions(permissions, UnverifiedPermissions)
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions)
+}
+
+// limitPermissions returns the permissions in limit that granted covers
+func limitPermissions(granted, limit []string) []string {
+	limited := []string{}
+	for _, permission := range limit {
+		if slices.ContainsFunc(granted, func(g string) bool { return MatchPermission(g, permission) }) {
+			limited = append(limited, permission)
+		}
+	}
+	return limited
+}
+
+// RolePermissionsMiddleware replaces the request's permissions with those granted by its role
+// Must run after tenant resolution so overrides are read for the agreed tenant;
+// RequirePermission then checks the expanded set.
+func RolePermissionsMiddleware(policy *PermissionPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(401, gin.H{"error": errors.ErrAuth("authentication required").Error()})
+			c.Abort()
+			return
+		}
+
+		permissions, err := policy.Permissions(c.Request.Context(), c.GetString("tenant_id"), claims)
+		if stderrors.Is(err, tenant.ErrUnknownTenant) {
+			c.JSON(403, gin.H{"error": errors.ErrTenant("tenant not found").Error()})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(503, gin.H{"error": errors.ErrPermission("role permissions unavailable").Error()})
+			c.Abort()
+			return
+		}
+
+		expanded := *claims
+		expanded.Permissions = permissions
+		setClaims(c, &expanded)
+		c.Next()
+	}
+}
+
+// RequirePermission rejects requests (403) whose permissions do not cover every listed permission
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(401, gin.H{"error": errors.ErrAuth("authentication required").Error()})
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				c.JSON(403, gin.H{"error": errors.ErrPermission("missing permission " + permission).Error()})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net"
+	"strings"
+
+	"crm-platform/pkg/errors"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TENANT RESOLVERS
+
+// TenantResolver finds the tenant a request targets from one source
+// An empty ID with a nil error means the source has no opinion about this request.
+type TenantResolver interface {
+	Name() string
+	ResolveTenant(c *gin.Context) (string, error)
+}
+
+// Claim resolver - tenant asserted by the credentials (set in auth middleware)
+type claimResolver struct{}
+
+// ClaimTenantResolver reads the tenant_id set by AuthMiddleware (JWT claim or dev header)
+func ClaimTenantResolver() TenantResolver {
+	return claimResolver{}
+}
+
+func (claimResolver) Name() string { return "claim" }
+
+func (claimResolver) ResolveTenant(c *gin.Context) (string, error) {
+	return c.GetString("tenant_id"), nil
+}
+
+// Header resolver - tenant named directly by a request header
+type headerResolver struct {
+	header string
+}
+
+// HeaderTenantResolver reads a tenant ID from header (e.g. X-Tenant-ID)
+// Headers are caller controlled; only use behind a trusted gateway or in development.
+func HeaderTenantResolver(header string) TenantResolver {
+	return headerResolver{header: header}
+}
+
+func (r headerResolver) Name() string { return "header" }
+
+func (r headerResolver) ResolveTenant(c *gin.Context) (string, error) {
+	return c.GetHeader(r.header), nil
+}
+
+// Subdomain resolver - <subdomain>.<baseDomain> hosts
+type subdomainResolver struct {
+	baseDomain string
+	lookup     tenant.SubdomainLookup
+	reserved   map[string]bool
+}
+
+// SubdomainTenantResolver maps <subdomain>.baseDomain hosts to tenants via lookup
+// Hosts outside baseDomain and reserved labels (www, api, ...) are ignored.
+func SubdomainTenantResolver(baseDomain string, lookup tenant.SubdomainLookup, reserved ...string) TenantResolver {
+	r := subdomainResolver{
+		baseDomain: strings.ToLower(strings.Trim(baseDomain, ".")),
+		lookup:     lookup,
+		reserved:   make(map[string]bool, len(reserved)),
+	}
+	for _, label := range reserved {
+		r.reserved[strings.ToLower(label)] = true
+	}
+	return r
+}
+
+func (r subdomainResolver) Name() string { return "subdomain" }
+
+func (r subdomainResolver) ResolveTenant(c *gin.Context) (string, error) {
+	if r.baseDomain == "" {
+		return "", nil
+	}
+
+	host := requestHost(c)
+	suffix := "." + r.baseDomain
+	if !strings.HasSuffix(host, suffix) {
+		return "", nil
+	}
+
+	// Only single-label subdomains identify a tenant
+	subdomain := strings.TrimSuffix(host, suffix)
+	if subdomain == "" || strings.Contains(subdomain, ".") || r.reserved[subdomain] {
+		return "", nil
+	}
+
+	return r.lookup.TenantIDBySubdomain(c.Request.Context(), subdomain)
+}
+
+// Domain resolver - custom domains mapped to tenants (crm.acme.com)
+type domainResolver struct {
+	domains map[string]string
+}
+
+// DomainTenantResolver maps full host names to tenant IDs
+func DomainTenantResolver(domains map[string]string) TenantResolver {
+	r := domainResolver{domains: make(map[string]string, len(domains))}
+	for domain, tenantID := range domains {
+		r.domains[strings.ToLower(strings.Trim(domain, "."))] = tenantID
+	}
+	return r
+}
+
+func (r domainResolver) Name() string { return "domain" }
+
+func (r domainResolver) ResolveTenant(c *gin.Context) (string, error) {
+	return r.domains[requestHost(c)], nil
+}
+
+// requestHost returns the lowercased request host without port
+func requestHost(c *gin.Context) string {
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// MIDDLEWARE
+
+// Resolve the request tenant from every resolver and require they agree
+// The agreed tenant is stored as tenant_id for TenantMiddleware; a token for
+// tenant A used on tenant B's host is rejected with 403.
+func TenantResolutionMiddleware(resolvers ...TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tenantID, source string
+
+		for _, resolver := range resolvers {
+			id, err := resolver.ResolveTenant(c)
+			if stderrors.Is(err, tenant.ErrUnknownTenant) {
+				c.JSON(404, gin.H{"error": errors.ErrTenant("unknown tenant for " + resolver.Name()).Error()})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(503, gin.H{"error": errors.ErrTenant("tenant resolution unavailable").Error()})
+				c.Abort()
+				return
+			}
+			if id == "" {
+				continue
+			}
+
+			// Cross-check: every source that names a tenant must name the same one
+			if tenantID != "" && id != tenantID {
+				c.JSON(403, gin.H{"error": errors.ErrTenant("tenant mismatch between " + source + " and " + resolver.Name()).Error()})
+				c.Abort()
+				return
+			}
+			if tenantID == "" {
+				tenantID, source = id, resolver.Name()
+			}
+		}
+
+		if tenantID == "" {
+			c.JSON(400, gin.H{"error": errors.ErrTenant("tenant could not be resolved").Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_id", tenantID)
+		c.Set("tenant_source", source)
+		c.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	stderrors "errors"
+
 	"crm-platform/pkg/errors"
 	"crm-platform/pkg/tenant"
 
@@ -8,7 +10,15 @@ import (
 )
 
 // Simple tenant middleware, convert gin context to global context
+// Only validates the tenant ID format; use TenantStatusMiddleware to enforce suspension.
 func TenantMiddleware() gin.HandlerFunc {
+	return TenantStatusMiddleware(nil)
+}
+
+// Tenant middleware that also rejects tenants that are not active
+// Suspended tenants get 403, pending (not yet provisioned) tenants get 423.
+// A nil resolver skips the status check.
+func TenantStatusMiddleware(resolver tenant.StatusResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get tenant ID from Gin context (set in auth middleware)
 		tenantID := c.GetString("tenant_id")
@@ -27,8 +37,39 @@ func TenantMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Check tenant lifecycle status
+		if resolver != nil && !checkTenantStatus(c, resolver, tenantID) {
+			c.Abort()
+			return
+		}
+
 		// Set tenant aware context
 		c.Request = c.Request.WithContext(tenantCtx)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// checkTenantStatus writes an error response and returns false unless the tenant is active
+func checkTenantStatus(c *gin.Context, resolver tenant.StatusResolver, tenantID string) bool {
+	status, err := resolver.TenantStatus(c.Request.Context(), tenantID)
+	if stderrors.Is(err, tenant.ErrUnknownTenant) {
+		c.JSON(403, gin.H{"error": errors.ErrTenant("tenant not found").Error()})
+		return false
+	}
+	if err != nil {
+		c.JSON(503, gin.H{"error": errors.ErrTenant("tenant status unavailable").Error()})
+		return false
+	}
+
+	switch status {
+	case tenant.StatusActive:
+		return true
+	case tenant.StatusSuspended:
+		c.JSON(403, gin.H{"error": errors.ErrTenantSuspended("tenant is suspended").Error()})
+	case tenant.StatusPending:
+		c.JSON(423, gin.H{"error": errors.ErrTenantPending("tenant is still being provisioned").Error()})
+	default:
+		c.JSON(403, gin.H{"error": errors.ErrTenant("tenant status " + status + " not allowed").Error()})
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strings"
+
+    "crm-platform/pkg/config"
+)
+
+// IsolationMode selects how tenant data is separated in PostgreSQL
+type IsolationMode string
+
+const (
+    // IsolationSchema gives every tenant its own tenant_<id> schema (default)
+    IsolationSchema IsolationMode = "schema"
+
+    // IsolationRLS stores all tenants in shared tables guarded by row-level security
+    IsolationRLS IsolationMode = "rls"
+)
+
+const (
+    // SharedSchemaName holds the shared tables used in IsolationRLS mode
+    SharedSchemaName = "tenant_shared"
+
+    // TenantIDSetting is the session variable RLS policies compare tenant_id against
+    TenantIDSetting = "app.tenant_id"
+)
+
+// Isolation error definitions
+var (
+    ErrInvalidIsolationMode = fmt.Errorf("invalid tenant isolation mode")
+    ErrSetTenantFailure     = fmt.Errorf("failed to set tenant for transaction")
+)
+
+// ParseIsolationMode validates an isolation mode name ("" means IsolationSchema)
+func ParseIsolationMode(value string) (IsolationMode, error) {
+    switch mode := IsolationMode(strings.ToLower(strings.TrimSpace(value))); mode {
+    case "":
+        return IsolationSchema, nil
+    case IsolationSchema, IsolationRLS:
+        return mode, nil
+    default:
+        return "", fmt.Errorf("%w: %q", ErrInvalidIsolationMode, value)
+    }
+}
+
+// IsolationModeFromEnv reads TENANT_ISOLATION_MODE, falling back to IsolationSchema
+func IsolationModeFromEnv() IsolationMode {
+    mode, err := ParseIsolationMode(config.GetTenantIsolationMode())
+    if err != nil {
+        log.Printf("Warning: %v, using %s isolation", err, IsolationSchema)
+        return IsolationSchema
+    }
+    return mode
+}
+
+// SetTransactionTenant scopes a transaction to a tenant for RLS policies
+// Both settings are transaction-local, so nothing leaks to the pooled connection.
+func SetTransactionTenant(ctx context.Context, exec Executor, tenantID string) error {
+    if err := validateID(tenantID); err != nil {
+        return fmt.Errorf("%w: %v", ErrSetTenantFailure, err)
+    }
+
+    _, err := exec.Exec(ctx,
+        "SELECT set_config($1, $2, true), set_config('search_path', $3, true)",
+        TenantIDSetting, tenantID, SharedSchemaName)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrSetTenantFailure, err)
+    }
+    return nil
+}
+
+// DeleteSharedTenantData removes every row a tenant owns in the shared tables
+// Tables are cleared children first so foreign keys are never violated.
+func DeleteSharedTenantData(ctx context.Context, exec Executor, tenantID string) error {
+    if err := SetTransactionTenant(ctx, exec, tenantID); err != nil {
+        return err
+    }
+
+    for _, table := range []string{"activities", "deals", "contacts", "companies", "users"} {
+        // RLS limits each DELETE to the tenant set above; the WHERE clause is a second guard
+        sql := fmt.Sprintf("DELETE FROM %s.%s WHERE tenant_id = $1", SharedSchemaName, table)
+        if _, err := exec.Exec(ctx, sql, tenantID); err != nil {
+            return fmt.Errorf("failed to delete tenant rows from %s: %w", table, err)
+        }
+    }
+    return nil
+}
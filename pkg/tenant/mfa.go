@@ -0,0 +1,103 @@
+package tenant
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// ErrMFAPolicyUnavailable is returned when a tenant's MFA requirements cannot be read or written
+var ErrMFAPolicyUnavailable = fmt.Errorf("failed to load tenant MFA policy")
+
+// DefaultMFAPolicyCacheTTL bounds how long an MFA requirement change takes to apply
+const DefaultMFAPolicyCacheTTL = time.Minute
+
+// MFAPolicySource returns the roles whose users must sign in with a second factor
+type MFAPolicySource interface {
+    MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error)
+}
+
+// MFAPolicyStore is an MFA policy source that can also change the requirements
+type MFAPolicyStore interface {
+    MFAPolicySource
+    SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error
+}
+
+// RegistryMFAPolicy reads tenants.mfa_required_roles from the global tenants table
+type RegistryMFAPolicy struct {
+    db Querier
+}
+
+// NewRegistryMFAPolicy creates an MFA policy source backed by the tenant registry
+func NewRegistryMFAPolicy(db Querier) *RegistryMFAPolicy {
+    return &RegistryMFAPolicy{db: db}
+}
+
+// MFARequiredRoles returns the tenant's required roles, or ErrUnknownTenant if no row exists
+func (r *RegistryMFAPolicy) MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+    var roles []string
+    err := r.db.QueryRow(ctx, "SELECT mfa_required_roles FROM public.tenants WHERE id = $1", tenantID).Scan(&roles)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrMFAPolicyUnavailable, err)
+    }
+    return roles, nil
+}
+
+// SetMFARequiredRoles replaces the roles that must use MFA in one tenant
+// An empty slice makes MFA optional for everyone; unknown roles violate a check constraint.
+func (r *RegistryMFAPolicy) SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+    if roles == nil {
+        roles = []string{}
+    }
+    tag, err := r.db.Exec(ctx, "UPDATE public.tenants SET mfa_required_roles = $2, updated_at = now() WHERE id = $1", tenantID, roles)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrMFAPolicyUnavailable, err)
+    }
+    if tag.RowsAffected() == 0 {
+        return fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+    }
+    return nil
+}
+
+// CachedMFAPolicy wraps an MFA policy store with an in-process TTL cache
+// Changes made through it apply at once in this process; other replicas see them
+// once their cached entry expires.
+type CachedMFAPolicy struct {
+    source MFAPolicyStore
+    cache  *ttlCache[[]string]
+}
+
+// NewCachedMFAPolicy caches source lookups for ttl (DefaultMFAPolicyCacheTTL if <= 0)
+func NewCachedMFAPolicy(source MFAPolicyStore, ttl time.Duration) *CachedMFAPolicy {
+    if ttl <= 0 {
+        ttl = DefaultMFAPolicyCacheTTL
+    }
+    return &CachedMFAPolicy{
+        source: source,
+        cache:  newTTLCache[[]string](ttl),
+    }
+}
+
+// MFARequiredRoles returns the cached roles, refreshing them from the source once expired
+func (c *CachedMFAPolicy) MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+    return c.cache.get(tenantID, func() ([]string, error) {
+        return c.source.MFARequiredRoles(ctx, tenantID)
+    })
+}
+
+// SetMFARequiredRoles writes the roles to the source and drops the cached ones
+func (c *CachedMFAPolicy) SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+    defer c.Invalidate(tenantID)
+    return c.source.SetMFARequiredRoles(ctx, tenantID, roles)
+}
+
+// Invalidate drops cached roles so the next lookup reads the source
+func (c *CachedMFAPolicy) Invalidate(tenantID string) {
+    c.cache.invalidate(tenantID)
+}
@@ -0,0 +1,70 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMFAStore keeps required roles per tenant and counts reads
+type memoryMFAStore struct {
+	roles  map[string][]string
+	setErr error // returned by SetMFARequiredRoles when set
+	reads  int
+}
+
+func (s *memoryMFAStore) MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+	s.reads++
+	return s.roles[tenantID], nil
+}
+
+func (s *memoryMFAStore) SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+	if s.setErr != nil {
+		return s.setErr
+	}
+	s.roles[tenantID] = roles
+	return nil
+}
+
+func TestCachedMFAPolicy_CachesReads(t *testing.T) {
+	store := &memoryMFAStore{roles: map[string][]string{"tenant-a": {"admin"}}}
+	policy := NewCachedMFAPolicy(store, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		roles, err := policy.MFARequiredRoles(context.Background(), "tenant-a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, roles)
+	}
+	assert.Equal(t, 1, store.reads)
+}
+
+func TestCachedMFAPolicy_SetInvalidates(t *testing.T) {
+	store := &memoryMFAStore{roles: map[string][]string{"tenant-a": {"admin"}, "tenant-b": {"admin"}}}
+	policy := NewCachedMFAPolicy(store, time.Minute)
+	ctx := context.Background()
+
+	_, err := policy.MFARequiredRoles(ctx, "tenant-a")
+	require.NoError(t, err)
+	_, err = policy.MFARequiredRoles(ctx, "tenant-b")
+	require.NoError(t, err)
+
+	require.NoError(t, policy.SetMFARequiredRoles(ctx, "tenant-a", []string{"admin", "manager"}))
+	roles, err := policy.MFARequiredRoles(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin", "manager"}, roles, "the change applies without waiting for the TTL")
+
+	_, err = policy.MFARequiredRoles(ctx, "tenant-b")
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.reads, "other tenants stay cached")
+}
+
+func TestCachedMFAPolicy_SetError(t *testing.T) {
+	store := &memoryMFAStore{roles: map[string][]string{}, setErr: ErrMFAPolicyUnavailable}
+	policy := NewCachedMFAPolicy(store, time.Minute)
+
+	err := policy.SetMFARequiredRoles(context.Background(), "tenant-a", []string{"admin"})
+	assert.ErrorIs(t, err, ErrMFAPolicyUnavailable)
+}
@@ -0,0 +1,493 @@
+package tenant
+
+import (
+    "context"
+    "embed"
+    "fmt"
+    "io/fs"
+    "log"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+
+    "crm-platform/pkg/database"
+    "github.com/jackc/pgx/v5"
+)
+
+// Migration error definitions
+var (
+    ErrInvalidMigrationName = fmt.Errorf("invalid migration file name")
+    ErrDuplicateMigration   = fmt.Errorf("duplicate migration version")
+    ErrNoMigrations         = fmt.Errorf("no migrations found")
+    ErrMigrationFailure     = fmt.Errorf("failed to apply migration")
+    ErrMigrationLock        = fmt.Errorf("failed to acquire migration lock")
+)
+
+const (
+    // TemplateSchemaName is the schema new tenants are cloned from
+    TemplateSchemaName = "tenant_template"
+
+    // MigrationsTable records applied migration versions inside each tenant schema
+    MigrationsTable = "schema_migrations"
+
+    // DefaultMigrationConcurrency limits how many schemas are migrated at once
+    DefaultMigrationConcurrency = 4
+)
+
+// Migration file names follow the repository convention: 000001_description.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.up\.sql$`)
+
+//go:embed migrations/*.up.sql
+var embeddedMigrations embed.FS
+
+// Migration is a single versioned change applied to every tenant schema
+type Migration struct {
+    Version int
+    Name    string
+    SQL     string
+}
+
+// SchemaStatus describes the migration state of one schema
+type SchemaStatus struct {
+    Schema         string `json:"schema"`
+    CurrentVersion int    `json:"current_version"`
+    LatestVersion  int    `json:"latest_version"`
+    Pending        []int  `json:"pending"`
+}
+
+// SchemaResult describes the outcome of migrating one schema
+type SchemaResult struct {
+    Schema      string        `json:"schema"`
+    FromVersion int           `json:"from_version"`
+    ToVersion   int           `json:"to_version"`
+    Applied     []int         `json:"applied"`
+    Duration    time.Duration `json:"duration"`
+    Error       string        `json:"error,omitempty"`
+}
+
+// MigrationReport summarizes a migration run across schemas
+type MigrationReport struct {
+    DryRun        bool           `json:"dry_run"`
+    LatestVersion int            `json:"latest_version"`
+    Succeeded     int            `json:"succeeded"`
+    Failed        int            `json:"failed"`
+    UpToDate      int            `json:"up_to_date"`
+    Results       []SchemaResult `json:"results"`
+}
+
+// MigrateOptions controls a migration run
+type MigrateOptions struct {
+    DryRun      bool     // Report pending migrations without applying them
+    Concurrency int      // Maximum schemas migrated in parallel
+    Schemas     []string // Limit the run to these schemas (all tenant schemas when empty)
+}
+
+// Migrator applies versioned migrations to the template and every tenant schema
+type Migrator struct {
+    pool       *database.Pool
+    migrations []Migration
+}
+
+// DefaultMigrations returns the tenant migrations embedded in this package
+func DefaultMigrations() ([]Migration, error) {
+    return LoadMigrations(embeddedMigrations, "migrations")
+}
+
+// LoadMigrations reads *.up.sql files from dir and returns them ordered by version
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+    entries, err := fs.ReadDir(fsys, dir)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations directory %s: %w", dir, err)
+    }
+
+    seen := make(map[int]string)
+    var migrations []Migration
+    for _, entry := range entries {
+        if entry.IsDir() {
+            continue
+        }
+
+        matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+        if matches == nil {
+            return nil, fmt.Errorf("%w: %s", ErrInvalidMigrationName, entry.Name())
+        }
+
+        version, err := strconv.Atoi(matches[1])
+        if err != nil || version < 1 {
+            return nil, fmt.Errorf("%w: %s", ErrInvalidMigrationName, entry.Name())
+        }
+        if existing, ok := seen[version]; ok {
+            return nil, fmt.Errorf("%w: %d (%s and %s)", ErrDuplicateMigration, version, existing, entry.Name())
+        }
+        seen[version] = entry.Name()
+
+        content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+        if err != nil {
+            return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+        }
+
+        migrations = append(migrations, Migration{
+            Version: version,
+            Name:    matches[2],
+            SQL:     string(content),
+        })
+    }
+
+    if len(migrations) == 0 {
+        return nil, fmt.Errorf("%w in %s", ErrNoMigrations, dir)
+    }
+
+    sort.Slice(migrations, func(i, j int) bool {
+        return migrations[i].Version < migrations[j].Version
+    })
+
+    return migrations, nil
+}
+
+// NewMigrator creates a migrator for the given migrations
+func NewMigrator(pool *database.Pool, migrations []Migration) *Migrator {
+    sorted := make([]Migration, len(migrations))
+    copy(sorted, migrations)
+    sort.Slice(sorted, func(i, j int) bool {
+        return sorted[i].Version < sorted[j].Version
+    })
+
+    return &Migrator{
+        pool:       pool,
+        migrations: sorted,
+    }
+}
+
+// LatestVersion returns the highest known migration version
+func (m *Migrator) LatestVersion() int {
+    if len(m.migrations) == 0 {
+        return 0
+    }
+    return m.migrations[len(m.migrations)-1].Version
+}
+
+// ListSchemas returns the template schema followed by every tenant schema
+func (m *Migrator) ListSchemas(ctx context.Context) ([]string, error) {
+    sql := `SELECT nspname FROM pg_namespace
+            WHERE nspname LIKE 'tenant\_%'
+            ORDER BY nspname <> $1, nspname`
+
+    rows, err := m.pool.Query(ctx, sql, TemplateSchemaName)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list tenant schemas: %w", err)
+    }
+    defer rows.Close()
+
+    var schemas []string
+    for rows.Next() {
+        var schemaName string
+        if err := rows.Scan(&schemaName); err != nil {
+            return nil, fmt.Errorf("failed to scan schema name: %w", err)
+        }
+        schemas = append(schemas, schemaName)
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating schema names: %w", err)
+    }
+
+    return schemas, nil
+}
+
+// SchemaVersion returns the highest migration version recorded in a schema (0 if none)
+func (m *Migrator) SchemaVersion(ctx context.Context, schemaName string) (int, error) {
+    return schemaVersion(ctx, m.pool, schemaName)
+}
+
+// schemaVersion reads the recorded version through pool, which may be a transaction
+func schemaVersion(ctx context.Context, pool Querier, schemaName string) (int, error) {
+    if err := validateSchemaName(schemaName); err != nil {
+        return 0, err
+    }
+
+    var exists bool
+    err := pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, migrationsTableName(schemaName)).Scan(&exists)
+    if err != nil {
+        return 0, fmt.Errorf("failed to check migrations table in %s: %w", schemaName, err)
+    }
+    if !exists {
+        return 0, nil
+    }
+
+    var version int
+    sql := fmt.Sprintf(`SELECT COALESCE(MAX(version), 0) FROM %s`, migrationsTableName(schemaName))
+    if err := pool.QueryRow(ctx, sql).Scan(&version); err != nil {
+        return 0, fmt.Errorf("failed to read migration version in %s: %w", schemaName, err)
+    }
+
+    return version, nil
+}
+
+// Status reports the current and pending versions for every tenant schema
+func (m *Migrator) Status(ctx context.Context) ([]SchemaStatus, error) {
+    schemas, err := m.ListSchemas(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]SchemaStatus, 0, len(schemas))
+    for _, schemaName := range schemas {
+        version, err := m.SchemaVersion(ctx, schemaName)
+        if err != nil {
+            return nil, err
+        }
+        statuses = append(statuses, SchemaStatus{
+            Schema:         schemaName,
+            CurrentVersion: version,
+            LatestVersion:  m.LatestVersion(),
+            Pending:        m.pendingVersions(version),
+        })
+    }
+
+    return statuses, nil
+}
+
+// MigrateSchema applies all pending migrations to a single schema
+// Each migration runs in its own transaction so a failure leaves the schema
+// at the last successfully applied version.
+func (m *Migrator) MigrateSchema(ctx context.Context, schemaName string) SchemaResult {
+    return m.migrateSchema(ctx, m.pool, schemaName, func(migration Migration) (bool, error) {
+        return m.applyMigration(ctx, schemaName, migration)
+    })
+}
+
+// MigrateSchemaTx applies all pending migrations to a single schema inside tx
+// Nothing is committed; on failure tx is aborted and the caller must roll it back.
+// Used while provisioning so the schema is built and migrated on one connection.
+func (m *Migrator) MigrateSchemaTx(ctx context.Context, tx pgx.Tx, schemaName string) SchemaResult {
+    return m.migrateSchema(ctx, tx, schemaName, func(migration Migration) (bool, error) {
+        return applyMigrationTx(ctx, tx, schemaName, migration)
+    })
+}
+
+// migrateSchema runs apply for every migration newer than the schema version read through pool
+func (m *Migrator) migrateSchema(ctx context.Context, pool Querier, schemaName string, apply func(Migration) (bool, error)) SchemaResult {
+    start := time.Now()
+    result := SchemaResult{Schema: schemaName, Applied: []int{}}
+
+    version, err := schemaVersion(ctx, pool, schemaName)
+    if err != nil {
+        result.Error = err.Error()
+        result.Duration = time.Since(start)
+        return result
+    }
+    result.FromVersion = version
+    result.ToVersion = version
+
+    for _, migration := range m.migrations {
+        if migration.Version <= result.ToVersion {
+            continue
+        }
+
+        applied, err := apply(migration)
+        if err != nil {
+            result.Error = err.Error()
+            break
+        }
+        if applied {
+            result.Applied = append(result.Applied, migration.Version)
+        }
+        result.ToVersion = migration.Version
+    }
+
+    result.Duration = time.Since(start)
+    return result
+}
+
+// MigrateAll applies pending migrations to the template and all tenant schemas
+// The template is migrated first so newly provisioned tenants start at the latest
+// version; tenant schemas are then migrated concurrently and a failure in one
+// schema never stops the others.
+func (m *Migrator) MigrateAll(ctx context.Context, opts MigrateOptions) (*MigrationReport, error) {
+    schemas := opts.Schemas
+    if len(schemas) == 0 {
+        var err error
+        schemas, err = m.ListSchemas(ctx)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    concurrency := opts.Concurrency
+    if concurrency < 1 {
+        concurrency = DefaultMigrationConcurrency
+    }
+
+    report := &MigrationReport{
+        DryRun:        opts.DryRun,
+        LatestVersion: m.LatestVersion(),
+        Results:       make([]SchemaResult, len(schemas)),
+    }
+
+    run := func(schemaName string) SchemaResult {
+        if err := ctx.Err(); err != nil {
+            return SchemaResult{Schema: schemaName, Applied: []int{}, Error: err.Error()}
+        }
+        if opts.DryRun {
+            return m.planSchema(ctx, schemaName)
+        }
+        return m.MigrateSchema(ctx, schemaName)
+    }
+
+    var wg sync.WaitGroup
+    sem := make(chan struct{}, concurrency)
+    for i, schemaName := range schemas {
+        if schemaName == TemplateSchemaName {
+            report.Results[i] = run(schemaName)
+            continue
+        }
+
+        wg.Add(1)
+        sem <- struct{}{}
+        go func(i int, schemaName string) {
+            defer wg.Done()
+            defer func() { <-sem }()
+            report.Results[i] = run(schemaName)
+        }(i, schemaName)
+    }
+    wg.Wait()
+
+    for _, result := range report.Results {
+        switch {
+        case result.Error != "":
+            report.Failed++
+            log.Printf("Migration failed for schema %s at version %d: %s", result.Schema, result.ToVersion, result.Error)
+        case len(result.Applied) == 0:
+            report.UpToDate++
+        default:
+            report.Succeeded++
+        }
+    }
+
+    return report, nil
+}
+
+// planSchema reports what MigrateSchema would apply without changing anything
+func (m *Migrator) planSchema(ctx context.Context, schemaName string) SchemaResult {
+    start := time.Now()
+    result := SchemaResult{Schema: schemaName, Applied: []int{}}
+
+    version, err := m.SchemaVersion(ctx, schemaName)
+    if err != nil {
+        result.Error = err.Error()
+        result.Duration = time.Since(start)
+        return result
+    }
+
+    result.FromVersion = version
+    result.ToVersion = m.LatestVersion()
+    if result.ToVersion < version {
+        result.ToVersion = version
+    }
+    result.Applied = m.pendingVersions(version)
+    result.Duration = time.Since(start)
+    return result
+}
+
+// applyMigration runs one migration in its own transaction and records it,
+// returning false if another runner applied it first
+func (m *Migrator) applyMigration(ctx context.Context, schemaName string, migration Migration) (bool, error) {
+    tx, err := m.pool.Pool.Begin(ctx)
+    if err != nil {
+        return false, fmt.Errorf("%w: %v", ErrFailedTransaction, err)
+    }
+    defer tx.Rollback(ctx)
+
+    applied, err := applyMigrationTx(ctx, tx, schemaName, migration)
+    if err != nil || !applied {
+        return false, err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return false, fmt.Errorf("failed to commit migration %d in %s: %w", migration.Version, schemaName, err)
+    }
+
+    log.Printf("Applied migration %d_%s to schema %s", migration.Version, migration.Name, schemaName)
+    return true, nil
+}
+
+// applyMigrationTx runs one migration and records it inside tx without committing,
+// returning false if another runner applied it first
+// The search path is restored afterwards so later statements in tx are unaffected.
+func applyMigrationTx(ctx context.Context, tx pgx.Tx, schemaName string, migration Migration) (bool, error) {
+    // Serialize runners per schema; released automatically at commit/rollback
+    if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, schemaName); err != nil {
+        return false, fmt.Errorf("%w for %s: %v", ErrMigrationLock, schemaName, err)
+    }
+
+    if err := ensureMigrationsTable(ctx, tx, schemaName); err != nil {
+        return false, err
+    }
+
+    var alreadyApplied bool
+    checkSQL := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE version = $1)`, migrationsTableName(schemaName))
+    if err := tx.QueryRow(ctx, checkSQL, migration.Version).Scan(&alreadyApplied); err != nil {
+        return false, fmt.Errorf("failed to check migration %d in %s: %w", migration.Version, schemaName, err)
+    }
+    if alreadyApplied {
+        return false, nil
+    }
+
+    var searchPath string
+    if err := tx.QueryRow(ctx, `SELECT current_setting('search_path')`).Scan(&searchPath); err != nil {
+        return false, fmt.Errorf("%w: %v", ErrSetSearchPathFailure, err)
+    }
+
+    // SET LOCAL keeps the search path scoped to this transaction
+    if _, err := tx.Exec(ctx, fmt.Sprintf(`SET LOCAL search_path TO "%s", public`, schemaName)); err != nil {
+        return false, fmt.Errorf("%w: %v", ErrSetSearchPathFailure, err)
+    }
+
+    if _, err := tx.Exec(ctx, migration.SQL); err != nil {
+        return false, fmt.Errorf("%w %d_%s to %s: %v", ErrMigrationFailure, migration.Version, migration.Name, schemaName, err)
+    }
+
+    if _, err := tx.Exec(ctx, `SELECT set_config('search_path', $1, true)`, searchPath); err != nil {
+        return false, fmt.Errorf("%w: %v", ErrSetSearchPathFailure, err)
+    }
+
+    insertSQL := fmt.Sprintf(`INSERT INTO %s (version, name) VALUES ($1, $2)`, migrationsTableName(schemaName))
+    if _, err := tx.Exec(ctx, insertSQL, migration.Version, migration.Name); err != nil {
+        return false, fmt.Errorf("failed to record migration %d in %s: %w", migration.Version, schemaName, err)
+    }
+
+    return true, nil
+}
+
+// pendingVersions lists migration versions newer than the given version
+func (m *Migrator) pendingVersions(version int) []int {
+    pending := []int{}
+    for _, migration := range m.migrations {
+        if migration.Version > version {
+            pending = append(pending, migration.Version)
+        }
+    }
+    return pending
+}
+
+// ensureMigrationsTable creates the version tracking table inside a schema
+func ensureMigrationsTable(ctx context.Context, tx pgx.Tx, schemaName string) error {
+    sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+        version INTEGER PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        applied_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+    )`, migrationsTableName(schemaName))
+
+    if _, err := tx.Exec(ctx, sql); err != nil {
+        return fmt.Errorf("failed to create migrations table in %s: %w", schemaName, err)
+    }
+    return nil
+}
+
+// migrationsTableName returns the quoted, schema-qualified migrations table name
+func migrationsTableName(schemaName string) string {
+    return fmt.Sprintf(`"%s".%s`, schemaName, MigrationsTable)
+}
@@ -0,0 +1,5 @@
+-- Baseline for tenant schemas
+-- Structure up to this point is created by migrations/000002_create_tenant_schema_template
+-- and copied into each tenant_<ulid> schema by CopyTemplateSchema. Recording this
+-- version lets every existing schema start tracking from the same point.
+SELECT 1;
@@ -0,0 +1,17 @@
+-- Reporting lines for record-level access: managers may access their team's records
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users(manager_id);
+
+-- A manager must belong to the same tenant. In tenant_shared that takes the composite
+-- key, and deleting the manager clears only manager_id, never tenant_id.
+DO $$
+BEGIN
+    IF current_schema() = 'tenant_shared' THEN
+        ALTER TABLE users ADD CONSTRAINT users_manager_id_fkey
+            FOREIGN KEY (tenant_id, manager_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (manager_id);
+    ELSE
+        ALTER TABLE users ADD CONSTRAINT users_manager_id_fkey
+            FOREIGN KEY (manager_id) REFERENCES users(id) ON DELETE SET NULL;
+    END IF;
+END $$;
@@ -0,0 +1,23 @@
+-- Columns the service user queries expect (auth-service and deal-service db/schema/users.sql)
+-- tenant_template predates them; status stays the lifecycle column and active the login switch.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS permissions JSONB DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS active BOOLEAN DEFAULT true,
+    ADD COLUMN IF NOT EXISTS last_login TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_by INTEGER REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- tenant_shared forces row level security on its owner too, and no app.tenant_id is set
+-- here, so the backfill would match no rows; lift FORCE just for this statement.
+DO $$
+BEGIN
+    IF current_schema() = 'tenant_shared' THEN
+        ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+        UPDATE users SET active = false WHERE status = 'inactive';
+        ALTER TABLE users FORCE ROW LEVEL SECURITY;
+    ELSE
+        UPDATE users SET active = false WHERE status = 'inactive';
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(active);
@@ -0,0 +1,47 @@
+-- Login sessions and their rotating refresh tokens (auth-service)
+-- Only a SHA-256 hash of each refresh token is stored. A token is single use:
+-- presenting a used one revokes its whole session.
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_reason VARCHAR(50)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_expires ON auth_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+-- Shared-table isolation: the same tables in tenant_shared carry tenant_id under RLS
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    IF current_schema() <> 'tenant_shared' THEN
+        RETURN;
+    END IF;
+
+    FOREACH t IN ARRAY ARRAY['auth_sessions', 'refresh_tokens'] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN tenant_id TEXT NOT NULL DEFAULT current_setting(''app.tenant_id'') REFERENCES public.tenants(id)', t);
+        EXECUTE format('CREATE INDEX ON %I (tenant_id)', t);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (tenant_id = current_setting(''app.tenant_id'', true))
+                WITH CHECK (tenant_id = current_setting(''app.tenant_id'', true))', t);
+    END LOOP;
+END $$;
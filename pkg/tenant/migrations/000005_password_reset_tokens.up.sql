@@ -0,0 +1,30 @@
+-- Password reset tokens (auth-service)
+-- Only a SHA-256 hash of each token is stored; the token itself is only in the reset email.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Rate limiting counts a user's recent tokens
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_created ON password_reset_tokens(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_expires ON password_reset_tokens(expires_at);
+
+-- Shared-table isolation: the same table in tenant_shared carries tenant_id under RLS
+DO $$
+BEGIN
+    IF current_schema() <> 'tenant_shared' THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE password_reset_tokens ADD COLUMN tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id);
+    CREATE INDEX ON password_reset_tokens (tenant_id);
+    ALTER TABLE password_reset_tokens ENABLE ROW LEVEL SECURITY;
+    ALTER TABLE password_reset_tokens FORCE ROW LEVEL SECURITY;
+    CREATE POLICY tenant_isolation ON password_reset_tokens
+        USING (tenant_id = current_setting('app.tenant_id', true))
+        WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+END $$;
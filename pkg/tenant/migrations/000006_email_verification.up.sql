@@ -0,0 +1,4 @@
+-- Email verification (auth-service)
+-- Verification tokens are signed and stateless; only the last send is recorded so resends can be throttled.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMPTZ;
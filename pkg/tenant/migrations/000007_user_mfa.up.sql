@@ -0,0 +1,34 @@
+-- TOTP multi-factor authentication (auth-service)
+-- mfa_secret is encrypted by auth-service; it is set at enrollment and only enforced once
+-- mfa_enabled is true. mfa_last_step is the last accepted TOTP time step, so codes work once.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS mfa_secret TEXT,
+    ADD COLUMN IF NOT EXISTS mfa_enabled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS mfa_last_step BIGINT;
+
+-- Single-use recovery codes; only a SHA-256 hash of each code is stored
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Shared-table isolation: the same table in tenant_shared carries tenant_id under RLS
+DO $$
+BEGIN
+    IF current_schema() <> 'tenant_shared' THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE mfa_recovery_codes ADD COLUMN tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant_id') REFERENCES public.tenants(id);
+    CREATE INDEX ON mfa_recovery_codes (tenant_id);
+    ALTER TABLE mfa_recovery_codes ENABLE ROW LEVEL SECURITY;
+    ALTER TABLE mfa_recovery_codes FORCE ROW LEVEL SECURITY;
+    CREATE POLICY tenant_isolation ON mfa_recovery_codes
+        USING (tenant_id = current_setting('app.tenant_id', true))
+        WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+END $$;
@@ -4,6 +4,7 @@ import (
     "context"
     "fmt"
 
+    "crm-platform/pkg/config"
     "crm-platform/pkg/database"
     "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgconn"
@@ -18,19 +19,92 @@ var (
 // TenantPool wraps database.Pool with tenant-aware functionality
 type TenantPool struct {
     *database.Pool
+    mode     IsolationMode
+    quota    *QuotaScheduler
+    timeouts *StatementTimeouts
 }
 
 // TenantTx wraps pgx.Tx with tenant context already set
 type TenantTx struct {
     pgx.Tx
     schemaName string
+    tenantID   string
+    pool       *database.Pool
+    release    func()
+}
+
+// TenantPoolOptions configures a TenantPool
+type TenantPoolOptions struct {
+    Mode              IsolationMode      // "" means IsolationSchema
+    Quota             *QuotaConfig       // nil disables per-tenant quotas
+    StatementTimeouts *StatementTimeouts // nil leaves statement_timeout at the server default
+}
+
+// TenantPoolOptionsFromConfig takes quotas and statement timeouts from the loaded
+// configuration and the isolation mode from TENANT_ISOLATION_MODE
+func TenantPoolOptionsFromConfig(cfg *config.Config) TenantPoolOptions {
+    return TenantPoolOptions{
+        Mode:              IsolationModeFromEnv(),
+        Quota:             QuotaConfigFromConfig(cfg.Tenant),
+        StatementTimeouts: StatementTimeoutsFromConfig(cfg.Tenant),
+    }
 }
 
 // NewTenantPool creates a new tenant-aware database pool
-func NewTenantPool(pool *database.Pool) *TenantPool {
-    return &TenantPool{
-        Pool: pool,
+// Options come from cfg (see TenantPoolOptionsFromConfig).
+func NewTenantPool(pool *database.Pool, cfg *config.Config) *TenantPool {
+    return NewTenantPoolWithOptions(pool, TenantPoolOptionsFromConfig(cfg))
+}
+
+// NewTenantPoolWithMode creates a tenant-aware database pool with an explicit isolation mode
+func NewTenantPoolWithMode(pool *database.Pool, mode IsolationMode) *TenantPool {
+    return NewTenantPoolWithOptions(pool, TenantPoolOptions{Mode: mode})
+}
+
+// NewTenantPoolWithQuota creates a tenant-aware database pool with per-tenant quotas
+func NewTenantPoolWithQuota(pool *database.Pool, mode IsolationMode, quota *QuotaConfig) *TenantPool {
+    return NewTenantPoolWithOptions(pool, TenantPoolOptions{Mode: mode, Quota: quota})
+}
+
+// NewTenantPoolWithOptions creates a tenant-aware database pool
+// A zero quota Capacity defaults to the pool's MaxConns.
+func NewTenantPoolWithOptions(pool *database.Pool, opts TenantPoolOptions) *TenantPool {
+    tp := &TenantPool{
+        Pool:     pool,
+        mode:     opts.Mode,
+        timeouts: opts.StatementTimeouts,
+    }
+    if tp.mode == "" {
+        tp.mode = IsolationSchema
     }
+
+    if opts.Quota != nil {
+        cfg := *opts.Quota
+        if cfg.Capacity <= 0 {
+            cfg.Capacity = int(pool.Config().MaxConns)
+        }
+        tp.quota = NewQuotaScheduler(cfg, pool.Metrics())
+    }
+
+    return tp
+}
+
+// Mode returns the isolation mode the pool enforces
+func (tp *TenantPool) Mode() IsolationMode {
+    return tp.mode
+}
+
+// acquireSlot waits for the tenant's quota; the release func is a no-op without quotas
+func (tp *TenantPool) acquireSlot(ctx context.Context) (func(), error) {
+    if tp.quota == nil {
+        return func() {}, nil
+    }
+
+    tenantID, err := FromContext(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+    }
+    return tp.quota.Acquire(ctx, tenantID)
 }
 
 // Query executes a query with tenant context isolation
@@ -39,13 +113,26 @@ func (tp *TenantPool) Query(ctx context.Context, sql string, args ...interface{}
     if !HasTenant(ctx) {
         return nil, fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")
     }
-    
-    // Always set search path before each query to handle connection reuse
-    if err := tp.ensureTenantSearchPath(ctx); err != nil {
-        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+
+    release, err := tp.acquireSlot(ctx)
+    if err != nil {
+        return nil, err
     }
-    
-    return tp.Pool.Query(ctx, sql, args...)
+
+    var rows pgx.Rows
+    if tp.mode == IsolationRLS {
+        rows, err = tp.queryRLS(ctx, sql, args...)
+    } else {
+        // Search path and query share one pinned connection
+        rows, err = tp.querySchema(ctx, sql, args...)
+    }
+    if err != nil {
+        release()
+        return nil, err
+    }
+
+    // The slot is held until the rows are consumed or closed
+    return &scopedRows{Rows: rows, end: endSlot(release)}, nil
 }
 
 // QueryRow executes a query that returns a single row with tenant context
@@ -54,13 +141,22 @@ func (tp *TenantPool) QueryRow(ctx context.Context, sql string, args ...interfac
     if !HasTenant(ctx) {
         return &errorRow{err: fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")}
     }
-    
-    // Always set search path before each query to handle connection reuse
-    if err := tp.ensureTenantSearchPath(ctx); err != nil {
-        return &errorRow{err: fmt.Errorf("SECURITY: tenant isolation failed: %w", err)}
+
+    release, err := tp.acquireSlot(ctx)
+    if err != nil {
+        return &errorRow{err: err}
     }
-    
-    return tp.Pool.QueryRow(ctx, sql, args...)
+
+    var row pgx.Row
+    if tp.mode == IsolationRLS {
+        row = tp.queryRowRLS(ctx, sql, args...)
+    } else {
+        // Search path and query share one pinned connection
+        row = tp.queryRowSchema(ctx, sql, args...)
+    }
+
+    // The slot is held until the row is scanned
+    return &scopedRow{Row: row, end: endSlot(release)}
 }
 
 // Exec executes a command with tenant context isolation
@@ -69,58 +165,133 @@ func (tp *TenantPool) Exec(ctx context.Context, sql string, args ...interface{})
     if !HasTenant(ctx) {
         return pgconn.CommandTag{}, fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")
     }
-    
-    // Set search path first
-    if err := tp.ensureTenantSearchPath(ctx); err != nil {
-        return pgconn.CommandTag{}, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+
+    release, err := tp.acquireSlot(ctx)
+    if err != nil {
+        return pgconn.CommandTag{}, err
+    }
+    defer release()
+
+    if tp.mode == IsolationRLS {
+        return tp.execRLS(ctx, sql, args...)
     }
     
-    return tp.Pool.Exec(ctx, sql, args...)
+    // Search path and command share one pinned connection
+    return tp.execSchema(ctx, sql, args...)
 }
 
 // Begin starts a tenant-aware transaction
 func (tp *TenantPool) Begin(ctx context.Context) (*TenantTx, error) {
+    return tp.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// BeginTx starts a tenant-aware transaction with opts
+// Only a read-only transaction (AccessMode pgx.ReadOnly) may be served by a read replica.
+func (tp *TenantPool) BeginTx(ctx context.Context, opts pgx.TxOptions) (*TenantTx, error) {
     // SECURITY: Explicit tenant validation - fail fast if no tenant context
     if !HasTenant(ctx) {
         return nil, fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")
     }
-    
+
+    // The slot is held until the transaction commits or rolls back
+    release, err := tp.acquireSlot(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    tx, schemaName, err := tp.beginTx(ctx, opts)
+    if err != nil {
+        release()
+        return nil, err
+    }
+    return tp.newTenantTx(ctx, tx, schemaName, release), nil
+}
+
+// beginTx starts a transaction scoped to the tenant in ctx and returns the schema it uses
+func (tp *TenantPool) beginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, string, error) {
+    if tp.mode == IsolationRLS {
+        tx, err := tp.beginRLS(ctx, opts)
+        return tx, SharedSchemaName, err
+    }
+
     schemaName, err := ExtractTenantSchema(ctx)
     if err != nil {
-        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+        return nil, "", fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
     }
     
-    tx, err := tp.Pool.Begin(ctx)
+    tx, err := tp.Pool.BeginTx(ctx, opts)
     if err != nil {
-        return nil, fmt.Errorf("%w: %v", ErrFailedTransaction, err)
+        return nil, "", fmt.Errorf("%w: %v", ErrFailedTransaction, err)
     }
     
-    // Set search path for the transaction
-    err = SetSearchPath(ctx, tx, schemaName)
+    // Set search path for this transaction only so it never outlives the connection checkout
+    err = SetLocalSearchPath(ctx, tx, schemaName)
     if err != nil {
         tx.Rollback(ctx)
-        return nil, fmt.Errorf("failed to set search path in transaction: %w", err)
+        return nil, "", fmt.Errorf("failed to set search path in transaction: %w", err)
+    }
+
+    if err := tp.applyStatementTimeout(ctx, tx, true); err != nil {
+        tx.Rollback(ctx)
+        return nil, "", err
     }
     
+    return tx, schemaName, nil
+}
+
+// newTenantTx wraps a transaction whose tenant settings are already applied
+func (tp *TenantPool) newTenantTx(ctx context.Context, tx pgx.Tx, schemaName string, release func()) *TenantTx {
+    tenantID, _ := FromContext(ctx)
     return &TenantTx{
         Tx:         tx,
         schemaName: schemaName,
-    }, nil
+        tenantID:   tenantID,
+        pool:       tp.Pool,
+        release:    release,
+    }
 }
 
 // Query executes a query within the tenant transaction
 func (tt *TenantTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
-    return tt.Tx.Query(ctx, sql, args...)
+    queryCtx, trace := tt.pool.TraceQuery(ctx, sql, args, tt.tenantID)
+    rows, err := tt.Tx.Query(queryCtx, sql, args...)
+    if err != nil {
+        trace.End(err)
+        return nil, err
+    }
+    return trace.Rows(rows), nil
 }
 
 // QueryRow executes a query that returns a single row within the tenant transaction
 func (tt *TenantTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-    return tt.Tx.QueryRow(ctx, sql, args...)
+    queryCtx, trace := tt.pool.TraceQuery(ctx, sql, args, tt.tenantID)
+    return trace.Row(tt.Tx.QueryRow(queryCtx, sql, args...))
 }
 
 // Exec executes a command within the tenant transaction
 func (tt *TenantTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
-    return tt.Tx.Exec(ctx, sql, args...)
+    queryCtx, trace := tt.pool.TraceQuery(ctx, sql, args, tt.tenantID)
+    tag, err := tt.Tx.Exec(queryCtx, sql, args...)
+    trace.End(err)
+    return tag, err
+}
+
+// Commit commits the tenant transaction and frees its quota slot
+func (tt *TenantTx) Commit(ctx context.Context) error {
+    defer tt.releaseSlot()
+    return tt.Tx.Commit(ctx)
+}
+
+// Rollback rolls back the tenant transaction and frees its quota slot
+func (tt *TenantTx) Rollback(ctx context.Context) error {
+    defer tt.releaseSlot()
+    return tt.Tx.Rollback(ctx)
+}
+
+func (tt *TenantTx) releaseSlot() {
+    if tt.release != nil {
+        tt.release()
+    }
 }
 
 // Close closes the underlying database pool
@@ -138,14 +309,12 @@ func (tp *TenantPool) HealthCheck(ctx context.Context) *database.HealthStatus {
     return tp.Pool.HealthCheck(ctx)
 }
 
-// ensureTenantSearchPath sets the search path for the current connection
-func (tp *TenantPool) ensureTenantSearchPath(ctx context.Context) error {
-    schemaName, err := ExtractTenantSchema(ctx)
-    if err != nil {
-        return fmt.Errorf("%w: %v", ErrSchemaWrapFailure, err)
+// endSlot frees a quota slot once the wrapped result has been consumed
+func endSlot(release func()) func(failed bool) error {
+    return func(bool) error {
+        release()
+        return nil
     }
-    
-    return SetSearchPath(ctx, tp.Pool, schemaName)
 }
 
 // errorRow implements pgx.Row for error cases
@@ -0,0 +1,91 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+
+    "crm-platform/pkg/database"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+)
+
+// Read-replica reads for TenantPool
+// Every statement runs in its own read-only transaction with the tenant's isolation
+// applied (search path or app.tenant_id), so database.Pool may serve it from a
+// healthy read replica and a write is rejected wherever it lands.
+
+// readOnlyTx is the transaction a TenantReader statement runs in
+var readOnlyTx = pgx.TxOptions{AccessMode: pgx.ReadOnly}
+
+// TenantReader runs tenant queries that tolerate replication lag
+// It satisfies the sqlc DBTX interface; Exec always fails with database.ErrReadOnly.
+type TenantReader struct {
+    tp *TenantPool
+}
+
+// Reader returns the pool's read-replica view
+func (tp *TenantPool) Reader() *TenantReader {
+    return &TenantReader{tp: tp}
+}
+
+// Query executes a read-only query with tenant context isolation
+func (r *TenantReader) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+    // SECURITY: Explicit tenant validation - fail fast if no tenant context
+    if !HasTenant(ctx) {
+        return nil, fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")
+    }
+
+    release, err := r.tp.acquireSlot(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    tx, _, err := r.tp.beginTx(ctx, readOnlyTx)
+    if err != nil {
+        release()
+        return nil, err
+    }
+
+    queryCtx, trace := r.tp.traceQuery(ctx, sql, args)
+    rows, err := tx.Query(queryCtx, sql, args...)
+    if err != nil {
+        trace.End(err)
+        tx.Rollback(ctx)
+        release()
+        return nil, err
+    }
+
+    // The transaction and the slot end when the rows are consumed or closed
+    rows = &scopedRows{Rows: trace.Rows(rows), end: endTx(ctx, tx)}
+    return &scopedRows{Rows: rows, end: endSlot(release)}, nil
+}
+
+// QueryRow executes a read-only query that returns a single row with tenant context
+func (r *TenantReader) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+    // SECURITY: Explicit tenant validation - fail fast if no tenant context
+    if !HasTenant(ctx) {
+        return &errorRow{err: fmt.Errorf("SECURITY VIOLATION: database operation attempted without tenant context")}
+    }
+
+    release, err := r.tp.acquireSlot(ctx)
+    if err != nil {
+        return &errorRow{err: err}
+    }
+
+    tx, _, err := r.tp.beginTx(ctx, readOnlyTx)
+    if err != nil {
+        release()
+        return &errorRow{err: err}
+    }
+
+    queryCtx, trace := r.tp.traceQuery(ctx, sql, args)
+    row := &scopedRow{Row: trace.Row(tx.QueryRow(queryCtx, sql, args...)), end: endTx(ctx, tx)}
+
+    // The transaction and the slot end after Scan
+    return &scopedRow{Row: row, end: endSlot(release)}
+}
+
+// Exec refuses to run commands; send them through TenantPool.Exec
+func (r *TenantReader) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+    return pgconn.CommandTag{}, database.ErrReadOnly
+}
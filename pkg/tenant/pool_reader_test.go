@@ -0,0 +1,27 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"crm-platform/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantReader_RejectsCommands(t *testing.T) {
+	reader := NewTenantPoolWithMode(nil, IsolationRLS).Reader()
+
+	_, err := reader.Exec(context.Background(), "DELETE FROM deals")
+	assert.ErrorIs(t, err, database.ErrReadOnly)
+}
+
+func TestTenantReader_RequiresTenant(t *testing.T) {
+	reader := NewTenantPoolWithMode(nil, IsolationSchema).Reader()
+
+	_, err := reader.Query(context.Background(), "SELECT 1")
+	assert.ErrorContains(t, err, "without tenant context")
+
+	var one int
+	assert.ErrorContains(t, reader.QueryRow(context.Background(), "SELECT 1").Scan(&one), "without tenant context")
+}
@@ -0,0 +1,103 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+)
+
+// Row-level-security isolation for TenantPool
+// Every operation runs in its own transaction with app.tenant_id set via
+// SET LOCAL semantics, so the setting and the statement always share a connection.
+
+// beginRLS starts a transaction with opts scoped to the tenant in ctx
+func (tp *TenantPool) beginRLS(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+    tenantID, err := FromContext(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+    }
+
+    tx, err := tp.Pool.BeginTx(ctx, opts)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrFailedTransaction, err)
+    }
+
+    if err := SetTransactionTenant(ctx, tx, tenantID); err != nil {
+        tx.Rollback(ctx)
+        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+    }
+
+    if err := tp.applyStatementTimeout(ctx, tx, true); err != nil {
+        tx.Rollback(ctx)
+        return nil, err
+    }
+
+    return tx, nil
+}
+
+// queryRLS runs a query in a tenant transaction that ends when the rows are closed
+func (tp *TenantPool) queryRLS(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+    tx, err := tp.beginRLS(ctx, pgx.TxOptions{})
+    if err != nil {
+        return nil, err
+    }
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    rows, err := tx.Query(queryCtx, sql, args...)
+    if err != nil {
+        trace.End(err)
+        tx.Rollback(ctx)
+        return nil, err
+    }
+
+    return &scopedRows{Rows: trace.Rows(rows), end: endTx(ctx, tx)}, nil
+}
+
+// queryRowRLS runs a single-row query in a tenant transaction that ends on Scan
+func (tp *TenantPool) queryRowRLS(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+    tx, err := tp.beginRLS(ctx, pgx.TxOptions{})
+    if err != nil {
+        return &errorRow{err: err}
+    }
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    row := trace.Row(tx.QueryRow(queryCtx, sql, args...))
+
+    return &scopedRow{Row: row, end: endTx(ctx, tx)}
+}
+
+// execRLS runs a command in its own tenant transaction
+func (tp *TenantPool) execRLS(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+    tx, err := tp.beginRLS(ctx, pgx.TxOptions{})
+    if err != nil {
+        return pgconn.CommandTag{}, err
+    }
+    defer tx.Rollback(ctx)
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    tag, err := tx.Exec(queryCtx, sql, args...)
+    trace.End(err)
+    if err != nil {
+        return tag, err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return pgconn.CommandTag{}, fmt.Errorf("failed to commit tenant transaction: %w", err)
+    }
+    return tag, nil
+}
+
+// endTx commits after success so INSERT/UPDATE ... RETURNING statements persist
+func endTx(ctx context.Context, tx pgx.Tx) func(failed bool) error {
+    return func(failed bool) error {
+        if failed {
+            return tx.Rollback(ctx)
+        }
+        if err := tx.Commit(ctx); err != nil {
+            return fmt.Errorf("failed to commit tenant transaction: %w", err)
+        }
+        return nil
+    }
+}
@@ -0,0 +1,171 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "crm-platform/pkg/database"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Connection-pinned sessions for schema isolation
+// Each operation acquires one connection, sets the search path (and the tenant's
+// statement_timeout) on it, runs the statement on that same connection and resets
+// every session setting before release.
+
+// resetTimeout bounds the RESET run when a connection goes back to the pool
+const resetTimeout = 5 * time.Second
+
+// acquireTenantConn acquires a connection with its search path set to the tenant schema
+func (tp *TenantPool) acquireTenantConn(ctx context.Context) (*pgxpool.Conn, error) {
+    schemaName, err := ExtractTenantSchema(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrSchemaWrapFailure, err)
+    }
+
+    conn, err := tp.Pool.Acquire(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to acquire connection: %w", err)
+    }
+
+    if err := SetSearchPath(ctx, conn, schemaName); err != nil {
+        releaseTenantConn(conn)
+        return nil, err
+    }
+
+    if err := tp.applyStatementTimeout(ctx, conn, false); err != nil {
+        releaseTenantConn(conn)
+        return nil, err
+    }
+
+    return conn, nil
+}
+
+// releaseTenantConn resets session settings and returns the connection to the pool
+// A connection that cannot be reset is closed so no tenant setting is ever reused.
+func releaseTenantConn(conn *pgxpool.Conn) {
+    ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+    defer cancel()
+
+    if _, err := conn.Exec(ctx, "RESET ALL"); err != nil {
+        conn.Hijack().Close(ctx)
+        return
+    }
+    conn.Release()
+}
+
+// querySchema runs a query on a pinned connection released when the rows are closed
+func (tp *TenantPool) querySchema(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+    conn, err := tp.acquireTenantConn(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+    }
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    rows, err := conn.Query(queryCtx, sql, args...)
+    if err != nil {
+        trace.End(err)
+        releaseTenantConn(conn)
+        return nil, err
+    }
+
+    return &scopedRows{Rows: trace.Rows(rows), end: endConn(conn)}, nil
+}
+
+// queryRowSchema runs a single-row query on a pinned connection released after Scan
+func (tp *TenantPool) queryRowSchema(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+    conn, err := tp.acquireTenantConn(ctx)
+    if err != nil {
+        return &errorRow{err: fmt.Errorf("SECURITY: tenant isolation failed: %w", err)}
+    }
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    row := trace.Row(conn.QueryRow(queryCtx, sql, args...))
+
+    return &scopedRow{Row: row, end: endConn(conn)}
+}
+
+// execSchema runs a command on a pinned connection
+func (tp *TenantPool) execSchema(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+    conn, err := tp.acquireTenantConn(ctx)
+    if err != nil {
+        return pgconn.CommandTag{}, fmt.Errorf("SECURITY: tenant isolation failed: %w", err)
+    }
+    defer releaseTenantConn(conn)
+
+    queryCtx, trace := tp.traceQuery(ctx, sql, args)
+    tag, err := conn.Exec(queryCtx, sql, args...)
+    trace.End(err)
+    return tag, err
+}
+
+// traceQuery starts metrics and observers for a statement, labeled with its tenant
+func (tp *TenantPool) traceQuery(ctx context.Context, sql string, args []interface{}) (context.Context, *database.QueryTrace) {
+    tenantID, _ := FromContext(ctx)
+    return tp.Pool.TraceQuery(ctx, sql, args, tenantID)
+}
+
+// endConn releases a pinned connection once its result has been consumed
+func endConn(conn *pgxpool.Conn) func(failed bool) error {
+    return func(bool) error {
+        releaseTenantConn(conn)
+        return nil
+    }
+}
+
+// scopedRows ends its session (connection or transaction) once iteration ends or Close is called
+type scopedRows struct {
+    pgx.Rows
+    end    func(failed bool) error
+    done   bool
+    endErr error
+}
+
+// Next advances the rows and ends the session after the last row
+func (r *scopedRows) Next() bool {
+    if r.Rows.Next() {
+        return true
+    }
+    r.finish()
+    return false
+}
+
+// Close closes the rows and ends the session
+func (r *scopedRows) Close() {
+    r.finish()
+}
+
+// Err reports the rows error, or the error from ending the session
+func (r *scopedRows) Err() error {
+    if err := r.Rows.Err(); err != nil {
+        return err
+    }
+    return r.endErr
+}
+
+func (r *scopedRows) finish() {
+    if r.done {
+        return
+    }
+    r.done = true
+    r.Rows.Close()
+    r.endErr = r.end(r.Rows.Err() != nil)
+}
+
+// scopedRow ends its session after Scan
+type scopedRow struct {
+    pgx.Row
+    end func(failed bool) error
+}
+
+// Scan reads the row and ends the session
+func (r *scopedRow) Scan(dest ...interface{}) error {
+    if err := r.Row.Scan(dest...); err != nil {
+        r.end(true)
+        return err
+    }
+    return r.end(false)
+}
@@ -0,0 +1,280 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "crm-platform/pkg/config"
+    "crm-platform/pkg/database"
+)
+
+// Quota error definitions
+var (
+    ErrTenantQuotaExceeded = fmt.Errorf("tenant connection quota exceeded")
+    ErrPoolSaturated       = fmt.Errorf("database pool saturated by all tenants")
+    ErrTenantQueueTimeout  = fmt.Errorf("timed out waiting for tenant connection slot")
+)
+
+const (
+    // DefaultQueueTimeout bounds how long an operation waits for a tenant slot
+    DefaultQueueTimeout = config.DefaultTenantQueueTimeout
+
+    // DefaultMaxQueued is how many operations one tenant may have waiting by default
+    DefaultMaxQueued = config.DefaultTenantMaxQueued
+)
+
+// QuotaConfig limits how much of the shared pool a single tenant can hold
+type QuotaConfig struct {
+    MaxConcurrent int            // Operations one tenant may run at once
+    MaxQueued     int            // Operations one tenant may have waiting, beyond this they are rejected (DefaultMaxQueued if 0, none if negative)
+    QueueTimeout  time.Duration  // Longest wait for a slot before giving up
+    Capacity      int            // Operations all tenants may run at once (normally the pool's MaxConns)
+    Overrides     map[string]int // Per-tenant MaxConcurrent overrides
+}
+
+// QuotaConfigFromConfig converts the loaded tenant settings into quotas
+// Returns nil (quotas disabled) when max_concurrent is 0; a max_queued of 0 disables queueing.
+func QuotaConfigFromConfig(cfg config.TenantConfig) *QuotaConfig {
+    if cfg.MaxConcurrent <= 0 {
+        return nil
+    }
+
+    quota := &QuotaConfig{
+        MaxConcurrent: cfg.MaxConcurrent,
+        MaxQueued:     cfg.MaxQueued,
+        QueueTimeout:  cfg.QueueTimeout,
+        Overrides:     make(map[string]int, len(cfg.QuotaOverrides)),
+    }
+    if quota.MaxQueued == 0 {
+        quota.MaxQueued = -1 // explicitly no queue: reject once the limit is reached
+    }
+    for tenantID, limit := range cfg.QuotaOverrides {
+        quota.Overrides[tenantID] = limit
+    }
+
+    return quota
+}
+
+// QuotaScheduler hands out connection slots with per-tenant limits
+// When slots free up, waiting tenants are served round-robin so one tenant with a
+// deep queue cannot starve the others; within a tenant, waiters are served FIFO.
+type QuotaScheduler struct {
+    cfg     QuotaConfig
+    metrics *database.Metrics
+
+    mu      sync.Mutex
+    inUse   int
+    tenants map[string]*tenantQueue
+    ring    []string // tenants with waiters, in round-robin order
+    next    int      // ring position to serve next
+}
+
+type tenantQueue struct {
+    active  int
+    waiters []*slotWaiter
+}
+
+type slotWaiter struct {
+    ready   chan struct{}
+    granted bool
+}
+
+// NewQuotaScheduler creates a scheduler; metrics may be nil
+func NewQuotaScheduler(cfg QuotaConfig, metrics *database.Metrics) *QuotaScheduler {
+    if cfg.QueueTimeout <= 0 {
+        cfg.QueueTimeout = DefaultQueueTimeout
+    }
+    if cfg.MaxQueued == 0 {
+        cfg.MaxQueued = DefaultMaxQueued
+    }
+    return &QuotaScheduler{
+        cfg:     cfg,
+        metrics: metrics,
+        tenants: make(map[string]*tenantQueue),
+    }
+}
+
+// Acquire blocks until the tenant may run one operation and returns its release func
+func (s *QuotaScheduler) Acquire(ctx context.Context, tenantID string) (func(), error) {
+    start := time.Now()
+
+    s.mu.Lock()
+    q := s.queue(tenantID)
+    if len(q.waiters) == 0 && s.canRun(tenantID, q) {
+        q.active++
+        s.inUse++
+        s.mu.Unlock()
+        s.metrics.RecordTenantAcquire(tenantID, 0)
+        return s.releaser(tenantID), nil
+    }
+
+    if len(q.waiters) >= max(s.cfg.MaxQueued, 0) {
+        active, queued, inUse := q.active, len(q.waiters), s.inUse
+        overLimit := active >= s.limit(tenantID)
+        if active == 0 && queued == 0 {
+            delete(s.tenants, tenantID)
+        }
+        s.mu.Unlock()
+        s.metrics.IncrementTenantRejections(tenantID)
+        if !overLimit {
+            // The tenant is within its own limit: every slot is held by the tenants together
+            return nil, fmt.Errorf("%w: %d of %d slots in use, tenant %s has %d queued",
+                ErrPoolSaturated, inUse, s.cfg.Capacity, tenantID, queued)
+        }
+        return nil, fmt.Errorf("%w: tenant %s has %d operations running and %d queued",
+            ErrTenantQuotaExceeded, tenantID, active, queued)
+    }
+
+    w := &slotWaiter{ready: make(chan struct{})}
+    q.waiters = append(q.waiters, w)
+    if len(q.waiters) == 1 {
+        s.ring = append(s.ring, tenantID)
+    }
+    s.mu.Unlock()
+
+    timer := time.NewTimer(s.cfg.QueueTimeout)
+    defer timer.Stop()
+
+    var waitErr error
+    select {
+    case <-w.ready:
+    case <-timer.C:
+        waitErr = fmt.Errorf("%w: tenant %s after %v", ErrTenantQueueTimeout, tenantID, s.cfg.QueueTimeout)
+    case <-ctx.Done():
+        waitErr = ctx.Err()
+    }
+
+    if waitErr != nil {
+        s.mu.Lock()
+        if !w.granted {
+            s.removeWaiter(tenantID, w)
+            s.mu.Unlock()
+            s.metrics.IncrementTenantRejections(tenantID)
+            return nil, waitErr
+        }
+        // Granted while giving up: keep the slot rather than leak it
+        s.mu.Unlock()
+    }
+
+    s.metrics.RecordTenantAcquire(tenantID, time.Since(start))
+    return s.releaser(tenantID), nil
+}
+
+// releaser returns a release func that is safe to call more than once
+func (s *QuotaScheduler) releaser(tenantID string) func() {
+    var once sync.Once
+    return func() {
+        once.Do(func() { s.release(tenantID) })
+    }
+}
+
+func (s *QuotaScheduler) release(tenantID string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    q := s.tenants[tenantID]
+    q.active--
+    s.inUse--
+    s.dispatch()
+
+    if q.active == 0 && len(q.waiters) == 0 {
+        delete(s.tenants, tenantID)
+    }
+}
+
+// dispatch grants free slots to waiting tenants round-robin; caller holds the lock
+func (s *QuotaScheduler) dispatch() {
+    for s.hasCapacity() && len(s.ring) > 0 {
+        granted := false
+        for i := 0; i < len(s.ring); i++ {
+            pos := (s.next + i) % len(s.ring)
+            tenantID := s.ring[pos]
+            q := s.tenants[tenantID]
+            if !s.canRun(tenantID, q) {
+                continue
+            }
+
+            w := q.waiters[0]
+            q.waiters = q.waiters[1:]
+            q.active++
+            s.inUse++
+            w.granted = true
+            close(w.ready)
+
+            if len(q.waiters) == 0 {
+                s.ring = append(s.ring[:pos], s.ring[pos+1:]...)
+                s.next = pos
+            } else {
+                s.next = pos + 1
+            }
+            if len(s.ring) > 0 {
+                s.next %= len(s.ring)
+            } else {
+                s.next = 0
+            }
+
+            granted = true
+            break
+        }
+        if !granted {
+            return
+        }
+    }
+}
+
+func (s *QuotaScheduler) removeWaiter(tenantID string, w *slotWaiter) {
+    q := s.tenants[tenantID]
+    for i, waiter := range q.waiters {
+        if waiter == w {
+            q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+            break
+        }
+    }
+    if len(q.waiters) > 0 {
+        return
+    }
+
+    for i, id := range s.ring {
+        if id == tenantID {
+            s.ring = append(s.ring[:i], s.ring[i+1:]...)
+            if s.next > i {
+                s.next--
+            }
+            break
+        }
+    }
+    if len(s.ring) == 0 {
+        s.next = 0
+    } else {
+        s.next %= len(s.ring)
+    }
+    if q.active == 0 {
+        delete(s.tenants, tenantID)
+    }
+}
+
+func (s *QuotaScheduler) queue(tenantID string) *tenantQueue {
+    q, ok := s.tenants[tenantID]
+    if !ok {
+        q = &tenantQueue{}
+        s.tenants[tenantID] = q
+    }
+    return q
+}
+
+func (s *QuotaScheduler) canRun(tenantID string, q *tenantQueue) bool {
+    return s.hasCapacity() && q.active < s.limit(tenantID)
+}
+
+func (s *QuotaScheduler) hasCapacity() bool {
+    return s.cfg.Capacity <= 0 || s.inUse < s.cfg.Capacity
+}
+
+func (s *QuotaScheduler) limit(tenantID string) int {
+    if n, ok := s.cfg.Overrides[tenantID]; ok {
+        return n
+    }
+    return s.cfg.MaxConcurrent
+}
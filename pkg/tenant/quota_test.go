@@ -0,0 +1,299 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireAsync starts an Acquire and reports its result on the returned channel
+func acquireAsync(ctx context.Context, s *QuotaScheduler, tenantID string) chan acquireResult {
+	done := make(chan acquireResult, 1)
+	go func() {
+		release, err := s.Acquire(ctx, tenantID)
+		done <- acquireResult{tenantID: tenantID, release: release, err: err}
+	}()
+	return done
+}
+
+type acquireResult struct {
+	tenantID string
+	release  func()
+	err      error
+}
+
+// waitQueued blocks until tenantID has n waiters
+func waitQueued(t *testing.T, s *QuotaScheduler, tenantID string, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		q, ok := s.tenants[tenantID]
+		return ok && len(q.waiters) == n
+	}, time.Second, time.Millisecond)
+}
+
+func mustAcquire(t *testing.T, s *QuotaScheduler, tenantID string) func() {
+	t.Helper()
+	release, err := s.Acquire(context.Background(), tenantID)
+	require.NoError(t, err)
+	return release
+}
+
+func receive(t *testing.T, done chan acquireResult) acquireResult {
+	t.Helper()
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not return")
+		return acquireResult{}
+	}
+}
+
+func assertPending(t *testing.T, done chan acquireResult) {
+	t.Helper()
+	select {
+	case result := <-done:
+		t.Fatalf("acquire for %s returned early (err: %v)", result.tenantID, result.err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestNewQuotaScheduler_Defaults(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 1}, nil)
+	assert.Equal(t, DefaultMaxQueued, s.cfg.MaxQueued)
+	assert.Equal(t, DefaultQueueTimeout, s.cfg.QueueTimeout)
+}
+
+func TestNewQuotaScheduler_ConfigDefaultQueues(t *testing.T) {
+	tenantConfig := config.Defaults().Tenant
+	tenantConfig.MaxConcurrent = 1
+
+	cfg := QuotaConfigFromConfig(tenantConfig)
+	require.NotNil(t, cfg)
+	s := NewQuotaScheduler(*cfg, nil)
+	assert.Equal(t, DefaultMaxQueued, s.cfg.MaxQueued)
+
+	release := mustAcquire(t, s, "tenant-a")
+	done := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 1)
+	release()
+	result := receive(t, done)
+	require.NoError(t, result.err)
+	result.release()
+}
+
+func TestQuotaConfigFromConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		maxQueued     int
+		wantNil       bool
+		wantQueued    int
+	}{
+		{name: "disabled without max concurrent", wantNil: true},
+		{name: "default queue size", maxConcurrent: 4, maxQueued: config.DefaultTenantMaxQueued, wantQueued: DefaultMaxQueued},
+		{name: "explicit queue size", maxConcurrent: 4, maxQueued: 10, wantQueued: 10},
+		{name: "zero disables queueing", maxConcurrent: 4, maxQueued: 0, wantQueued: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := QuotaConfigFromConfig(config.TenantConfig{MaxConcurrent: tt.maxConcurrent, MaxQueued: tt.maxQueued})
+			if tt.wantNil {
+				assert.Nil(t, cfg)
+				return
+			}
+			require.NotNil(t, cfg)
+			assert.Equal(t, 4, cfg.MaxConcurrent)
+			assert.Equal(t, tt.wantQueued, cfg.MaxQueued)
+		})
+	}
+}
+
+func TestQuotaConfigFromConfig_TimeoutAndOverrides(t *testing.T) {
+	overrides := map[string]int{"big": 8, "small": 1}
+	cfg := QuotaConfigFromConfig(config.TenantConfig{
+		MaxConcurrent:  2,
+		MaxQueued:      5,
+		QueueTimeout:   250 * time.Millisecond,
+		QuotaOverrides: overrides,
+	})
+	require.NotNil(t, cfg)
+	assert.Equal(t, 250*time.Millisecond, cfg.QueueTimeout)
+	assert.Equal(t, overrides, cfg.Overrides)
+
+	overrides["big"] = 1
+	assert.Equal(t, 8, cfg.Overrides["big"], "overrides are copied")
+}
+
+func TestStatementTimeoutsFromConfig(t *testing.T) {
+	assert.Nil(t, StatementTimeoutsFromConfig(config.TenantConfig{}))
+
+	timeouts := StatementTimeoutsFromConfig(config.TenantConfig{
+		StatementTimeout:          30 * time.Second,
+		StatementTimeoutOverrides: map[string]time.Duration{"slow": time.Minute},
+	})
+	assert.Equal(t, time.Minute, timeouts.For("slow"))
+	assert.Equal(t, 30*time.Second, timeouts.For("other"))
+
+	overridesOnly := StatementTimeoutsFromConfig(config.TenantConfig{
+		StatementTimeoutOverrides: map[string]time.Duration{"slow": time.Minute},
+	})
+	assert.Equal(t, time.Duration(0), overridesOnly.For("other"))
+}
+
+func TestAcquire_QueuesFIFOWithinTenant(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 1, QueueTimeout: time.Second}, nil)
+	release := mustAcquire(t, s, "tenant-a")
+
+	first := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 1)
+	second := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 2)
+	assertPending(t, first)
+
+	release()
+	result := receive(t, first)
+	require.NoError(t, result.err)
+	assertPending(t, second)
+
+	result.release()
+	result = receive(t, second)
+	require.NoError(t, result.err)
+	result.release()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Zero(t, s.inUse)
+	assert.Empty(t, s.tenants)
+}
+
+func TestAcquire_RoundRobinAcrossTenants(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 2, Capacity: 1, QueueTimeout: time.Second}, nil)
+	release := mustAcquire(t, s, "tenant-a")
+
+	// tenant-a queues two operations before tenant-b queues one
+	a1 := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 1)
+	a2 := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 2)
+	b1 := acquireAsync(context.Background(), s, "tenant-b")
+	waitQueued(t, s, "tenant-b", 1)
+
+	release()
+	result := receive(t, a1)
+	require.NoError(t, result.err)
+
+	result.release()
+	result = receive(t, b1)
+	require.NoError(t, result.err, "tenant-b is served before tenant-a's second waiter")
+	assertPending(t, a2)
+
+	result.release()
+	result = receive(t, a2)
+	require.NoError(t, result.err)
+	result.release()
+}
+
+func TestAcquire_TenantLimitAndOverride(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{
+		MaxConcurrent: 1,
+		MaxQueued:     -1,
+		Overrides:     map[string]int{"big": 2},
+	}, nil)
+
+	releaseSmall := mustAcquire(t, s, "small")
+	_, err := s.Acquire(context.Background(), "small")
+	assert.ErrorIs(t, err, ErrTenantQuotaExceeded)
+
+	releaseBig1 := mustAcquire(t, s, "big")
+	releaseBig2 := mustAcquire(t, s, "big")
+	_, err = s.Acquire(context.Background(), "big")
+	assert.ErrorIs(t, err, ErrTenantQuotaExceeded)
+
+	releaseSmall()
+	releaseSmall() // safe to call twice
+	releaseBig1()
+	releaseBig2()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Zero(t, s.inUse)
+}
+
+func TestAcquire_PoolSaturated(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 2, MaxQueued: -1, Capacity: 2}, nil)
+	releaseA := mustAcquire(t, s, "tenant-a")
+	releaseB := mustAcquire(t, s, "tenant-b")
+	defer releaseA()
+	defer releaseB()
+
+	// tenant-c holds nothing, so the pool is what is full, not its quota
+	_, err := s.Acquire(context.Background(), "tenant-c")
+	assert.ErrorIs(t, err, ErrPoolSaturated)
+	assert.NotErrorIs(t, err, ErrTenantQuotaExceeded)
+}
+
+func TestAcquire_QueueFull(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: time.Second}, nil)
+	release := mustAcquire(t, s, "tenant-a")
+
+	queued := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 1)
+
+	_, err := s.Acquire(context.Background(), "tenant-a")
+	assert.ErrorIs(t, err, ErrTenantQuotaExceeded)
+
+	release()
+	result := receive(t, queued)
+	require.NoError(t, result.err)
+	result.release()
+}
+
+func TestAcquire_QueueTimeout(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 1, QueueTimeout: 20 * time.Millisecond}, nil)
+	release := mustAcquire(t, s, "tenant-a")
+	defer release()
+
+	_, err := s.Acquire(context.Background(), "tenant-a")
+	assert.ErrorIs(t, err, ErrTenantQueueTimeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Empty(t, s.tenants["tenant-a"].waiters)
+	assert.Empty(t, s.ring)
+}
+
+func TestAcquire_ContextCancelled(t *testing.T) {
+	s := NewQuotaScheduler(QuotaConfig{MaxConcurrent: 1, QueueTimeout: time.Second}, nil)
+	release := mustAcquire(t, s, "tenant-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := acquireAsync(ctx, s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 1)
+	next := acquireAsync(context.Background(), s, "tenant-a")
+	waitQueued(t, s, "tenant-a", 2)
+
+	cancel()
+	result := receive(t, cancelled)
+	assert.ErrorIs(t, result.err, context.Canceled)
+	waitQueued(t, s, "tenant-a", 1)
+
+	// The freed slot goes to the remaining waiter, not the cancelled one
+	release()
+	result = receive(t, next)
+	require.NoError(t, result.err)
+	result.release()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Zero(t, s.inUse)
+	assert.Empty(t, s.tenants)
+}
@@ -0,0 +1,232 @@
+package tenant
+
+import (
+    "container/list"
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Resolver error definitions
+var (
+    ErrUnknownTenant     = fmt.Errorf("tenant is not registered")
+    ErrStatusUnavailable = fmt.Errorf("failed to resolve tenant status")
+    ErrLookupUnavailable = fmt.Errorf("failed to look up tenant")
+)
+
+const (
+    // DefaultStatusCacheTTL bounds how long a suspension can take to reach other services
+    DefaultStatusCacheTTL = 30 * time.Second
+
+    // DefaultLookupCacheTTL bounds how long a renamed subdomain keeps resolving to its tenant
+    DefaultLookupCacheTTL = 5 * time.Minute
+
+    // DefaultCacheSize caps the entries a lookup cache keeps; the least recently
+    // used one is evicted first, so a flood of unknown subdomains cannot grow it
+    DefaultCacheSize = 10000
+)
+
+// StatusResolver looks up the lifecycle status of a tenant (see status.go)
+type StatusResolver interface {
+    TenantStatus(ctx context.Context, tenantID string) (string, error)
+}
+
+// SubdomainLookup maps a tenant subdomain to its tenant ID
+type SubdomainLookup interface {
+    TenantIDBySubdomain(ctx context.Context, subdomain string) (string, error)
+}
+
+// RegistryStatusResolver reads tenant status from the global tenants table
+type RegistryStatusResolver struct {
+    db Querier
+}
+
+// NewRegistryStatusResolver creates a resolver backed by the tenant registry
+func NewRegistryStatusResolver(db Querier) *RegistryStatusResolver {
+    return &RegistryStatusResolver{db: db}
+}
+
+// TenantStatus returns the registry status, or ErrUnknownTenant if no row exists
+func (r *RegistryStatusResolver) TenantStatus(ctx context.Context, tenantID string) (string, error) {
+    var status string
+    err := r.db.QueryRow(ctx, "SELECT status FROM public.tenants WHERE id = $1", tenantID).Scan(&status)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+    }
+    if err != nil {
+        return "", fmt.Errorf("%w: %v", ErrStatusUnavailable, err)
+    }
+    return status, nil
+}
+
+// RegistrySubdomainLookup resolves subdomains against the global tenants table
+type RegistrySubdomainLookup struct {
+    db Querier
+}
+
+// NewRegistrySubdomainLookup creates a subdomain lookup backed by the tenant registry
+func NewRegistrySubdomainLookup(db Querier) *RegistrySubdomainLookup {
+    return &RegistrySubdomainLookup{db: db}
+}
+
+// TenantIDBySubdomain returns the tenant ID, or ErrUnknownTenant if no row exists
+func (r *RegistrySubdomainLookup) TenantIDBySubdomain(ctx context.Context, subdomain string) (string, error) {
+    var tenantID string
+    err := r.db.QueryRow(ctx, "SELECT id FROM public.tenants WHERE subdomain = $1", subdomain).Scan(&tenantID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", fmt.Errorf("%w: subdomain %s", ErrUnknownTenant, subdomain)
+    }
+    if err != nil {
+        return "", fmt.Errorf("%w: %v", ErrLookupUnavailable, err)
+    }
+    return tenantID, nil
+}
+
+// CachedStatusResolver wraps a resolver with an in-process TTL cache
+// Unknown tenants are cached too so invalid IDs do not hit the database on every request.
+type CachedStatusResolver struct {
+    source StatusResolver
+    cache  *ttlCache[string]
+}
+
+// NewCachedStatusResolver caches source lookups for ttl (DefaultStatusCacheTTL if <= 0)
+func NewCachedStatusResolver(source StatusResolver, ttl time.Duration) *CachedStatusResolver {
+    if ttl <= 0 {
+        ttl = DefaultStatusCacheTTL
+    }
+    return &CachedStatusResolver{
+        source: source,
+        cache:  newTTLCache[string](ttl),
+    }
+}
+
+// TenantStatus returns the cached status, refreshing it from the source once expired
+func (c *CachedStatusResolver) TenantStatus(ctx context.Context, tenantID string) (string, error) {
+    return c.cache.get(tenantID, func() (string, error) {
+        return c.source.TenantStatus(ctx, tenantID)
+    })
+}
+
+// Invalidate drops a cached status so the next lookup reads the source
+func (c *CachedStatusResolver) Invalidate(tenantID string) {
+    c.cache.invalidate(tenantID)
+}
+
+// CachedSubdomainLookup wraps a subdomain lookup with an in-process TTL cache
+type CachedSubdomainLookup struct {
+    source SubdomainLookup
+    cache  *ttlCache[string]
+}
+
+// NewCachedSubdomainLookup caches source lookups for ttl (DefaultLookupCacheTTL if <= 0)
+func NewCachedSubdomainLookup(source SubdomainLookup, ttl time.Duration) *CachedSubdomainLookup {
+    if ttl <= 0 {
+        ttl = DefaultLookupCacheTTL
+    }
+    return &CachedSubdomainLookup{
+        source: source,
+        cache:  newTTLCache[string](ttl),
+    }
+}
+
+// TenantIDBySubdomain returns the cached tenant ID, refreshing it from the source once expired
+func (c *CachedSubdomainLookup) TenantIDBySubdomain(ctx context.Context, subdomain string) (string, error) {
+    return c.cache.get(subdomain, func() (string, error) {
+        return c.source.TenantIDBySubdomain(ctx, subdomain)
+    })
+}
+
+// Invalidate drops a cached subdomain so the next lookup reads the source
+func (c *CachedSubdomainLookup) Invalidate(subdomain string) {
+    c.cache.invalidate(subdomain)
+}
+
+// ttlCache memoizes lookups by string key, including ErrUnknownTenant results
+// It holds at most size entries and evicts the least recently used one past that.
+type ttlCache[V any] struct {
+    ttl  time.Duration
+    size int
+    now  func() time.Time
+
+    mu      sync.Mutex
+    entries map[string]*list.Element // values are *cacheEntry[V]
+    order   *list.List               // most recently used first
+}
+
+type cacheEntry[V any] struct {
+    key       string
+    value     V
+    err       error
+    expiresAt time.Time
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+    return &ttlCache[V]{
+        ttl:     ttl,
+        size:    DefaultCacheSize,
+        now:     time.Now,
+        entries: make(map[string]*list.Element),
+        order:   list.New(),
+    }
+}
+
+// get returns the cached value for key or calls load once it has expired
+func (c *ttlCache[V]) get(key string, load func() (V, error)) (V, error) {
+    now := c.now()
+
+    c.mu.Lock()
+    if elem, ok := c.entries[key]; ok {
+        entry := elem.Value.(*cacheEntry[V])
+        if now.Before(entry.expiresAt) {
+            c.order.MoveToFront(elem)
+            c.mu.Unlock()
+            return entry.value, entry.err
+        }
+        c.remove(elem)
+    }
+    c.mu.Unlock()
+
+    value, err := load()
+    if err != nil && !errors.Is(err, ErrUnknownTenant) {
+        // Transient failures are not cached
+        var zero V
+        return zero, err
+    }
+
+    c.mu.Lock()
+    if elem, ok := c.entries[key]; ok {
+        c.remove(elem) // loaded concurrently
+    }
+    c.entries[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value, err: err, expiresAt: now.Add(c.ttl)})
+    for c.order.Len() > c.size {
+        c.remove(c.order.Back())
+    }
+    c.mu.Unlock()
+
+    return value, err
+}
+
+func (c *ttlCache[V]) invalidate(key string) {
+    c.mu.Lock()
+    if elem, ok := c.entries[key]; ok {
+        c.remove(elem)
+    }
+    c.mu.Unlock()
+}
+
+// len returns the number of cached entries, expired ones included
+func (c *ttlCache[V]) len() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.order.Len()
+}
+
+// remove drops one entry; caller must hold mu
+func (c *ttlCache[V]) remove(elem *list.Element) {
+    c.order.Remove(elem)
+    delete(c.entries, elem.Value.(*cacheEntry[V]).key)
+}
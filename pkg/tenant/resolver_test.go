@@ -0,0 +1,155 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLookup maps subdomains from a fixed table and counts calls
+type countingLookup struct {
+	tenants map[string]string
+	err     error // returned for every lookup when set
+	calls   int
+}
+
+func (l *countingLookup) TenantIDBySubdomain(ctx context.Context, subdomain string) (string, error) {
+	l.calls++
+	if l.err != nil {
+		return "", l.err
+	}
+	if id, ok := l.tenants[subdomain]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("%w: subdomain %s", ErrUnknownTenant, subdomain)
+}
+
+// newTestLookup caches source with a clock the test moves
+func newTestLookup(source SubdomainLookup, ttl time.Duration) (*CachedSubdomainLookup, *time.Time) {
+	now := time.Now()
+	lookup := NewCachedSubdomainLookup(source, ttl)
+	lookup.cache.now = func() time.Time { return now }
+	return lookup, &now
+}
+
+func TestCachedSubdomainLookup_Hit(t *testing.T) {
+	source := &countingLookup{tenants: map[string]string{"acme": "01HK153X003BMPJNJB6JHKXK8T"}}
+	lookup, _ := newTestLookup(source, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		id, err := lookup.TenantIDBySubdomain(context.Background(), "acme")
+		require.NoError(t, err)
+		assert.Equal(t, "01HK153X003BMPJNJB6JHKXK8T", id)
+	}
+	assert.Equal(t, 1, source.calls, "later lookups are served from the cache")
+}
+
+func TestCachedSubdomainLookup_MissIsCached(t *testing.T) {
+	source := &countingLookup{}
+	lookup, _ := newTestLookup(source, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := lookup.TenantIDBySubdomain(context.Background(), "unknown")
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+	}
+	assert.Equal(t, 1, source.calls, "unknown subdomains are cached too")
+}
+
+func TestCachedSubdomainLookup_TransientErrorNotCached(t *testing.T) {
+	source := &countingLookup{err: ErrLookupUnavailable}
+	lookup, _ := newTestLookup(source, time.Minute)
+
+	_, err := lookup.TenantIDBySubdomain(context.Background(), "acme")
+	assert.ErrorIs(t, err, ErrLookupUnavailable)
+
+	source.err = nil
+	source.tenants = map[string]string{"acme": "01HK153X003BMPJNJB6JHKXK8T"}
+	id, err := lookup.TenantIDBySubdomain(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "01HK153X003BMPJNJB6JHKXK8T", id)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedSubdomainLookup_Expiry(t *testing.T) {
+	source := &countingLookup{tenants: map[string]string{"acme": "01HK153X003BMPJNJB6JHKXK8T"}}
+	lookup, now := newTestLookup(source, time.Minute)
+
+	_, err := lookup.TenantIDBySubdomain(context.Background(), "acme")
+	require.NoError(t, err)
+
+	*now = now.Add(59 * time.Second)
+	_, err = lookup.TenantIDBySubdomain(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, 1, source.calls, "still fresh")
+
+	// A renamed subdomain stops resolving once its entry expires
+	delete(source.tenants, "acme")
+	*now = now.Add(2 * time.Second)
+	_, err = lookup.TenantIDBySubdomain(context.Background(), "acme")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedSubdomainLookup_Invalidate(t *testing.T) {
+	source := &countingLookup{tenants: map[string]string{"acme": "01HK153X003BMPJNJB6JHKXK8T"}}
+	lookup, _ := newTestLookup(source, time.Minute)
+
+	_, _ = lookup.TenantIDBySubdomain(context.Background(), "acme")
+	lookup.Invalidate("acme")
+	_, _ = lookup.TenantIDBySubdomain(context.Background(), "acme")
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedSubdomainLookup_SizeBounded(t *testing.T) {
+	source := &countingLookup{tenants: map[string]string{"acme": "01HK153X003BMPJNJB6JHKXK8T"}}
+	lookup, _ := newTestLookup(source, time.Hour)
+	lookup.cache.size = 100
+
+	_, _ = lookup.TenantIDBySubdomain(context.Background(), "acme")
+	for i := 0; i < 1000; i++ {
+		_, err := lookup.TenantIDBySubdomain(context.Background(), fmt.Sprintf("random%d", i))
+		assert.True(t, errors.Is(err, ErrUnknownTenant))
+		if i%10 == 0 {
+			_, _ = lookup.TenantIDBySubdomain(context.Background(), "acme") // keeps it recently used
+		}
+	}
+
+	assert.Equal(t, 100, lookup.cache.len(), "a flood of unknown subdomains cannot grow the cache")
+	calls := source.calls
+	_, err := lookup.TenantIDBySubdomain(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, calls, source.calls, "the recently used tenant survived the flood")
+}
+
+// statusFunc adapts a function to StatusResolver
+type statusFunc func(ctx context.Context, tenantID string) (string, error)
+
+func (f statusFunc) TenantStatus(ctx context.Context, tenantID string) (string, error) {
+	return f(ctx, tenantID)
+}
+
+func TestCachedStatusResolver_DefaultTTL(t *testing.T) {
+	calls := 0
+	resolver := NewCachedStatusResolver(statusFunc(func(context.Context, string) (string, error) {
+		calls++
+		return StatusActive, nil
+	}), 0)
+	now := time.Now()
+	resolver.cache.now = func() time.Time { return now }
+
+	_, _ = resolver.TenantStatus(context.Background(), "tenant")
+	now = now.Add(DefaultStatusCacheTTL - time.Second)
+	_, _ = resolver.TenantStatus(context.Background(), "tenant")
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(time.Second)
+	status, err := resolver.TenantStatus(context.Background(), "tenant")
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, status)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,94 @@
+package tenant
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// ErrRolesUnavailable is returned when role overrides cannot be read
+var ErrRolesUnavailable = fmt.Errorf("failed to load tenant role permissions")
+
+// DefaultRolesCacheTTL bounds how long a role permission change takes to apply
+const DefaultRolesCacheTTL = time.Minute
+
+// RoleOverrideSource returns a tenant's role permission overrides (role -> permissions)
+// A role present in the map replaces the built-in permissions for that role.
+type RoleOverrideSource interface {
+    RolePermissions(ctx context.Context, tenantID string) (map[string][]string, error)
+}
+
+// RegistryRoleOverrides reads tenants.role_permissions from the global tenants table
+type RegistryRoleOverrides struct {
+    db Querier
+}
+
+// NewRegistryRoleOverrides creates a role override source backed by the tenant registry
+func NewRegistryRoleOverrides(db Querier) *RegistryRoleOverrides {
+    return &RegistryRoleOverrides{db: db}
+}
+
+// RolePermissions returns the tenant's overrides, or ErrUnknownTenant if no row exists
+func (r *RegistryRoleOverrides) RolePermissions(ctx context.Context, tenantID string) (map[string][]string, error) {
+    var overrides map[string][]string
+    err := r.db.QueryRow(ctx, "SELECT role_permissions FROM public.tenants WHERE id = $1", tenantID).Scan(&overrides)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrRolesUnavailable, err)
+    }
+    return overrides, nil
+}
+
+// SetRolePermissions replaces the permissions of role for one tenant
+// A nil permissions slice removes the override, restoring the built-in role.
+func (r *RegistryRoleOverrides) SetRolePermissions(ctx context.Context, tenantID, role string, permissions []string) error {
+    query := "UPDATE public.tenants SET role_permissions = role_permissions || jsonb_build_object($2::text, $3::jsonb), updated_at = now() WHERE id = $1"
+    args := []interface{}{tenantID, role, permissions}
+    if permissions == nil {
+        query = "UPDATE public.tenants SET role_permissions = role_permissions - $2::text, updated_at = now() WHERE id = $1"
+        args = args[:2]
+    }
+
+    tag, err := r.db.Exec(ctx, query, args...)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrRolesUnavailable, err)
+    }
+    if tag.RowsAffected() == 0 {
+        return fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+    }
+    return nil
+}
+
+// CachedRoleOverrides wraps a role override source with an in-process TTL cache
+type CachedRoleOverrides struct {
+    source RoleOverrideSource
+    cache  *ttlCache[map[string][]string]
+}
+
+// NewCachedRoleOverrides caches source lookups for ttl (DefaultRolesCacheTTL if <= 0)
+func NewCachedRoleOverrides(source RoleOverrideSource, ttl time.Duration) *CachedRoleOverrides {
+    if ttl <= 0 {
+        ttl = DefaultRolesCacheTTL
+    }
+    return &CachedRoleOverrides{
+        source: source,
+        cache:  newTTLCache[map[string][]string](ttl),
+    }
+}
+
+// RolePermissions returns the cached overrides, refreshing them from the source once expired
+func (c *CachedRoleOverrides) RolePermissions(ctx context.Context, tenantID string) (map[string][]string, error) {
+    return c.cache.get(tenantID, func() (map[string][]string, error) {
+        return c.source.RolePermissions(ctx, tenantID)
+    })
+}
+
+// Invalidate drops cached overrides so the next lookup reads the source
+func (c *CachedRoleOverrides) Invalidate(tenantID string) {
+    c.cache.invalidate(tenantID)
+}
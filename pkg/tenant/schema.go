@@ -7,6 +7,7 @@ import (
     "regexp"
     
     "crm-platform/pkg/database"
+    "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgconn"
 )
 
@@ -15,6 +16,14 @@ type Executor interface {
     Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
 }
 
+// Querier interface for schema operations that read as well as execute
+// Satisfied by *database.Pool and pgx.Tx so schema work can run inside a transaction
+type Querier interface {
+    Executor
+    Query(context.Context, string, ...interface{}) (pgx.Rows, error)
+    QueryRow(context.Context, string, ...interface{}) pgx.Row
+}
+
 // Schema error definitions
 var (
     ErrSchemaNotFound       = fmt.Errorf("schema does not exist")
@@ -25,6 +34,8 @@ var (
     ErrTemplateNotFound     = fmt.Errorf("template schema does not exist")
     ErrTableCopyFailure     = fmt.Errorf("failed to copy table")
     ErrSeedDataFailure      = fmt.Errorf("failed to copy seed data")
+    ErrFailedSchemaDrop     = fmt.Errorf("failed to drop schema")
+    ErrProvisionFailure     = fmt.Errorf("failed to provision schema")
 )
 
 // Schema name validation regex - PostgreSQL identifier rules (allow hyphens for ULIDs)
@@ -67,8 +78,24 @@ func SetSearchPath(ctx context.Context, exec Executor, schemaName string) error
     return nil
 }
 
+// SetLocalSearchPath sets the search path for the current transaction only
+func SetLocalSearchPath(ctx context.Context, exec Executor, schemaName string) error {
+    if err := validateSchemaName(schemaName); err != nil {
+        return err
+    }
+
+    sql := fmt.Sprintf(`SET LOCAL search_path TO "%s", public`, schemaName)
+
+    _, err := exec.Exec(ctx, sql)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrSetSearchPathFailure, err)
+    }
+
+    return nil
+}
+
 // SchemaExists checks if schema exists in PostgreSQL
-func SchemaExists(ctx context.Context, pool *database.Pool, schemaName string) (bool, error) {
+func SchemaExists(ctx context.Context, pool Querier, schemaName string) (bool, error) {
     if err := validateSchemaName(schemaName); err != nil {
         return false, err
     }
@@ -88,7 +115,7 @@ func SchemaExists(ctx context.Context, pool *database.Pool, schemaName string) (
 }
 
 // CreateSchema creates a new PostgreSQL schema
-func CreateSchema(ctx context.Context, pool *database.Pool, schemaName string) error {
+func CreateSchema(ctx context.Context, pool Executor, schemaName string) error {
     if err := validateSchemaName(schemaName); err != nil {
         return err
     }
@@ -103,8 +130,57 @@ func CreateSchema(ctx context.Context, pool *database.Pool, schemaName string) e
     return nil
 }
 
+// DropSchema removes a PostgreSQL schema and everything in it
+func DropSchema(ctx context.Context, pool Executor, schemaName string) error {
+    if err := validateSchemaName(schemaName); err != nil {
+        return err
+    }
+
+    sql := fmt.Sprintf(`DROP SCHEMA IF EXISTS "%s" CASCADE`, schemaName)
+
+    _, err := pool.Exec(ctx, sql)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrFailedSchemaDrop, err)
+    }
+
+    return nil
+}
+
+// ProvisionSchema creates a schema and copies the template into it in a single transaction
+// Either the schema is fully built or nothing is left behind.
+func ProvisionSchema(ctx context.Context, pool *database.Pool, templateSchema, targetSchema string) error {
+    tx, err := pool.Pool.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("%w: %v", ErrFailedTransaction, err)
+    }
+    defer tx.Rollback(ctx)
+
+    if err := ProvisionSchemaTx(ctx, tx, templateSchema, targetSchema); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("%w: commit failed: %v", ErrProvisionFailure, err)
+    }
+
+    return nil
+}
+
+// ProvisionSchemaTx creates a schema and copies the template into it inside tx without committing
+func ProvisionSchemaTx(ctx context.Context, tx Querier, templateSchema, targetSchema string) error {
+    if err := CreateSchema(ctx, tx, targetSchema); err != nil {
+        return fmt.Errorf("%w: %v", ErrProvisionFailure, err)
+    }
+
+    if err := CopyTemplateSchema(ctx, tx, templateSchema, targetSchema); err != nil {
+        return fmt.Errorf("%w: %v", ErrProvisionFailure, err)
+    }
+
+    return nil
+}
+
 // CopyTemplateSchema copies table structure from template to new schema
-func CopyTemplateSchema(ctx context.Context, pool *database.Pool, templateSchema, targetSchema string) error {
+func CopyTemplateSchema(ctx context.Context, pool Querier, templateSchema, targetSchema string) error {
     if err := validateSchemaName(templateSchema); err != nil {
         return fmt.Errorf("invalid template schema: %w", err)
     }
@@ -153,7 +229,7 @@ func validateSchemaName(schemaName string) error {
 }
 
 // getTableNames retrieves all table names from the specified schema
-func getTableNames(ctx context.Context, pool *database.Pool, schemaName string) ([]string, error) {
+func getTableNames(ctx context.Context, pool Querier, schemaName string) ([]string, error) {
     sql := `SELECT table_name 
             FROM information_schema.tables 
             WHERE table_schema = $1 
@@ -183,17 +259,11 @@ func getTableNames(ctx context.Context, pool *database.Pool, schemaName string)
 }
 
 // copyTable copies a single table structure from source to target schema
-func copyTable(ctx context.Context, pool *database.Pool, sourceSchema, targetSchema, tableName string) error {
+func copyTable(ctx context.Context, pool Querier, sourceSchema, targetSchema, tableName string) error {
     // First, check if table already exists
-    existsSQL := fmt.Sprintf(`SELECT EXISTS (
-        SELECT FROM information_schema.tables 
-        WHERE table_schema = '%s' AND table_name = '%s'
-    )`, targetSchema, tableName)
-    
-    var exists bool
-    err := pool.QueryRow(ctx, existsSQL).Scan(&exists)
+    exists, err := tableExists(ctx, pool, targetSchema, tableName)
     if err != nil {
-        return fmt.Errorf("failed to check table existence: %v", err)
+        return err
     }
     
     if exists {
@@ -216,17 +286,28 @@ func copyTable(ctx context.Context, pool *database.Pool, sourceSchema, targetSch
 }
 
 // copySeedData copies initial data for specific tables
-func copySeedData(ctx context.Context, pool *database.Pool, sourceSchema, targetSchema string) error {
-    seedTables := []string{"roles", "settings", "default_pipeline_stages"}
+func copySeedData(ctx context.Context, pool Querier, sourceSchema, targetSchema string) error {
+    // schema_migrations carries the template's migration version into the new schema
+    seedTables := []string{"roles", "settings", "default_pipeline_stages", MigrationsTable}
 
     for _, tableName := range seedTables {
+        // Skip seed tables the template does not define; a failed INSERT would
+        // abort the surrounding transaction when provisioning atomically
+        exists, err := tableExists(ctx, pool, sourceSchema, tableName)
+        if err != nil {
+            return err
+        }
+        if !exists {
+            continue
+        }
+
         // Use ON CONFLICT DO NOTHING to make seed data insertion idempotent
         sql := fmt.Sprintf(`INSERT INTO "%s"."%s" 
                            SELECT * FROM "%s"."%s"
                            ON CONFLICT DO NOTHING`, 
                            targetSchema, tableName, sourceSchema, tableName)
         
-        _, err := pool.Exec(ctx, sql)
+        _, err = pool.Exec(ctx, sql)
         if err != nil {
             // Continue with other tables - seed data is optional
             log.Printf("Warning: %v: %s: %v", ErrSeedDataFailure, tableName, err)
@@ -237,4 +318,20 @@ func copySeedData(ctx context.Context, pool *database.Pool, sourceSchema, target
     }
 
     return nil
+}
+
+// tableExists checks if a table exists in the specified schema
+func tableExists(ctx context.Context, pool Querier, schemaName, tableName string) (bool, error) {
+    sql := `SELECT EXISTS (
+        SELECT FROM information_schema.tables 
+        WHERE table_schema = $1 AND table_name = $2
+    )`
+
+    var exists bool
+    err := pool.QueryRow(ctx, sql, schemaName, tableName).Scan(&exists)
+    if err != nil {
+        return false, fmt.Errorf("failed to check table existence: %v", err)
+    }
+
+    return exists, nil
 }
\ No newline at end of file
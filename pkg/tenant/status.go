@@ -0,0 +1,8 @@
+package tenant
+
+// Tenant lifecycle states stored in tenants.status (see tenants_status_valid)
+const (
+    StatusPending   = "pending"   // Registered, schema not yet provisioned
+    StatusActive    = "active"    // Fully provisioned and serving requests
+    StatusSuspended = "suspended" // Access blocked, data retained
+)
@@ -0,0 +1,72 @@
+package tenant
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "crm-platform/pkg/config"
+)
+
+// ErrSetStatementTimeout is returned when a tenant statement_timeout cannot be applied
+var ErrSetStatementTimeout = fmt.Errorf("failed to set statement timeout")
+
+// StatementTimeouts sets PostgreSQL statement_timeout for tenant sessions
+// The server cancels any statement that runs longer, independent of client deadlines.
+type StatementTimeouts struct {
+    Default   time.Duration            // Applied to every tenant (0 leaves the server default)
+    Overrides map[string]time.Duration // Per-tenant values
+}
+
+// StatementTimeoutsFromConfig converts the loaded tenant settings into statement timeouts
+// Returns nil when neither a default nor an override is set.
+func StatementTimeoutsFromConfig(cfg config.TenantConfig) *StatementTimeouts {
+    if cfg.StatementTimeout == 0 && len(cfg.StatementTimeoutOverrides) == 0 {
+        return nil
+    }
+
+    timeouts := &StatementTimeouts{
+        Default:   cfg.StatementTimeout,
+        Overrides: make(map[string]time.Duration, len(cfg.StatementTimeoutOverrides)),
+    }
+    for tenantID, d := range cfg.StatementTimeoutOverrides {
+        timeouts.Overrides[tenantID] = d
+    }
+    return timeouts
+}
+
+// For returns the statement timeout for a tenant (0 means none); safe on nil
+func (t *StatementTimeouts) For(tenantID string) time.Duration {
+    if t == nil {
+        return 0
+    }
+    if d, ok := t.Overrides[tenantID]; ok {
+        return d
+    }
+    return t.Default
+}
+
+// SetStatementTimeout sets statement_timeout for the session, or for the current
+// transaction only when local is true
+func SetStatementTimeout(ctx context.Context, exec Executor, timeout time.Duration, local bool) error {
+    ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+    if _, err := exec.Exec(ctx, "SELECT set_config('statement_timeout', $1, $2)", ms, local); err != nil {
+        return fmt.Errorf("%w: %v", ErrSetStatementTimeout, err)
+    }
+    return nil
+}
+
+// applyStatementTimeout sets the tenant's statement_timeout if one is configured
+func (tp *TenantPool) applyStatementTimeout(ctx context.Context, exec Executor, local bool) error {
+    tenantID, err := FromContext(ctx)
+    if err != nil {
+        return err
+    }
+
+    timeout := tp.timeouts.For(tenantID)
+    if timeout <= 0 {
+        return nil
+    }
+    return SetStatementTimeout(ctx, exec, timeout, local)
+}
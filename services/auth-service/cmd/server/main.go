@@ -1,9 +1,394 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	authconfig "crm-platform/auth-service/internal/config"
+	"crm-platform/auth-service/internal/errors"
+	"crm-platform/auth-service/internal/handlers"
+	"crm-platform/auth-service/internal/lockout"
+	"crm-platform/auth-service/internal/mail"
+	"crm-platform/pkg/config"
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/health"
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
 )
 
+// =============================================================================
+// CONFIGURATION
+// =============================================================================
+
+// Load server port from environment with fallback
+func getServerPort() string {
+	port := os.Getenv("PORT")
+	if port == "" {
+		return "8080"
+	}
+
+	return port
+}
+
+// =============================================================================
+// SETUP FUNCTIONS
+// =============================================================================
+
+// Initialize database connection with backoff retries (canceled by the startup timeout)
+func setupDatabase(cfg *config.Config) (*database.Pool, error) {
+	// Build pool config from the loaded settings
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, errors.ErrDatabase("failed to load database config: " + err.Error())
+	}
+
+	// Create connection pool with timeout context
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool, err := database.NewPool(ctx, poolConfig)
+	if err != nil {
+		return nil, errors.ErrDatabase("failed to create connection pool: " + err.Error())
+	}
+
+	// Trace every statement as a child of the request span
+	pool.AddQueryObserver(database.NewTracingObserver(nil))
+
+	// With DB_BACKGROUND_CONNECT the service starts degraded and /health reports it
+	if pool.Degraded() {
+		log.Println("Database unavailable, starting degraded and reconnecting in background")
+		return pool, nil
+	}
+
+	log.Println("Database connection established successfully")
+	return pool, nil
+}
+
+// Initialize the access token signer
+// A JWT_SIGNING_KEY_FILE signs RS256/ES256 tokens published at /.well-known/jwks.json
+// (point JWT_JWKS_URL of the other services there); otherwise SHARED_JWT_SECRET signs HS256.
+func setupTokenIssuer(cfg *config.Config, settings *authconfig.Settings) (*auth.TokenIssuer, error) {
+	opts := auth.IssuerOptions{
+		Issuer:   cfg.Auth.Issuer,
+		Audience: cfg.Auth.Audience,
+		TTL:      settings.AccessTokenTTL,
+	}
+
+	if keyFile := settings.SigningKeyFile; keyFile != "" {
+		signer, err := auth.LoadSigningKey(keyFile)
+		if err != nil {
+			return nil, errors.ErrHandler(err.Error())
+		}
+		issuer, err := auth.NewKeyIssuer(signer, settings.SigningKeyID, opts)
+		if err != nil {
+			return nil, errors.ErrHandler(err.Error())
+		}
+		log.Println("Signing access tokens with key from JWT_SIGNING_KEY_FILE")
+		return issuer, nil
+	}
+
+	if cfg.Auth.JWTSecret == "" {
+		return nil, errors.ErrHandler("JWT_SIGNING_KEY_FILE or SHARED_JWT_SECRET is required to issue tokens")
+	}
+	log.Println("Signing access tokens with SHARED_JWT_SECRET")
+	return auth.NewHMACIssuer([]byte(cfg.Auth.JWTSecret), opts), nil
+}
+
+// Initialize the mail sender for password reset and verification emails
+// SMTP_HOST sends through a relay; otherwise mail is written to MAIL_DIR (a temp dir in development).
+func setupMailer(cfg *config.Config, settings *authconfig.Settings) (mail.Sender, error) {
+	if host := settings.SMTPHost; host != "" {
+		log.Printf("Sending email through SMTP relay %s", host)
+		return mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     host,
+			Port:     settings.SMTPPort,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword,
+			From:     settings.MailFrom,
+		}), nil
+	}
+
+	dir := settings.MailDir
+	if dir == "" {
+		if !cfg.IsDevelopment() {
+			return nil, errors.ErrHandler("SMTP_HOST or MAIL_DIR is required outside development")
+		}
+		dir = filepath.Join(os.TempDir(), "auth-service-mail")
+	}
+	sender, err := mail.NewFileSender(dir, settings.MailFrom)
+	if err != nil {
+		return nil, errors.ErrHandler(err.Error())
+	}
+	log.Printf("Writing email to %s instead of sending it", dir)
+	return sender, nil
+}
+
+// Initialize the password reset settings
+func setupPasswordReset(cfg *config.Config, settings *authconfig.Settings, mailer mail.Sender) (auth.ResetOptions, error) {
+	resetURL := settings.PasswordResetURL
+	if resetURL == "" {
+		if !cfg.IsDevelopment() {
+			return auth.ResetOptions{}, errors.ErrHandler("PASSWORD_RESET_URL is required outside development")
+		}
+		resetURL = "http://localhost:3000/reset-password"
+	}
+	return auth.ResetOptions{URL: resetURL, Mailer: mailer}, nil
+}
+
+// Initialize the email verification settings
+// Without EMAIL_VERIFICATION_SECRET the signing key is derived from SHARED_JWT_SECRET,
+// so verification tokens can never pass as access tokens.
+func setupEmailVerification(cfg *config.Config, settings *authconfig.Settings, mailer mail.Sender) (auth.VerificationOptions, error) {
+	policy, err := auth.ParseVerificationPolicy(settings.EmailVerificationPolicy)
+	if err != nil {
+		return auth.VerificationOptions{}, errors.ErrValidation(err.Error())
+	}
+
+	verifyURL := settings.EmailVerificationURL
+	if verifyURL == "" {
+		if !cfg.IsDevelopment() {
+			return auth.VerificationOptions{}, errors.ErrHandler("EMAIL_VERIFICATION_URL is required outside development")
+		}
+		verifyURL = "http://localhost:3000/verify-email"
+	}
+
+	secret := []byte(settings.EmailVerificationSecret)
+	if len(secret) == 0 {
+		if cfg.Auth.JWTSecret == "" {
+			return auth.VerificationOptions{}, errors.ErrHandler("EMAIL_VERIFICATION_SECRET or SHARED_JWT_SECRET is required to verify email")
+		}
+		secret = auth.DeriveKey([]byte(cfg.Auth.JWTSecret), "email-verification")
+	}
+
+	log.Printf("Email verification policy: %s", policy)
+	return auth.VerificationOptions{
+		Mailer:         mailer,
+		URL:            verifyURL,
+		Secret:         secret,
+		TokenTTL:       settings.EmailVerificationTTL,
+		ResendInterval: settings.EmailVerificationResendInterval,
+		Policy:         policy,
+	}, nil
+}
+
+// Initialize the MFA settings; the per-role requirement is read from the tenant registry
+// and cached per tenant (DefaultMFAPolicyCacheTTL), dropped here when an admin changes it.
+// Without MFA_ENCRYPTION_KEY the key is derived from SHARED_JWT_SECRET. Changing either
+// makes stored TOTP secrets unreadable, so users would have to enroll again.
+func setupMFA(cfg *config.Config, settings *authconfig.Settings, pool *database.Pool) (auth.MFAOptions, error) {
+	key := []byte(settings.MFAEncryptionKey)
+	if len(key) == 0 {
+		if cfg.Auth.JWTSecret == "" {
+			return auth.MFAOptions{}, errors.ErrHandler("MFA_ENCRYPTION_KEY or SHARED_JWT_SECRET is required for MFA")
+		}
+		key = auth.DeriveKey([]byte(cfg.Auth.JWTSecret), "mfa")
+	}
+
+	return auth.MFAOptions{
+		Issuer:       settings.MFAIssuer,
+		Key:          key,
+		ChallengeTTL: settings.MFAChallengeTTL,
+		Policy:       tenant.NewCachedMFAPolicy(tenant.NewRegistryMFAPolicy(pool), tenant.DefaultMFAPolicyCacheTTL),
+	}, nil
+}
+
+// Initialize failed-login tracking
+// With REDIS_URL every replica shares the counts, falling back to memory while Redis is
+// down; without it each replica counts on its own.
+func setupLockout(cfg *config.Config, settings *authconfig.Settings) (auth.LockoutOptions, error) {
+	opts := auth.LockoutOptions{
+		Policy: lockout.Policy{
+			Account:         lockout.Limits{LockAfter: settings.LockoutAccountAttempts},
+			IP:              lockout.Limits{LockAfter: settings.LockoutIPAttempts},
+			LockoutDuration: settings.LockoutDuration,
+		},
+	}
+
+	if cfg.RedisURL == "" {
+		log.Println("Counting failed logins in memory (per replica); set REDIS_URL to share them")
+		opts.Store = lockout.NewMemoryStore()
+		return opts, nil
+	}
+	redis, err := lockout.NewRedisStore(cfg.RedisURL)
+	if err != nil {
+		return auth.LockoutOptions{}, errors.ErrValidation(err.Error())
+	}
+	log.Println("Counting failed logins in Redis")
+	opts.Store = lockout.NewFallbackStore(redis)
+	return opts, nil
+}
+
+// Initialize all handlers with database dependencies
+func setupHandlers(pool *database.Pool, cfg *config.Config, settings *authconfig.Settings, issuer *auth.TokenIssuer, reset auth.ResetOptions, verification auth.VerificationOptions, mfa auth.MFAOptions, lockouts auth.LockoutOptions) (*handlers.AuthHandler, *handlers.SystemHandler) {
+	// Create the auth service over tenant-isolated queries
+	store := auth.NewTenantStore(tenant.NewTenantPool(pool, cfg))
+	service := auth.NewService(store, issuer, auth.Options{
+		SessionTTL:   settings.SessionTTL,
+		Reset:        reset,
+		Verification: verification,
+		MFA:          mfa,
+		Lockout:      lockouts,
+	})
+
+	// Create handler instances
+	authHandler := handlers.NewAuthHandler(service, issuer)
+	systemHandler := handlers.NewSystemHandler(pool)
+
+	log.Println("Handlers initialized successfully")
+	return authHandler, systemHandler
+}
+
+// Expose Kubernetes probes: /livez for liveness, /readyz for readiness
+// Readiness results are cached briefly so frequent probes don't load the database.
+func setupProbes(router *gin.Engine, cfg *config.Config, pool *database.Pool) error {
+	checker, err := health.NewServiceChecker("auth-service", pool, cfg)
+	if err != nil {
+		return errors.ErrHandler("failed to set up health checks: " + err.Error())
+	}
+	if cfg.RedisURL != "" {
+		// Lockout counting falls back to memory while Redis is down, so logins keep working
+		checker.RegisterOptional("redis", health.RedisCheck(cfg.RedisURL))
+	}
+	router.GET("/livez", gin.WrapH(checker.LivenessHandler()))  // GET /livez
+	router.GET("/readyz", gin.WrapH(checker.ReadinessHandler())) // GET /readyz
+	return nil
+}
+
+// Expose database metrics for Prometheus
+// Registered before the middleware stack so scrapers need no tenant credentials.
+func setupMetrics(router *gin.Engine, pool *database.Pool) {
+	exporter := database.NewExporter(pool, database.ExporterOptions{
+		Service:      "auth-service",
+		QueryLabels:  true,
+		TenantLabels: config.GetMetricsTenantLabels(),
+	})
+	router.GET("/metrics", gin.WrapH(exporter)) // GET /metrics
+}
+
+// Setup middleware stack for the tenant-scoped auth endpoints
+// Callers have no access token yet, so the tenant comes from the host or X-Tenant-ID.
+func setupMiddleware(group *gin.RouterGroup, pool *database.Pool) {
+	// Tracing wraps everything so rejected requests are traced too
+	group.Use(middleware.TracingMiddleware("auth-service"))
+
+	// Tenant resolution first - header, subdomain and custom domain must agree
+	subdomains := tenant.NewCachedSubdomainLookup(tenant.NewRegistrySubdomainLookup(pool), tenant.DefaultLookupCacheTTL)
+	group.Use(middleware.TenantResolutionMiddleware(
+		middleware.HeaderTenantResolver("X-Tenant-ID"),
+		middleware.SubdomainTenantResolver(config.GetTenantBaseDomain(), subdomains, "www", "api", "app"),
+		middleware.DomainTenantResolver(config.GetTenantCustomDomains()),
+	))
+
+	// Tenant middleware second - converts tenant ID to request context
+	// and blocks suspended or pending tenants (status cached in-process)
+	statusResolver := tenant.NewCachedStatusResolver(tenant.NewRegistryStatusResolver(pool), tenant.DefaultStatusCacheTTL)
+	group.Use(middleware.TenantStatusMiddleware(statusResolver))
+
+	log.Println("Middleware configured successfully")
+}
+
+// Register all API routes
+func setupRoutes(router *gin.Engine, pool *database.Pool, authHandler *handlers.AuthHandler, systemHandler *handlers.SystemHandler) {
+	// Register system endpoints (no auth required)
+	router.GET("/health", systemHandler.HealthCheck)              // GET /health
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)         // GET /.well-known/jwks.json
+
+	// Create API version groups
+	v1 := router.Group("/api/v1")
+
+	// Register auth endpoints behind tenant resolution
+	authGroup := v1.Group("/auth")
+	setupMiddleware(authGroup, pool)
+	handlers.RegisterAuthRoutes(authGroup, authHandler)
+
+	log.Println("Routes registered successfully")
+}
+
+// =============================================================================
+// MAIN APPLICATION
+// =============================================================================
+
 func main() {
-	fmt.Println("Hello World")
-}
\ No newline at end of file
+	log.Println("Starting Auth Service...")
+
+	// Initialize Gin router
+	router := gin.Default()
+
+	// Load and validate configuration (defaults, CONFIG_FILE, env, secret files);
+	// any invalid value, shared or auth-specific, stops startup
+	cfg, settings, err := authconfig.Load()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Client IPs key the failed-login counts, so X-Forwarded-For is only believed
+	// from TRUSTED_PROXIES; without any, the connection's address is used
+	if err := router.SetTrustedProxies(settings.TrustedProxies); err != nil {
+		log.Fatal(errors.ErrValidation("invalid TRUSTED_PROXIES: " + err.Error()).Error())
+	}
+
+	// Setup token signing before touching the database
+	issuer, err := setupTokenIssuer(cfg, settings)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Setup mail delivery for password resets and email verification
+	mailer, err := setupMailer(cfg, settings)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	reset, err := setupPasswordReset(cfg, settings, mailer)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	verification, err := setupEmailVerification(cfg, settings, mailer)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Setup failed-login tracking (Redis when configured)
+	lockouts, err := setupLockout(cfg, settings)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Setup database connection
+	pool, err := setupDatabase(cfg)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer pool.Close()
+
+	// Setup MFA over the tenant registry
+	mfa, err := setupMFA(cfg, settings, pool)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Setup metrics and probe endpoints
+	setupMetrics(router, pool)
+	if err := setupProbes(router, cfg, pool); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Setup handlers
+	authHandler, systemHandler := setupHandlers(pool, cfg, settings, issuer, reset, verification, mfa, lockouts)
+
+	// Setup routes (with the tenant middleware on the auth group)
+	setupRoutes(router, pool, authHandler, systemHandler)
+
+	// Get server port from environment
+	port := getServerPort()
+
+	log.Printf("Auth Service running on port %s", port)
+	if err := router.Run(":" + port); err != nil {
+		log.Fatal(errors.ErrHandler("failed to start server: " + err.Error()).Error())
+	}
+}
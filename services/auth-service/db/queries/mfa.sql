@@ -0,0 +1,43 @@
+-- name: GetUserMFA :one
+SELECT id, email, role, active, email_verified, mfa_enabled, mfa_secret, mfa_last_step
+FROM users
+WHERE id = $1 AND active = true AND deleted_at IS NULL;
+
+-- name: SetMFASecret :execrows
+UPDATE users
+SET mfa_secret = $2, mfa_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND mfa_enabled = false;
+
+-- name: EnableMFA :execrows
+UPDATE users
+SET mfa_enabled = true, mfa_enabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND mfa_enabled = false AND mfa_secret IS NOT NULL;
+
+-- name: DisableMFA :exec
+UPDATE users
+SET mfa_enabled = false, mfa_secret = NULL, mfa_enabled_at = NULL, mfa_last_step = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: UseMFAStep :execrows
+UPDATE users
+SET mfa_last_step = $2
+WHERE id = $1 AND (mfa_last_step IS NULL OR mfa_last_step < $2);
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (user_id, code_hash)
+VALUES ($1, $2);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*)
+FROM mfa_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
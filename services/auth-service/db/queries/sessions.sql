@@ -0,0 +1,34 @@
+-- name: CreateSession :one
+INSERT INTO auth_sessions (id, user_id, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, user_agent, ip_address, created_at, expires_at, revoked_at, revoked_reason;
+
+-- name: RevokeSession :execrows
+UPDATE auth_sessions
+SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: GetRefreshToken :one
+SELECT rt.session_id, rt.expires_at, rt.used_at,
+       s.user_id, s.expires_at AS session_expires_at, s.revoked_at
+FROM refresh_tokens rt
+JOIN auth_sessions s ON rt.session_id = s.id
+WHERE rt.token_hash = $1;
+
+-- name: UseRefreshToken :execrows
+UPDATE refresh_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL;
+
+-- name: CleanupExpiredSessions :exec
+DELETE FROM auth_sessions
+WHERE expires_at < CURRENT_TIMESTAMP;
+
+-- name: RevokeUserSessions :execrows
+UPDATE auth_sessions
+SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $2
+WHERE user_id = $1 AND revoked_at IS NULL;
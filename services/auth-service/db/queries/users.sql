@@ -16,7 +16,7 @@ FROM users
 WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetUserForAuth :one
-SELECT id, password_hash, role, active, email_verified
+SELECT id, password_hash, role, active, email_verified, mfa_enabled
 FROM users 
 WHERE email = $1 AND active = true AND deleted_at IS NULL;
 
@@ -30,6 +30,13 @@ UPDATE users
 SET email_verified = true, updated_at = CURRENT_TIMESTAMP, updated_by = $2
 WHERE id = $1;
 
+-- name: MarkVerificationEmailSent :execrows
+UPDATE users
+SET verification_sent_at = sqlc.arg(sent_at)
+WHERE id = sqlc.arg(id)
+  AND email_verified IS NOT TRUE
+  AND (verification_sent_at IS NULL OR verification_sent_at < sqlc.arg(resend_before));
+
 -- name: UpdateUserRole :exec
 UPDATE users
 SET role = $2, updated_at = CURRENT_TIMESTAMP, updated_by = $3
@@ -1,12 +1,12 @@
 CREATE TABLE password_reset_tokens (
     id SERIAL PRIMARY KEY,
-    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-    token VARCHAR(255) UNIQUE NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
     expires_at TIMESTAMPTZ NOT NULL,
     used_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Performance indexes
-CREATE INDEX idx_password_reset_tokens_token ON password_reset_tokens (token);
-CREATE INDEX idx_password_reset_tokens_expires ON password_reset_tokens (expires_at);
\ No newline at end of file
+CREATE INDEX idx_password_reset_tokens_user_created ON password_reset_tokens (user_id, created_at);
+CREATE INDEX idx_password_reset_tokens_expires ON password_reset_tokens (expires_at);
@@ -8,6 +8,11 @@ CREATE TABLE users (
     permissions JSONB DEFAULT '{}',
     active BOOLEAN DEFAULT true,
     email_verified BOOLEAN DEFAULT false,
+    verification_sent_at TIMESTAMPTZ,
+    mfa_enabled BOOLEAN NOT NULL DEFAULT false,
+    mfa_secret TEXT,
+    mfa_enabled_at TIMESTAMPTZ,
+    mfa_last_step BIGINT,
     last_login TIMESTAMPTZ,
     created_by INTEGER REFERENCES users(id),
     updated_by INTEGER REFERENCES users(id),
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"crm-platform/auth-service/internal/lockout"
+)
+
+// LockoutOptions controls failed-login tracking for passwords and MFA codes
+type LockoutOptions struct {
+	Store  lockout.Store  // lockout.NewMemoryStore() if nil, which counts per replica
+	Policy lockout.Policy // zero fields take the lockout package defaults
+}
+
+// Lockouts lists the accounts and client IPs of the tenant with recent failed logins
+func (s *Service) Lockouts(ctx context.Context, tenantID string) ([]lockout.Entry, error) {
+	return s.lockout.List(ctx, tenantID)
+}
+
+// Lockout returns the failed-login state of one account (email) or client IP
+func (s *Service) Lockout(ctx context.Context, tenantID, kind, subject string) (lockout.Entry, error) {
+	return s.lockout.Status(ctx, tenantID, kind, subject)
+}
+
+// ClearLockout forgets the failed logins of one account or client IP, lifting its lockout
+func (s *Service) ClearLockout(ctx context.Context, tenantID, kind, subject string) error {
+	return s.lockout.Clear(ctx, tenantID, kind, subject)
+}
+
+// settleAttempt settles an attempt Begin counted once its password or MFA step is over
+// Wrong credentials stay counted against the email and the client IP; a completed login
+// clears the email; anything else (an MFA challenge, an unverified email, an internal
+// error) takes the count back. err is returned unchanged unless settling fails.
+func (s *Service) settleAttempt(ctx context.Context, tenantID, email string, client Client, err error) error {
+	var settleErr error
+	switch {
+	case err == nil:
+		settleErr = s.lockout.Succeed(ctx, tenantID, email, client.IPAddress)
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidMFACode):
+	default:
+		settleErr = s.lockout.Forgive(ctx, tenantID, email, client.IPAddress)
+	}
+	if settleErr != nil {
+		if err != nil {
+			log.Printf("Failed to settle login attempt: %v", settleErr)
+			return err
+		}
+		return settleErr
+	}
+	return err
+}
@@ -0,0 +1,537 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"crm-platform/auth-service/internal/db"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MFA defaults
+const (
+	DefaultMFAIssuer       = "MTenant CRM"
+	DefaultMFAChallengeTTL = 5 * time.Minute
+	RecoveryCodeCount      = 10
+)
+
+// mfaChallengeAudience keeps MFA challenges from passing as any other token
+const mfaChallengeAudience = "mfa-challenge"
+
+// MFAPolicy reads and changes the roles that must use MFA in a tenant (tenant.RegistryMFAPolicy)
+type MFAPolicy interface {
+	MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error)
+	SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error
+}
+
+// MFAOptions controls TOTP enrollment and the second login step
+type MFAOptions struct {
+	Issuer       string        // name authenticator apps show, DefaultMFAIssuer if empty
+	Key          []byte        // encrypts stored TOTP secrets and signs challenges; required for MFA
+	ChallengeTTL time.Duration // time to enter the code after the password, DefaultMFAChallengeTTL if <= 0
+	Policy       MFAPolicy     // roles that must use MFA; nil requires it of nobody
+}
+
+func (o MFAOptions) withDefaults() MFAOptions {
+	if o.Issuer == "" {
+		o.Issuer = DefaultMFAIssuer
+	}
+	if o.ChallengeTTL <= 0 {
+		o.ChallengeTTL = DefaultMFAChallengeTTL
+	}
+	return o
+}
+
+// MFAChallenge is returned by Login instead of tokens when a second factor is needed
+type MFAChallenge struct {
+	Token     string // pass to CompleteMFALogin with a code
+	ExpiresAt time.Time
+	Enroll    bool // the user's role requires MFA but none is set up; EnrollMFA first
+}
+
+// MFAEnrollment is a new TOTP secret waiting for ConfirmMFA
+type MFAEnrollment struct {
+	Secret          string // base32, for typing into an authenticator app
+	ProvisioningURI string // otpauth:// URI to render as a QR code
+}
+
+// MFAStatus describes a user's MFA setup
+type MFAStatus struct {
+	Enabled           bool
+	Required          bool // the user's role must use MFA in this tenant
+	RecoveryCodesLeft int64
+}
+
+// mfaChallengeClaims binds a challenge to one user in one tenant
+type mfaChallengeClaims struct {
+	TenantID string `json:"tenant_id"`
+	Enroll   bool   `json:"enroll,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// CompleteMFALogin checks the second factor of a Login challenge and opens the session
+// code is a TOTP code or an unused recovery code. For enrollment challenges the code
+// must come from the secret EnrollMFA returned; MFA is then enabled and the new
+// recovery codes are returned along with the tokens. Wrong codes count as failed
+// logins for the user's email and the client IP.
+func (s *Service) CompleteMFALogin(ctx context.Context, tenantID, challenge, code string, client Client) (*Tokens, []string, error) {
+	claims, err := s.parseMFAChallenge(tenantID, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.challengeUser(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	emailVerified, err := s.checkVerification(user.EmailVerified)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.lockout.Begin(ctx, tenantID, user.Email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	var recoveryCodes []string
+	switch {
+	case user.MfaEnabled:
+		err = s.checkSecondFactor(ctx, user, code)
+	case claims.Enroll:
+		recoveryCodes, err = s.confirmMFA(ctx, user, code)
+	default:
+		err = ErrInvalidMFAChallenge // MFA was turned off after the password step
+	}
+
+	var tokens *Tokens
+	if err == nil {
+		tokens, err = s.startSession(ctx, tenantID, user.ID, user.Role, emailVerified, client)
+	}
+	if err := s.settleAttempt(ctx, tenantID, user.Email, client, err); err != nil {
+		return nil, nil, err
+	}
+	return tokens, recoveryCodes, nil
+}
+
+// EnrollmentChallengeUser returns the user an enrollment challenge was issued to
+// Lets users whose role requires MFA enroll before they have an access token.
+func (s *Service) EnrollmentChallengeUser(tenantID, challenge string) (int32, error) {
+	claims, err := s.parseMFAChallenge(tenantID, challenge)
+	if err != nil {
+		return 0, err
+	}
+	if !claims.Enroll {
+		return 0, ErrInvalidMFAChallenge
+	}
+	return challengeUserID(claims)
+}
+
+// EnrollMFA stores a new TOTP secret for the user, replacing any unconfirmed one
+// MFA is enforced only after ConfirmMFA proves the authenticator app has the secret.
+func (s *Service) EnrollMFA(ctx context.Context, userID int32) (*MFAEnrollment, error) {
+	if len(s.opts.MFA.Key) == 0 {
+		return nil, fmt.Errorf("mfa not configured")
+	}
+	user, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MfaEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.sealMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.store.SetMFASecret(ctx, db.SetMFASecretParams{ID: user.ID, MfaSecret: &sealed})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store mfa secret: %w", err)
+	}
+	if stored == 0 {
+		return nil, ErrMFAAlreadyEnabled // confirmed concurrently
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: ProvisioningURI(s.opts.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables MFA once code matches the enrolled secret and returns the recovery codes
+// The codes are only returned here; only their hashes are stored.
+func (s *Service) ConfirmMFA(ctx context.Context, userID int32, code string) ([]string, error) {
+	user, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MfaEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return s.confirmMFA(ctx, user, code)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a second factor
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int32, code string) ([]string, error) {
+	user, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MfaEnabled {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.store.WithTx(ctx, func(q db.Querier) error {
+		codes, err = replaceRecoveryCodes(ctx, q, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// DisableMFA turns MFA off after checking a second factor
+// Users whose role requires MFA in the tenant get ErrMFARequiredByPolicy.
+func (s *Service) DisableMFA(ctx context.Context, tenantID string, userID int32, code string) error {
+	user, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MfaEnabled {
+		return ErrMFANotEnrolled
+	}
+	required, err := s.mfaRequired(ctx, tenantID, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByPolicy
+	}
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	err = s.store.WithTx(ctx, func(q db.Querier) error {
+		if err := q.DisableMFA(ctx, user.ID); err != nil {
+			return err
+		}
+		return q.DeleteRecoveryCodes(ctx, user.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disable mfa: %w", err)
+	}
+	return nil
+}
+
+// MFAStatus reports whether the user has MFA, must have it, and how many recovery codes are left
+func (s *Service) MFAStatus(ctx context.Context, tenantID string, userID int32) (*MFAStatus, error) {
+	user, err := s.mfaUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.mfaRequired(ctx, tenantID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	left, err := s.store.CountUnusedRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return &MFAStatus{Enabled: user.MfaEnabled, Required: required, RecoveryCodesLeft: left}, nil
+}
+
+// MFARequiredRoles returns the roles that must use MFA in the tenant
+func (s *Service) MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+	if s.opts.MFA.Policy == nil {
+		return []string{}, nil
+	}
+	roles, err := s.opts.MFA.Policy.MFARequiredRoles(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mfa policy: %w", err)
+	}
+	return roles, nil
+}
+
+// SetMFARequiredRoles replaces the roles that must use MFA in the tenant
+// Their users without MFA are sent through enrollment at their next login.
+func (s *Service) SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+	if s.opts.MFA.Policy == nil {
+		return fmt.Errorf("mfa policy not configured")
+	}
+	if err := s.opts.MFA.Policy.SetMFARequiredRoles(ctx, tenantID, roles); err != nil {
+		return fmt.Errorf("failed to store mfa policy: %w", err)
+	}
+	return nil
+}
+
+// mfaChallenge decides whether a user who passed the password check needs a second factor
+// It returns nil when the user can be signed in directly.
+func (s *Service) mfaChallenge(ctx context.Context, tenantID string, userID int32, role string, mfaEnabled bool) (*MFAChallenge, error) {
+	required, err := s.mfaRequired(ctx, tenantID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !mfaEnabled && !required {
+		return nil, nil
+	}
+	if len(s.opts.MFA.Key) == 0 {
+		return nil, fmt.Errorf("mfa not configured")
+	}
+
+	now := s.opts.Now()
+	expiresAt := now.Add(s.opts.MFA.ChallengeTTL)
+	claims := mfaChallengeClaims{
+		TenantID: tenantID,
+		Enroll:   !mfaEnabled,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(int(userID)),
+			Audience:  jwt.ClaimStrings{mfaChallengeAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(DeriveKey(s.opts.MFA.Key, mfaChallengeAudience))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mfa challenge: %w", err)
+	}
+	return &MFAChallenge{Token: token, ExpiresAt: expiresAt, Enroll: !mfaEnabled}, nil
+}
+
+// mfaRequired reports whether the tenant requires MFA for role
+func (s *Service) mfaRequired(ctx context.Context, tenantID, role string) (bool, error) {
+	roles, err := s.MFARequiredRoles(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, role), nil
+}
+
+// parseMFAChallenge checks the signature, audience, expiry and tenant of a challenge
+func (s *Service) parseMFAChallenge(tenantID, challenge string) (*mfaChallengeClaims, error) {
+	if len(s.opts.MFA.Key) == 0 {
+		return nil, ErrInvalidMFAChallenge
+	}
+	claims := &mfaChallengeClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(mfaChallengeAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.opts.Now),
+	)
+	_, err := parser.ParseWithClaims(challenge, claims, func(*jwt.Token) (interface{}, error) {
+		return DeriveKey(s.opts.MFA.Key, mfaChallengeAudience), nil
+	})
+	if err != nil || claims.TenantID != tenantID {
+		return nil, ErrInvalidMFAChallenge
+	}
+	return claims, nil
+}
+
+// challengeUser loads the active user a challenge was issued to
+func (s *Service) challengeUser(ctx context.Context, claims *mfaChallengeClaims) (db.GetUserMFARow, error) {
+	userID, err := challengeUserID(claims)
+	if err != nil {
+		return db.GetUserMFARow{}, err
+	}
+	user, err := s.store.GetUserMFA(ctx, userID)
+	if isNoRows(err) {
+		return db.GetUserMFARow{}, ErrInvalidMFAChallenge
+	}
+	if err != nil {
+		return db.GetUserMFARow{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	return user, nil
+}
+
+// mfaUser loads an active user for the MFA management calls
+func (s *Service) mfaUser(ctx context.Context, userID int32) (db.GetUserMFARow, error) {
+	user, err := s.store.GetUserMFA(ctx, userID)
+	if isNoRows(err) {
+		return db.GetUserMFARow{}, ErrUserInactive
+	}
+	if err != nil {
+		return db.GetUserMFARow{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	return user, nil
+}
+
+// confirmMFA checks code against the enrolled secret, enables MFA and issues recovery codes
+func (s *Service) confirmMFA(ctx context.Context, user db.GetUserMFARow, code string) ([]string, error) {
+	if user.MfaSecret == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.store.WithTx(ctx, func(q db.Querier) error {
+		enabled, err := q.EnableMFA(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if enabled == 0 {
+			return ErrMFAAlreadyEnabled // confirmed concurrently
+		}
+		codes, err = replaceRecoveryCodes(ctx, q, user.ID)
+		return err
+	})
+	if errors.Is(err, ErrMFAAlreadyEnabled) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable mfa: %w", err)
+	}
+	return codes, nil
+}
+
+// checkSecondFactor accepts a TOTP code or an unused recovery code, using it up
+func (s *Service) checkSecondFactor(ctx context.Context, user db.GetUserMFARow, code string) error {
+	if len(strings.TrimSpace(code)) == TOTPDigits {
+		return s.checkTOTP(ctx, user, code)
+	}
+
+	used, err := s.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: hashToken(normalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if used == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// checkTOTP accepts a TOTP code once; a code for a step at or before the last accepted one is refused
+func (s *Service) checkTOTP(ctx context.Context, user db.GetUserMFARow, code string) error {
+	if user.MfaSecret == nil {
+		return ErrMFANotEnrolled
+	}
+	secret, err := s.openMFASecret(*user.MfaSecret)
+	if err != nil {
+		return err
+	}
+	step, ok := matchTOTP(secret, code, s.opts.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	used, err := s.store.UseMFAStep(ctx, db.UseMFAStepParams{ID: user.ID, MfaLastStep: &step})
+	if err != nil {
+		return fmt.Errorf("failed to record mfa code: %w", err)
+	}
+	if used == 0 {
+		return ErrInvalidMFACode // replayed
+	}
+	return nil
+}
+
+// sealMFASecret encrypts a TOTP secret with AES-256-GCM for storage
+func (s *Service) sealMFASecret(secret string) (string, error) {
+	aead, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// openMFASecret decrypts a TOTP secret sealed by sealMFASecret
+func (s *Service) openMFASecret(stored string) (string, error) {
+	aead, err := s.mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(stored)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid stored mfa secret")
+	}
+	secret, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt mfa secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// mfaCipher returns the AEAD stored secrets are encrypted with
+func (s *Service) mfaCipher() (cipher.AEAD, error) {
+	if len(s.opts.MFA.Key) == 0 {
+		return nil, fmt.Errorf("mfa not configured")
+	}
+	block, err := aes.NewCipher(DeriveKey(s.opts.MFA.Key, "mfa-secret"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores RecoveryCodeCount new ones
+func replaceRecoveryCodes(ctx context.Context, q db.Querier, userID int32) ([]string, error) {
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, RecoveryCodeCount)
+	for len(codes) < RecoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		err = q.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{UserID: userID, CodeHash: hashToken(code)})
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// newRecoveryCode returns a code like "k3j9-x2mq-7hpa" (60 bits, lowercase base32)
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	raw := strings.ToLower(totpEncoding.EncodeToString(buf))[:12]
+	return raw[:4] + "-" + raw[4:8] + "-" + raw[8:], nil
+}
+
+// normalizeRecoveryCode lowercases a typed recovery code and restores its dashes
+func normalizeRecoveryCode(code string) string {
+	raw := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(raw) != 12 {
+		return raw
+	}
+	return raw[:4] + "-" + raw[4:8] + "-" + raw[8:]
+}
+
+// challengeUserID parses the user ID a challenge was issued to
+func challengeUserID(claims *mfaChallengeClaims) (int32, error) {
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidMFAChallenge
+	}
+	return int32(userID), nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptCost is the work factor for new password hashes (users.password_hash is VARCHAR(60))
+const BcryptCost = 12
+
+// dummyHash is compared against when no user matches, so unknown emails take as long as wrong passwords
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("crm-platform-dummy-password"), BcryptCost)
+	return hash
+})
+
+// HashPassword returns the bcrypt hash of password
+// Passwords longer than 72 bytes are rejected rather than silently truncated.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// burnPasswordCheck spends the time of one password comparison
+func burnPasswordCheck(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"crm-platform/auth-service/internal/db"
+	"crm-platform/auth-service/internal/mail"
+)
+
+// Password reset defaults
+const (
+	DefaultResetTokenTTL = time.Hour
+	DefaultResetLimit    = 3
+	DefaultResetWindow   = time.Hour
+)
+
+// ResetOptions controls password reset emails
+type ResetOptions struct {
+	Mailer   mail.Sender   // required for RequestPasswordReset
+	URL      string        // page the emailed link opens; the token is appended as ?token=
+	TokenTTL time.Duration // DefaultResetTokenTTL if <= 0
+	Limit    int           // reset emails per user per Window, DefaultResetLimit if <= 0
+	Window   time.Duration // DefaultResetWindow if <= 0; keep it <= TokenTTL, expired tokens are cleaned up
+}
+
+func (o ResetOptions) withDefaults() ResetOptions {
+	if o.TokenTTL <= 0 {
+		o.TokenTTL = DefaultResetTokenTTL
+	}
+	if o.Limit <= 0 {
+		o.Limit = DefaultResetLimit
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultResetWindow
+	}
+	return o
+}
+
+// RequestPasswordReset emails a single-use reset link to email, if it belongs to an active user
+// Unknown emails return nil so callers cannot probe which accounts exist. More than
+// Limit requests for one user per Window return ErrResetRateLimited without sending.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	if s.opts.Reset.Mailer == nil {
+		return fmt.Errorf("password reset mailer not configured")
+	}
+
+	user, err := s.store.GetUserByEmail(ctx, email)
+	if isNoRows(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+
+	now := s.opts.Now()
+	recent, err := s.store.CountRecentPasswordResetTokens(ctx, db.CountRecentPasswordResetTokensParams{
+		UserID:    user.ID,
+		CreatedAt: now.Add(-s.opts.Reset.Window),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count reset requests: %w", err)
+	}
+	if recent >= int64(s.opts.Reset.Limit) {
+		return ErrResetRateLimited
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.opts.Reset.TokenTTL)
+	_, err = s.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	return s.opts.Reset.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and works once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.FirstName, s.opts.Reset.TokenTTL, tokenLink(s.opts.Reset.URL, token)),
+	})
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// The token and any other outstanding reset tokens are used up, and every session
+// of the user is revoked so stolen refresh tokens stop working.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tokenHash := hashToken(token)
+	reset, err := s.store.GetPasswordResetToken(ctx, tokenHash)
+	if isNoRows(err) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load reset token: %w", err)
+	}
+	if !s.opts.Now().Before(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	passwordHash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	reason := RevokedPasswordReset
+	err = s.store.WithTx(ctx, func(q db.Querier) error {
+		used, err := q.MarkPasswordResetTokenUsed(ctx, tokenHash)
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			return ErrInvalidResetToken // used concurrently
+		}
+		err = q.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+			ID:           reset.UserID,
+			PasswordHash: passwordHash,
+			UpdatedBy:    &reset.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.InvalidatePasswordResetTokens(ctx, reset.UserID); err != nil {
+			return err
+		}
+		_, err = q.RevokeUserSessions(ctx, db.RevokeUserSessionsParams{UserID: reset.UserID, RevokedReason: &reason})
+		return err
+	})
+	if errors.Is(err, ErrInvalidResetToken) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	return nil
+}
+
+// tokenLink appends token to the URL of the page an emailed link opens
+func tokenLink(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
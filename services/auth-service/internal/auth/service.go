@@ -0,0 +1,332 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"crm-platform/auth-service/internal/db"
+	"crm-platform/auth-service/internal/lockout"
+	"crm-platform/pkg/middleware"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultSessionTTL is how long a login lasts before the user must sign in again
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// Session revocation reasons (auth_sessions.revoked_reason)
+const (
+	RevokedLogout        = "logout"
+	RevokedTokenReuse    = "refresh_token_reuse"
+	RevokedUserDisabled  = "user_disabled"
+	RevokedPasswordReset = "password_reset"
+)
+
+// Service errors; handlers map them to status codes
+var (
+	ErrInvalidCredentials  = fmt.Errorf("invalid email or password")
+	ErrInvalidRefreshToken = fmt.Errorf("invalid or expired refresh token")
+	ErrRefreshTokenReused  = fmt.Errorf("refresh token reuse detected, session revoked")
+	ErrInvalidResetToken   = fmt.Errorf("invalid or expired password reset token")
+	ErrResetRateLimited    = fmt.Errorf("too many password reset requests")
+
+	ErrInvalidVerificationToken = fmt.Errorf("invalid or expired email verification token")
+	ErrVerificationRateLimited  = fmt.Errorf("verification email sent recently")
+	ErrEmailNotVerified         = fmt.Errorf("email address not verified")
+
+	ErrInvalidMFAChallenge = fmt.Errorf("invalid or expired mfa challenge")
+	ErrInvalidMFACode      = fmt.Errorf("invalid mfa code")
+	ErrMFAAlreadyEnabled   = fmt.Errorf("mfa already enabled")
+	ErrMFANotEnrolled      = fmt.Errorf("mfa not enrolled")
+	ErrMFARequiredByPolicy = fmt.Errorf("mfa is required for this role")
+	ErrUserInactive        = fmt.Errorf("user not found or inactive")
+)
+
+// Options controls session lifetime, password resets, email verification, MFA and lockouts
+type Options struct {
+	SessionTTL   time.Duration    // DefaultSessionTTL if <= 0; refresh never extends it
+	Now          func() time.Time // time.Now if nil; also the TOTP and lockout clock
+	Reset        ResetOptions
+	Verification VerificationOptions
+	MFA          MFAOptions
+	Lockout      LockoutOptions
+}
+
+// Client identifies the device a session was created from
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Tokens is the result of a login or refresh
+type Tokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	SessionID        string
+	UserID           int32
+	Role             string
+}
+
+// Service implements login, refresh token rotation, logout, password resets, email verification and MFA
+type Service struct {
+	store   Store
+	tokens  *TokenIssuer
+	opts    Options
+	lockout *lockout.Guard
+}
+
+// NewService creates the auth service
+func NewService(store Store, tokens *TokenIssuer, opts Options) *Service {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	opts.Reset = opts.Reset.withDefaults()
+	opts.Verification = opts.Verification.withDefaults()
+	opts.MFA = opts.MFA.withDefaults()
+	if opts.Lockout.Store == nil {
+		opts.Lockout.Store = lockout.NewMemoryStore()
+	}
+	return &Service{
+		store:   store,
+		tokens:  tokens,
+		opts:    opts,
+		lockout: lockout.NewGuard(opts.Lockout.Store, opts.Lockout.Policy, opts.Now),
+	}
+}
+
+// Login checks email and password in the context's tenant and opens a session
+// Unknown, inactive and deleted users fail exactly like a wrong password. Users with
+// an unverified email get ErrEmailNotVerified or a limited token, per the verification policy.
+// Users with MFA, or whose role requires it, get an MFAChallenge instead of tokens.
+// Repeated failures for the email or client IP are delayed and then locked out with a
+// *lockout.ThrottledError. Each attempt is counted before the password is checked, so
+// locked accounts cannot be probed and concurrent guesses cannot outrun the limits.
+func (s *Service) Login(ctx context.Context, tenantID, email, password string, client Client) (*Tokens, *MFAChallenge, error) {
+	if err := s.lockout.Begin(ctx, tenantID, email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, challenge, err := s.login(ctx, tenantID, email, password, client)
+	if challenge != nil {
+		// The password was right, but earlier failures stay counted until the second factor is passed too
+		if err := s.lockout.Forgive(ctx, tenantID, email, client.IPAddress); err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+	if err := s.settleAttempt(ctx, tenantID, email, client, err); err != nil {
+		return nil, nil, err
+	}
+	return tokens, nil, nil
+}
+
+// login checks the password of an attempt Login admitted
+func (s *Service) login(ctx context.Context, tenantID, email, password string, client Client) (*Tokens, *MFAChallenge, error) {
+	user, err := s.store.GetUserForAuth(ctx, email)
+	if isNoRows(err) {
+		burnPasswordCheck(password)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if !CheckPassword(user.PasswordHash, password) {
+		return nil, nil, ErrInvalidCredentials
+	}
+	emailVerified, err := s.checkVerification(user.EmailVerified)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := s.mfaChallenge(ctx, tenantID, user.ID, user.Role, user.MfaEnabled)
+	if err != nil || challenge != nil {
+		return nil, challenge, err
+	}
+
+	tokens, err := s.startSession(ctx, tenantID, user.ID, user.Role, emailVerified, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, nil, nil
+}
+
+// Refresh exchanges a refresh token for new access and refresh tokens
+// Each refresh token works once. Presenting one that was already exchanged means
+// it leaked, so the whole session is revoked and ErrRefreshTokenReused returned.
+func (s *Service) Refresh(ctx context.Context, tenantID, refreshToken string) (*Tokens, error) {
+	tokenHash := hashToken(refreshToken)
+	stored, err := s.store.GetRefreshToken(ctx, tokenHash)
+	if isNoRows(err) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refresh token: %w", err)
+	}
+
+	if stored.UsedAt.Valid {
+		return nil, s.revokeForReuse(ctx, stored.SessionID)
+	}
+	now := s.opts.Now()
+	if stored.RevokedAt.Valid || !now.Before(stored.ExpiresAt) || !now.Before(stored.SessionExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.store.GetUserByID(ctx, stored.UserID)
+	if isNoRows(err) || (err == nil && (user.Active == nil || !*user.Active)) {
+		_ = s.revoke(ctx, stored.SessionID, RevokedUserDisabled)
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	emailVerified, err := s.checkVerification(user.EmailVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	reused := false
+	err = s.store.WithTx(ctx, func(q db.Querier) error {
+		used, err := q.UseRefreshToken(ctx, tokenHash)
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			reused = true // exchanged concurrently
+			return nil
+		}
+		return q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+			SessionID: stored.SessionID,
+			TokenHash: hashToken(next),
+			ExpiresAt: stored.SessionExpiresAt,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if reused {
+		return nil, s.revokeForReuse(ctx, stored.SessionID)
+	}
+
+	return s.issue(tenantID, user.ID, user.Role, emailVerified, stored.SessionID, next, stored.SessionExpiresAt)
+}
+
+// Logout revokes the session a refresh token belongs to so none of its tokens work again
+// Access tokens already issued stay valid until they expire (TokenIssuer TTL).
+// Unknown tokens and already revoked sessions are not an error.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.store.GetRefreshToken(ctx, hashToken(refreshToken))
+	if isNoRows(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load refresh token: %w", err)
+	}
+	return s.revoke(ctx, stored.SessionID, RevokedLogout)
+}
+
+// startSession opens a session with its first refresh token and issues the token pair
+func (s *Service) startSession(ctx context.Context, tenantID string, userID int32, role string, emailVerified *bool, client Client) (*Tokens, error) {
+	sessionID, err := randomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := s.opts.Now().Add(s.opts.SessionTTL)
+
+	err = s.store.WithTx(ctx, func(q db.Querier) error {
+		_, err := q.CreateSession(ctx, db.CreateSessionParams{
+			ID:        sessionID,
+			UserID:    userID,
+			UserAgent: optional(client.UserAgent),
+			IpAddress: optional(client.IPAddress),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+			SessionID: sessionID,
+			TokenHash: hashToken(refreshToken),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		return q.UpdateUserLastLogin(ctx, userID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.issue(tenantID, userID, role, emailVerified, sessionID, refreshToken, expiresAt)
+}
+
+// issue signs an access token for a session and bundles it with the refresh token
+func (s *Service) issue(tenantID string, userID int32, role string, emailVerified *bool, sessionID, refreshToken string, refreshExpiresAt time.Time) (*Tokens, error) {
+	accessToken, accessExpiresAt, err := s.tokens.Issue(middleware.Claims{
+		UserID:        strconv.Itoa(int(userID)),
+		TenantID:      tenantID,
+		Role:          role,
+		SessionID:     sessionID,
+		EmailVerified: emailVerified,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		SessionID:        sessionID,
+		UserID:           userID,
+		Role:             role,
+	}, nil
+}
+
+// revoke marks a session revoked with reason
+func (s *Service) revoke(ctx context.Context, sessionID, reason string) error {
+	if _, err := s.store.RevokeSession(ctx, db.RevokeSessionParams{ID: sessionID, RevokedReason: &reason}); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// revokeForReuse revokes a session whose refresh token was replayed
+func (s *Service) revokeForReuse(ctx context.Context, sessionID string) error {
+	if err := s.revoke(ctx, sessionID, RevokedTokenReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// isNoRows reports whether err means the query matched nothing
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows)
+}
+
+// optional returns nil for an empty string
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+
+	"crm-platform/auth-service/internal/db"
+	"crm-platform/pkg/tenant"
+)
+
+// Store runs the auth queries in the tenant carried by the context
+type Store interface {
+	db.Querier
+	// WithTx runs fn in one transaction, committing only if it returns nil
+	WithTx(ctx context.Context, fn func(q db.Querier) error) error
+}
+
+// TenantStore is a Store over a tenant-aware pool
+type TenantStore struct {
+	*db.Queries
+	pool *tenant.TenantPool
+}
+
+// NewTenantStore creates a store whose queries are isolated by the request's tenant
+func NewTenantStore(pool *tenant.TenantPool) *TenantStore {
+	return &TenantStore{Queries: db.New(pool), pool: pool}
+}
+
+// WithTx runs fn in a tenant transaction
+func (s *TenantStore) WithTx(ctx context.Context, fn func(q db.Querier) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(db.New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
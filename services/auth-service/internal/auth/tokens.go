@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"crm-platform/pkg/middleware"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultAccessTokenTTL bounds how long an access token outlives its session's revocation
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// ErrUnsupportedKey is returned for signing keys other than RSA (2048+ bits) and EC P-256
+var ErrUnsupportedKey = fmt.Errorf("unsupported signing key")
+
+// IssuerOptions are the registered claims and lifetime of issued access tokens
+// Issuer and Audience must match JWT_ISSUER and JWT_AUDIENCE of the verifying services.
+type IssuerOptions struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration    // DefaultAccessTokenTTL if <= 0
+	Now      func() time.Time // time.Now if nil
+}
+
+// TokenIssuer signs access tokens that middleware.AuthMiddleware accepts
+type TokenIssuer struct {
+	opts   IssuerOptions
+	method jwt.SigningMethod
+	key    interface{} // crypto.Signer, or []byte for HMAC
+	kid    string
+}
+
+// NewHMACIssuer signs HS256 tokens with the shared secret (SHARED_JWT_SECRET)
+func NewHMACIssuer(secret []byte, opts IssuerOptions) *TokenIssuer {
+	return newTokenIssuer(jwt.SigningMethodHS256, secret, "", opts)
+}
+
+// NewKeyIssuer signs RS256 (RSA) or ES256 (EC P-256) tokens and publishes the public key in JWKS
+// An empty kid is derived from the public key.
+func NewKeyIssuer(signer crypto.Signer, kid string, opts IssuerOptions) (*TokenIssuer, error) {
+	var method jwt.SigningMethod
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("%w: RSA key has %d bits, need at least 2048", ErrUnsupportedKey, pub.N.BitLen())
+		}
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: EC keys must use P-256", ErrUnsupportedKey)
+		}
+		method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, pub)
+	}
+
+	if kid == "" {
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+		}
+		sum := sha256.Sum256(der)
+		kid = hex.EncodeToString(sum[:8])
+	}
+	return newTokenIssuer(method, signer, kid, opts), nil
+}
+
+func newTokenIssuer(method jwt.SigningMethod, key interface{}, kid string, opts IssuerOptions) *TokenIssuer {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultAccessTokenTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &TokenIssuer{opts: opts, method: method, key: key, kid: kid}
+}
+
+// LoadSigningKey reads a PEM private key (PKCS#8, PKCS#1 RSA or SEC 1 EC)
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s is not PEM encoded", ErrUnsupportedKey, path)
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %s holds no RSA or EC private key", ErrUnsupportedKey, path)
+}
+
+// TTL returns the lifetime of issued access tokens
+func (t *TokenIssuer) TTL() time.Duration {
+	return t.opts.TTL
+}
+
+// Issue signs an access token for claims, filling in the registered claims
+func (t *TokenIssuer) Issue(claims middleware.Claims) (string, time.Time, error) {
+	now := t.opts.Now()
+	expiresAt := now.Add(t.opts.TTL)
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    t.opts.Issuer,
+		Subject:   claims.UserID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		ID:        jti,
+	}
+	if t.opts.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{t.opts.Audience}
+	}
+
+	token := jwt.NewWithClaims(t.method, claims)
+	if t.kid != "" {
+		token.Header["kid"] = t.kid
+	}
+	signed, err := token.SignedString(t.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks an access token this issuer signed and returns its claims
+// Lets auth-service authenticate its own endpoints without fetching its JWKS.
+func (t *TokenIssuer) Verify(token string) (*middleware.Claims, error) {
+	key := t.key
+	if signer, ok := t.key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{t.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(t.opts.Now),
+	}
+	if t.opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(t.opts.Issuer))
+	}
+	if t.opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(t.opts.Audience))
+	}
+
+	claims := &middleware.Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, parserOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKS returns the public key set verifying services fetch (JWT_JWKS_URL)
+// HMAC issuers publish no keys.
+func (t *TokenIssuer) JWKS() map[string]interface{} {
+	keys := []map[string]string{}
+
+	if signer, ok := t.key.(crypto.Signer); ok {
+		jwk := map[string]string{"kid": t.kid, "use": "sig", "alg": t.method.Alg()}
+		switch pub := signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = encodeBigInt(pub.N)
+			jwk["e"] = encodeBigInt(big.NewInt(int64(pub.E)))
+		case *ecdsa.PublicKey:
+			jwk["kty"] = "EC"
+			jwk["crv"] = "P-256"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+			jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+		}
+		keys = append(keys, jwk)
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
+
+// encodeBigInt encodes n as unpadded base64url, as JWK requires
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// randomToken returns n random bytes encoded as unpadded base64url
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the hex SHA-256 of a token, the only form stored in the database
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeriveKey derives an HMAC key for one purpose from a shared secret
+// Tokens signed with derived keys cannot be replayed as tokens for another purpose.
+func DeriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crm-platform/auth-service/internal/db"
+	"crm-platform/auth-service/internal/mail"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Email verification defaults
+const (
+	DefaultVerificationTokenTTL       = 24 * time.Hour
+	DefaultVerificationResendInterval = 5 * time.Minute
+)
+
+// verificationAudience keeps verification tokens from passing as access tokens and vice versa
+const verificationAudience = "email-verification"
+
+// VerificationPolicy decides what users with an unverified email may do
+type VerificationPolicy string
+
+// Verification policies (EMAIL_VERIFICATION_POLICY)
+const (
+	VerificationOff   VerificationPolicy = "off"   // no restriction
+	VerificationLimit VerificationPolicy = "limit" // access tokens carry email_verified=false, limiting permissions
+	VerificationBlock VerificationPolicy = "block" // login and refresh fail with ErrEmailNotVerified
+)
+
+// ParseVerificationPolicy parses a policy name; empty means VerificationOff
+func ParseVerificationPolicy(s string) (VerificationPolicy, error) {
+	switch policy := VerificationPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case "", VerificationOff:
+		return VerificationOff, nil
+	case VerificationLimit, VerificationBlock:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown email verification policy %q (want off, limit or block)", s)
+	}
+}
+
+// VerificationOptions controls verification emails and what unverified users may do
+type VerificationOptions struct {
+	Mailer         mail.Sender        // required for SendVerificationEmail
+	URL            string             // page the emailed link opens; the token is appended as ?token=
+	Secret         []byte             // HMAC key verification tokens are signed with; required for sending and verifying
+	TokenTTL       time.Duration      // DefaultVerificationTokenTTL if <= 0
+	ResendInterval time.Duration      // minimum time between emails to one user, DefaultVerificationResendInterval if <= 0
+	Policy         VerificationPolicy // VerificationOff if empty
+}
+
+func (o VerificationOptions) withDefaults() VerificationOptions {
+	if o.TokenTTL <= 0 {
+		o.TokenTTL = DefaultVerificationTokenTTL
+	}
+	if o.ResendInterval <= 0 {
+		o.ResendInterval = DefaultVerificationResendInterval
+	}
+	if o.Policy == "" {
+		o.Policy = VerificationOff
+	}
+	return o
+}
+
+// verificationClaims binds a verification token to one user and address in one tenant
+type verificationClaims struct {
+	TenantID string `json:"tenant_id"`
+	Email    string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// SendVerificationEmail emails a verification link to email, if it belongs to an active unverified user
+// Unknown and already verified emails return nil so callers cannot probe which accounts
+// exist. A second email within ResendInterval returns ErrVerificationRateLimited without sending.
+func (s *Service) SendVerificationEmail(ctx context.Context, tenantID, email string) error {
+	if s.opts.Verification.Mailer == nil || len(s.opts.Verification.Secret) == 0 {
+		return fmt.Errorf("email verification not configured")
+	}
+
+	user, err := s.store.GetUserByEmail(ctx, email)
+	if isNoRows(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	if isVerified(user.EmailVerified) {
+		return nil
+	}
+
+	now := s.opts.Now()
+	marked, err := s.store.MarkVerificationEmailSent(ctx, db.MarkVerificationEmailSentParams{
+		SentAt:       sql.NullTime{Time: now, Valid: true},
+		ID:           user.ID,
+		ResendBefore: sql.NullTime{Time: now.Add(-s.opts.Verification.ResendInterval), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record verification email: %w", err)
+	}
+	if marked == 0 {
+		return ErrVerificationRateLimited // sent recently, or verified concurrently
+	}
+
+	token, err := s.signVerificationToken(tenantID, user.ID, user.Email, now)
+	if err != nil {
+		return err
+	}
+
+	return s.opts.Verification.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email address. It expires in %s.\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.FirstName, s.opts.Verification.TokenTTL, tokenLink(s.opts.Verification.URL, token)),
+	})
+}
+
+// VerifyEmail marks the user a token from SendVerificationEmail was issued to as verified
+// Tokens from another tenant, for an address the user no longer has, or past their
+// expiry return ErrInvalidVerificationToken. Verifying twice is not an error.
+func (s *Service) VerifyEmail(ctx context.Context, tenantID, token string) error {
+	if len(s.opts.Verification.Secret) == 0 {
+		return fmt.Errorf("email verification not configured")
+	}
+
+	claims, err := s.parseVerificationToken(token)
+	if err != nil || claims.TenantID != tenantID {
+		return ErrInvalidVerificationToken
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.store.GetUserByID(ctx, int32(userID))
+	if isNoRows(err) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return ErrInvalidVerificationToken
+	}
+	if isVerified(user.EmailVerified) {
+		return nil
+	}
+
+	err = s.store.VerifyUserEmail(ctx, db.VerifyUserEmailParams{ID: user.ID, UpdatedBy: &user.ID})
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// checkVerification applies the verification policy to a user signing in or refreshing
+// It returns the email_verified claim to put in the access token (nil for no restriction).
+func (s *Service) checkVerification(emailVerified *bool) (*bool, error) {
+	verified := isVerified(emailVerified)
+	switch s.opts.Verification.Policy {
+	case VerificationBlock:
+		if !verified {
+			return nil, ErrEmailNotVerified
+		}
+	case VerificationLimit:
+		if !verified {
+			return &verified, nil
+		}
+	}
+	return nil, nil
+}
+
+// signVerificationToken signs a verification token for a user's current address
+func (s *Service) signVerificationToken(tenantID string, userID int32, email string, now time.Time) (string, error) {
+	claims := verificationClaims{
+		TenantID: tenantID,
+		Email:    email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(int(userID)),
+			Audience:  jwt.ClaimStrings{verificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.opts.Verification.TokenTTL)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.opts.Verification.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign verification token: %w", err)
+	}
+	return signed, nil
+}
+
+// parseVerificationToken checks the signature, audience and expiry of a verification token
+func (s *Service) parseVerificationToken(token string) (*verificationClaims, error) {
+	claims := &verificationClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(verificationAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.opts.Now),
+	)
+	_, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.opts.Verification.Secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// isVerified treats a NULL email_verified as unverified
+func isVerified(emailVerified *bool) bool {
+	return emailVerified != nil && *emailVerified
+}
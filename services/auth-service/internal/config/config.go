@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/pkg/config"
+)
+
+// Settings are the auth-service settings on top of the shared pkg/config ones
+// They load in the same layers: Defaults, the CONFIG_FILE "service" section, environment
+// variables, then secret files for the secrets. Zero durations and counts keep the
+// auth package defaults.
+type Settings struct {
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl"` // JWT_ACCESS_TTL (0 = 15m)
+	SessionTTL     time.Duration `yaml:"session_ttl"`      // JWT_REFRESH_TTL (0 = 30 days)
+	SigningKeyFile string        `yaml:"signing_key_file"` // JWT_SIGNING_KEY_FILE (PEM private key; HMAC with SHARED_JWT_SECRET when unset)
+	SigningKeyID   string        `yaml:"signing_key_id"`   // JWT_SIGNING_KEY_ID (empty derives it from the key)
+
+	PasswordResetURL string `yaml:"password_reset_url"` // PASSWORD_RESET_URL
+
+	MailFrom     string `yaml:"mail_from"`     // MAIL_FROM
+	MailDir      string `yaml:"mail_dir"`      // MAIL_DIR (used when SMTP_HOST is unset)
+	SMTPHost     string `yaml:"smtp_host"`     // SMTP_HOST (empty disables SMTP)
+	SMTPPort     int    `yaml:"smtp_port"`     // SMTP_PORT (0 = 587)
+	SMTPUsername string `yaml:"smtp_username"` // SMTP_USERNAME
+	SMTPPassword string `yaml:"smtp_password"` // SMTP_PASSWORD (secret)
+
+	EmailVerificationPolicy         string        `yaml:"email_verification_policy"`          // EMAIL_VERIFICATION_POLICY (off, limit or block)
+	EmailVerificationURL            string        `yaml:"email_verification_url"`             // EMAIL_VERIFICATION_URL
+	EmailVerificationSecret         string        `yaml:"email_verification_secret"`          // EMAIL_VERIFICATION_SECRET (secret; derived from SHARED_JWT_SECRET when unset)
+	EmailVerificationTTL            time.Duration `yaml:"email_verification_ttl"`             // EMAIL_VERIFICATION_TTL (0 = 24h)
+	EmailVerificationResendInterval time.Duration `yaml:"email_verification_resend_interval"` // EMAIL_VERIFICATION_RESEND_INTERVAL (0 = 5m)
+
+	MFAIssuer        string        `yaml:"mfa_issuer"`         // MFA_ISSUER (empty = "MTenant CRM")
+	MFAEncryptionKey string        `yaml:"mfa_encryption_key"` // MFA_ENCRYPTION_KEY (secret; derived from SHARED_JWT_SECRET when unset)
+	MFAChallengeTTL  time.Duration `yaml:"mfa_challenge_ttl"`  // MFA_CHALLENGE_TTL (0 = 5m)
+
+	LockoutAccountAttempts int           `yaml:"lockout_account_attempts"` // LOCKOUT_ACCOUNT_ATTEMPTS (0 = 10)
+	LockoutIPAttempts      int           `yaml:"lockout_ip_attempts"`      // LOCKOUT_IP_ATTEMPTS (0 = 100)
+	LockoutDuration        time.Duration `yaml:"lockout_duration"`         // LOCKOUT_DURATION (0 = 15m)
+
+	TrustedProxies []string `yaml:"trusted_proxies"` // TRUSTED_PROXIES (IPs or CIDRs whose X-Forwarded-For is believed; none by default)
+}
+
+// Defaults returns the built-in auth-service settings
+func Defaults() *Settings {
+	return &Settings{
+		MailFrom: "no-reply@localhost",
+	}
+}
+
+// Load reads the shared configuration and the auth-service settings, failing on any invalid value
+func Load() (*config.Config, *Settings, error) {
+	settings := Defaults()
+	cfg, err := config.LoadService(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, settings, nil
+}
+
+// ApplyEnv overlays the auth-service environment variables
+func (s *Settings) ApplyEnv(env *config.EnvParser) {
+	env.Duration("JWT_ACCESS_TTL", &s.AccessTokenTTL)
+	env.Duration("JWT_REFRESH_TTL", &s.SessionTTL)
+	env.String("JWT_SIGNING_KEY_FILE", &s.SigningKeyFile)
+	env.String("JWT_SIGNING_KEY_ID", &s.SigningKeyID)
+	env.String("PASSWORD_RESET_URL", &s.PasswordResetURL)
+	env.String("MAIL_FROM", &s.MailFrom)
+	env.String("MAIL_DIR", &s.MailDir)
+	env.String("SMTP_HOST", &s.SMTPHost)
+	env.Int("SMTP_PORT", &s.SMTPPort)
+	env.String("SMTP_USERNAME", &s.SMTPUsername)
+	env.Secret("SMTP_PASSWORD", &s.SMTPPassword)
+	env.String("EMAIL_VERIFICATION_POLICY", &s.EmailVerificationPolicy)
+	env.String("EMAIL_VERIFICATION_URL", &s.EmailVerificationURL)
+	env.Secret("EMAIL_VERIFICATION_SECRET", &s.EmailVerificationSecret)
+	env.Duration("EMAIL_VERIFICATION_TTL", &s.EmailVerificationTTL)
+	env.Duration("EMAIL_VERIFICATION_RESEND_INTERVAL", &s.EmailVerificationResendInterval)
+	env.String("MFA_ISSUER", &s.MFAIssuer)
+	env.Secret("MFA_ENCRYPTION_KEY", &s.MFAEncryptionKey)
+	env.Duration("MFA_CHALLENGE_TTL", &s.MFAChallengeTTL)
+	env.Int("LOCKOUT_ACCOUNT_ATTEMPTS", &s.LockoutAccountAttempts)
+	env.Int("LOCKOUT_IP_ATTEMPTS", &s.LockoutIPAttempts)
+	env.Duration("LOCKOUT_DURATION", &s.LockoutDuration)
+	env.List("TRUSTED_PROXIES", &s.TrustedProxies)
+}
+
+// Validate reports every invalid auth-service setting
+func (s *Settings) Validate() []string {
+	var problems []string
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"service.access_token_ttl", s.AccessTokenTTL},
+		{"service.session_ttl", s.SessionTTL},
+		{"service.email_verification_ttl", s.EmailVerificationTTL},
+		{"service.email_verification_resend_interval", s.EmailVerificationResendInterval},
+		{"service.mfa_challenge_ttl", s.MFAChallengeTTL},
+		{"service.lockout_duration", s.LockoutDuration},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			problem("%s must not be negative, got %v", d.name, d.value)
+		}
+	}
+
+	if s.SMTPPort < 0 || s.SMTPPort > 65535 {
+		problem("service.smtp_port must be between 1 and 65535 (0 = 587), got %d", s.SMTPPort)
+	}
+	if s.LockoutAccountAttempts < 0 {
+		problem("service.lockout_account_attempts must not be negative, got %d", s.LockoutAccountAttempts)
+	}
+	if s.LockoutIPAttempts < 0 {
+		problem("service.lockout_ip_attempts must not be negative, got %d", s.LockoutIPAttempts)
+	}
+	for _, proxy := range s.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problem("service.trusted_proxies: %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+	if _, err := auth.ParseVerificationPolicy(s.EmailVerificationPolicy); err != nil {
+		problem("service.email_verification_policy: %v", err)
+	}
+	return problems
+}
@@ -10,29 +10,62 @@ import (
 	"time"
 )
 
+type AuthSession struct {
+	ID            string       `json:"id"`
+	UserID        int32        `json:"user_id"`
+	UserAgent     *string      `json:"user_agent"`
+	IpAddress     *string      `json:"ip_address"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	RevokedAt     sql.NullTime `json:"revoked_at"`
+	RevokedReason *string      `json:"revoked_reason"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type PasswordResetToken struct {
 	ID        int32        `json:"id"`
-	UserID    *int32       `json:"user_id"`
-	Token     string       `json:"token"`
+	UserID    int32        `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        int32        `json:"id"`
+	SessionID string       `json:"session_id"`
+	TokenHash string       `json:"token_hash"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	UsedAt    sql.NullTime `json:"used_at"`
 	CreatedAt time.Time    `json:"created_at"`
 }
 
 type User struct {
-	ID            int32           `json:"id"`
-	Email         string          `json:"email"`
-	PasswordHash  string          `json:"password_hash"`
-	FirstName     string          `json:"first_name"`
-	LastName      string          `json:"last_name"`
-	Role          string          `json:"role"`
-	Permissions   json.RawMessage `json:"permissions"`
-	Active        *bool           `json:"active"`
-	EmailVerified *bool           `json:"email_verified"`
-	LastLogin     sql.NullTime    `json:"last_login"`
-	CreatedBy     *int32          `json:"created_by"`
-	UpdatedBy     *int32          `json:"updated_by"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
-	DeletedAt     sql.NullTime    `json:"deleted_at"`
+	ID                 int32           `json:"id"`
+	Email              string          `json:"email"`
+	PasswordHash       string          `json:"password_hash"`
+	FirstName          string          `json:"first_name"`
+	LastName           string          `json:"last_name"`
+	Role               string          `json:"role"`
+	Permissions        json.RawMessage `json:"permissions"`
+	Active             *bool           `json:"active"`
+	EmailVerified      *bool           `json:"email_verified"`
+	VerificationSentAt sql.NullTime    `json:"verification_sent_at"`
+	MfaEnabled         bool            `json:"mfa_enabled"`
+	MfaSecret          *string         `json:"mfa_secret"`
+	MfaEnabledAt       sql.NullTime    `json:"mfa_enabled_at"`
+	MfaLastStep        *int64          `json:"mfa_last_step"`
+	LastLogin          sql.NullTime    `json:"last_login"`
+	CreatedBy          *int32          `json:"created_by"`
+	UpdatedBy          *int32          `json:"updated_by"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	DeletedAt          sql.NullTime    `json:"deleted_at"`
 }
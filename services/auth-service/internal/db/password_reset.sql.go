@@ -21,43 +21,55 @@ func (q *Queries) CleanupExpiredTokens(ctx context.Context) error {
 	return err
 }
 
+const countRecentPasswordResetTokens = `-- name: CountRecentPasswordResetTokens :one
+SELECT COUNT(*)
+FROM password_reset_tokens
+WHERE user_id = $1 AND created_at > $2
+`
+
+type CountRecentPasswordResetTokensParams struct {
+	UserID    int32     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountRecentPasswordResetTokens(ctx context.Context, arg CountRecentPasswordResetTokensParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countRecentPasswordResetTokens, arg.UserID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPasswordResetToken = `-- name: CreatePasswordResetToken :one
-INSERT INTO password_reset_tokens (user_id, token, expires_at)
+INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
 VALUES ($1, $2, $3)
-RETURNING id, token, expires_at, created_at
+RETURNING id, expires_at, created_at
 `
 
 type CreatePasswordResetTokenParams struct {
-	UserID    *int32    `json:"user_id"`
-	Token     string    `json:"token"`
+	UserID    int32     `json:"user_id"`
+	TokenHash string    `json:"token_hash"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type CreatePasswordResetTokenRow struct {
 	ID        int32     `json:"id"`
-	Token     string    `json:"token"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (CreatePasswordResetTokenRow, error) {
-	row := q.db.QueryRow(ctx, createPasswordResetToken, arg.UserID, arg.Token, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, createPasswordResetToken, arg.UserID, arg.TokenHash, arg.ExpiresAt)
 	var i CreatePasswordResetTokenRow
-	err := row.Scan(
-		&i.ID,
-		&i.Token,
-		&i.ExpiresAt,
-		&i.CreatedAt,
-	)
+	err := row.Scan(&i.ID, &i.ExpiresAt, &i.CreatedAt)
 	return i, err
 }
 
 const getPasswordResetToken = `-- name: GetPasswordResetToken :one
-SELECT prt.id, prt.user_id, prt.token, prt.expires_at, prt.used_at,
+SELECT prt.id, prt.user_id, prt.expires_at, prt.used_at,
        u.email, u.first_name, u.last_name, u.active
 FROM password_reset_tokens prt
 JOIN users u ON prt.user_id = u.id
-WHERE prt.token = $1 
+WHERE prt.token_hash = $1 
   AND prt.used_at IS NULL 
   AND prt.expires_at > CURRENT_TIMESTAMP
   AND u.active = true 
@@ -66,8 +78,7 @@ WHERE prt.token = $1
 
 type GetPasswordResetTokenRow struct {
 	ID        int32        `json:"id"`
-	UserID    *int32       `json:"user_id"`
-	Token     string       `json:"token"`
+	UserID    int32        `json:"user_id"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	UsedAt    sql.NullTime `json:"used_at"`
 	Email     string       `json:"email"`
@@ -76,13 +87,12 @@ type GetPasswordResetTokenRow struct {
 	Active    *bool        `json:"active"`
 }
 
-func (q *Queries) GetPasswordResetToken(ctx context.Context, token string) (GetPasswordResetTokenRow, error) {
-	row := q.db.QueryRow(ctx, getPasswordResetToken, token)
+func (q *Queries) GetPasswordResetToken(ctx context.Context, tokenHash string) (GetPasswordResetTokenRow, error) {
+	row := q.db.QueryRow(ctx, getPasswordResetToken, tokenHash)
 	var i GetPasswordResetTokenRow
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.Token,
 		&i.ExpiresAt,
 		&i.UsedAt,
 		&i.Email,
@@ -94,7 +104,7 @@ func (q *Queries) GetPasswordResetToken(ctx context.Context, token string) (GetP
 }
 
 const getUserPasswordResetTokens = `-- name: GetUserPasswordResetTokens :many
-SELECT id, token, expires_at, used_at, created_at
+SELECT id, expires_at, used_at, created_at
 FROM password_reset_tokens
 WHERE user_id = $1
 ORDER BY created_at DESC
@@ -102,13 +112,12 @@ ORDER BY created_at DESC
 
 type GetUserPasswordResetTokensRow struct {
 	ID        int32        `json:"id"`
-	Token     string       `json:"token"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	UsedAt    sql.NullTime `json:"used_at"`
 	CreatedAt time.Time    `json:"created_at"`
 }
 
-func (q *Queries) GetUserPasswordResetTokens(ctx context.Context, userID *int32) ([]GetUserPasswordResetTokensRow, error) {
+func (q *Queries) GetUserPasswordResetTokens(ctx context.Context, userID int32) ([]GetUserPasswordResetTokensRow, error) {
 	rows, err := q.db.Query(ctx, getUserPasswordResetTokens, userID)
 	if err != nil {
 		return nil, err
@@ -119,7 +128,6 @@ func (q *Queries) GetUserPasswordResetTokens(ctx context.Context, userID *int32)
 		var i GetUserPasswordResetTokensRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Token,
 			&i.ExpiresAt,
 			&i.UsedAt,
 			&i.CreatedAt,
@@ -134,13 +142,27 @@ func (q *Queries) GetUserPasswordResetTokens(ctx context.Context, userID *int32)
 	return items, nil
 }
 
-const markPasswordResetTokenUsed = `-- name: MarkPasswordResetTokenUsed :exec
+const invalidatePasswordResetTokens = `-- name: InvalidatePasswordResetTokens :exec
 UPDATE password_reset_tokens
 SET used_at = CURRENT_TIMESTAMP
-WHERE token = $1
+WHERE user_id = $1 AND used_at IS NULL
 `
 
-func (q *Queries) MarkPasswordResetTokenUsed(ctx context.Context, token string) error {
-	_, err := q.db.Exec(ctx, markPasswordResetTokenUsed, token)
+func (q *Queries) InvalidatePasswordResetTokens(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, invalidatePasswordResetTokens, userID)
 	return err
 }
+
+const markPasswordResetTokenUsed = `-- name: MarkPasswordResetTokenUsed :execrows
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE token_hash = $1 AND used_at IS NULL
+`
+
+func (q *Queries) MarkPasswordResetTokenUsed(ctx context.Context, tokenHash string) (int64, error) {
+	result, err := q.db.Exec(ctx, markPasswordResetTokenUsed, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
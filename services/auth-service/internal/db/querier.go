@@ -10,23 +10,42 @@ import (
 
 type Querier interface {
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	CleanupExpiredSessions(ctx context.Context) error
 	CleanupExpiredTokens(ctx context.Context) error
+	CountRecentPasswordResetTokens(ctx context.Context, arg CountRecentPasswordResetTokensParams) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error)
 	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (CreatePasswordResetTokenRow, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (AuthSession, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
 	DeactivateUser(ctx context.Context, arg DeactivateUserParams) error
+	DeleteRecoveryCodes(ctx context.Context, userID int32) error
+	DisableMFA(ctx context.Context, id int32) error
+	EnableMFA(ctx context.Context, id int32) (int64, error)
 	GetPasswordResetToken(ctx context.Context, token string) (GetPasswordResetTokenRow, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (GetRefreshTokenRow, error)
 	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
 	GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, error)
 	GetUserForAuth(ctx context.Context, email string) (GetUserForAuthRow, error)
-	GetUserPasswordResetTokens(ctx context.Context, userID *int32) ([]GetUserPasswordResetTokensRow, error)
+	GetUserMFA(ctx context.Context, id int32) (GetUserMFARow, error)
+	GetUserPasswordResetTokens(ctx context.Context, userID int32) ([]GetUserPasswordResetTokensRow, error)
+	InvalidatePasswordResetTokens(ctx context.Context, userID int32) error
 	ListActiveUsers(ctx context.Context) ([]ListActiveUsersRow, error)
 	ListUsersByRole(ctx context.Context, role string) ([]ListUsersByRoleRow, error)
-	MarkPasswordResetTokenUsed(ctx context.Context, token string) error
+	MarkPasswordResetTokenUsed(ctx context.Context, tokenHash string) (int64, error)
+	MarkVerificationEmailSent(ctx context.Context, arg MarkVerificationEmailSentParams) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) (int64, error)
+	SetMFASecret(ctx context.Context, arg SetMFASecretParams) (int64, error)
 	SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) error
 	UpdateUserLastLogin(ctx context.Context, id int32) error
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
 	UpdateUserPermissions(ctx context.Context, arg UpdateUserPermissionsParams) error
 	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error
+	UseMFAStep(ctx context.Context, arg UseMFAStepParams) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseRefreshToken(ctx context.Context, tokenHash string) (int64, error)
 	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) error
 }
 
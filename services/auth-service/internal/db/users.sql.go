@@ -174,7 +174,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, er
 }
 
 const getUserForAuth = `-- name: GetUserForAuth :one
-SELECT id, password_hash, role, active, email_verified
+SELECT id, password_hash, role, active, email_verified, mfa_enabled
 FROM users 
 WHERE email = $1 AND active = true AND deleted_at IS NULL
 `
@@ -185,6 +185,7 @@ type GetUserForAuthRow struct {
 	Role          string `json:"role"`
 	Active        *bool  `json:"active"`
 	EmailVerified *bool  `json:"email_verified"`
+	MfaEnabled    bool   `json:"mfa_enabled"`
 }
 
 func (q *Queries) GetUserForAuth(ctx context.Context, email string) (GetUserForAuthRow, error) {
@@ -196,6 +197,7 @@ func (q *Queries) GetUserForAuth(ctx context.Context, email string) (GetUserForA
 		&i.Role,
 		&i.Active,
 		&i.EmailVerified,
+		&i.MfaEnabled,
 	)
 	return i, err
 }
@@ -300,6 +302,28 @@ func (q *Queries) ListUsersByRole(ctx context.Context, role string) ([]ListUsers
 	return items, nil
 }
 
+const markVerificationEmailSent = `-- name: MarkVerificationEmailSent :execrows
+UPDATE users
+SET verification_sent_at = $1
+WHERE id = $2
+  AND email_verified IS NOT TRUE
+  AND (verification_sent_at IS NULL OR verification_sent_at < $3)
+`
+
+type MarkVerificationEmailSentParams struct {
+	SentAt       sql.NullTime `json:"sent_at"`
+	ID           int32        `json:"id"`
+	ResendBefore sql.NullTime `json:"resend_before"`
+}
+
+func (q *Queries) MarkVerificationEmailSent(ctx context.Context, arg MarkVerificationEmailSentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markVerificationEmailSent, arg.SentAt, arg.ID, arg.ResendBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const softDeleteUser = `-- name: SoftDeleteUser :exec
 UPDATE users
 SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, updated_by = $2
@@ -0,0 +1,36 @@
+package errors
+
+import "fmt"
+
+// Error functions for each process - reusable with custom messages
+var (
+	// Authentication process errors
+	ErrAuth = func(msg string) error {
+		return fmt.Errorf("AUTHENTICATION ERROR: %s", msg)
+	}
+
+	// Session and refresh token errors
+	ErrSession = func(msg string) error {
+		return fmt.Errorf("SESSION ERROR: %s", msg)
+	}
+
+	// Tenant isolation process errors
+	ErrTenant = func(msg string) error {
+		return fmt.Errorf("TENANT ERROR: %s", msg)
+	}
+
+	// Database process errors
+	ErrDatabase = func(msg string) error {
+		return fmt.Errorf("DATABASE ERROR: %s", msg)
+	}
+
+	// Validation process errors
+	ErrValidation = func(msg string) error {
+		return fmt.Errorf("VALIDATION ERROR: %s", msg)
+	}
+
+	// Handler process errors
+	ErrHandler = func(msg string) error {
+		return fmt.Errorf("HANDLER ERROR: %s", msg)
+	}
+)
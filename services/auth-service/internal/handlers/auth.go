@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/internal/errors"
+	"crm-platform/auth-service/internal/models"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HANDLER STRUCT
+
+// Auth handler for login, token refresh, logout, password resets, email verification, MFA and lockouts
+type AuthHandler struct {
+	service *auth.Service
+	tokens  *auth.TokenIssuer
+}
+
+// Create new auth handler
+func NewAuthHandler(service *auth.Service, tokens *auth.TokenIssuer) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		tokens:  tokens,
+	}
+}
+
+// CORE HANDLERS
+
+// Log in with email and password in the resolved tenant
+// Users with MFA get an mfa_token to complete at /login/mfa instead of tokens.
+// Repeated failures answer 429 with Retry-After until the delay or lockout ends.
+func (h *AuthHandler) Login(c *gin.Context) {
+	// Parse and validate request JSON
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("email and password are required").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	client := auth.Client{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	tokens, challenge, err := h.service.Login(c.Request.Context(), tenantID, req.Email, req.Password, client)
+	if writeThrottled(c, err) {
+		return
+	}
+	if stderrors.Is(err, auth.ErrInvalidCredentials) {
+		c.JSON(401, gin.H{"error": errors.ErrAuth("invalid email or password").Error()})
+		return
+	}
+	if stderrors.Is(err, auth.ErrEmailNotVerified) {
+		c.JSON(403, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to log in").Error()})
+		return
+	}
+	if challenge != nil {
+		c.JSON(200, models.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           challenge.Token,
+			EnrollmentRequired: challenge.Enroll,
+			ExpiresIn:          int64(time.Until(challenge.ExpiresAt).Seconds()),
+		})
+		return
+	}
+
+	c.JSON(200, h.convertToResponse(tokens))
+}
+
+// Exchange a refresh token for a new token pair
+// A refresh token that was already used revokes its session.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("refresh_token is required").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), tenantID, req.RefreshToken)
+	if stderrors.Is(err, auth.ErrInvalidRefreshToken) || stderrors.Is(err, auth.ErrRefreshTokenReused) {
+		c.JSON(401, gin.H{"error": errors.ErrSession(err.Error()).Error()})
+		return
+	}
+	if stderrors.Is(err, auth.ErrEmailNotVerified) {
+		c.JSON(403, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to refresh session").Error()})
+		return
+	}
+
+	c.JSON(200, h.convertToResponse(tokens))
+}
+
+// Revoke the session of a refresh token
+// Always 204 for well-formed requests, so tokens cannot be probed.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("refresh_token is required").Error()})
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to revoke session").Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// Email a password reset link
+// Always 202 for well-formed requests: unknown emails and rate-limited users
+// look the same as a sent email, so accounts cannot be probed.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("a valid email is required").Error()})
+		return
+	}
+
+	err := h.service.RequestPasswordReset(c.Request.Context(), req.Email)
+	if err != nil && !stderrors.Is(err, auth.ErrResetRateLimited) {
+		c.JSON(500, gin.H{"error": errors.ErrHandler("failed to send password reset email").Error()})
+		return
+	}
+
+	c.JSON(202, gin.H{"message": "if the account exists, a password reset email has been sent"})
+}
+
+// Set a new password with a reset token; signs the user out everywhere
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("token and a password of 8 to 72 characters are required").Error()})
+		return
+	}
+
+	err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password)
+	if stderrors.Is(err, auth.ErrInvalidResetToken) {
+		c.JSON(400, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to reset password").Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// Mark the email address a verification token was sent to as verified
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("token is required").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	err := h.service.VerifyEmail(c.Request.Context(), tenantID, req.Token)
+	if stderrors.Is(err, auth.ErrInvalidVerificationToken) {
+		c.JSON(400, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to verify email").Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// Email a new verification link
+// Always 202 for well-formed requests: unknown, verified and throttled emails
+// look the same as a sent email, so accounts cannot be probed.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("a valid email is required").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	err := h.service.SendVerificationEmail(c.Request.Context(), tenantID, req.Email)
+	if err != nil && !stderrors.Is(err, auth.ErrVerificationRateLimited) {
+		c.JSON(500, gin.H{"error": errors.ErrHandler("failed to send verification email").Error()})
+		return
+	}
+
+	c.JSON(202, gin.H{"message": "if the account exists and is unverified, a verification email has been sent"})
+}
+
+// Publish the public keys other services verify access tokens with
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, h.tokens.JWKS())
+}
+
+// HELPER FUNCTIONS
+
+// Extract tenant ID set by TenantStatusMiddleware (writes 400 when missing)
+func extractTenantID(c *gin.Context) string {
+	tenantID, err := tenant.FromContext(c.Request.Context())
+	if err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrTenant("tenant context missing").Error()})
+		return ""
+	}
+	return tenantID
+}
+
+// Convert issued tokens to the response model
+func (h *AuthHandler) convertToResponse(tokens *auth.Tokens) models.TokenResponse {
+	return models.TokenResponse{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(h.tokens.TTL().Seconds()),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresIn: int64(time.Until(tokens.RefreshExpiresAt).Seconds()),
+	}
+}
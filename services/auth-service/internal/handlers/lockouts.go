@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"math"
+	"strconv"
+	"time"
+
+	"crm-platform/auth-service/internal/errors"
+	"crm-platform/auth-service/internal/lockout"
+	"crm-platform/auth-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LOCKOUT HANDLERS
+
+// List the accounts and client IPs of the tenant with recent failed logins (admins only)
+func (h *AuthHandler) ListLockouts(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	entries, err := h.service.Lockouts(c.Request.Context(), c.GetString("tenant_id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to load lockouts").Error()})
+		return
+	}
+
+	response := models.LockoutListResponse{Lockouts: make([]models.LockoutResponse, 0, len(entries))}
+	for _, entry := range entries {
+		response.Lockouts = append(response.Lockouts, convertLockout(entry))
+	}
+	c.JSON(200, response)
+}
+
+// Show the failed-login state of one account (email) or client IP (admins only)
+func (h *AuthHandler) GetLockout(c *gin.Context) {
+	kind, ok := lockoutKind(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.Lockout(c.Request.Context(), c.GetString("tenant_id"), kind, c.Param("subject"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to load lockout").Error()})
+		return
+	}
+
+	c.JSON(200, convertLockout(entry))
+}
+
+// Clear the failed logins of one account or client IP, lifting its lockout (admins only)
+func (h *AuthHandler) ClearLockout(c *gin.Context) {
+	kind, ok := lockoutKind(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ClearLockout(c.Request.Context(), c.GetString("tenant_id"), kind, c.Param("subject")); err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to clear lockout").Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// HELPER FUNCTIONS
+
+// Check the caller is an admin and the :kind parameter is account or ip (writes 403/400 when not)
+func lockoutKind(c *gin.Context) (string, bool) {
+	if !requireAdmin(c) {
+		return "", false
+	}
+	kind := c.Param("kind")
+	if !lockout.ValidKind(kind) {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("kind must be account or ip").Error()})
+		return "", false
+	}
+	return kind, true
+}
+
+// Answer 429 with Retry-After when err is a lockout; reports whether it was
+func writeThrottled(c *gin.Context, err error) bool {
+	var throttled *lockout.ThrottledError
+	if !stderrors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.FormatInt(retryAfterSeconds(throttled.RetryAfter), 10))
+	c.JSON(429, gin.H{"error": errors.ErrAuth(throttled.Error()).Error()})
+	return true
+}
+
+// Convert a lockout entry to its response
+func convertLockout(entry lockout.Entry) models.LockoutResponse {
+	response := models.LockoutResponse{
+		Kind:       entry.Kind,
+		Subject:    entry.Subject,
+		Failures:   entry.Failures,
+		Locked:     entry.Locked,
+		RetryAfter: retryAfterSeconds(entry.RetryAfter),
+	}
+	if !entry.LastFailure.IsZero() {
+		response.LastFailureAt = &entry.LastFailure
+	}
+	if entry.Locked {
+		response.LockedUntil = &entry.LockedUntil
+	}
+	return response
+}
+
+// Round a wait up to whole seconds, so clients never retry early
+func retryAfterSeconds(wait time.Duration) int64 {
+	return int64(math.Ceil(wait.Seconds()))
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/internal/errors"
+	"crm-platform/auth-service/internal/models"
+	"crm-platform/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFA HANDLERS
+
+// Complete a login that returned an MFA challenge
+// Logins that enrolled MFA on the way also return the new recovery codes.
+func (h *AuthHandler) CompleteMFALogin(c *gin.Context) {
+	var req models.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("mfa_token and code are required").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	client := auth.Client{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	tokens, recoveryCodes, err := h.service.CompleteMFALogin(c.Request.Context(), tenantID, req.MFAToken, req.Code, client)
+	if err != nil {
+		writeMFAError(c, err, "failed to log in")
+		return
+	}
+
+	response := h.convertToResponse(tokens)
+	response.RecoveryCodes = recoveryCodes
+	c.JSON(200, response)
+}
+
+// Start MFA enrollment with a new TOTP secret
+// The user comes from the access token, or from the mfa_token of a login whose role requires MFA.
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req models.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !stderrors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("invalid mfa_token").Error()})
+		return
+	}
+
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var userID int32
+	if req.MFAToken != "" {
+		id, err := h.service.EnrollmentChallengeUser(tenantID, req.MFAToken)
+		if err != nil {
+			writeMFAError(c, err, "failed to enroll")
+			return
+		}
+		userID = id
+	} else {
+		claims := h.authenticate(c, tenantID)
+		if claims == nil {
+			return
+		}
+		if userID = claimsUserID(c, claims); userID == 0 {
+			return
+		}
+	}
+
+	enrollment, err := h.service.EnrollMFA(c.Request.Context(), userID)
+	if err != nil {
+		writeMFAError(c, err, "failed to enroll")
+		return
+	}
+
+	c.JSON(200, models.MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// Enable MFA with a code from the enrolled secret; returns the recovery codes once
+func (h *AuthHandler) ConfirmMFA(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("code is required").Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+	if userID == 0 {
+		return
+	}
+
+	codes, err := h.service.ConfirmMFA(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err, "failed to enable mfa")
+		return
+	}
+
+	c.JSON(200, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Replace the recovery codes after checking a second factor
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("code is required").Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+	if userID == 0 {
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err, "failed to replace recovery codes")
+		return
+	}
+
+	c.JSON(200, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Turn MFA off after checking a second factor (403 when the role requires it)
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("code is required").Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+	if userID == 0 {
+		return
+	}
+
+	if err := h.service.DisableMFA(c.Request.Context(), c.GetString("tenant_id"), userID, req.Code); err != nil {
+		writeMFAError(c, err, "failed to disable mfa")
+		return
+	}
+
+	c.Status(204)
+}
+
+// Report whether the user has MFA, must have it, and how many recovery codes are left
+func (h *AuthHandler) MFAStatus(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		return
+	}
+
+	status, err := h.service.MFAStatus(c.Request.Context(), c.GetString("tenant_id"), userID)
+	if err != nil {
+		writeMFAError(c, err, "failed to load mfa status")
+		return
+	}
+
+	c.JSON(200, models.MFAStatusResponse{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+// List the roles that must use MFA in the tenant (admins only)
+func (h *AuthHandler) GetMFAPolicy(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	roles, err := h.service.MFARequiredRoles(c.Request.Context(), c.GetString("tenant_id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to load mfa policy").Error()})
+		return
+	}
+
+	c.JSON(200, models.MFAPolicyResponse{Roles: roles})
+}
+
+// Replace the roles that must use MFA in the tenant (admins only)
+// Users of those roles without MFA enroll at their next login.
+func (h *AuthHandler) SetMFAPolicy(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var req models.MFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": errors.ErrValidation("roles must list admin, manager, sales_rep or viewer").Error()})
+		return
+	}
+
+	if err := h.service.SetMFARequiredRoles(c.Request.Context(), c.GetString("tenant_id"), req.Roles); err != nil {
+		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to store mfa policy").Error()})
+		return
+	}
+
+	c.JSON(200, models.MFAPolicyResponse{Roles: req.Roles})
+}
+
+// MIDDLEWARE
+
+// Require an access token issued by this service for the resolved tenant
+// The claims are stored on the request context for the MFA handlers.
+func (h *AuthHandler) RequireAccessToken(c *gin.Context) {
+	tenantID := extractTenantID(c)
+	if tenantID == "" {
+		c.Abort()
+		return
+	}
+	claims := h.authenticate(c, tenantID)
+	if claims == nil {
+		c.Abort()
+		return
+	}
+
+	c.Set("tenant_id", tenantID)
+	c.Request = c.Request.WithContext(middleware.WithClaims(c.Request.Context(), claims))
+	c.Next()
+}
+
+// HELPER FUNCTIONS
+
+// Verify the bearer access token and check it belongs to tenantID (writes 401 when not)
+func (h *AuthHandler) authenticate(c *gin.Context, tenantID string) *middleware.Claims {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.JSON(401, gin.H{"error": errors.ErrAuth("access token required").Error()})
+		return nil
+	}
+	claims, err := h.tokens.Verify(token)
+	if err != nil || claims.TenantID != tenantID {
+		c.JSON(401, gin.H{"error": errors.ErrAuth("invalid access token").Error()})
+		return nil
+	}
+	return claims
+}
+
+// User ID of the access token RequireAccessToken verified (writes 401 when missing)
+func currentUserID(c *gin.Context) int32 {
+	claims, ok := middleware.ClaimsFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(401, gin.H{"error": errors.ErrAuth("access token required").Error()})
+		return 0
+	}
+	return claimsUserID(c, claims)
+}
+
+// Parse the numeric user ID of claims (writes 401 when malformed)
+func claimsUserID(c *gin.Context, claims *middleware.Claims) int32 {
+	userID, err := strconv.ParseInt(claims.UserID, 10, 32)
+	if err != nil || userID <= 0 {
+		c.JSON(401, gin.H{"error": errors.ErrAuth("invalid access token").Error()})
+		return 0
+	}
+	return int32(userID)
+}
+
+// Allow only tenant admins with a verified email (writes 403 otherwise)
+// Tokens issued under the limit verification policy keep their role, so the
+// unverified mark has to be checked here as well.
+func requireAdmin(c *gin.Context) bool {
+	claims, ok := middleware.ClaimsFromContext(c.Request.Context())
+	if !ok || claims.Role != middleware.RoleAdmin {
+		c.JSON(403, gin.H{"error": errors.ErrAuth("admin role required").Error()})
+		return false
+	}
+	if claims.Unverified() {
+		c.JSON(403, gin.H{"error": errors.ErrAuth(auth.ErrEmailNotVerified.Error()).Error()})
+		return false
+	}
+	return true
+}
+
+// Map MFA service errors to status codes; anything unexpected is a 500 with fallback
+func writeMFAError(c *gin.Context, err error, fallback string) {
+	if writeThrottled(c, err) {
+		return
+	}
+	switch {
+	case stderrors.Is(err, auth.ErrInvalidMFAChallenge), stderrors.Is(err, auth.ErrInvalidMFACode),
+		stderrors.Is(err, auth.ErrUserInactive):
+		c.JSON(401, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+	case stderrors.Is(err, auth.ErrEmailNotVerified), stderrors.Is(err, auth.ErrMFARequiredByPolicy):
+		c.JSON(403, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+	case stderrors.Is(err, auth.ErrMFAAlreadyEnabled), stderrors.Is(err, auth.ErrMFANotEnrolled):
+		c.JSON(409, gin.H{"error": errors.ErrAuth(err.Error()).Error()})
+	default:
+		c.JSON(500, gin.H{"error": errors.ErrDatabase(fallback).Error()})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Register auth endpoints
+// Expects the request tenant to be resolved earlier in the chain (no access token needed,
+// except for the /mfa management and /lockouts routes).
+func RegisterAuthRoutes(authGroup *gin.RouterGroup, h *AuthHandler) {
+	authGroup.POST("/login", h.Login)     // POST /api/v1/auth/login
+	authGroup.POST("/refresh", h.Refresh) // POST /api/v1/auth/refresh
+	authGroup.POST("/logout", h.Logout)   // POST /api/v1/auth/logout
+
+	authGroup.POST("/password/forgot", h.ForgotPassword) // POST /api/v1/auth/password/forgot
+	authGroup.POST("/password/reset", h.ResetPassword)   // POST /api/v1/auth/password/reset
+
+	authGroup.POST("/email/verify", h.VerifyEmail)               // POST /api/v1/auth/email/verify
+	authGroup.POST("/email/verify/resend", h.ResendVerification) // POST /api/v1/auth/email/verify/resend
+
+	authGroup.POST("/login/mfa", h.CompleteMFALogin) // POST /api/v1/auth/login/mfa
+	authGroup.POST("/mfa/enroll", h.EnrollMFA)       // POST /api/v1/auth/mfa/enroll (access token or mfa_token)
+
+	// MFA management needs an access token
+	mfaGroup := authGroup.Group("/mfa", h.RequireAccessToken)
+	mfaGroup.GET("", h.MFAStatus)                               // GET /api/v1/auth/mfa
+	mfaGroup.POST("/confirm", h.ConfirmMFA)                     // POST /api/v1/auth/mfa/confirm
+	mfaGroup.POST("/recovery-codes", h.RegenerateRecoveryCodes) // POST /api/v1/auth/mfa/recovery-codes
+	mfaGroup.POST("/disable", h.DisableMFA)                     // POST /api/v1/auth/mfa/disable
+	mfaGroup.GET("/policy", h.GetMFAPolicy)                     // GET /api/v1/auth/mfa/policy (admins)
+	mfaGroup.PUT("/policy", h.SetMFAPolicy)                     // PUT /api/v1/auth/mfa/policy (admins)
+
+	// Failed-login lockouts, for tenant admins
+	lockoutGroup := authGroup.Group("/lockouts", h.RequireAccessToken)
+	lockoutGroup.GET("", h.ListLockouts)                   // GET /api/v1/auth/lockouts
+	lockoutGroup.GET("/:kind/:subject", h.GetLockout)      // GET /api/v1/auth/lockouts/{account|ip}/{subject}
+	lockoutGroup.DELETE("/:kind/:subject", h.ClearLockout) // DELETE /api/v1/auth/lockouts/{account|ip}/{subject}
+}
@@ -0,0 +1,364 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of subject failed attempts are counted for, each within one tenant
+const (
+	KindAccount = "account" // the email a login was attempted for, known or not
+	KindIP      = "ip"      // the client address the attempt came from
+)
+
+// Policy defaults
+const (
+	DefaultAccountDelayAfter = 3
+	DefaultAccountLockAfter  = 10
+	DefaultIPDelayAfter      = 20
+	DefaultIPLockAfter       = 100
+	DefaultBaseDelay         = time.Second
+	DefaultMaxDelay          = 30 * time.Second
+	DefaultLockoutDuration   = 15 * time.Minute
+	DefaultWindow            = 15 * time.Minute
+)
+
+// keyPrefix namespaces lockout records in shared stores such as Redis
+const keyPrefix = "auth:lockout:"
+
+// ErrThrottled matches every *ThrottledError
+var ErrThrottled = errors.New("too many failed attempts")
+
+// ThrottledError refuses an attempt until RetryAfter has passed
+type ThrottledError struct {
+	Kind       string // KindAccount or KindIP
+	Locked     bool   // locked out, rather than waiting out a progressive delay
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, %s temporarily locked", e.Kind)
+	}
+	return "too many failed attempts, try again later"
+}
+
+// Is makes errors.Is(err, ErrThrottled) true
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// Limits are the failure thresholds for one kind of subject
+type Limits struct {
+	DelayAfter int // failures allowed before each further attempt must wait
+	LockAfter  int // failures that lock the subject for LockoutDuration
+}
+
+// Policy controls progressive delays and lockouts
+// Past DelayAfter failures each attempt waits BaseDelay, doubling per failure up to
+// MaxDelay. Failures are forgotten Window after the last one, or once a lockout ends.
+type Policy struct {
+	Account         Limits        // DefaultAccountDelayAfter / DefaultAccountLockAfter for zero fields
+	IP              Limits        // DefaultIPDelayAfter / DefaultIPLockAfter for zero fields
+	BaseDelay       time.Duration // DefaultBaseDelay if <= 0
+	MaxDelay        time.Duration // DefaultMaxDelay if <= 0
+	LockoutDuration time.Duration // DefaultLockoutDuration if <= 0
+	Window          time.Duration // DefaultWindow if <= 0
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Account.DelayAfter <= 0 {
+		p.Account.DelayAfter = DefaultAccountDelayAfter
+	}
+	if p.Account.LockAfter <= 0 {
+		p.Account.LockAfter = DefaultAccountLockAfter
+	}
+	if p.IP.DelayAfter <= 0 {
+		p.IP.DelayAfter = DefaultIPDelayAfter
+	}
+	if p.IP.LockAfter <= 0 {
+		p.IP.LockAfter = DefaultIPLockAfter
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+	if p.LockoutDuration <= 0 {
+		p.LockoutDuration = DefaultLockoutDuration
+	}
+	if p.Window <= 0 {
+		p.Window = DefaultWindow
+	}
+	return p
+}
+
+// limits returns the thresholds for kind
+func (p Policy) limits(kind string) Limits {
+	if kind == KindIP {
+		return p.IP
+	}
+	return p.Account
+}
+
+// Record is the failure history of one subject
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time // zero unless the subject was locked
+}
+
+// Attempt is one attempt for Store.Attempt to admit and count atomically
+// A record not locked at At starts over if its last failure is older than ResetBefore
+// or its lockout ended. The attempt is then refused while the record is locked or
+// waiting out the delay its failures earn; otherwise it is counted as a failure.
+type Attempt struct {
+	At          time.Time
+	ResetBefore time.Time
+	DelayAfter  int           // failures allowed before each further attempt must wait
+	BaseDelay   time.Duration // first wait, doubling per further failure
+	MaxDelay    time.Duration // longest wait
+	LockAfter   int           // failures that set LockedUntil
+	LockedUntil time.Time     // lockout end to set once LockAfter is reached
+	TTL         time.Duration // how long the store keeps the record after this attempt
+}
+
+// delay is the wait record's failures earn before the next attempt
+func (a Attempt) delay(failures int) time.Duration {
+	return progressiveDelay(failures, a.DelayAfter, a.BaseDelay, a.MaxDelay)
+}
+
+// Store keeps failure records by key
+// Implementations must apply Attempt and Forgive atomically, so concurrent attempts
+// on several replicas are all counted and none slips past a limit.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error) // zero Record if none
+	// Attempt counts an admitted attempt as a failure; refused ones leave the record as it was
+	Attempt(ctx context.Context, key string, attempt Attempt) (record Record, admitted bool, err error)
+	// Forgive takes back one counted failure, lifting a lockout once fewer than lockAfter remain
+	Forgive(ctx context.Context, key string, lockAfter int) error
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) (map[string]Record, error)
+}
+
+// Entry is the state of one tracked subject
+type Entry struct {
+	Kind    string
+	Subject string
+	Record
+	Locked     bool
+	RetryAfter time.Duration // zero when the next attempt is allowed now
+}
+
+// Guard counts failed attempts per account and per client IP
+// and refuses attempts that come too soon after them.
+type Guard struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+// NewGuard creates a guard over store; now is time.Now if nil
+func NewGuard(store Store, policy Policy, now func() time.Time) *Guard {
+	if now == nil {
+		now = time.Now
+	}
+	return &Guard{store: store, policy: policy.withDefaults(), now: now}
+}
+
+// Begin admits an attempt for the account and the IP, counting it as failed in advance
+// Counting before the credentials are checked means concurrent attempts cannot all pass
+// a limit none of them has reached yet. Returns a *ThrottledError (the longer wait when
+// both are throttled) and counts nothing if either must wait or is locked. Settle every
+// admitted attempt with Succeed or Forgive unless it failed.
+func (g *Guard) Begin(ctx context.Context, tenantID, account, ip string) error {
+	now := g.now()
+	var admitted []subject
+	var throttled *ThrottledError
+	for _, subject := range g.subjects(account, ip) {
+		limits := g.policy.limits(subject.kind)
+		record, ok, err := g.store.Attempt(ctx, key(tenantID, subject.kind, subject.value), Attempt{
+			At:          now,
+			ResetBefore: now.Add(-g.policy.Window),
+			DelayAfter:  limits.DelayAfter,
+			BaseDelay:   g.policy.BaseDelay,
+			MaxDelay:    g.policy.MaxDelay,
+			LockAfter:   limits.LockAfter,
+			LockedUntil: now.Add(g.policy.LockoutDuration),
+			TTL:         max(g.policy.Window, g.policy.LockoutDuration),
+		})
+		if err != nil {
+			g.forgive(ctx, tenantID, admitted)
+			return fmt.Errorf("failed to record attempt: %w", err)
+		}
+		if ok {
+			admitted = append(admitted, subject)
+			continue
+		}
+		entry := g.entry(subject.kind, subject.value, record)
+		if throttled == nil || entry.RetryAfter > throttled.RetryAfter {
+			throttled = &ThrottledError{Kind: entry.Kind, Locked: entry.Locked, RetryAfter: entry.RetryAfter}
+		}
+	}
+	if throttled != nil {
+		g.forgive(ctx, tenantID, admitted)
+		return throttled
+	}
+	return nil
+}
+
+// Succeed settles an attempt that signed in: the account's failures are forgotten and the
+// IP's advance count is taken back. The IP keeps its earlier failures, so one valid account
+// cannot reset an attack from that address.
+func (g *Guard) Succeed(ctx context.Context, tenantID, account, ip string) error {
+	if err := g.Clear(ctx, tenantID, KindAccount, account); err != nil {
+		return err
+	}
+	return g.forgiveSubjects(ctx, tenantID, g.subjects("", ip))
+}
+
+// Forgive settles an attempt that ended for another reason than wrong credentials,
+// taking back its advance count for the account and the IP
+func (g *Guard) Forgive(ctx context.Context, tenantID, account, ip string) error {
+	return g.forgiveSubjects(ctx, tenantID, g.subjects(account, ip))
+}
+
+func (g *Guard) forgiveSubjects(ctx context.Context, tenantID string, subjects []subject) error {
+	for _, subject := range subjects {
+		err := g.store.Forgive(ctx, key(tenantID, subject.kind, subject.value), g.policy.limits(subject.kind).LockAfter)
+		if err != nil {
+			return fmt.Errorf("failed to settle attempt: %w", err)
+		}
+	}
+	return nil
+}
+
+// forgive takes back the counts of a refused attempt; the refusal is what the caller reports
+func (g *Guard) forgive(ctx context.Context, tenantID string, subjects []subject) {
+	if err := g.forgiveSubjects(ctx, tenantID, subjects); err != nil {
+		log.Printf("Failed to take back refused login attempt: %v", err)
+	}
+}
+
+// Status returns the state of one subject; untracked subjects have no failures
+func (g *Guard) Status(ctx context.Context, tenantID, kind, subject string) (Entry, error) {
+	subject = normalize(kind, subject)
+	record, err := g.store.Get(ctx, key(tenantID, kind, subject))
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to load failed attempts: %w", err)
+	}
+	return g.entry(kind, subject, record), nil
+}
+
+// List returns every subject of the tenant with recent failures, locked ones first
+func (g *Guard) List(ctx context.Context, tenantID string) ([]Entry, error) {
+	prefix := keyPrefix + tenantID + ":"
+	records, err := g.store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed attempts: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(records))
+	for k, record := range records {
+		kind, subject, ok := strings.Cut(strings.TrimPrefix(k, prefix), ":")
+		if !ok {
+			continue
+		}
+		if entry := g.entry(kind, subject, record); entry.Failures > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Locked != entries[j].Locked {
+			return entries[i].Locked
+		}
+		return entries[i].LastFailure.After(entries[j].LastFailure)
+	})
+	return entries, nil
+}
+
+// Clear forgets a subject's failures, lifting any lockout or delay
+func (g *Guard) Clear(ctx context.Context, tenantID, kind, subject string) error {
+	if err := g.store.Delete(ctx, key(tenantID, kind, normalize(kind, subject))); err != nil {
+		return fmt.Errorf("failed to clear failed attempts: %w", err)
+	}
+	return nil
+}
+
+// entry applies the policy to a stored record
+func (g *Guard) entry(kind, subject string, record Record) Entry {
+	entry := Entry{Kind: kind, Subject: subject}
+	now := g.now()
+	if now.Before(record.LockedUntil) {
+		entry.Record = record
+		entry.Locked = true
+		entry.RetryAfter = record.LockedUntil.Sub(now)
+		return entry
+	}
+	if record.Failures == 0 || !now.Before(record.LastFailure.Add(g.policy.Window)) || !record.LockedUntil.IsZero() {
+		return entry // forgotten: the window passed or the lockout ended
+	}
+
+	entry.Record = record
+	if next := record.LastFailure.Add(g.delay(kind, record.Failures)); now.Before(next) {
+		entry.RetryAfter = next.Sub(now)
+	}
+	return entry
+}
+
+// delay is the wait the subject's failures earn before the next attempt
+func (g *Guard) delay(kind string, failures int) time.Duration {
+	return progressiveDelay(failures, g.policy.limits(kind).DelayAfter, g.policy.BaseDelay, g.policy.MaxDelay)
+}
+
+// progressiveDelay is the wait after failures failures: none up to delayAfter, then base doubling up to maxDelay
+func progressiveDelay(failures, delayAfter int, base, maxDelay time.Duration) time.Duration {
+	over := failures - delayAfter
+	if over <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < over && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+type subject struct{ kind, value string }
+
+// subjects lists the account and IP an attempt is counted against, skipping empty ones
+func (g *Guard) subjects(account, ip string) []subject {
+	var subjects []subject
+	if account = normalize(KindAccount, account); account != "" {
+		subjects = append(subjects, subject{KindAccount, account})
+	}
+	if ip = normalize(KindIP, ip); ip != "" {
+		subjects = append(subjects, subject{KindIP, ip})
+	}
+	return subjects
+}
+
+// ValidKind reports whether kind is KindAccount or KindIP
+func ValidKind(kind string) bool {
+	return kind == KindAccount || kind == KindIP
+}
+
+// normalize makes emails that differ only in case and spacing count as one account
+func normalize(kind, subject string) string {
+	subject = strings.TrimSpace(subject)
+	if kind == KindAccount {
+		return strings.ToLower(subject)
+	}
+	return subject
+}
+
+// key is the store key of a subject: auth:lockout:<tenant>:<kind>:<subject>
+func key(tenantID, kind, subject string) string {
+	return keyPrefix + tenantID + ":" + kind + ":" + subject
+}
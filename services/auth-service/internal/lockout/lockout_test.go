@@ -0,0 +1,162 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTenant = "01HZXTENANT0000000000000000"
+
+// newTestGuard creates a guard over a memory store with a clock the test moves
+func newTestGuard(policy Policy) (*Guard, *time.Time) {
+	now := time.Now()
+	return NewGuard(NewMemoryStore(), policy, func() time.Time { return now }), &now
+}
+
+// failures returns the counted failures of one subject
+func failures(t *testing.T, g *Guard, kind, subject string) int {
+	t.Helper()
+	entry, err := g.Status(context.Background(), testTenant, kind, subject)
+	require.NoError(t, err)
+	return entry.Failures
+}
+
+func TestGuard_BeginCountsInAdvance(t *testing.T) {
+	g, _ := newTestGuard(Policy{})
+	require.NoError(t, g.Begin(context.Background(), testTenant, "Rep@Example.com ", "192.0.2.1"))
+
+	assert.Equal(t, 1, failures(t, g, KindAccount, "rep@example.com"), "emails are normalized")
+	assert.Equal(t, 1, failures(t, g, KindIP, "192.0.2.1"))
+}
+
+func TestGuard_ProgressiveDelay(t *testing.T) {
+	g, now := newTestGuard(Policy{Account: Limits{DelayAfter: 2, LockAfter: 10}})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", ""))
+	}
+
+	err := g.Begin(ctx, testTenant, "rep@example.com", "")
+	var throttled *ThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.ErrorIs(t, err, ErrThrottled)
+	assert.False(t, throttled.Locked)
+	assert.Equal(t, DefaultBaseDelay, throttled.RetryAfter)
+	assert.Equal(t, 3, failures(t, g, KindAccount, "rep@example.com"), "refused attempts are not counted")
+
+	*now = now.Add(DefaultBaseDelay)
+	require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", ""))
+	err = g.Begin(ctx, testTenant, "rep@example.com", "")
+	require.ErrorAs(t, err, &throttled)
+	assert.Equal(t, 2*DefaultBaseDelay, throttled.RetryAfter, "the wait doubles")
+}
+
+func TestGuard_Lockout(t *testing.T) {
+	g, now := newTestGuard(Policy{Account: Limits{DelayAfter: 10, LockAfter: 3}})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	}
+
+	err := g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.2")
+	var throttled *ThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.True(t, throttled.Locked)
+	assert.Equal(t, KindAccount, throttled.Kind)
+	assert.Equal(t, DefaultLockoutDuration, throttled.RetryAfter)
+	assert.Zero(t, failures(t, g, KindIP, "192.0.2.2"), "a refused attempt takes back the IP it was counted for")
+
+	*now = now.Add(DefaultLockoutDuration)
+	assert.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	assert.Equal(t, 1, failures(t, g, KindAccount, "rep@example.com"), "the count starts over after the lockout")
+}
+
+func TestGuard_ConcurrentAttemptsCannotOutrunTheLimit(t *testing.T) {
+	g, _ := newTestGuard(Policy{Account: Limits{DelayAfter: 10, LockAfter: 10}})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if g.Begin(context.Background(), testTenant, "rep@example.com", "") == nil {
+				mu.Lock()
+				admitted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, admitted)
+}
+
+func TestGuard_Succeed(t *testing.T) {
+	g, _ := newTestGuard(Policy{})
+	ctx := context.Background()
+
+	require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	require.NoError(t, g.Begin(ctx, testTenant, "other@example.com", "192.0.2.1"))
+	require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	require.NoError(t, g.Succeed(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+
+	assert.Zero(t, failures(t, g, KindAccount, "rep@example.com"))
+	assert.Equal(t, 2, failures(t, g, KindIP, "192.0.2.1"), "the IP keeps earlier failures, only the successful attempt is taken back")
+}
+
+func TestGuard_Forgive(t *testing.T) {
+	g, _ := newTestGuard(Policy{})
+	ctx := context.Background()
+
+	require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	require.NoError(t, g.Begin(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+	require.NoError(t, g.Forgive(ctx, testTenant, "rep@example.com", "192.0.2.1"))
+
+	assert.Equal(t, 1, failures(t, g, KindAccount, "rep@example.com"))
+	assert.Equal(t, 1, failures(t, g, KindIP, "192.0.2.1"))
+}
+
+func TestGuard_ListAndClear(t *testing.T) {
+	g, _ := newTestGuard(Policy{Account: Limits{DelayAfter: 10, LockAfter: 2}})
+	ctx := context.Background()
+
+	require.NoError(t, g.Begin(ctx, testTenant, "locked@example.com", ""))
+	require.NoError(t, g.Begin(ctx, testTenant, "locked@example.com", ""))
+	require.NoError(t, g.Begin(ctx, testTenant, "other@example.com", "192.0.2.1"))
+	require.NoError(t, g.Begin(ctx, "01HZXOTHERTENANT00000000000", "elsewhere@example.com", ""))
+
+	entries, err := g.List(ctx, testTenant)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "locked@example.com", entries[0].Subject, "locked subjects first")
+	assert.True(t, entries[0].Locked)
+
+	require.NoError(t, g.Clear(ctx, testTenant, KindAccount, "Locked@Example.com"))
+	assert.NoError(t, g.Begin(ctx, testTenant, "locked@example.com", ""))
+}
+
+func TestProgressiveDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 7, want: 8 * time.Second},
+		{failures: 50, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, progressiveDelay(tt.failures, 3, time.Second, 10*time.Second), "%d failures", tt.failures)
+	}
+}
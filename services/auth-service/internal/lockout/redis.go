@@ -0,0 +1,392 @@
+package lockout
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// REDIS STORE
+
+// DefaultRedisTimeout bounds each Redis command when the context has no deadline
+const DefaultRedisTimeout = 2 * time.Second
+
+// redisIdleConns is how many connections RedisStore keeps open between commands
+const redisIdleConns = 8
+
+// attemptScript applies an Attempt to the hash at KEYS[1] in one step
+// ARGV: at, reset before (Unix ms), delay after, base delay, max delay (ms), lock after,
+// locked until (Unix ms), TTL (ms). Returns admitted (0/1), failures, last_failure, locked_until.
+const attemptScript = `
+local at = tonumber(ARGV[1])
+local failures = tonumber(redis.call('HGET', KEYS[1], 'failures') or '0')
+local last = tonumber(redis.call('HGET', KEYS[1], 'last_failure') or '0')
+local locked = tonumber(redis.call('HGET', KEYS[1], 'locked_until') or '0')
+if at < locked then
+  return {0, failures, last, locked}
+end
+if last < tonumber(ARGV[2]) or locked > 0 then
+  redis.call('DEL', KEYS[1])
+  failures, last, locked = 0, 0, 0
+end
+local over = failures - tonumber(ARGV[3])
+if over > 0 then
+  local delay, max_delay = tonumber(ARGV[4]), tonumber(ARGV[5])
+  for i = 2, over do
+    if delay >= max_delay then break end
+    delay = delay * 2
+  end
+  if at < last + math.min(delay, max_delay) then
+    return {0, failures, last, locked}
+  end
+end
+failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+redis.call('HSET', KEYS[1], 'last_failure', ARGV[1])
+if failures >= tonumber(ARGV[6]) then
+  redis.call('HSET', KEYS[1], 'locked_until', ARGV[7])
+  locked = tonumber(ARGV[7])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[8])
+return {1, failures, at, locked}
+`
+
+// forgiveScript takes back one failure of the hash at KEYS[1]
+// ARGV: lock after. The lockout is lifted once fewer failures remain.
+const forgiveScript = `
+local failures = tonumber(redis.call('HGET', KEYS[1], 'failures') or '0')
+if failures <= 0 then
+  return 0
+end
+failures = redis.call('HINCRBY', KEYS[1], 'failures', -1)
+if failures < tonumber(ARGV[1]) then
+  redis.call('HDEL', KEYS[1], 'locked_until')
+end
+return failures
+`
+
+// RedisStore keeps records as Redis hashes so every replica sees the same counts
+// Records expire with a Redis TTL. It speaks just enough RESP for these commands.
+type RedisStore struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      bool
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+// redisError is an error reply from the server; the connection stays usable
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewRedisStore creates a store for redis://[user:password@]host[:port][/db] (rediss:// for TLS)
+// Connections are opened on first use.
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	u, err := url.Parse(redisURL)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "redis" && u.Scheme != "rediss") {
+		return nil, fmt.Errorf("invalid Redis URL %q", redisURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "6379"
+	}
+
+	store := &RedisStore{
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		tls:     u.Scheme == "rediss",
+		timeout: DefaultRedisTimeout,
+		idle:    make(chan *redisConn, redisIdleConns),
+	}
+	if u.User != nil {
+		store.username = u.User.Username()
+		store.password, _ = u.User.Password()
+	}
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		if store.db, err = strconv.Atoi(path); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", path)
+		}
+	}
+	return store, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Record, error) {
+	reply, err := s.do(ctx, "HMGET", key, "failures", "last_failure", "locked_until")
+	if err != nil {
+		return Record{}, err
+	}
+	return parseRecord(reply)
+}
+
+func (s *RedisStore) Attempt(ctx context.Context, key string, attempt Attempt) (Record, bool, error) {
+	reply, err := s.do(ctx, "EVAL", attemptScript, "1", key,
+		strconv.FormatInt(attempt.At.UnixMilli(), 10),
+		strconv.FormatInt(attempt.ResetBefore.UnixMilli(), 10),
+		strconv.Itoa(attempt.DelayAfter),
+		strconv.FormatInt(attempt.BaseDelay.Milliseconds(), 10),
+		strconv.FormatInt(attempt.MaxDelay.Milliseconds(), 10),
+		strconv.Itoa(attempt.LockAfter),
+		strconv.FormatInt(attempt.LockedUntil.UnixMilli(), 10),
+		strconv.FormatInt(attempt.TTL.Milliseconds(), 10),
+	)
+	if err != nil {
+		return Record{}, false, err
+	}
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields) != 4 {
+		return Record{}, false, fmt.Errorf("redis: unexpected attempt reply")
+	}
+	record, err := parseRecord(fields[1:])
+	if err != nil {
+		return Record{}, false, err
+	}
+	admitted, _ := fields[0].(int64)
+	return record, admitted == 1, nil
+}
+
+func (s *RedisStore) Forgive(ctx context.Context, key string, lockAfter int) error {
+	_, err := s.do(ctx, "EVAL", forgiveScript, "1", key, strconv.Itoa(lockAfter))
+	return err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", key)
+	return err
+}
+
+func (s *RedisStore) List(ctx context.Context, prefix string) (map[string]Record, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := s.do(ctx, "SCAN", cursor, "MATCH", escapeGlob(prefix)+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply")
+		}
+		batch, _ := page[1].([]interface{})
+		for _, k := range batch {
+			if k, ok := k.(string); ok {
+				keys = append(keys, k)
+			}
+		}
+		if cursor, _ = page[0].(string); cursor == "0" || cursor == "" {
+			break
+		}
+	}
+
+	records := make(map[string]Record, len(keys))
+	for _, key := range keys {
+		record, err := s.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if record.Failures > 0 { // expired since the scan
+			records[key] = record
+		}
+	}
+	return records, nil
+}
+
+// do sends one command and returns its reply
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.command(ctx, s.timeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.Close() // broken mid-command
+		return nil, err
+	}
+	s.release(conn)
+	return reply, err
+}
+
+// conn takes an idle connection or dials a new one
+func (s *RedisStore) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-s.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: s.timeout}
+	var raw net.Conn
+	var err error
+	if s.tls {
+		host, _, _ := net.SplitHostPort(s.addr)
+		raw, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	conn := &redisConn{Conn: raw, r: bufio.NewReader(raw)}
+	if s.password != "" {
+		args := []string{"AUTH", s.password}
+		if s.username != "" {
+			args = []string{"AUTH", s.username, s.password}
+		}
+		if _, err := conn.command(ctx, s.timeout, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := conn.command(ctx, s.timeout, "SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// release returns a healthy connection to the idle pool, closing it when the pool is full
+func (s *RedisStore) release(conn *redisConn) {
+	select {
+	case s.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// command writes args as a RESP array and reads the reply
+func (c *redisConn) command(ctx context.Context, timeout time.Duration, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	c.SetDeadline(deadline)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c, b.String()); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return c.reply()
+}
+
+// reply reads one RESP reply: strings, int64s, nil or []interface{}
+func (c *redisConn) reply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply %q", line)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk reply %q", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array reply %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.reply(); err != nil {
+				var replyErr redisError
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = nil
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// parseRecord reads the failures, last_failure and locked_until fields of an HMGET reply
+func parseRecord(reply interface{}) (Record, error) {
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields) != 3 {
+		return Record{}, fmt.Errorf("redis: unexpected record reply")
+	}
+	var values [3]int64
+	for i, field := range fields {
+		switch v := field.(type) {
+		case nil:
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return Record{}, fmt.Errorf("redis: invalid record field %q", v)
+			}
+			values[i] = n
+		case int64:
+			values[i] = v
+		}
+	}
+
+	record := Record{Failures: int(values[0])}
+	if values[1] > 0 {
+		record.LastFailure = time.UnixMilli(values[1])
+	}
+	if values[2] > 0 {
+		record.LockedUntil = time.UnixMilli(values[2])
+	}
+	return record, nil
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as patterns
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
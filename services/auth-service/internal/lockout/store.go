@@ -0,0 +1,198 @@
+package lockout
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MEMORY STORE
+
+// MemoryStore keeps records in process
+// Each replica counts on its own, so limits are per replica; use RedisStore when running several.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+	expires time.Time // wall clock, like a Redis TTL
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*memoryRecord)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.live(key); ok {
+		return record.Record, nil
+	}
+	return Record{}, nil
+}
+
+func (s *MemoryStore) Attempt(ctx context.Context, key string, attempt Attempt) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+
+	record, ok := s.live(key)
+	if ok && attempt.At.Before(record.LockedUntil) {
+		return record.Record, false, nil
+	}
+	if !ok || startsOver(record.Record, attempt) {
+		record = &memoryRecord{}
+		s.records[key] = record
+	}
+	if record.Failures > 0 && attempt.At.Before(record.LastFailure.Add(attempt.delay(record.Failures))) {
+		return record.Record, false, nil
+	}
+
+	record.Failures++
+	record.LastFailure = attempt.At
+	if record.Failures >= attempt.LockAfter {
+		record.LockedUntil = attempt.LockedUntil
+	}
+	record.expires = time.Now().Add(attempt.TTL)
+	return record.Record, true, nil
+}
+
+func (s *MemoryStore) Forgive(ctx context.Context, key string, lockAfter int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.live(key)
+	if !ok || record.Failures == 0 {
+		return nil
+	}
+	record.Failures--
+	if record.Failures < lockAfter {
+		record.LockedUntil = time.Time{}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, prefix string) (map[string]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+
+	records := make(map[string]Record)
+	for key, record := range s.records {
+		if strings.HasPrefix(key, prefix) {
+			records[key] = record.Record
+		}
+	}
+	return records, nil
+}
+
+// live returns the record for key unless it expired; callers hold mu
+func (s *MemoryStore) live(key string) (*memoryRecord, bool) {
+	record, ok := s.records[key]
+	if !ok || !time.Now().Before(record.expires) {
+		return nil, false
+	}
+	return record, true
+}
+
+// prune drops expired records; callers hold mu
+func (s *MemoryStore) prune() {
+	now := time.Now()
+	for key, record := range s.records {
+		if !now.Before(record.expires) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// startsOver reports whether attempt begins a new record rather than adding to record
+func startsOver(record Record, attempt Attempt) bool {
+	if attempt.At.Before(record.LockedUntil) {
+		return false // still locked
+	}
+	return record.LastFailure.Before(attempt.ResetBefore) || !record.LockedUntil.IsZero()
+}
+
+// FALLBACK STORE
+
+// FallbackStore uses Primary and switches to Fallback while Primary fails
+// Logins keep working through a Redis outage; counts kept meanwhile are per replica
+// and are not merged back.
+type FallbackStore struct {
+	Primary  Store
+	Fallback Store
+	degraded atomic.Bool
+}
+
+// NewFallbackStore falls back from primary to an in-memory store
+func NewFallbackStore(primary Store) *FallbackStore {
+	return &FallbackStore{Primary: primary, Fallback: NewMemoryStore()}
+}
+
+func (s *FallbackStore) Get(ctx context.Context, key string) (Record, error) {
+	record, err := s.Primary.Get(ctx, key)
+	if s.failed(err) {
+		return s.Fallback.Get(ctx, key)
+	}
+	return record, nil
+}
+
+func (s *FallbackStore) Attempt(ctx context.Context, key string, attempt Attempt) (Record, bool, error) {
+	record, admitted, err := s.Primary.Attempt(ctx, key, attempt)
+	if s.failed(err) {
+		return s.Fallback.Attempt(ctx, key, attempt)
+	}
+	return record, admitted, nil
+}
+
+func (s *FallbackStore) Forgive(ctx context.Context, key string, lockAfter int) error {
+	err := s.Primary.Forgive(ctx, key, lockAfter)
+	if s.failed(err) {
+		return s.Fallback.Forgive(ctx, key, lockAfter)
+	}
+	return nil
+}
+
+func (s *FallbackStore) Delete(ctx context.Context, key string) error {
+	err := s.Primary.Delete(ctx, key)
+	if s.failed(err) {
+		return s.Fallback.Delete(ctx, key)
+	}
+	// Also clear what was counted during an outage
+	return s.Fallback.Delete(ctx, key)
+}
+
+func (s *FallbackStore) List(ctx context.Context, prefix string) (map[string]Record, error) {
+	records, err := s.Primary.List(ctx, prefix)
+	if s.failed(err) {
+		return s.Fallback.List(ctx, prefix)
+	}
+	return records, nil
+}
+
+// failed logs when the primary store starts and stops failing
+func (s *FallbackStore) failed(err error) bool {
+	if err != nil {
+		if !s.degraded.Swap(true) {
+			log.Printf("Lockout store unavailable, counting failed logins in memory: %v", err)
+		}
+		return true
+	}
+	if s.degraded.Swap(false) {
+		log.Println("Lockout store available again")
+	}
+	return false
+}
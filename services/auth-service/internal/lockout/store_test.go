@@ -0,0 +1,256 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAttempt is an attempt at at under a policy without delays that locks after lockAfter
+func testAttempt(at time.Time, lockAfter int) Attempt {
+	return Attempt{
+		At:          at,
+		ResetBefore: at.Add(-DefaultWindow),
+		DelayAfter:  lockAfter,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Second,
+		LockAfter:   lockAfter,
+		LockedUntil: at.Add(DefaultLockoutDuration),
+		TTL:         time.Hour,
+	}
+}
+
+func TestMemoryStore_AttemptCountsAndLocks(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		record, admitted, err := store.Attempt(ctx, "k", testAttempt(now, 3))
+		require.NoError(t, err)
+		assert.True(t, admitted)
+		assert.Equal(t, i, record.Failures)
+	}
+
+	record, admitted, err := store.Attempt(ctx, "k", testAttempt(now, 3))
+	require.NoError(t, err)
+	assert.False(t, admitted, "locked")
+	assert.Equal(t, 3, record.Failures, "refused attempts are not counted")
+	assert.Equal(t, now.Add(DefaultLockoutDuration), record.LockedUntil)
+
+	// Once the lockout ends the record starts over
+	record, admitted, err = store.Attempt(ctx, "k", testAttempt(now.Add(DefaultLockoutDuration), 3))
+	require.NoError(t, err)
+	assert.True(t, admitted)
+	assert.Equal(t, 1, record.Failures)
+	assert.True(t, record.LockedUntil.IsZero())
+}
+
+func TestMemoryStore_AttemptDelay(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+	attempt := testAttempt(now, 10)
+	attempt.DelayAfter = 1
+
+	_, admitted, err := store.Attempt(ctx, "k", attempt)
+	require.NoError(t, err)
+	assert.True(t, admitted)
+	_, admitted, err = store.Attempt(ctx, "k", attempt)
+	require.NoError(t, err)
+	assert.True(t, admitted, "the first failure is free")
+
+	_, admitted, err = store.Attempt(ctx, "k", attempt)
+	require.NoError(t, err)
+	assert.False(t, admitted, "the second failure earns a delay")
+
+	attempt.At = now.Add(time.Second)
+	_, admitted, err = store.Attempt(ctx, "k", attempt)
+	require.NoError(t, err)
+	assert.True(t, admitted)
+}
+
+func TestMemoryStore_AttemptWindow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	_, _, err := store.Attempt(ctx, "k", testAttempt(now, 10))
+	require.NoError(t, err)
+	record, _, err := store.Attempt(ctx, "k", testAttempt(now.Add(DefaultWindow+time.Second), 10))
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.Failures, "failures older than the window are forgotten")
+}
+
+func TestMemoryStore_AttemptConcurrent(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admittedCount := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, admitted, err := store.Attempt(context.Background(), "k", testAttempt(now, 5))
+			assert.NoError(t, err)
+			if admitted {
+				mu.Lock()
+				admittedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, admittedCount)
+	record, err := store.Get(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, 5, record.Failures)
+}
+
+func TestMemoryStore_Forgive(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, _, err := store.Attempt(ctx, "k", testAttempt(now, 3))
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Forgive(ctx, "k", 3))
+
+	record, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, 2, record.Failures)
+	assert.True(t, record.LockedUntil.IsZero(), "the lockout the forgiven attempt set is lifted")
+
+	require.NoError(t, store.Forgive(ctx, "k", 3))
+	require.NoError(t, store.Forgive(ctx, "k", 3))
+	require.NoError(t, store.Forgive(ctx, "k", 3))
+	record, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures, "never below zero")
+
+	assert.NoError(t, store.Forgive(ctx, "missing", 3))
+}
+
+func TestMemoryStore_DeleteAndList(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, key := range []string{"a:1", "a:2", "b:1"} {
+		_, _, err := store.Attempt(ctx, key, testAttempt(now, 10))
+		require.NoError(t, err)
+	}
+	records, err := store.List(ctx, "a:")
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	require.NoError(t, store.Delete(ctx, "a:1"))
+	records, err = store.List(ctx, "a:")
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Contains(t, records, "a:2")
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	attempt := testAttempt(time.Now(), 10)
+	attempt.TTL = time.Millisecond
+
+	_, _, err := store.Attempt(ctx, "k", attempt)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	record, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures)
+}
+
+// toggleStore fails every call while down, delegating to a MemoryStore otherwise
+type toggleStore struct {
+	*MemoryStore
+	down bool
+}
+
+var errStoreDown = errors.New("connection refused")
+
+func (s *toggleStore) Get(ctx context.Context, key string) (Record, error) {
+	if s.down {
+		return Record{}, errStoreDown
+	}
+	return s.MemoryStore.Get(ctx, key)
+}
+
+func (s *toggleStore) Attempt(ctx context.Context, key string, attempt Attempt) (Record, bool, error) {
+	if s.down {
+		return Record{}, false, errStoreDown
+	}
+	return s.MemoryStore.Attempt(ctx, key, attempt)
+}
+
+func (s *toggleStore) Forgive(ctx context.Context, key string, lockAfter int) error {
+	if s.down {
+		return errStoreDown
+	}
+	return s.MemoryStore.Forgive(ctx, key, lockAfter)
+}
+
+func (s *toggleStore) Delete(ctx context.Context, key string) error {
+	if s.down {
+		return errStoreDown
+	}
+	return s.MemoryStore.Delete(ctx, key)
+}
+
+func (s *toggleStore) List(ctx context.Context, prefix string) (map[string]Record, error) {
+	if s.down {
+		return nil, errStoreDown
+	}
+	return s.MemoryStore.List(ctx, prefix)
+}
+
+func TestFallbackStore(t *testing.T) {
+	primary := &toggleStore{MemoryStore: NewMemoryStore()}
+	store := NewFallbackStore(primary)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, _, err := store.Attempt(ctx, "k", testAttempt(now, 10))
+	require.NoError(t, err)
+
+	primary.down = true
+	record, admitted, err := store.Attempt(ctx, "k", testAttempt(now, 10))
+	require.NoError(t, err, "falls back instead of failing logins")
+	assert.True(t, admitted)
+	assert.Equal(t, 1, record.Failures, "counted in memory, apart from the primary")
+	require.NoError(t, store.Forgive(ctx, "k", 10))
+	records, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Contains(t, records, "k")
+
+	primary.down = false
+	record, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.Failures, "back on the primary")
+
+	// Delete clears both, so failures counted during the outage do not come back
+	_, _, err = store.Fallback.Attempt(ctx, "k", testAttempt(now, 10))
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(ctx, "k"))
+	record, err = store.Fallback.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures)
+	record, err = store.Primary.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures)
+}
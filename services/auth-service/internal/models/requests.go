@@ -0,0 +1,58 @@
+package models
+
+// Login request model
+// Passwords are capped at bcrypt's 72 byte input limit.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// Refresh token request model (refresh and logout)
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required,max=128"`
+}
+
+// Forgot password request model
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+// Reset password request model (token from the reset email)
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required,max=128"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// Verify email request model (token from the verification email)
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required,max=1024"`
+}
+
+// Resend verification email request model
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+// Second login step request model (mfa_token from the login response)
+// Code is a 6-digit TOTP code or a recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required,max=1024"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+// MFA enrollment request model
+// MFAToken is only needed when enrolling from a login that requires MFA; otherwise
+// the access token identifies the user.
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"max=1024"`
+}
+
+// MFA code request model (confirm, regenerate recovery codes, disable)
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// MFA policy request model; an empty list makes MFA optional for every role
+type MFAPolicyRequest struct {
+	Roles []string `json:"roles" binding:"required,dive,oneof=admin manager sales_rep viewer"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Token response for login and refresh
+// ExpiresIn is the access token lifetime in seconds; the refresh token
+// replaces the one presented and is only valid once. RecoveryCodes is only
+// set when the login completed an MFA enrollment.
+type TokenResponse struct {
+	AccessToken      string   `json:"access_token"`
+	TokenType        string   `json:"token_type"`
+	ExpiresIn        int64    `json:"expires_in"`
+	RefreshToken     string   `json:"refresh_token"`
+	RefreshExpiresIn int64    `json:"refresh_expires_in"`
+	RecoveryCodes    []string `json:"recovery_codes,omitempty"`
+}
+
+// MFA challenge response for a login that needs a second factor
+// With EnrollmentRequired the user must enroll (POST /mfa/enroll with the
+// mfa_token) before completing the login with a code from the new secret.
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+// MFA enrollment response; the secret is only shown once
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// Recovery codes response; the codes are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFA status response
+type MFAStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// MFA policy response: the roles that must use MFA in the tenant
+type MFAPolicyResponse struct {
+	Roles []string `json:"roles"`
+}
+
+// Failed-login state of one account or client IP
+type LockoutResponse struct {
+	Kind          string     `json:"kind"`
+	Subject       string     `json:"subject"`
+	Failures      int        `json:"failures"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	Locked        bool       `json:"locked"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	RetryAfter    int64      `json:"retry_after"` // seconds until the next attempt is allowed
+}
+
+// Lockout list response, locked subjects first
+type LockoutListResponse struct {
+	Lockouts []LockoutResponse `json:"lockouts"`
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/tests/helpers"
+	"crm-platform/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+// AuthTestSuite covers login, refresh token rotation and logout without a database
+type AuthTestSuite struct {
+	suite.Suite
+	server *helpers.TestServer
+	user   *helpers.MemoryUser
+}
+
+// SetupTest starts a server with one sales rep
+func (suite *AuthTestSuite) SetupTest() {
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{})
+	suite.user = suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+}
+
+// verifier checks tokens the way the other services do with SHARED_JWT_SECRET
+func (suite *AuthTestSuite) verifier() *middleware.JWTVerifier {
+	return middleware.NewJWTVerifier(middleware.JWTOptions{
+		Secret:    helpers.TestJWTSecret,
+		Issuer:    helpers.TestJWTIssuer,
+		Audience:  helpers.TestJWTAudience,
+		ClockSkew: 30 * time.Second,
+	})
+}
+
+// Login returns a token pair and records the login time
+func (suite *AuthTestSuite) TestLogin_Success() {
+	resp := suite.server.Post("/api/v1/auth/login", map[string]string{
+		"email":    "rep@example.com",
+		"password": "correct horse battery",
+	})
+
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.Equal("Bearer", resp.Body["token_type"])
+	suite.Equal(float64(auth.DefaultAccessTokenTTL.Seconds()), resp.Body["expires_in"])
+	suite.NotEmpty(resp.Body["refresh_token"])
+	suite.NotNil(suite.server.Store.User(suite.user.ID).LastLogin)
+}
+
+// Wrong passwords, unknown emails and inactive users get the same 401
+func (suite *AuthTestSuite) TestLogin_InvalidCredentials() {
+	inactive := suite.server.Store.AddUser("gone@example.com", "correct horse battery", "sales_rep")
+	suite.server.Store.SetActive(inactive.ID, false)
+
+	cases := map[string]map[string]string{
+		"wrong password": {"email": "rep@example.com", "password": "wrong"},
+		"unknown email":  {"email": "nobody@example.com", "password": "correct horse battery"},
+		"inactive user":  {"email": "gone@example.com", "password": "correct horse battery"},
+	}
+	for name, body := range cases {
+		resp := suite.server.Post("/api/v1/auth/login", body)
+		suite.Equal(401, resp.StatusCode, name)
+		suite.Equal("AUTHENTICATION ERROR: invalid email or password", resp.Body["error"], name)
+	}
+}
+
+// Malformed login requests are rejected before any lookup
+func (suite *AuthTestSuite) TestLogin_Validation() {
+	resp := suite.server.Post("/api/v1/auth/login", map[string]string{"email": "not-an-email", "password": "x"})
+	suite.Equal(400, resp.StatusCode)
+}
+
+// Access tokens carry the user, tenant, role and session, and pass AuthMiddleware
+func (suite *AuthTestSuite) TestAccessToken_AcceptedByAuthMiddleware() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+
+	claims, err := suite.verifier().Verify(context.Background(), access)
+	suite.Require().NoError(err)
+	suite.Equal("1", claims.UserID)
+	suite.Equal(helpers.TestTenantID, claims.TenantID)
+	suite.Equal("sales_rep", claims.Role)
+	suite.NotEmpty(claims.SessionID)
+
+	router := gin.New()
+	router.Use(middleware.AuthMiddlewareWithVerifier(suite.verifier()))
+	router.GET("/me", func(c *gin.Context) {
+		claims, _ := middleware.GetClaims(c)
+		c.JSON(200, gin.H{"user_id": claims.UserID})
+	})
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+access)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	suite.Equal(200, recorder.Code)
+}
+
+// Refreshing returns a new refresh token and the old one stops working
+func (suite *AuthTestSuite) TestRefresh_RotatesToken() {
+	_, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	rotated := resp.Body["refresh_token"].(string)
+	suite.NotEqual(refresh, rotated)
+
+	_, err := suite.verifier().Verify(context.Background(), resp.Body["access_token"].(string))
+	suite.NoError(err)
+
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": rotated})
+	suite.Equal(200, resp.StatusCode)
+}
+
+// Replaying a used refresh token revokes the session, so the current token dies too
+func (suite *AuthTestSuite) TestRefresh_ReuseRevokesSession() {
+	access, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+	claims, err := suite.verifier().Verify(context.Background(), access)
+	suite.Require().NoError(err)
+
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Require().Equal(200, resp.StatusCode)
+	rotated := resp.Body["refresh_token"].(string)
+
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Equal(401, resp.StatusCode)
+	suite.Contains(resp.Body["error"], "reuse detected")
+
+	session, ok := suite.server.Store.Session(claims.SessionID)
+	suite.Require().True(ok)
+	suite.True(session.RevokedAt.Valid)
+	suite.Equal(auth.RevokedTokenReuse, *session.RevokedReason)
+
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": rotated})
+	suite.Equal(401, resp.StatusCode)
+}
+
+// Unknown refresh tokens are rejected
+func (suite *AuthTestSuite) TestRefresh_UnknownToken() {
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": "bogus"})
+	suite.Equal(401, resp.StatusCode)
+}
+
+// Deactivated users cannot refresh and their session is revoked
+func (suite *AuthTestSuite) TestRefresh_InactiveUser() {
+	_, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+	suite.server.Store.SetActive(suite.user.ID, false)
+
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Equal(401, resp.StatusCode)
+}
+
+// Logout revokes the session and is idempotent
+func (suite *AuthTestSuite) TestLogout_RevokesSession() {
+	_, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+
+	resp := suite.server.Post("/api/v1/auth/logout", map[string]string{"refresh_token": refresh})
+	suite.Equal(204, resp.StatusCode)
+
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Equal(401, resp.StatusCode)
+
+	resp = suite.server.Post("/api/v1/auth/logout", map[string]string{"refresh_token": refresh})
+	suite.Equal(204, resp.StatusCode)
+}
+
+// Requests without a tenant never reach the handlers
+func (suite *AuthTestSuite) TestLogin_RequiresTenant() {
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+	recorder := httptest.NewRecorder()
+	suite.server.Router.ServeHTTP(recorder, req)
+	suite.Equal(400, recorder.Code)
+}
+
+// HMAC issuers publish an empty key set
+func (suite *AuthTestSuite) TestJWKS_EmptyForHMAC() {
+	resp := suite.server.Get("/.well-known/jwks.json")
+	suite.Equal(200, resp.StatusCode)
+	suite.Empty(resp.Body["keys"])
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+// SessionExpiryTestSuite drives the service clock past token and session lifetimes
+type SessionExpiryTestSuite struct {
+	suite.Suite
+	now    time.Time
+	server *helpers.TestServer
+}
+
+// SetupTest starts a server whose service clock the test controls
+func (suite *SessionExpiryTestSuite) SetupTest() {
+	suite.now = time.Now()
+	clock := func() time.Time { return suite.now }
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{SessionTTL: time.Hour, Now: clock})
+	suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+}
+
+// Refresh never extends the session beyond its original lifetime
+func (suite *SessionExpiryTestSuite) TestRefresh_SessionExpires() {
+	_, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+
+	suite.now = suite.now.Add(59 * time.Minute)
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.LessOrEqual(resp.Body["refresh_expires_in"], float64(3600))
+
+	suite.now = suite.now.Add(2 * time.Minute)
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": resp.Body["refresh_token"].(string)})
+	suite.Equal(401, resp.StatusCode)
+}
+
+func TestSessionExpiryTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionExpiryTestSuite))
+}
+
+// KeyIssuerTestSuite checks RS256/ES256 tokens against the published JWKS
+type KeyIssuerTestSuite struct {
+	suite.Suite
+}
+
+// Tokens signed with an RSA or P-256 key verify against the JWKS the service publishes
+func (suite *KeyIssuerTestSuite) TestIssue_VerifiesAgainstPublishedJWKS() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+
+	opts := auth.IssuerOptions{Issuer: helpers.TestJWTIssuer, Audience: helpers.TestJWTAudience}
+	for _, signer := range []crypto.Signer{rsaKey, ecKey} {
+		issuer, err := auth.NewKeyIssuer(signer, "", opts)
+		suite.Require().NoError(err)
+
+		server := helpers.SetupTestServer(suite.T(), issuer, auth.Options{})
+		server.Store.AddUser("rep@example.com", "correct horse battery", "admin")
+		access, _ := server.Login("rep@example.com", "correct horse battery")
+
+		// Publish the served key set where the verifier reads it (JWT_JWKS_URL)
+		resp := server.Get("/.well-known/jwks.json")
+		suite.Require().Equal(200, resp.StatusCode)
+		jwks, err := json.Marshal(resp.Body)
+		suite.Require().NoError(err)
+		path := filepath.Join(suite.T().TempDir(), "jwks.json")
+		suite.Require().NoError(os.WriteFile(path, jwks, 0o600))
+
+		verifier := middleware.NewJWTVerifier(middleware.JWTOptions{
+			JWKSURL:  path,
+			Issuer:   helpers.TestJWTIssuer,
+			Audience: helpers.TestJWTAudience,
+		})
+		claims, err := verifier.Verify(context.Background(), access)
+		suite.Require().NoError(err)
+		suite.Equal("admin", claims.Role)
+	}
+}
+
+// Weak RSA keys are refused
+func (suite *KeyIssuerTestSuite) TestNewKeyIssuer_RejectsSmallRSAKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	suite.Require().NoError(err)
+
+	_, err = auth.NewKeyIssuer(key, "", auth.IssuerOptions{})
+	suite.ErrorIs(err, auth.ErrUnsupportedKey)
+}
+
+func TestKeyIssuerTestSuite(t *testing.T) {
+	suite.Run(t, new(KeyIssuerTestSuite))
+}
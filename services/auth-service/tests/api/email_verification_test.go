@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/tests/helpers"
+	"crm-platform/pkg/middleware"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// EmailVerificationTestSuite covers verification emails, resend throttling and the login policies
+type EmailVerificationTestSuite struct {
+	suite.Suite
+	server  *helpers.TestServer
+	mailbox *helpers.TestMailbox
+	user    *helpers.MemoryUser
+	now     time.Time
+}
+
+// SetupTest starts a server with the off policy and a clock the test controls
+func (suite *EmailVerificationTestSuite) SetupTest() {
+	suite.mailbox = helpers.NewTestMailbox(suite.T())
+	suite.start(auth.VerificationOff)
+}
+
+// start replaces the server with one enforcing policy
+func (suite *EmailVerificationTestSuite) start(policy auth.VerificationPolicy) {
+	suite.now = time.Now()
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{
+		Now: func() time.Time { return suite.now },
+		Verification: auth.VerificationOptions{
+			Mailer: suite.mailbox,
+			URL:    helpers.TestVerifyURL,
+			Secret: []byte("verification-test-secret"),
+			Policy: policy,
+		},
+	})
+	suite.user = suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+}
+
+// resend requests a verification email for email
+func (suite *EmailVerificationTestSuite) resend(email string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/email/verify/resend", map[string]string{"email": email})
+}
+
+// verify confirms an address with token
+func (suite *EmailVerificationTestSuite) verify(token string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/email/verify", map[string]string{"token": token})
+}
+
+// The emailed link verifies the address, and using it again is harmless
+func (suite *EmailVerificationTestSuite) TestVerify_MarksEmailVerified() {
+	suite.Equal(202, suite.resend("rep@example.com").StatusCode)
+	token := suite.mailbox.LastVerificationToken()
+	suite.Contains(suite.mailbox.Messages()[0], "To: rep@example.com")
+
+	suite.Equal(204, suite.verify(token).StatusCode)
+	suite.True(suite.server.Store.User(suite.user.ID).EmailVerified)
+	suite.Equal(204, suite.verify(token).StatusCode)
+
+	suite.Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Len(suite.mailbox.Messages(), 1, "verified users get no more mail")
+}
+
+// Tokens stop working after their TTL
+func (suite *EmailVerificationTestSuite) TestVerify_ExpiredToken() {
+	suite.Require().Equal(202, suite.resend("rep@example.com").StatusCode)
+	token := suite.mailbox.LastVerificationToken()
+
+	suite.now = suite.now.Add(auth.DefaultVerificationTokenTTL + time.Minute)
+	suite.Equal(400, suite.verify(token).StatusCode)
+	suite.False(suite.server.Store.User(suite.user.ID).EmailVerified)
+}
+
+// Tampered tokens, tokens of another tenant and access tokens are rejected
+func (suite *EmailVerificationTestSuite) TestVerify_RejectsForeignTokens() {
+	suite.Require().Equal(202, suite.resend("rep@example.com").StatusCode)
+	token := suite.mailbox.LastVerificationToken()
+
+	suite.Equal(400, suite.verify(token[:len(token)-2]+"xx").StatusCode)
+	suite.Equal(400, suite.verify("bogus").StatusCode)
+
+	err := suite.server.Service.VerifyEmail(context.Background(), "01HK153X003BMPJNJB6JHKXK8Z", token)
+	suite.ErrorIs(err, auth.ErrInvalidVerificationToken)
+
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	suite.Equal(400, suite.verify(access).StatusCode)
+	suite.False(suite.server.Store.User(suite.user.ID).EmailVerified)
+}
+
+// A second email within the resend interval is accepted but not sent
+func (suite *EmailVerificationTestSuite) TestResend_Throttled() {
+	suite.Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Len(suite.mailbox.Messages(), 1)
+
+	suite.now = suite.now.Add(auth.DefaultVerificationResendInterval + time.Second)
+	suite.Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Len(suite.mailbox.Messages(), 2)
+}
+
+// Unknown emails look the same as known ones and send nothing
+func (suite *EmailVerificationTestSuite) TestResend_UnknownEmail() {
+	suite.Equal(202, suite.resend("nobody@example.com").StatusCode)
+	suite.Empty(suite.mailbox.Messages())
+}
+
+// The block policy refuses login until the address is verified
+func (suite *EmailVerificationTestSuite) TestPolicyBlock_RefusesLogin() {
+	suite.start(auth.VerificationBlock)
+
+	resp := suite.server.Post("/api/v1/auth/login", map[string]string{"email": "rep@example.com", "password": "correct horse battery"})
+	suite.Equal(403, resp.StatusCode)
+
+	suite.Require().Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Require().Equal(204, suite.verify(suite.mailbox.LastVerificationToken()).StatusCode)
+	suite.server.Login("rep@example.com", "correct horse battery")
+}
+
+// The limit policy issues tokens whose permissions are cut down to UnverifiedPermissions
+func (suite *EmailVerificationTestSuite) TestPolicyLimit_LimitsPermissions() {
+	suite.start(auth.VerificationLimit)
+	verifier := middleware.NewJWTVerifier(middleware.JWTOptions{
+		Secret:   helpers.TestJWTSecret,
+		Issuer:   helpers.TestJWTIssuer,
+		Audience: helpers.TestJWTAudience,
+	})
+	policy := middleware.NewPermissionPolicy(nil, nil)
+
+	access, refresh := suite.server.Login("rep@example.com", "correct horse battery")
+	claims, err := verifier.Verify(context.Background(), access)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(claims.EmailVerified)
+	suite.False(*claims.EmailVerified)
+
+	permissions, err := policy.Permissions(context.Background(), helpers.TestTenantID, claims)
+	suite.Require().NoError(err)
+	suite.Contains(permissions, "deals:read")
+	suite.NotContains(permissions, "deals:write")
+
+	suite.Require().Equal(202, suite.resend("rep@example.com").StatusCode)
+	suite.Require().Equal(204, suite.verify(suite.mailbox.LastVerificationToken()).StatusCode)
+
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Require().Equal(200, resp.StatusCode)
+	claims, err = verifier.Verify(context.Background(), resp.Body["access_token"].(string))
+	suite.Require().NoError(err)
+	suite.Nil(claims.EmailVerified)
+
+	permissions, err = policy.Permissions(context.Background(), helpers.TestTenantID, claims)
+	suite.Require().NoError(err)
+	suite.Contains(permissions, "deals:write")
+}
+
+// Admins with an unverified email cannot use the admin endpoints until they verify
+func (suite *EmailVerificationTestSuite) TestPolicyLimit_UnverifiedAdminForbidden() {
+	suite.start(auth.VerificationLimit)
+	suite.server.Store.AddUser("admin@example.com", "correct horse battery", "admin")
+
+	access, refresh := suite.server.Login("admin@example.com", "correct horse battery")
+	suite.Equal(403, suite.server.Send("GET", "/api/v1/auth/mfa/policy", access, nil).StatusCode)
+	suite.Equal(403, suite.server.Send("PUT", "/api/v1/auth/mfa/policy", access, map[string][]string{"roles": {"admin"}}).StatusCode)
+	suite.Equal(403, suite.server.Send("GET", "/api/v1/auth/lockouts", access, nil).StatusCode)
+
+	suite.Require().Equal(202, suite.resend("admin@example.com").StatusCode)
+	suite.Require().Equal(204, suite.verify(suite.mailbox.LastVerificationToken()).StatusCode)
+
+	resp := suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Require().Equal(200, resp.StatusCode)
+	access = resp.Body["access_token"].(string)
+	suite.Equal(200, suite.server.Send("GET", "/api/v1/auth/mfa/policy", access, nil).StatusCode)
+	suite.Equal(200, suite.server.Send("GET", "/api/v1/auth/lockouts", access, nil).StatusCode)
+}
+
+// Unknown policy names are a configuration error
+func (suite *EmailVerificationTestSuite) TestParseVerificationPolicy() {
+	policy, err := auth.ParseVerificationPolicy("")
+	suite.NoError(err)
+	suite.Equal(auth.VerificationOff, policy)
+
+	policy, err = auth.ParseVerificationPolicy("Block")
+	suite.NoError(err)
+	suite.Equal(auth.VerificationBlock, policy)
+
+	_, err = auth.ParseVerificationPolicy("strict")
+	suite.Error(err)
+}
+
+func TestEmailVerificationTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailVerificationTestSuite))
+}
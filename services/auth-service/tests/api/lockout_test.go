@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/internal/lockout"
+	"crm-platform/auth-service/tests/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LockoutTestSuite covers progressive delays, lockouts per account and per IP, and the admin endpoints
+type LockoutTestSuite struct {
+	suite.Suite
+	server *helpers.TestServer
+	now    time.Time
+}
+
+// SetupTest starts a server with the default lockout policy and a clock the test controls
+func (suite *LockoutTestSuite) SetupTest() {
+	suite.start(lockout.Policy{})
+}
+
+// start replaces the server with one enforcing policy
+func (suite *LockoutTestSuite) start(policy lockout.Policy) {
+	suite.now = time.Now()
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{
+		Now:     func() time.Time { return suite.now },
+		Lockout: auth.LockoutOptions{Policy: policy},
+		MFA:     auth.MFAOptions{Key: []byte("mfa-test-key")},
+	})
+	suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+	suite.server.Store.AddUser("admin@example.com", "correct horse battery", "admin")
+}
+
+// login posts email and password
+func (suite *LockoutTestSuite) login(email, password string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/login", map[string]string{"email": email, "password": password})
+}
+
+// fail makes n failed logins for email, waiting out each delay
+func (suite *LockoutTestSuite) fail(email string, n int) {
+	for i := 0; i < n; i++ {
+		suite.Require().Equal(401, suite.login(email, "wrong password").StatusCode, "attempt %d", i+1)
+		suite.now = suite.now.Add(lockout.DefaultMaxDelay)
+	}
+}
+
+// Past the free attempts each failure doubles the wait, answered with 429 and Retry-After
+func (suite *LockoutTestSuite) TestProgressiveDelay() {
+	for i := 0; i < lockout.DefaultAccountDelayAfter+1; i++ {
+		suite.Require().Equal(401, suite.login("rep@example.com", "wrong password").StatusCode)
+	}
+
+	resp := suite.login("rep@example.com", "correct horse battery")
+	suite.Equal(429, resp.StatusCode)
+	suite.Contains(resp.Body["error"], "try again later")
+
+	suite.now = suite.now.Add(lockout.DefaultBaseDelay)
+	suite.Equal(401, suite.login("rep@example.com", "wrong password").StatusCode)
+	suite.now = suite.now.Add(lockout.DefaultBaseDelay)
+	suite.Equal(429, suite.login("rep@example.com", "wrong password").StatusCode, "the wait doubled")
+	suite.now = suite.now.Add(lockout.DefaultBaseDelay)
+	suite.Equal(200, suite.login("rep@example.com", "correct horse battery").StatusCode)
+
+	entry, err := suite.server.Service.Lockout(context.Background(), helpers.TestTenantID, lockout.KindAccount, "rep@example.com")
+	suite.Require().NoError(err)
+	suite.Zero(entry.Failures, "a successful login clears the account")
+}
+
+// The account locks after LockAfter failures, refusing even the right password until it ends
+func (suite *LockoutTestSuite) TestAccountLockout() {
+	suite.fail("rep@example.com", lockout.DefaultAccountLockAfter)
+
+	resp := suite.login("Rep@Example.com", "correct horse battery")
+	suite.Equal(429, resp.StatusCode)
+	suite.Contains(resp.Body["error"], "account temporarily locked")
+
+	suite.Equal(200, suite.login("admin@example.com", "correct horse battery").StatusCode, "other accounts are not affected")
+
+	suite.now = suite.now.Add(lockout.DefaultLockoutDuration)
+	suite.Equal(200, suite.login("rep@example.com", "correct horse battery").StatusCode)
+}
+
+// Failures for many emails from one client IP lock that IP
+func (suite *LockoutTestSuite) TestIPLockout() {
+	suite.start(lockout.Policy{IP: lockout.Limits{DelayAfter: 4, LockAfter: 5}})
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		suite.fail(email, 1)
+	}
+
+	resp := suite.login("rep@example.com", "correct horse battery")
+	suite.Equal(429, resp.StatusCode)
+	suite.Contains(resp.Body["error"], "ip temporarily locked")
+}
+
+// Attempts are counted before the password is checked, but successful ones are taken back
+func (suite *LockoutTestSuite) TestSuccessfulLoginsDoNotCount() {
+	suite.start(lockout.Policy{IP: lockout.Limits{DelayAfter: 2, LockAfter: 3}})
+	for i := 0; i < 5; i++ {
+		suite.Require().Equal(200, suite.login("rep@example.com", "correct horse battery").StatusCode, "login %d", i+1)
+	}
+
+	entry, err := suite.server.Service.Lockout(context.Background(), helpers.TestTenantID, lockout.KindIP, "192.0.2.1")
+	suite.Require().NoError(err)
+	suite.Zero(entry.Failures)
+}
+
+// Wrong MFA codes count against the account like wrong passwords
+func (suite *LockoutTestSuite) TestMFACodeFailuresCount() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	resp := suite.server.Send("POST", "/api/v1/auth/mfa/enroll", access, nil)
+	suite.Require().Equal(200, resp.StatusCode)
+	code, err := auth.TOTPCode(resp.Body["secret"].(string), suite.now)
+	suite.Require().NoError(err)
+	suite.Require().Equal(200, suite.server.Send("POST", "/api/v1/auth/mfa/confirm", access, map[string]string{"code": code}).StatusCode)
+
+	challenge := suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	for i := 0; i < lockout.DefaultAccountDelayAfter+1; i++ {
+		resp := suite.server.Post("/api/v1/auth/login/mfa", map[string]string{"mfa_token": challenge, "code": "000000"})
+		suite.Require().Equal(401, resp.StatusCode)
+	}
+	resp = suite.server.Post("/api/v1/auth/login/mfa", map[string]string{"mfa_token": challenge, "code": "000000"})
+	suite.Equal(429, resp.StatusCode)
+	suite.Equal(429, suite.login("rep@example.com", "correct horse battery").StatusCode)
+}
+
+// Admins list and clear lockouts of their tenant; others get 403
+func (suite *LockoutTestSuite) TestAdminEndpoints() {
+	admin, _ := suite.server.Login("admin@example.com", "correct horse battery")
+	suite.fail("rep@example.com", lockout.DefaultAccountLockAfter)
+
+	resp := suite.server.Send("GET", "/api/v1/auth/lockouts", admin, nil)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	lockouts := resp.Body["lockouts"].([]interface{})
+	suite.Require().NotEmpty(lockouts)
+	first := lockouts[0].(map[string]interface{})
+	suite.Equal("account", first["kind"])
+	suite.Equal("rep@example.com", first["subject"])
+	suite.Equal(true, first["locked"])
+
+	resp = suite.server.Send("GET", "/api/v1/auth/lockouts/account/rep@example.com", admin, nil)
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.EqualValues(lockout.DefaultAccountLockAfter, resp.Body["failures"])
+	suite.Equal(400, suite.server.Send("GET", "/api/v1/auth/lockouts/user/rep@example.com", admin, nil).StatusCode)
+
+	suite.Equal(204, suite.server.Send("DELETE", "/api/v1/auth/lockouts/account/rep@example.com", admin, nil).StatusCode)
+	rep, _ := suite.server.Login("rep@example.com", "correct horse battery")
+
+	suite.Equal(403, suite.server.Send("GET", "/api/v1/auth/lockouts", rep, nil).StatusCode)
+	suite.Equal(403, suite.server.Send("DELETE", "/api/v1/auth/lockouts/ip/192.0.2.1", rep, nil).StatusCode)
+	suite.Equal(401, suite.server.Send("GET", "/api/v1/auth/lockouts", "", nil).StatusCode)
+}
+
+// failingStore is a lockout store whose backend is down
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) (lockout.Record, error) {
+	return lockout.Record{}, errors.New("connection refused")
+}
+
+func (failingStore) Attempt(context.Context, string, lockout.Attempt) (lockout.Record, bool, error) {
+	return lockout.Record{}, false, errors.New("connection refused")
+}
+
+func (failingStore) Forgive(context.Context, string, int) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Delete(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) List(context.Context, string) (map[string]lockout.Record, error) {
+	return nil, errors.New("connection refused")
+}
+
+// While the shared store is down, failures are counted in memory and logins keep working
+func (suite *LockoutTestSuite) TestFallbackStore() {
+	suite.now = time.Now()
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{
+		Now:     func() time.Time { return suite.now },
+		Lockout: auth.LockoutOptions{Store: lockout.NewFallbackStore(failingStore{})},
+	})
+	suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+
+	suite.fail("rep@example.com", lockout.DefaultAccountLockAfter)
+	suite.Equal(429, suite.login("rep@example.com", "correct horse battery").StatusCode)
+
+	suite.now = suite.now.Add(lockout.DefaultLockoutDuration)
+	suite.Equal(200, suite.login("rep@example.com", "correct horse battery").StatusCode)
+}
+
+func TestLockoutTestSuite(t *testing.T) {
+	suite.Run(t, new(LockoutTestSuite))
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/tests/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// MFATestSuite covers TOTP enrollment, the second login step, recovery codes and the tenant policy
+type MFATestSuite struct {
+	suite.Suite
+	server *helpers.TestServer
+	policy *helpers.MemoryMFAPolicy
+	user   *helpers.MemoryUser
+	now    time.Time
+}
+
+// SetupTest starts a server with MFA configured and a clock the test controls
+func (suite *MFATestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.policy = helpers.NewMemoryMFAPolicy()
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{
+		Now: func() time.Time { return suite.now },
+		MFA: auth.MFAOptions{
+			Issuer: "CRM Test",
+			Key:    []byte("mfa-test-key"),
+			Policy: suite.policy,
+		},
+	})
+	suite.user = suite.server.Store.AddUser("rep@example.com", "correct horse battery", "sales_rep")
+}
+
+// code returns the current TOTP code for secret and moves the clock to the next step,
+// so the following code is not a replay
+func (suite *MFATestSuite) code(secret string) string {
+	code, err := auth.TOTPCode(secret, suite.now)
+	suite.Require().NoError(err)
+	suite.now = suite.now.Add(auth.TOTPPeriod)
+	return code
+}
+
+// login posts the password step
+func (suite *MFATestSuite) login(email, password string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/login", map[string]string{"email": email, "password": password})
+}
+
+// completeLogin posts the second step of a login challenge
+func (suite *MFATestSuite) completeLogin(challenge, code string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/login/mfa", map[string]string{"mfa_token": challenge, "code": code})
+}
+
+// enable enrolls the user signed in with access and returns the secret and recovery codes
+func (suite *MFATestSuite) enable(access string) (string, []interface{}) {
+	resp := suite.server.Send("POST", "/api/v1/auth/mfa/enroll", access, nil)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	secret := resp.Body["secret"].(string)
+
+	resp = suite.server.Send("POST", "/api/v1/auth/mfa/confirm", access, map[string]string{"code": suite.code(secret)})
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	return secret, resp.Body["recovery_codes"].([]interface{})
+}
+
+// Enrolling and confirming turns MFA on; later logins need a code
+func (suite *MFATestSuite) TestEnroll_ThenLoginNeedsCode() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	secret, codes := suite.enable(access)
+	suite.Len(codes, auth.RecoveryCodeCount)
+	suite.True(suite.server.Store.User(suite.user.ID).MFAEnabled)
+	suite.NotEqual(secret, *suite.server.Store.User(suite.user.ID).MFASecret, "secrets are stored encrypted")
+
+	resp := suite.login("rep@example.com", "correct horse battery")
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.Equal(true, resp.Body["mfa_required"])
+	suite.Nil(resp.Body["access_token"])
+	challenge := resp.Body["mfa_token"].(string)
+
+	suite.Equal(401, suite.completeLogin(challenge, "000000").StatusCode)
+	resp = suite.completeLogin(challenge, suite.code(secret))
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.NotEmpty(resp.Body["access_token"])
+	suite.Nil(resp.Body["recovery_codes"])
+}
+
+// A TOTP code is accepted once
+func (suite *MFATestSuite) TestLogin_RejectsReplayedCode() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	secret, _ := suite.enable(access)
+
+	code, err := auth.TOTPCode(secret, suite.now)
+	suite.Require().NoError(err)
+	challenge := suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Require().Equal(200, suite.completeLogin(challenge, code).StatusCode)
+
+	challenge = suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Equal(401, suite.completeLogin(challenge, code).StatusCode)
+}
+
+// Challenges expire, and access tokens cannot stand in for them
+func (suite *MFATestSuite) TestLogin_RejectsExpiredChallenge() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	secret, _ := suite.enable(access)
+
+	challenge := suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Equal(401, suite.completeLogin(access, suite.code(secret)).StatusCode)
+
+	suite.now = suite.now.Add(auth.DefaultMFAChallengeTTL + time.Minute)
+	suite.Equal(401, suite.completeLogin(challenge, suite.code(secret)).StatusCode)
+}
+
+// Each recovery code works once in place of a TOTP code
+func (suite *MFATestSuite) TestLogin_RecoveryCodeWorksOnce() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	_, codes := suite.enable(access)
+	recovery := codes[0].(string)
+
+	challenge := suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Require().Equal(200, suite.completeLogin(challenge, recovery).StatusCode)
+	suite.Equal(auth.RecoveryCodeCount-1, suite.server.Store.UnusedRecoveryCodes(suite.user.ID))
+
+	challenge = suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Equal(401, suite.completeLogin(challenge, recovery).StatusCode)
+}
+
+// Regenerating replaces every recovery code
+func (suite *MFATestSuite) TestRegenerateRecoveryCodes() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	secret, old := suite.enable(access)
+
+	resp := suite.server.Send("POST", "/api/v1/auth/mfa/recovery-codes", access, map[string]string{"code": suite.code(secret)})
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.Len(resp.Body["recovery_codes"], auth.RecoveryCodeCount)
+
+	challenge := suite.login("rep@example.com", "correct horse battery").Body["mfa_token"].(string)
+	suite.Equal(401, suite.completeLogin(challenge, old[0].(string)).StatusCode)
+}
+
+// Users whose role requires MFA enroll during login and get their recovery codes with the tokens
+func (suite *MFATestSuite) TestPolicy_EnrollsAtLogin() {
+	suite.policy.SetMFARequiredRoles(context.Background(), helpers.TestTenantID, []string{"sales_rep"})
+
+	resp := suite.login("rep@example.com", "correct horse battery")
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.Equal(true, resp.Body["enrollment_required"])
+	challenge := resp.Body["mfa_token"].(string)
+
+	resp = suite.server.Post("/api/v1/auth/mfa/enroll", map[string]string{"mfa_token": challenge})
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	secret := resp.Body["secret"].(string)
+
+	resp = suite.completeLogin(challenge, suite.code(secret))
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.NotEmpty(resp.Body["access_token"])
+	suite.Len(resp.Body["recovery_codes"], auth.RecoveryCodeCount)
+	suite.True(suite.server.Store.User(suite.user.ID).MFAEnabled)
+}
+
+// MFA can be turned off with a code, unless the role requires it
+func (suite *MFATestSuite) TestDisable_BlockedByPolicy() {
+	access, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	secret, _ := suite.enable(access)
+
+	suite.policy.SetMFARequiredRoles(context.Background(), helpers.TestTenantID, []string{"sales_rep"})
+	resp := suite.server.Send("POST", "/api/v1/auth/mfa/disable", access, map[string]string{"code": suite.code(secret)})
+	suite.Equal(403, resp.StatusCode)
+
+	resp = suite.server.Send("GET", "/api/v1/auth/mfa", access, nil)
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.Equal(true, resp.Body["required"])
+
+	suite.policy.SetMFARequiredRoles(context.Background(), helpers.TestTenantID, nil)
+	resp = suite.server.Send("POST", "/api/v1/auth/mfa/disable", access, map[string]string{"code": suite.code(secret)})
+	suite.Equal(204, resp.StatusCode)
+	suite.False(suite.server.Store.User(suite.user.ID).MFAEnabled)
+	suite.Zero(suite.server.Store.UnusedRecoveryCodes(suite.user.ID))
+}
+
+// Only admins read and change the policy, and only known roles are accepted
+func (suite *MFATestSuite) TestPolicyEndpoints_AdminOnly() {
+	suite.server.Store.AddUser("admin@example.com", "correct horse battery", "admin")
+	rep, _ := suite.server.Login("rep@example.com", "correct horse battery")
+	admin, _ := suite.server.Login("admin@example.com", "correct horse battery")
+
+	suite.Equal(401, suite.server.Send("GET", "/api/v1/auth/mfa/policy", "", nil).StatusCode)
+	suite.Equal(403, suite.server.Send("GET", "/api/v1/auth/mfa/policy", rep, nil).StatusCode)
+	suite.Equal(403, suite.server.Send("PUT", "/api/v1/auth/mfa/policy", rep, map[string][]string{"roles": {"viewer"}}).StatusCode)
+
+	resp := suite.server.Send("PUT", "/api/v1/auth/mfa/policy", admin, map[string][]string{"roles": {"admin", "manager"}})
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.Equal(400, suite.server.Send("PUT", "/api/v1/auth/mfa/policy", admin, map[string][]string{"roles": {"root"}}).StatusCode)
+
+	resp = suite.server.Send("GET", "/api/v1/auth/mfa/policy", admin, nil)
+	suite.Require().Equal(200, resp.StatusCode)
+	suite.Equal([]interface{}{"admin", "manager"}, resp.Body["roles"])
+}
+
+// The provisioning URI carries what authenticator apps need
+func (suite *MFATestSuite) TestProvisioningURI() {
+	uri, err := url.Parse(auth.ProvisioningURI("CRM Test", "rep@example.com", "JBSWY3DPEHPK3PXP"))
+	suite.Require().NoError(err)
+	suite.Equal("otpauth", uri.Scheme)
+	suite.Equal("totp", uri.Host)
+	suite.Equal("/CRM Test:rep@example.com", uri.Path)
+	suite.Equal("JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	suite.Equal("CRM Test", uri.Query().Get("issuer"))
+	suite.Equal("6", uri.Query().Get("digits"))
+	suite.Equal("30", uri.Query().Get("period"))
+}
+
+// Codes match the RFC 6238 SHA-1 test vectors
+func (suite *MFATestSuite) TestTOTPCode_RFCVectors() {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924"} {
+		code, err := auth.TOTPCode(secret, time.Unix(unix, 0))
+		suite.Require().NoError(err)
+		suite.Equal(want, code)
+	}
+}
+
+func TestMFATestSuite(t *testing.T) {
+	suite.Run(t, new(MFATestSuite))
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/tests/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// PasswordResetTestSuite covers reset emails, rate limiting and reset confirmation
+type PasswordResetTestSuite struct {
+	suite.Suite
+	server  *helpers.TestServer
+	mailbox *helpers.TestMailbox
+	user    *helpers.MemoryUser
+}
+
+// SetupTest starts a server whose mail lands in a temp dir
+func (suite *PasswordResetTestSuite) SetupTest() {
+	suite.mailbox = helpers.NewTestMailbox(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), helpers.NewTestHMACIssuer(), auth.Options{
+		Reset: auth.ResetOptions{Mailer: suite.mailbox, URL: helpers.TestResetURL, Limit: 2},
+	})
+	suite.user = suite.server.Store.AddUser("rep@example.com", "old password", "sales_rep")
+}
+
+// forgot requests a reset email for email
+func (suite *PasswordResetTestSuite) forgot(email string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/password/forgot", map[string]string{"email": email})
+}
+
+// reset confirms a reset with token
+func (suite *PasswordResetTestSuite) reset(token, password string) *helpers.TestResponse {
+	return suite.server.Post("/api/v1/auth/password/reset", map[string]string{"token": token, "password": password})
+}
+
+// The reset link works once, changes the password and signs out every session
+func (suite *PasswordResetTestSuite) TestReset_ChangesPasswordAndRevokesSessions() {
+	_, refresh := suite.server.Login("rep@example.com", "old password")
+
+	suite.Equal(202, suite.forgot("rep@example.com").StatusCode)
+	token := suite.mailbox.LastResetToken()
+	suite.Contains(suite.mailbox.Messages()[0], "To: rep@example.com")
+
+	suite.Equal(204, suite.reset(token, "new password").StatusCode)
+
+	suite.server.Login("rep@example.com", "new password")
+	resp := suite.server.Post("/api/v1/auth/login", map[string]string{"email": "rep@example.com", "password": "old password"})
+	suite.Equal(401, resp.StatusCode)
+
+	resp = suite.server.Post("/api/v1/auth/refresh", map[string]string{"refresh_token": refresh})
+	suite.Equal(401, resp.StatusCode)
+
+	resp = suite.reset(token, "another password")
+	suite.Equal(400, resp.StatusCode)
+}
+
+// Only a hash of the token is stored
+func (suite *PasswordResetTestSuite) TestForgot_StoresTokenHash() {
+	suite.Require().Equal(202, suite.forgot("rep@example.com").StatusCode)
+	token := suite.mailbox.LastResetToken()
+
+	_, err := suite.server.Store.GetPasswordResetToken(context.Background(), token)
+	suite.Error(err, "token must not be stored in plain text")
+
+	sum := sha256.Sum256([]byte(token))
+	_, err = suite.server.Store.GetPasswordResetToken(context.Background(), hex.EncodeToString(sum[:]))
+	suite.NoError(err)
+}
+
+// A successful reset uses up the user's other outstanding reset links
+func (suite *PasswordResetTestSuite) TestReset_InvalidatesOtherTokens() {
+	suite.forgot("rep@example.com")
+	first := suite.mailbox.LastResetToken()
+	suite.forgot("rep@example.com")
+	second := suite.mailbox.LastResetToken()
+
+	suite.Equal(204, suite.reset(second, "new password").StatusCode)
+	suite.Equal(400, suite.reset(first, "other password").StatusCode)
+}
+
+// Unknown emails look the same as known ones and send nothing
+func (suite *PasswordResetTestSuite) TestForgot_UnknownEmail() {
+	suite.Equal(202, suite.forgot("nobody@example.com").StatusCode)
+	suite.Empty(suite.mailbox.Messages())
+}
+
+// Requests beyond the per-user limit are accepted but send no mail
+func (suite *PasswordResetTestSuite) TestForgot_RateLimited() {
+	for i := 0; i < 4; i++ {
+		suite.Equal(202, suite.forgot("rep@example.com").StatusCode)
+	}
+	suite.Len(suite.mailbox.Messages(), 2)
+}
+
+// Invalid tokens and weak passwords are rejected
+func (suite *PasswordResetTestSuite) TestReset_Validation() {
+	suite.Equal(400, suite.reset("bogus", "new password").StatusCode)
+
+	suite.forgot("rep@example.com")
+	suite.Equal(400, suite.reset(suite.mailbox.LastResetToken(), "short").StatusCode)
+}
+
+func TestPasswordResetTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetTestSuite))
+}
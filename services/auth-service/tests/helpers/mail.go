@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"crm-platform/auth-service/internal/mail"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Pages the test service links to
+const (
+	TestResetURL  = "https://app.test.local/reset-password"
+	TestVerifyURL = "https://app.test.local/verify-email"
+)
+
+var (
+	resetLinkPattern  = regexp.MustCompile(`https://app\.test\.local/reset-password\?\S+`)
+	verifyLinkPattern = regexp.MustCompile(`https://app\.test\.local/verify-email\?\S+`)
+)
+
+// TestMailbox is a FileSender whose messages the test can read back
+type TestMailbox struct {
+	*mail.FileSender
+	t *testing.T
+}
+
+// NewTestMailbox writes mail into a temp dir
+func NewTestMailbox(t *testing.T) *TestMailbox {
+	sender, err := mail.NewFileSender(t.TempDir(), "no-reply@test.local")
+	require.NoError(t, err)
+	return &TestMailbox{FileSender: sender, t: t}
+}
+
+// Messages returns the raw messages in the order they were written
+func (m *TestMailbox) Messages() []string {
+	paths, err := filepath.Glob(filepath.Join(m.Dir, "*.eml"))
+	require.NoError(m.t, err)
+	sort.Strings(paths)
+
+	messages := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(m.t, err)
+		messages = append(messages, string(data))
+	}
+	return messages
+}
+
+// LastResetToken extracts the token from the newest reset link, requiring one
+func (m *TestMailbox) LastResetToken() string {
+	return m.lastToken(resetLinkPattern, "reset")
+}
+
+// LastVerificationToken extracts the token from the newest verification link, requiring one
+func (m *TestMailbox) LastVerificationToken() string {
+	return m.lastToken(verifyLinkPattern, "verification")
+}
+
+// lastToken extracts the token query parameter of the newest message's link
+func (m *TestMailbox) lastToken(pattern *regexp.Regexp, kind string) string {
+	messages := m.Messages()
+	require.NotEmpty(m.t, messages, "no mail sent")
+
+	link := pattern.FindString(messages[len(messages)-1])
+	require.NotEmpty(m.t, link, "no %s link in mail", kind)
+	parsed, err := url.Parse(link)
+	require.NoError(m.t, err)
+	return parsed.Query().Get("token")
+}
@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryMFAPolicy is an in-memory auth.MFAPolicy standing in for the tenant registry
+type MemoryMFAPolicy struct {
+	mu    sync.Mutex
+	roles map[string][]string
+}
+
+// NewMemoryMFAPolicy creates a policy that requires MFA of nobody
+func NewMemoryMFAPolicy() *MemoryMFAPolicy {
+	return &MemoryMFAPolicy{roles: make(map[string][]string)}
+}
+
+func (p *MemoryMFAPolicy) MFARequiredRoles(ctx context.Context, tenantID string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.roles[tenantID]), nil
+}
+
+func (p *MemoryMFAPolicy) SetMFARequiredRoles(ctx context.Context, tenantID string, roles []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles[tenantID] = slices.Clone(roles)
+	return nil
+}
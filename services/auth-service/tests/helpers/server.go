@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/internal/handlers"
+	"crm-platform/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// Test token settings shared by the auth tests
+const (
+	TestTenantID    = "01HK153X003BMPJNJB6JHKXK8T"
+	TestJWTSecret   = "auth-service-test-secret"
+	TestJWTIssuer   = "https://auth.test.local"
+	TestJWTAudience = "crm-api"
+)
+
+// TestServer serves the auth routes over a MemoryStore
+type TestServer struct {
+	Router  *gin.Engine
+	Store   *MemoryStore
+	Service *auth.Service
+	Issuer  *auth.TokenIssuer
+	t       *testing.T
+}
+
+// TestResponse is a recorded response with its JSON body decoded
+type TestResponse struct {
+	StatusCode int
+	Body       map[string]interface{}
+}
+
+// SetupTestServer creates a server whose tokens are signed by issuer
+// The tenant comes from X-Tenant-ID, as behind the gateway in production.
+func SetupTestServer(t *testing.T, issuer *auth.TokenIssuer, opts auth.Options) *TestServer {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	store := NewMemoryStore(t)
+	service := auth.NewService(store, issuer, opts)
+	authHandler := handlers.NewAuthHandler(service, issuer)
+
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+	authGroup := router.Group("/api/v1/auth")
+	authGroup.Use(middleware.TenantResolutionMiddleware(middleware.HeaderTenantResolver("X-Tenant-ID")))
+	authGroup.Use(middleware.TenantMiddleware())
+	handlers.RegisterAuthRoutes(authGroup, authHandler)
+
+	return &TestServer{Router: router, Store: store, Service: service, Issuer: issuer, t: t}
+}
+
+// NewTestHMACIssuer returns an HS256 issuer using the test secret, issuer and audience
+func NewTestHMACIssuer() *auth.TokenIssuer {
+	return auth.NewHMACIssuer([]byte(TestJWTSecret), auth.IssuerOptions{Issuer: TestJWTIssuer, Audience: TestJWTAudience})
+}
+
+// Post sends a JSON body to path in the test tenant
+func (ts *TestServer) Post(path string, body interface{}) *TestResponse {
+	return ts.Send("POST", path, "", body)
+}
+
+// Send sends a request to path in the test tenant, with accessToken as the bearer
+// token unless empty and body as JSON unless nil
+func (ts *TestServer) Send(method, path, accessToken string, body interface{}) *TestResponse {
+	req := httptest.NewRequest(method, path, nil)
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(ts.t, err)
+		req = httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Tenant-ID", TestTenantID)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return ts.serve(req)
+}
+
+// Get requests path without a tenant
+func (ts *TestServer) Get(path string) *TestResponse {
+	return ts.serve(httptest.NewRequest("GET", path, nil))
+}
+
+func (ts *TestServer) serve(req *http.Request) *TestResponse {
+	recorder := httptest.NewRecorder()
+	ts.Router.ServeHTTP(recorder, req)
+
+	var body map[string]interface{}
+	if recorder.Body.Len() > 0 {
+		require.NoError(ts.t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	}
+	return &TestResponse{StatusCode: recorder.Code, Body: body}
+}
+
+// Login logs in and returns the access and refresh tokens, requiring success
+func (ts *TestServer) Login(email, password string) (string, string) {
+	resp := ts.Post("/api/v1/auth/login", map[string]string{"email": email, "password": password})
+	require.Equal(ts.t, 200, resp.StatusCode, resp.Body)
+	return resp.Body["access_token"].(string), resp.Body["refresh_token"].(string)
+}
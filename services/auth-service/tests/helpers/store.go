@@ -0,0 +1,438 @@
+package helpers
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"crm-platform/auth-service/internal/auth"
+	"crm-platform/auth-service/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// MemoryStore is an in-memory auth.Store for tests that need no database
+// Only the queries the auth service uses are implemented; others panic.
+type MemoryStore struct {
+	db.Querier
+
+	t        *testing.T
+	mu       sync.Mutex
+	nextID   int32
+	users    map[int32]*MemoryUser
+	sessions map[string]*db.AuthSession
+	tokens   map[string]*db.RefreshToken
+	resets   map[string]*db.PasswordResetToken
+	recovery map[int32]map[string]bool // user ID -> code hash -> used
+}
+
+// MemoryUser is a user row held by MemoryStore
+type MemoryUser struct {
+	ID           int32
+	Email        string
+	FirstName    string
+	PasswordHash string
+	Role         string
+	Active       bool
+	LastLogin    *time.Time
+
+	EmailVerified      bool
+	VerificationSentAt *time.Time
+
+	MFAEnabled  bool
+	MFASecret   *string // sealed, as stored in mfa_secret
+	MFALastStep *int64
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore(t *testing.T) *MemoryStore {
+	return &MemoryStore{
+		t:        t,
+		users:    make(map[int32]*MemoryUser),
+		sessions: make(map[string]*db.AuthSession),
+		tokens:   make(map[string]*db.RefreshToken),
+		resets:   make(map[string]*db.PasswordResetToken),
+		recovery: make(map[int32]map[string]bool),
+	}
+}
+
+// AddUser stores an active user with a bcrypt hash of password
+func (s *MemoryStore) AddUser(email, password, role string) *MemoryUser {
+	hash, err := auth.HashPassword(password)
+	require.NoError(s.t, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	user := &MemoryUser{ID: s.nextID, Email: email, FirstName: "Test", PasswordHash: hash, Role: role, Active: true}
+	s.users[user.ID] = user
+	return user
+}
+
+// SetActive activates or deactivates a user
+func (s *MemoryStore) SetActive(userID int32, active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[userID].Active = active
+}
+
+// Session returns a copy of a stored session
+func (s *MemoryStore) Session(id string) (db.AuthSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return db.AuthSession{}, false
+	}
+	return *session, true
+}
+
+// User returns a copy of a stored user
+func (s *MemoryStore) User(id int32) MemoryUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.users[id]
+}
+
+// UnusedRecoveryCodes returns how many recovery codes a user has left
+func (s *MemoryStore) UnusedRecoveryCodes(userID int32) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var left int
+	for _, used := range s.recovery[userID] {
+		if !used {
+			left++
+		}
+	}
+	return left
+}
+
+// WithTx runs fn against the store itself (no rollback)
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(q db.Querier) error) error {
+	return fn(s)
+}
+
+func (s *MemoryStore) GetUserForAuth(ctx context.Context, email string) (db.GetUserForAuthRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if user.Email == email && user.Active {
+			active := true
+			verified := user.EmailVerified
+			return db.GetUserForAuthRow{ID: user.ID, PasswordHash: user.PasswordHash, Role: user.Role, Active: &active, EmailVerified: &verified, MfaEnabled: user.MFAEnabled}, nil
+		}
+	}
+	return db.GetUserForAuthRow{}, pgx.ErrNoRows
+}
+
+func (s *MemoryStore) GetUserByEmail(ctx context.Context, email string) (db.GetUserByEmailRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if user.Email == email && user.Active {
+			active := true
+			verified := user.EmailVerified
+			return db.GetUserByEmailRow{ID: user.ID, Email: user.Email, FirstName: user.FirstName, Role: user.Role, Active: &active, EmailVerified: &verified}, nil
+		}
+	}
+	return db.GetUserByEmailRow{}, pgx.ErrNoRows
+}
+
+func (s *MemoryStore) GetUserByID(ctx context.Context, id int32) (db.GetUserByIDRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return db.GetUserByIDRow{}, pgx.ErrNoRows
+	}
+	active, verified := user.Active, user.EmailVerified
+	return db.GetUserByIDRow{ID: user.ID, Email: user.Email, Role: user.Role, Active: &active, EmailVerified: &verified}, nil
+}
+
+func (s *MemoryStore) VerifyUserEmail(ctx context.Context, arg db.VerifyUserEmailParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[arg.ID].EmailVerified = true
+	return nil
+}
+
+func (s *MemoryStore) MarkVerificationEmailSent(ctx context.Context, arg db.MarkVerificationEmailSentParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[arg.ID]
+	if user.EmailVerified || (user.VerificationSentAt != nil && !user.VerificationSentAt.Before(arg.ResendBefore.Time)) {
+		return 0, nil
+	}
+	sentAt := arg.SentAt.Time
+	user.VerificationSentAt = &sentAt
+	return 1, nil
+}
+
+func (s *MemoryStore) UpdateUserLastLogin(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.users[id].LastLogin = &now
+	return nil
+}
+
+func (s *MemoryStore) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[arg.ID].PasswordHash = arg.PasswordHash
+	return nil
+}
+
+func (s *MemoryStore) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.AuthSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := &db.AuthSession{
+		ID:        arg.ID,
+		UserID:    arg.UserID,
+		UserAgent: arg.UserAgent,
+		IpAddress: arg.IpAddress,
+		CreatedAt: time.Now(),
+		ExpiresAt: arg.ExpiresAt,
+	}
+	s.sessions[arg.ID] = session
+	return *session, nil
+}
+
+func (s *MemoryStore) RevokeSession(ctx context.Context, arg db.RevokeSessionParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[arg.ID]
+	if !ok || session.RevokedAt.Valid {
+		return 0, nil
+	}
+	session.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	session.RevokedReason = arg.RevokedReason
+	return 1, nil
+}
+
+func (s *MemoryStore) RevokeUserSessions(ctx context.Context, arg db.RevokeUserSessionsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var revoked int64
+	for _, session := range s.sessions {
+		if session.UserID == arg.UserID && !session.RevokedAt.Valid {
+			session.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			session.RevokedReason = arg.RevokedReason
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (s *MemoryStore) CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[arg.TokenHash] = &db.RefreshToken{
+		SessionID: arg.SessionID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetRefreshToken(ctx context.Context, tokenHash string) (db.GetRefreshTokenRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return db.GetRefreshTokenRow{}, pgx.ErrNoRows
+	}
+	session := s.sessions[token.SessionID]
+	return db.GetRefreshTokenRow{
+		SessionID:        token.SessionID,
+		ExpiresAt:        token.ExpiresAt,
+		UsedAt:           token.UsedAt,
+		UserID:           session.UserID,
+		SessionExpiresAt: session.ExpiresAt,
+		RevokedAt:        session.RevokedAt,
+	}, nil
+}
+
+func (s *MemoryStore) UseRefreshToken(ctx context.Context, tokenHash string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[tokenHash]
+	if !ok || token.UsedAt.Valid {
+		return 0, nil
+	}
+	token.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return 1, nil
+}
+
+func (s *MemoryStore) CountRecentPasswordResetTokens(ctx context.Context, arg db.CountRecentPasswordResetTokensParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, reset := range s.resets {
+		if reset.UserID == arg.UserID && reset.CreatedAt.After(arg.CreatedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryStore) CreatePasswordResetToken(ctx context.Context, arg db.CreatePasswordResetTokenParams) (db.CreatePasswordResetTokenRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reset := &db.PasswordResetToken{
+		ID:        int32(len(s.resets) + 1),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	s.resets[arg.TokenHash] = reset
+	return db.CreatePasswordResetTokenRow{ID: reset.ID, ExpiresAt: reset.ExpiresAt, CreatedAt: reset.CreatedAt}, nil
+}
+
+func (s *MemoryStore) GetPasswordResetToken(ctx context.Context, tokenHash string) (db.GetPasswordResetTokenRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reset, ok := s.resets[tokenHash]
+	if !ok || reset.UsedAt.Valid || !time.Now().Before(reset.ExpiresAt) || !s.users[reset.UserID].Active {
+		return db.GetPasswordResetTokenRow{}, pgx.ErrNoRows
+	}
+	user := s.users[reset.UserID]
+	active := true
+	return db.GetPasswordResetTokenRow{
+		ID:        reset.ID,
+		UserID:    reset.UserID,
+		ExpiresAt: reset.ExpiresAt,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		Active:    &active,
+	}, nil
+}
+
+func (s *MemoryStore) MarkPasswordResetTokenUsed(ctx context.Context, tokenHash string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reset, ok := s.resets[tokenHash]
+	if !ok || reset.UsedAt.Valid {
+		return 0, nil
+	}
+	reset.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return 1, nil
+}
+
+func (s *MemoryStore) InvalidatePasswordResetTokens(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, reset := range s.resets {
+		if reset.UserID == userID && !reset.UsedAt.Valid {
+			reset.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetUserMFA(ctx context.Context, id int32) (db.GetUserMFARow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok || !user.Active {
+		return db.GetUserMFARow{}, pgx.ErrNoRows
+	}
+	active, verified := true, user.EmailVerified
+	return db.GetUserMFARow{
+		ID:            user.ID,
+		Email:         user.Email,
+		Role:          user.Role,
+		Active:        &active,
+		EmailVerified: &verified,
+		MfaEnabled:    user.MFAEnabled,
+		MfaSecret:     user.MFASecret,
+		MfaLastStep:   user.MFALastStep,
+	}, nil
+}
+
+func (s *MemoryStore) SetMFASecret(ctx context.Context, arg db.SetMFASecretParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[arg.ID]
+	if user.MFAEnabled {
+		return 0, nil
+	}
+	user.MFASecret, user.MFALastStep = arg.MfaSecret, nil
+	return 1, nil
+}
+
+func (s *MemoryStore) EnableMFA(ctx context.Context, id int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[id]
+	if user.MFAEnabled || user.MFASecret == nil {
+		return 0, nil
+	}
+	user.MFAEnabled = true
+	return 1, nil
+}
+
+func (s *MemoryStore) DisableMFA(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[id]
+	user.MFAEnabled, user.MFASecret, user.MFALastStep = false, nil, nil
+	return nil
+}
+
+func (s *MemoryStore) UseMFAStep(ctx context.Context, arg db.UseMFAStepParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[arg.ID]
+	if user.MFALastStep != nil && *user.MFALastStep >= *arg.MfaLastStep {
+		return 0, nil
+	}
+	step := *arg.MfaLastStep
+	user.MFALastStep = &step
+	return 1, nil
+}
+
+func (s *MemoryStore) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recovery[arg.UserID] == nil {
+		s.recovery[arg.UserID] = make(map[string]bool)
+	}
+	s.recovery[arg.UserID][arg.CodeHash] = false
+	return nil
+}
+
+func (s *MemoryStore) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.recovery, userID)
+	return nil
+}
+
+func (s *MemoryStore) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	used, ok := s.recovery[arg.UserID][arg.CodeHash]
+	if !ok || used {
+		return 0, nil
+	}
+	s.recovery[arg.UserID][arg.CodeHash] = true
+	return 1, nil
+}
+
+func (s *MemoryStore) CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, used := range s.recovery[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
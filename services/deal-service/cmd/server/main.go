@@ -6,10 +6,14 @@ import (
 	"os"
 	"time"
 
+	"crm-platform/pkg/authz"
+	"crm-platform/pkg/config"
 	"crm-platform/pkg/database"
+	"crm-platform/pkg/health"
 	"crm-platform/deal-service/internal/errors"
 	"crm-platform/deal-service/internal/handlers"
 	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,10 +36,10 @@ func getServerPort() string {
 // SETUP FUNCTIONS
 // =============================================================================
 
-// Initialize database connection with retry logic
-func setupDatabase() (*database.Pool, error) {
-	// Load config from environment
-	config, err := database.LoadConfigFromEnv()
+// Initialize database connection with backoff retries (canceled by the startup timeout)
+func setupDatabase(cfg *config.Config) (*database.Pool, error) {
+	// Build pool config from the loaded settings
+	poolConfig, err := cfg.PoolConfig()
 	if err != nil {
 		return nil, errors.ErrDatabase("failed to load database config: " + err.Error())
 	}
@@ -44,15 +48,18 @@ func setupDatabase() (*database.Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	pool, err := database.NewPool(ctx, config)
+	pool, err := database.NewPool(ctx, poolConfig)
 	if err != nil {
 		return nil, errors.ErrDatabase("failed to create connection pool: " + err.Error())
 	}
 
-	// Test connection with ping
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, errors.ErrDatabase("failed to ping database: " + err.Error())
+	// Trace every statement as a child of the request span
+	pool.AddQueryObserver(database.NewTracingObserver(nil))
+
+	// With DB_BACKGROUND_CONNECT the service starts degraded and /health reports it
+	if pool.Degraded() {
+		log.Println("Database unavailable, starting degraded and reconnecting in background")
+		return pool, nil
 	}
 
 	log.Println("Database connection established successfully")
@@ -60,23 +67,66 @@ func setupDatabase() (*database.Pool, error) {
 }
 
 // Initialize all handlers with database dependencies
-func setupHandlers(pool *database.Pool) (*handlers.DealHandler, *handlers.SystemHandler) {
+func setupHandlers(pool *database.Pool, cfg *config.Config) (*handlers.DealHandler, *handlers.SystemHandler) {
 	// Create handler instances
-	dealHandler := handlers.NewDealHandler(pool)
+	dealHandler := handlers.NewDealHandler(pool, cfg)
 	systemHandler := handlers.NewSystemHandler(pool)
 	
 	log.Println("Handlers initialized successfully")
 	return dealHandler, systemHandler
 }
 
+// Expose Kubernetes probes: /livez for liveness, /readyz for readiness
+// Readiness results are cached briefly so frequent probes don't load the database.
+func setupProbes(router *gin.Engine, cfg *config.Config, pool *database.Pool) error {
+	checker, err := health.NewServiceChecker("deal-service", pool, cfg)
+	if err != nil {
+		return errors.ErrHandler("failed to set up health checks: " + err.Error())
+	}
+	router.GET("/livez", gin.WrapH(checker.LivenessHandler()))  // GET /livez
+	router.GET("/readyz", gin.WrapH(checker.ReadinessHandler())) // GET /readyz
+	return nil
+}
+
+// Expose database metrics for Prometheus
+// Registered before the middleware stack so scrapers need no tenant credentials.
+func setupMetrics(router *gin.Engine, pool *database.Pool) {
+	exporter := database.NewExporter(pool, database.ExporterOptions{
+		Service:      "deal-service",
+		QueryLabels:  true,
+		TenantLabels: config.GetMetricsTenantLabels(),
+	})
+	router.GET("/metrics", gin.WrapH(exporter)) // GET /metrics
+}
+
 // Setup middleware stack in correct order
-func setupMiddleware(router *gin.Engine) {
+func setupMiddleware(router *gin.Engine, cfg *config.Config, pool *database.Pool) {
 	// Add middleware in critical order
+	// Tracing wraps everything so rejected requests are traced too
+	router.Use(middleware.TracingMiddleware("deal-service"))
+
 	// Auth middleware first - validates JWT and sets user context
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(cfg))
 	
-	// Tenant middleware second - converts tenant ID to request context
-	router.Use(middleware.TenantMiddleware())
+	// Tenant resolution second - token tenant must match the host tenant
+	subdomains := tenant.NewCachedSubdomainLookup(tenant.NewRegistrySubdomainLookup(pool), tenant.DefaultLookupCacheTTL)
+	router.Use(middleware.TenantResolutionMiddleware(
+		middleware.ClaimTenantResolver(),
+		middleware.SubdomainTenantResolver(config.GetTenantBaseDomain(), subdomains, "www", "api", "app"),
+		middleware.DomainTenantResolver(config.GetTenantCustomDomains()),
+	))
+
+	// Tenant middleware third - converts tenant ID to request context
+	// and blocks suspended or pending tenants (status cached in-process)
+	statusResolver := tenant.NewCachedStatusResolver(tenant.NewRegistryStatusResolver(pool), tenant.DefaultStatusCacheTTL)
+	router.Use(middleware.TenantStatusMiddleware(statusResolver))
+
+	// Role permissions fourth - expands the user's role, with per-tenant overrides
+	roleOverrides := tenant.NewCachedRoleOverrides(tenant.NewRegistryRoleOverrides(pool), tenant.DefaultRolesCacheTTL)
+	router.Use(middleware.RolePermissionsMiddleware(middleware.NewPermissionPolicy(nil, roleOverrides)))
+
+	// Record scope fifth - limits sales reps to their own deals and managers to their team's
+	router.Use(authz.ScopeMiddleware(authz.NewPolicy(authz.NewCachedTeamLookup(authz.NewUserTeamLookup(tenant.NewTenantPool(pool, cfg)), authz.DefaultTeamCacheTTL))))
 	
 	log.Println("Middleware configured successfully")
 }
@@ -89,18 +139,8 @@ func setupRoutes(router *gin.Engine, dealHandler *handlers.DealHandler, systemHa
 	// Create API version groups
 	v1 := router.Group("/api/v1")
 	
-	// Register deal endpoints
-	deals := v1.Group("/deals")
-	{
-		deals.POST("", dealHandler.CreateDeal)           		// POST /api/v1/deals
-		deals.GET("", dealHandler.ListDeals)             		// GET /api/v1/deals
-		deals.GET("/pipeline", dealHandler.GetPipelineView) 	// GET /api/v1/deals/pipeline
-		deals.GET("/owner/:id", dealHandler.GetDealsByOwner) 	// GET /api/v1/deals/owner/:id
-		deals.GET("/:id", dealHandler.GetDeal)           		// GET /api/v1/deals/:id
-		deals.PUT("/:id", dealHandler.UpdateDeal)        		// PUT /api/v1/deals/:id
-		deals.PUT("/:id/close", dealHandler.CloseDeal)   		// PUT /api/v1/deals/:id/close
-		deals.DELETE("/:id", dealHandler.DeleteDeal)     		// DELETE /api/v1/deals/:id
-	}
+	// Register deal endpoints (each requires a deals:* permission)
+	handlers.RegisterDealRoutes(v1.Group("/deals"), dealHandler)
 	
 	log.Println("Routes registered successfully")
 }
@@ -115,18 +155,30 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 	
+	// Load and validate configuration (defaults, CONFIG_FILE, env, secret files)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// Setup database connection
-	pool, err := setupDatabase()
+	pool, err := setupDatabase(cfg)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 	defer pool.Close()
 	
+	// Setup metrics and probe endpoints
+	setupMetrics(router, pool)
+	if err := setupProbes(router, cfg, pool); err != nil {
+		log.Fatal(err.Error())
+	}
+	
 	// Setup middleware stack
-	setupMiddleware(router)
+	setupMiddleware(router, cfg, pool)
 	
 	// Setup handlers
-	dealHandler, systemHandler := setupHandlers(pool)
+	dealHandler, systemHandler := setupHandlers(pool, cfg)
 	
 	// Setup routes
 	setupRoutes(router, dealHandler, systemHandler)
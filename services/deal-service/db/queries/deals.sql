@@ -15,7 +15,8 @@ FROM deals d
 LEFT JOIN contacts c ON d.primary_contact_id = c.id
 LEFT JOIN companies comp ON d.company_id = comp.id
 LEFT JOIN users u ON d.owner_id = u.id AND u.status = 'active'
-WHERE d.id = $1;
+WHERE d.id = @id
+  AND (@unrestricted::boolean OR d.owner_id = ANY(@owner_ids::int[]));
 
 -- name: ListDeals :many
 SELECT d.*, 
@@ -26,19 +27,22 @@ FROM deals d
 LEFT JOIN contacts c ON d.primary_contact_id = c.id
 LEFT JOIN companies comp ON d.company_id = comp.id
 LEFT JOIN users u ON d.owner_id = u.id AND u.status = 'active'
+WHERE @unrestricted::boolean OR d.owner_id = ANY(@owner_ids::int[])
 ORDER BY d.created_at DESC
-LIMIT $1 OFFSET $2;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: CountDeals :one
-SELECT COUNT(*) FROM deals;
+SELECT COUNT(*) FROM deals
+WHERE @unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]);
 
 -- name: UpdateDeal :one
 UPDATE deals 
-SET title = $2, value = $3, probability = $4, stage = $5,
-    primary_contact_id = $6, company_id = $7, owner_id = $8,
-    expected_close_date = $9, source = $10, description = $11,
+SET title = @title, value = @value, probability = @probability, stage = @stage,
+    primary_contact_id = @primary_contact_id, company_id = @company_id,
+    expected_close_date = @expected_close_date, source = @source, description = @description,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = @id
+  AND (@unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]))
 RETURNING *;
 
 -- name: GetDealsByStage :many
@@ -47,6 +51,7 @@ SELECT stage, COUNT(*) as deal_count,
        COALESCE(SUM(value * probability / 100), 0) as weighted_value
 FROM deals 
 WHERE actual_close_date IS NULL
+  AND (@unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]))
 GROUP BY stage 
 ORDER BY 
     CASE stage
@@ -61,7 +66,8 @@ ORDER BY
 
 -- name: GetDealsByOwner :many
 SELECT * FROM deals 
-WHERE owner_id = $1 AND actual_close_date IS NULL
+WHERE owner_id = @owner_id AND actual_close_date IS NULL
+  AND (@unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]))
 ORDER BY expected_close_date ASC;
 
 -- name: GetMonthlyForecast :many
@@ -76,8 +82,9 @@ ORDER BY forecast_month;
 
 -- name: CloseDeal :one
 UPDATE deals 
-SET stage = $2, actual_close_date = $3, updated_at = NOW()
-WHERE id = $1
+SET stage = @stage, actual_close_date = @actual_close_date, updated_at = NOW()
+WHERE id = @id
+  AND (@unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]))
 RETURNING *;
 
 -- name: GetSalesRepPerformance :many
@@ -89,4 +96,6 @@ GROUP BY u.id, u.first_name, u.last_name
 ORDER BY total_revenue DESC;
 
 -- name: DeleteDeal :execrows
-DELETE FROM deals WHERE id = $1;
\ No newline at end of file
+DELETE FROM deals
+WHERE id = @id
+  AND (@unrestricted::boolean OR owner_id = ANY(@owner_ids::int[]));
\ No newline at end of file
@@ -13,10 +13,12 @@ CREATE TABLE users (
     updated_by INTEGER REFERENCES users(id),
     created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMPTZ
+    deleted_at TIMESTAMPTZ,
+    manager_id INTEGER REFERENCES users(id) ON DELETE SET NULL
 );
 
 -- Performance indexes
 CREATE INDEX idx_users_email ON users (email);
 CREATE INDEX idx_users_active ON users (active);
-CREATE INDEX idx_users_role ON users (role);
\ No newline at end of file
+CREATE INDEX idx_users_role ON users (role);
+CREATE INDEX idx_users_manager_id ON users (manager_id);
\ No newline at end of file
@@ -15,19 +15,28 @@ import (
 
 const closeDeal = `-- name: CloseDeal :one
 UPDATE deals 
-SET stage = $2, actual_close_date = $3, updated_at = NOW()
-WHERE id = $1
+SET stage = $1, actual_close_date = $2, updated_at = NOW()
+WHERE id = $3
+  AND ($4::boolean OR owner_id = ANY($5::int[]))
 RETURNING id, title, description, value, currency, stage, probability, expected_close_date, actual_close_date, owner_id, company_id, primary_contact_id, source, close_reason, custom_fields, created_at, updated_at, created_by
 `
 
 type CloseDealParams struct {
-	ID              int32        `json:"id"`
 	Stage           string       `json:"stage"`
 	ActualCloseDate sql.NullTime `json:"actual_close_date"`
+	ID              int32        `json:"id"`
+	Unrestricted    bool         `json:"unrestricted"`
+	OwnerIds        []int32      `json:"owner_ids"`
 }
 
 func (q *Queries) CloseDeal(ctx context.Context, arg CloseDealParams) (Deal, error) {
-	row := q.db.QueryRow(ctx, closeDeal, arg.ID, arg.Stage, arg.ActualCloseDate)
+	row := q.db.QueryRow(ctx, closeDeal,
+		arg.Stage,
+		arg.ActualCloseDate,
+		arg.ID,
+		arg.Unrestricted,
+		arg.OwnerIds,
+	)
 	var i Deal
 	err := row.Scan(
 		&i.ID,
@@ -54,10 +63,16 @@ func (q *Queries) CloseDeal(ctx context.Context, arg CloseDealParams) (Deal, err
 
 const countDeals = `-- name: CountDeals :one
 SELECT COUNT(*) FROM deals
+WHERE $1::boolean OR owner_id = ANY($2::int[])
 `
 
-func (q *Queries) CountDeals(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countDeals)
+type CountDealsParams struct {
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+}
+
+func (q *Queries) CountDeals(ctx context.Context, arg CountDealsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeals, arg.Unrestricted, arg.OwnerIds)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -125,11 +140,19 @@ func (q *Queries) CreateDeal(ctx context.Context, arg CreateDealParams) (Deal, e
 }
 
 const deleteDeal = `-- name: DeleteDeal :execrows
-DELETE FROM deals WHERE id = $1
+DELETE FROM deals
+WHERE id = $1
+  AND ($2::boolean OR owner_id = ANY($3::int[]))
 `
 
-func (q *Queries) DeleteDeal(ctx context.Context, id int32) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteDeal, id)
+type DeleteDealParams struct {
+	ID           int32   `json:"id"`
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+}
+
+func (q *Queries) DeleteDeal(ctx context.Context, arg DeleteDealParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDeal, arg.ID, arg.Unrestricted, arg.OwnerIds)
 	if err != nil {
 		return 0, err
 	}
@@ -146,8 +169,15 @@ LEFT JOIN contacts c ON d.primary_contact_id = c.id
 LEFT JOIN companies comp ON d.company_id = comp.id
 LEFT JOIN users u ON d.owner_id = u.id AND u.status = 'active'
 WHERE d.id = $1
+  AND ($2::boolean OR d.owner_id = ANY($3::int[]))
 `
 
+type GetDealByIDParams struct {
+	ID           int32   `json:"id"`
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+}
+
 type GetDealByIDRow struct {
 	ID                 int32          `json:"id"`
 	Title              string         `json:"title"`
@@ -172,8 +202,8 @@ type GetDealByIDRow struct {
 	OwnerName          interface{}    `json:"owner_name"`
 }
 
-func (q *Queries) GetDealByID(ctx context.Context, id int32) (GetDealByIDRow, error) {
-	row := q.db.QueryRow(ctx, getDealByID, id)
+func (q *Queries) GetDealByID(ctx context.Context, arg GetDealByIDParams) (GetDealByIDRow, error) {
+	row := q.db.QueryRow(ctx, getDealByID, arg.ID, arg.Unrestricted, arg.OwnerIds)
 	var i GetDealByIDRow
 	err := row.Scan(
 		&i.ID,
@@ -204,11 +234,18 @@ func (q *Queries) GetDealByID(ctx context.Context, id int32) (GetDealByIDRow, er
 const getDealsByOwner = `-- name: GetDealsByOwner :many
 SELECT id, title, description, value, currency, stage, probability, expected_close_date, actual_close_date, owner_id, company_id, primary_contact_id, source, close_reason, custom_fields, created_at, updated_at, created_by FROM deals 
 WHERE owner_id = $1 AND actual_close_date IS NULL
+  AND ($2::boolean OR owner_id = ANY($3::int[]))
 ORDER BY expected_close_date ASC
 `
 
-func (q *Queries) GetDealsByOwner(ctx context.Context, ownerID *int32) ([]Deal, error) {
-	rows, err := q.db.Query(ctx, getDealsByOwner, ownerID)
+type GetDealsByOwnerParams struct {
+	OwnerID      *int32  `json:"owner_id"`
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+}
+
+func (q *Queries) GetDealsByOwner(ctx context.Context, arg GetDealsByOwnerParams) ([]Deal, error) {
+	rows, err := q.db.Query(ctx, getDealsByOwner, arg.OwnerID, arg.Unrestricted, arg.OwnerIds)
 	if err != nil {
 		return nil, err
 	}
@@ -252,6 +289,7 @@ SELECT stage, COUNT(*) as deal_count,
        COALESCE(SUM(value * probability / 100), 0) as weighted_value
 FROM deals 
 WHERE actual_close_date IS NULL
+  AND ($1::boolean OR owner_id = ANY($2::int[]))
 GROUP BY stage 
 ORDER BY 
     CASE stage
@@ -265,6 +303,11 @@ ORDER BY
     END
 `
 
+type GetDealsByStageParams struct {
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+}
+
 type GetDealsByStageRow struct {
 	Stage         string      `json:"stage"`
 	DealCount     int64       `json:"deal_count"`
@@ -272,8 +315,8 @@ type GetDealsByStageRow struct {
 	WeightedValue interface{} `json:"weighted_value"`
 }
 
-func (q *Queries) GetDealsByStage(ctx context.Context) ([]GetDealsByStageRow, error) {
-	rows, err := q.db.Query(ctx, getDealsByStage)
+func (q *Queries) GetDealsByStage(ctx context.Context, arg GetDealsByStageParams) ([]GetDealsByStageRow, error) {
+	rows, err := q.db.Query(ctx, getDealsByStage, arg.Unrestricted, arg.OwnerIds)
 	if err != nil {
 		return nil, err
 	}
@@ -384,13 +427,16 @@ FROM deals d
 LEFT JOIN contacts c ON d.primary_contact_id = c.id
 LEFT JOIN companies comp ON d.company_id = comp.id
 LEFT JOIN users u ON d.owner_id = u.id AND u.status = 'active'
+WHERE $1::boolean OR d.owner_id = ANY($2::int[])
 ORDER BY d.created_at DESC
-LIMIT $1 OFFSET $2
+LIMIT $3 OFFSET $4
 `
 
 type ListDealsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Unrestricted bool    `json:"unrestricted"`
+	OwnerIds     []int32 `json:"owner_ids"`
+	Limit        int32   `json:"limit"`
+	Offset       int32   `json:"offset"`
 }
 
 type ListDealsRow struct {
@@ -418,7 +464,12 @@ type ListDealsRow struct {
 }
 
 func (q *Queries) ListDeals(ctx context.Context, arg ListDealsParams) ([]ListDealsRow, error) {
-	rows, err := q.db.Query(ctx, listDeals, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listDeals,
+		arg.Unrestricted,
+		arg.OwnerIds,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -461,41 +512,44 @@ func (q *Queries) ListDeals(ctx context.Context, arg ListDealsParams) ([]ListDea
 
 const updateDeal = `-- name: UpdateDeal :one
 UPDATE deals 
-SET title = $2, value = $3, probability = $4, stage = $5,
-    primary_contact_id = $6, company_id = $7, owner_id = $8,
-    expected_close_date = $9, source = $10, description = $11,
+SET title = $1, value = $2, probability = $3, stage = $4,
+    primary_contact_id = $5, company_id = $6,
+    expected_close_date = $7, source = $8, description = $9,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $10
+  AND ($11::boolean OR owner_id = ANY($12::int[]))
 RETURNING id, title, description, value, currency, stage, probability, expected_close_date, actual_close_date, owner_id, company_id, primary_contact_id, source, close_reason, custom_fields, created_at, updated_at, created_by
 `
 
 type UpdateDealParams struct {
-	ID                int32          `json:"id"`
 	Title             string         `json:"title"`
 	Value             pgtype.Numeric `json:"value"`
 	Probability       *int32         `json:"probability"`
 	Stage             string         `json:"stage"`
 	PrimaryContactID  *int32         `json:"primary_contact_id"`
 	CompanyID         *int32         `json:"company_id"`
-	OwnerID           *int32         `json:"owner_id"`
 	ExpectedCloseDate sql.NullTime   `json:"expected_close_date"`
 	Source            *string        `json:"source"`
 	Description       *string        `json:"description"`
+	ID                int32          `json:"id"`
+	Unrestricted      bool           `json:"unrestricted"`
+	OwnerIds          []int32        `json:"owner_ids"`
 }
 
 func (q *Queries) UpdateDeal(ctx context.Context, arg UpdateDealParams) (Deal, error) {
 	row := q.db.QueryRow(ctx, updateDeal,
-		arg.ID,
 		arg.Title,
 		arg.Value,
 		arg.Probability,
 		arg.Stage,
 		arg.PrimaryContactID,
 		arg.CompanyID,
-		arg.OwnerID,
 		arg.ExpectedCloseDate,
 		arg.Source,
 		arg.Description,
+		arg.ID,
+		arg.Unrestricted,
+		arg.OwnerIds,
 	)
 	var i Deal
 	err := row.Scan(
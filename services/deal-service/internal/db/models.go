@@ -93,4 +93,5 @@ type User struct {
 	CreatedAt     time.Time          `json:"created_at"`
 	UpdatedAt     time.Time          `json:"updated_at"`
 	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
+	ManagerID     *int32             `json:"manager_id"`
 }
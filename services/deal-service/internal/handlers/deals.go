@@ -4,6 +4,8 @@ import (
 	"crm-platform/deal-service/internal/db"
 	"crm-platform/deal-service/internal/errors"
 	"crm-platform/deal-service/internal/models"
+	"crm-platform/pkg/authz"
+	"crm-platform/pkg/config"
 	"crm-platform/pkg/database"
 	"crm-platform/pkg/tenant"
 	"database/sql"
@@ -25,9 +27,9 @@ type DealHandler struct {
 }
 
 // Create new deal handler with tenant-aware database dependencies
-func NewDealHandler(pool *database.Pool) *DealHandler {
+func NewDealHandler(pool *database.Pool, cfg *config.Config) *DealHandler {
 	return &DealHandler{
-		tenantPool: tenant.NewTenantPool(pool),
+		tenantPool: tenant.NewTenantPool(pool, cfg),
 	}
 }
 
@@ -83,9 +85,19 @@ func (h *DealHandler) GetDeal(c *gin.Context) {
 		return
 	}
 
-	// 2. Query deal with related data using SQLC and automatic tenant isolation
+	// 2. Limit the lookup to deals the user may access (others are not found)
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
+
+	// 3. Query deal with related data using SQLC and automatic tenant isolation
 	queries := db.New(h.tenantPool)
-	deal, err := queries.GetDealByID(c.Request.Context(), int32(dealID))
+	deal, err := queries.GetDealByID(c.Request.Context(), db.GetDealByIDParams{
+		ID:           int32(dealID),
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+	})
 	if err != nil {
 		// Check for both sql.ErrNoRows and pgx.ErrNoRows
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
@@ -96,10 +108,10 @@ func (h *DealHandler) GetDeal(c *gin.Context) {
 		return
 	}
 
-	// 3. Convert to response with calculated fields
+	// 4. Convert to response with calculated fields
 	response := h.convertToResponse(deal)
 
-	// 4. Return JSON response
+	// 5. Return JSON response
 	c.JSON(200, response)
 }
 
@@ -126,8 +138,14 @@ func (h *DealHandler) UpdateDeal(c *gin.Context) {
 		return
 	}
 
-	// 4. Convert to SQLC update params
+	// 4. Convert to SQLC update params, limited to deals the user may access
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
 	params := h.convertToUpdateParams(int32(dealID), req, userID)
+	params.Unrestricted = scope.Unrestricted
+	params.OwnerIds = scope.Owners()
 
 	// 5. Execute update operation with automatic tenant isolation
 	queries := db.New(h.tenantPool)
@@ -159,11 +177,17 @@ func (h *DealHandler) ListDeals(c *gin.Context) {
 	// 2. Set default pagination values
 	offset, limit := calculatePagination(query.Page, query.Limit)
 
-	// 3. Execute paginated query with automatic tenant isolation
+	// 3. Execute paginated query over the deals the user may access
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
 	queries := db.New(h.tenantPool)
 	deals, err := queries.ListDeals(c.Request.Context(), db.ListDealsParams{
-		Limit:  limit,
-		Offset: offset,
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+		Limit:        limit,
+		Offset:       offset,
 	})
 	if err != nil {
 		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to list deals").Error()})
@@ -213,7 +237,10 @@ func (h *DealHandler) ListDeals(c *gin.Context) {
 	}
 
 	// 5. Get total count for proper pagination
-	totalCount, err := queries.CountDeals(c.Request.Context())
+	totalCount, err := queries.CountDeals(c.Request.Context(), db.CountDealsParams{
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+	})
 	if err != nil {
 		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to count deals").Error()})
 		return
@@ -241,9 +268,17 @@ func (h *DealHandler) ListDeals(c *gin.Context) {
 
 // Get pipeline view with stage analytics and automatic tenant isolation
 func (h *DealHandler) GetPipelineView(c *gin.Context) {
-	// 1. Query deals by stage analytics with automatic tenant isolation
-	queries := db.New(h.tenantPool)
-	stageData, err := queries.GetDealsByStage(c.Request.Context())
+	// 1. Query deals by stage analytics over the deals the user may access
+	// Analytics tolerate replication lag, so a read replica may serve them
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
+	queries := db.New(h.tenantPool.Reader())
+	stageData, err := queries.GetDealsByStage(c.Request.Context(), db.GetDealsByStageParams{
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+	})
 	if err != nil {
 		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to get pipeline data: " + err.Error()).Error()})
 		return
@@ -288,10 +323,18 @@ func (h *DealHandler) GetDealsByOwner(c *gin.Context) {
 		return
 	}
 
-	// 2. Query deals by owner with automatic tenant isolation
+	// 2. Query deals by owner; owners outside the user's scope yield no deals
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
 	queries := db.New(h.tenantPool)
 	ownerID32 := int32(ownerID)
-	deals, err := queries.GetDealsByOwner(c.Request.Context(), &ownerID32)
+	deals, err := queries.GetDealsByOwner(c.Request.Context(), db.GetDealsByOwnerParams{
+		OwnerID:      &ownerID32,
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+	})
 	if err != nil {
 		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to get deals by owner").Error()})
 		return
@@ -337,12 +380,18 @@ func (h *DealHandler) CloseDeal(c *gin.Context) {
 		closeDate = &now
 	}
 
-	// 5. Execute close deal operation with automatic tenant isolation
+	// 5. Execute close deal operation, limited to deals the user may access
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
 	queries := db.New(h.tenantPool)
 	deal, err := queries.CloseDeal(c.Request.Context(), db.CloseDealParams{
-		ID:              int32(dealID),
 		Stage:           req.Stage,
 		ActualCloseDate: h.convertTimeToNullTime(closeDate),
+		ID:              int32(dealID),
+		Unrestricted:    scope.Unrestricted,
+		OwnerIds:        scope.Owners(),
 	})
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
@@ -368,15 +417,23 @@ func (h *DealHandler) DeleteDeal(c *gin.Context) {
 		return
 	}
 
-	// 2. Execute delete operation with automatic tenant isolation
+	// 2. Execute delete operation, limited to deals the user may access
+	scope, ok := extractScope(c)
+	if !ok {
+		return
+	}
 	queries := db.New(h.tenantPool)
-	rowsAffected, err := queries.DeleteDeal(c.Request.Context(), int32(dealID))
+	rowsAffected, err := queries.DeleteDeal(c.Request.Context(), db.DeleteDealParams{
+		ID:           int32(dealID),
+		Unrestricted: scope.Unrestricted,
+		OwnerIds:     scope.Owners(),
+	})
 	if err != nil {
 		c.JSON(500, gin.H{"error": errors.ErrDatabase("failed to delete deal").Error()})
 		return
 	}
 	
-	// Check if any rows were affected (deal found and deleted)
+	// Check if any rows were affected (deal found, in scope and deleted)
 	if rowsAffected == 0 {
 		c.JSON(404, gin.H{"error": errors.ErrDeal("deal not found").Error()})
 		return
@@ -413,6 +470,7 @@ func (h *DealHandler) convertToCreateParams(req models.CreateDealRequest, userID
 // Convert update request to SQLC parameters with user context
 func (h *DealHandler) convertToUpdateParams(dealID int32, req models.UpdateDealRequest, _ string) db.UpdateDealParams {
 	// Map partial update fields to SQLC UpdateDealParams
+	// Ownership is not changed by updates
 	dbReq := db.UpdateDealParams{
 		ID: dealID,
 		Title: func() string {
@@ -692,6 +750,16 @@ func extractUserID(c *gin.Context) string {
 	return id
 }
 
+// Extract record scope from request context (set by authz.ScopeMiddleware)
+// A missing scope fails closed instead of exposing every deal in the tenant.
+func extractScope(c *gin.Context) (authz.Scope, bool) {
+	scope, ok := authz.ScopeFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(500, gin.H{"error": errors.ErrHandler("could not extract record scope").Error()})
+	}
+	return scope, ok
+}
+
 // Convert pagination query to offset/limit for SQLC
 func calculatePagination(page, limit int) (int32, int32) {
 	if page < 1 {
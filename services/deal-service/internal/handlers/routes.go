@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"crm-platform/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register deal endpoints with the permission each one requires
+// Expects middleware.RolePermissionsMiddleware earlier in the chain.
+func RegisterDealRoutes(deals *gin.RouterGroup, h *DealHandler) {
+	read := middleware.RequirePermission("deals:read")
+	write := middleware.RequirePermission("deals:write")
+	remove := middleware.RequirePermission("deals:delete")
+
+	deals.POST("", write, h.CreateDeal)              // POST /api/v1/deals
+	deals.GET("", read, h.ListDeals)                 // GET /api/v1/deals
+	deals.GET("/pipeline", read, h.GetPipelineView)  // GET /api/v1/deals/pipeline
+	deals.GET("/owner/:id", read, h.GetDealsByOwner) // GET /api/v1/deals/owner/:id
+	deals.GET("/:id", read, h.GetDeal)               // GET /api/v1/deals/:id
+	deals.PUT("/:id", write, h.UpdateDeal)           // PUT /api/v1/deals/:id
+	deals.PUT("/:id/close", write, h.CloseDeal)      // PUT /api/v1/deals/:id/close
+	deals.DELETE("/:id", remove, h.DeleteDeal)       // DELETE /api/v1/deals/:id
+}
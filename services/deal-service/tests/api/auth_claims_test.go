@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+// AuthClaimsTestSuite checks that signed token claims reach handlers with their real types
+type AuthClaimsTestSuite struct {
+	suite.Suite
+	issuer   *helpers.TestTokenIssuer
+	key      helpers.TestSigningKey
+	router   *gin.Engine
+	tenantID string
+}
+
+// claimsView is what the test route reports about the request identity
+type claimsView struct {
+	UserID        string   `json:"user_id"`
+	TenantID      string   `json:"tenant_id"`
+	Role          string   `json:"role"`
+	SessionID     string   `json:"session_id"`
+	Permissions   []string `json:"permissions"`
+	CanWrite      bool     `json:"can_write"`
+	ContextUserID string   `json:"context_user_id"`
+}
+
+// SetupTest builds a router that echoes the claims seen through every accessor
+func (suite *AuthClaimsTestSuite) SetupTest() {
+	suite.tenantID = helpers.GetTestTenants()[0]
+
+	suite.issuer = helpers.NewTestTokenIssuer(suite.T())
+	suite.key = suite.issuer.NewRSAKey("rsa-1")
+	suite.issuer.Publish(suite.key)
+
+	verifier := middleware.NewJWTVerifier(middleware.JWTOptions{
+		JWKSURL:   suite.issuer.JWKSPath,
+		Issuer:    helpers.TestJWTIssuer,
+		Audience:  helpers.TestJWTAudience,
+		ClockSkew: 30 * time.Second,
+	})
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.Use(middleware.AuthMiddlewareWithVerifier(verifier))
+	suite.router.GET("/whoami", func(c *gin.Context) {
+		claims, ok := middleware.GetClaims(c)
+		suite.Require().True(ok)
+
+		view := claimsView{
+			UserID:      middleware.ExtractUserId(c),
+			TenantID:    claims.TenantID,
+			Role:        claims.Role,
+			SessionID:   claims.SessionID,
+			Permissions: middleware.ExtractPermissions(c),
+			CanWrite:    middleware.HasPermission(c, "deals:write"),
+		}
+		if ctxClaims, ok := middleware.ClaimsFromContext(c.Request.Context()); ok {
+			view.ContextUserID = ctxClaims.UserID
+		}
+		c.JSON(http.StatusOK, view)
+	})
+}
+
+// Permissions arrive as a JSON array and are returned as []string
+func (suite *AuthClaimsTestSuite) TestPermissions_FromSignedToken() {
+	claims := suite.issuer.Claims("user-7", suite.tenantID)
+	claims["role"] = "manager"
+	claims["sid"] = "session-1"
+	claims["user_permissions"] = []string{"deals:read", "deals:write"}
+
+	view := suite.whoami(claims, http.StatusOK)
+	suite.Equal([]string{"deals:read", "deals:write"}, view.Permissions)
+	suite.True(view.CanWrite)
+	suite.Equal("manager", view.Role)
+	suite.Equal("session-1", view.SessionID)
+	suite.Equal(suite.tenantID, view.TenantID)
+}
+
+// Numeric user IDs are exposed as strings, not floats
+func (suite *AuthClaimsTestSuite) TestUserID_Numeric() {
+	claims := suite.issuer.Claims("", suite.tenantID)
+	claims["user_id"] = 1234567
+	delete(claims, "sub")
+
+	view := suite.whoami(claims, http.StatusOK)
+	suite.Equal("1234567", view.UserID)
+	suite.Equal("1234567", view.ContextUserID)
+}
+
+// sub stands in for a missing user_id
+func (suite *AuthClaimsTestSuite) TestUserID_FallsBackToSubject() {
+	claims := suite.issuer.Claims("user-9", suite.tenantID)
+	delete(claims, "user_id")
+
+	view := suite.whoami(claims, http.StatusOK)
+	suite.Equal("user-9", view.UserID)
+	suite.Empty(view.Permissions)
+	suite.False(view.CanWrite)
+}
+
+// Tokens that do not identify a user and tenant, or carry malformed claims, are rejected
+func (suite *AuthClaimsTestSuite) TestInvalidClaims_Rejected() {
+	cases := map[string]func(jwt.MapClaims){
+		"no tenant":            func(c jwt.MapClaims) { delete(c, "tenant_id") },
+		"no user":              func(c jwt.MapClaims) { delete(c, "user_id"); delete(c, "sub") },
+		"fractional user id":   func(c jwt.MapClaims) { c["user_id"] = 12.5 },
+		"permissions not list": func(c jwt.MapClaims) { c["user_permissions"] = "deals:write" },
+	}
+
+	for name, mutate := range cases {
+		claims := suite.issuer.Claims("user-1", suite.tenantID)
+		mutate(claims)
+		suite.whoami(claims, http.StatusUnauthorized, name)
+	}
+}
+
+// The verifier returns the same typed claims outside of gin
+func (suite *AuthClaimsTestSuite) TestVerify_ReturnsTypedClaims() {
+	verifier := middleware.NewJWTVerifier(middleware.JWTOptions{JWKSURL: suite.issuer.JWKSPath})
+	claims := suite.issuer.Claims("42", suite.tenantID)
+	claims["user_permissions"] = []string{"contacts:read"}
+
+	parsed, err := verifier.Verify(context.Background(), suite.issuer.Sign(suite.key, claims))
+	suite.Require().NoError(err)
+	suite.Equal("42", parsed.UserID)
+	suite.True(parsed.HasPermission("contacts:read"))
+	suite.False(parsed.HasPermission("contacts:write"))
+
+	ctx := middleware.WithClaims(context.Background(), parsed)
+	fromCtx, ok := middleware.ClaimsFromContext(ctx)
+	suite.True(ok)
+	suite.Same(parsed, fromCtx)
+
+	_, ok = middleware.ClaimsFromContext(context.Background())
+	suite.False(ok)
+}
+
+// whoami signs claims, calls the test route and decodes its answer
+func (suite *AuthClaimsTestSuite) whoami(claims jwt.MapClaims, wantStatus int, msgAndArgs ...interface{}) claimsView {
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.issuer.Sign(suite.key, claims))
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, req)
+
+	suite.Require().Equal(wantStatus, recorder.Code, msgAndArgs...)
+	var view claimsView
+	if wantStatus == http.StatusOK {
+		suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &view))
+	}
+	return view
+}
+
+// Run the auth claims test suite
+func TestAuthClaimsTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthClaimsTestSuite))
+}
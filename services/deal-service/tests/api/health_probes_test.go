@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/health"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// HealthProbesTestSuite checks the /livez and /readyz handlers against the test database
+type HealthProbesTestSuite struct {
+	suite.Suite
+	db *helpers.TestDatabase
+}
+
+// SetupSuite connects to the test database
+func (suite *HealthProbesTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+}
+
+// TearDownSuite closes database connection
+func (suite *HealthProbesTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// Readiness passes when the database and template schema are present
+func (suite *HealthProbesTestSuite) TestReadyz_DependenciesUp() {
+	checker := health.NewChecker(health.Options{Service: "deal-service"})
+	checker.Register("database", health.DatabaseCheck(suite.db.Pool))
+	checker.Register("template_schema", health.TemplateSchemaCheck(suite.db.Pool))
+
+	report := suite.serve(checker.ReadinessHandler(), http.StatusOK)
+	suite.Equal(health.StatusOK, report.Status)
+	suite.Equal(health.StatusOK, report.Checks["database"].Status)
+	suite.Equal(health.StatusOK, report.Checks["template_schema"].Status)
+}
+
+// A failing dependency makes readiness 503 while liveness stays 200
+func (suite *HealthProbesTestSuite) TestReadyz_FailingCheck_LivezUnaffected() {
+	checker := health.NewChecker(health.Options{Service: "deal-service"})
+	checker.Register("database", health.DatabaseCheck(suite.db.Pool))
+	checker.Register("redis", health.RedisCheck("redis://127.0.0.1:1"))
+
+	report := suite.serve(checker.ReadinessHandler(), http.StatusServiceUnavailable)
+	suite.Equal(health.StatusFail, report.Status)
+	suite.Equal(health.StatusOK, report.Checks["database"].Status)
+	suite.Equal(health.StatusFail, report.Checks["redis"].Status)
+	suite.NotEmpty(report.Checks["redis"].Error)
+
+	live := suite.serve(checker.LivenessHandler(), http.StatusOK)
+	suite.Equal(health.StatusOK, live.Status)
+	suite.Empty(live.Checks)
+}
+
+// Readiness results are reused within the cache TTL
+func (suite *HealthProbesTestSuite) TestReadyz_CachesResults() {
+	calls := 0
+	checker := health.NewChecker(health.Options{})
+	checker.Register("counted", func(ctx context.Context) error {
+		calls++
+		return fmt.Errorf("call %d", calls)
+	})
+
+	for i := 0; i < 5; i++ {
+		checker.Ready(context.Background())
+	}
+	suite.Equal(1, calls)
+}
+
+// serve runs a probe handler and decodes its report
+func (suite *HealthProbesTestSuite) serve(handler http.Handler, wantStatus int) health.Report {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	suite.Require().Equal(wantStatus, recorder.Code, recorder.Body.String())
+
+	var report health.Report
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &report))
+	return report
+}
+
+// Run the health probes test suite
+func TestHealthProbesTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthProbesTestSuite))
+}
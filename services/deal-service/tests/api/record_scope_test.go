@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"crm-platform/deal-service/tests/fixtures"
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/authz"
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+// RecordScopeTestSuite checks that deals outside a user's scope behave as missing
+type RecordScopeTestSuite struct {
+	suite.Suite
+	db       *helpers.TestDatabase
+	server   *helpers.TestServer
+	fixtures *fixtures.DealFixtures
+	tenantID string
+
+	manager string // manages rep
+	rep     string
+	other   string // sales rep outside the manager's team
+	users   []string
+}
+
+// SetupSuite brings the test tenant to the latest tenant migration (users.manager_id)
+func (suite *RecordScopeTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), suite.db)
+	suite.fixtures = fixtures.NewDealFixtures()
+	suite.tenantID = helpers.TestTenant1
+	suite.db.UsePredefinedTenant(suite.tenantID)
+
+	migrations, err := tenant.DefaultMigrations()
+	suite.Require().NoError(err)
+	result := tenant.NewMigrator(suite.db.Pool, migrations).
+		MigrateSchema(context.Background(), tenant.GenerateSchemaName(suite.tenantID))
+	suite.Require().Empty(result.Error)
+}
+
+// TearDownSuite closes database connection
+func (suite *RecordScopeTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// SetupTest creates a manager with one report and an unrelated sales rep
+func (suite *RecordScopeTestSuite) SetupTest() {
+	if err := suite.db.CleanTenantData(suite.tenantID); err != nil {
+		suite.T().Logf("Warning: Failed to clean tenant data for %s: %v", suite.tenantID, err)
+	}
+
+	suite.users = nil
+	suite.manager = suite.createUser(middleware.RoleManager, "")
+	suite.rep = suite.createUser(middleware.RoleSalesRep, suite.manager)
+	suite.other = suite.createUser(middleware.RoleSalesRep, "")
+}
+
+// TearDownTest removes the deals and users created by the test
+func (suite *RecordScopeTestSuite) TearDownTest() {
+	_ = suite.db.CleanTenantData(suite.tenantID)
+
+	ctx := suite.db.GetTenantContext(suite.tenantID)
+	for i := len(suite.users) - 1; i >= 0; i-- {
+		_, err := suite.db.TenantPool.Exec(ctx, "DELETE FROM users WHERE id = $1", suite.users[i])
+		suite.NoError(err)
+	}
+}
+
+// createUser inserts a user with role, reporting to managerID unless it is empty
+func (suite *RecordScopeTestSuite) createUser(role, managerID string) string {
+	ctx := suite.db.GetTenantContext(suite.tenantID)
+	email := fmt.Sprintf("%s-%d@scope.test", role, time.Now().UnixNano())
+
+	var manager *int32
+	if managerID != "" {
+		id, err := strconv.ParseInt(managerID, 10, 32)
+		suite.Require().NoError(err)
+		manager = new(int32)
+		*manager = int32(id)
+	}
+
+	var id int32
+	err := suite.db.TenantPool.QueryRow(ctx,
+		`INSERT INTO users (email, password_hash, first_name, last_name, role, status, manager_id)
+         VALUES ($1, 'x', 'Scope', 'Test', $2, 'active', $3) RETURNING id`,
+		email, role, manager).Scan(&id)
+	suite.Require().NoError(err)
+
+	userID := strconv.Itoa(int(id))
+	suite.users = append(suite.users, userID)
+	return userID
+}
+
+// createDeal creates a deal owned by userID and returns its ID
+func (suite *RecordScopeTestSuite) createDeal(userID string) string {
+	resp := suite.request(http.MethodPost, "/api/v1/deals", userID, middleware.RoleSalesRep, suite.fixtures.ValidDeal())
+	resp.AssertStatus(suite.T(), http.StatusCreated)
+	return resp.GetIDString()
+}
+
+// request calls the API as userID with role
+func (suite *RecordScopeTestSuite) request(method, path, userID, role string, body interface{}) *helpers.TestResponse {
+	return helpers.NewRequest(suite.T(), method, path).
+		WithServer(suite.server).
+		WithTenant(suite.tenantID).
+		WithUser(userID).
+		WithRole(role).
+		WithBody(body).
+		Execute()
+}
+
+// listedDeals returns the IDs of the deals the list endpoint shows userID
+func (suite *RecordScopeTestSuite) listedDeals(userID, role string) []string {
+	resp := suite.request(http.MethodGet, "/api/v1/deals?limit=100", userID, role, nil)
+	resp.AssertStatus(suite.T(), http.StatusOK)
+
+	deals, _ := resp.Body["deals"].([]interface{})
+	var ids []string
+	for _, deal := range deals {
+		ids = append(ids, strconv.Itoa(int(deal.(map[string]interface{})["id"].(float64))))
+	}
+	return ids
+}
+
+// A sales rep reads and edits their own deals; everyone else's are not found
+func (suite *RecordScopeTestSuite) TestSalesRep_OwnDealsOnly() {
+	own := suite.createDeal(suite.rep)
+	foreign := suite.createDeal(suite.other)
+
+	suite.request(http.MethodGet, "/api/v1/deals/"+own, suite.rep, middleware.RoleSalesRep, nil).
+		AssertStatus(suite.T(), http.StatusOK)
+	suite.request(http.MethodPut, "/api/v1/deals/"+own, suite.rep, middleware.RoleSalesRep, suite.fixtures.UpdateRequest()).
+		AssertStatus(suite.T(), http.StatusOK)
+
+	suite.request(http.MethodGet, "/api/v1/deals/"+foreign, suite.rep, middleware.RoleSalesRep, nil).
+		AssertError(suite.T(), http.StatusNotFound, "deal not found")
+	suite.request(http.MethodPut, "/api/v1/deals/"+foreign, suite.rep, middleware.RoleSalesRep, suite.fixtures.UpdateRequest()).
+		AssertError(suite.T(), http.StatusNotFound, "deal not found")
+	suite.request(http.MethodPut, "/api/v1/deals/"+foreign+"/close", suite.rep, middleware.RoleSalesRep, suite.fixtures.CloseWonRequest()).
+		AssertError(suite.T(), http.StatusNotFound, "deal not found")
+
+	// The update kept the deal with its owner
+	suite.ElementsMatch([]string{own}, suite.listedDeals(suite.rep, middleware.RoleSalesRep))
+}
+
+// A manager reaches their own and their team's deals but not other teams'
+func (suite *RecordScopeTestSuite) TestManager_TeamDeals() {
+	managed := suite.createDeal(suite.manager)
+	team := suite.createDeal(suite.rep)
+	foreign := suite.createDeal(suite.other)
+
+	suite.ElementsMatch([]string{managed, team}, suite.listedDeals(suite.manager, middleware.RoleManager))
+
+	suite.request(http.MethodGet, "/api/v1/deals/"+team, suite.manager, middleware.RoleManager, nil).
+		AssertStatus(suite.T(), http.StatusOK)
+	suite.request(http.MethodDelete, "/api/v1/deals/"+foreign, suite.manager, middleware.RoleManager, nil).
+		AssertError(suite.T(), http.StatusNotFound, "deal not found")
+	suite.request(http.MethodDelete, "/api/v1/deals/"+team, suite.manager, middleware.RoleManager, nil).
+		AssertStatus(suite.T(), http.StatusNoContent)
+}
+
+// Admins and viewers see every deal in the tenant
+func (suite *RecordScopeTestSuite) TestTenantWideRoles() {
+	deals := []string{suite.createDeal(suite.manager), suite.createDeal(suite.rep), suite.createDeal(suite.other)}
+
+	suite.ElementsMatch(deals, suite.listedDeals(suite.other, middleware.RoleAdmin))
+	suite.ElementsMatch(deals, suite.listedDeals(suite.other, middleware.RoleViewer))
+}
+
+// Run the record scope test suite
+func TestRecordScopeTestSuite(t *testing.T) {
+	suite.Run(t, new(RecordScopeTestSuite))
+}
+
+// staticTeams is an authz.TeamLookup backed by a map (manager -> reports)
+type staticTeams map[int32][]int32
+
+func (s staticTeams) TeamMemberIDs(ctx context.Context, managerID int32) ([]int32, error) {
+	if managerID < 0 {
+		return nil, authz.ErrTeamUnavailable
+	}
+	return s[managerID], nil
+}
+
+// RecordPolicyTestSuite checks role scoping and the middleware without a database
+type RecordPolicyTestSuite struct {
+	suite.Suite
+	policy *authz.Policy
+}
+
+// SetupTest builds a policy where user 10 manages users 11 and 12
+func (suite *RecordPolicyTestSuite) SetupTest() {
+	suite.policy = authz.NewPolicy(staticTeams{10: {11, 12}})
+}
+
+// Each role maps to the expected scope
+func (suite *RecordPolicyTestSuite) TestScope_PerRole() {
+	cases := []struct {
+		role, userID string
+		want         authz.Scope
+	}{
+		{middleware.RoleAdmin, "10", authz.TenantScope()},
+		{middleware.RoleViewer, "11", authz.TenantScope()},
+		{middleware.RoleManager, "10", authz.OwnerScope(10, 11, 12)},
+		{middleware.RoleManager, "20", authz.OwnerScope(20)},
+		{middleware.RoleSalesRep, "11", authz.OwnerScope(11)},
+		{"unknown", "11", authz.OwnerScope(11)},
+		{middleware.RoleSalesRep, "dev-user", authz.OwnerScope()},
+	}
+
+	for _, tc := range cases {
+		scope, err := suite.policy.Scope(context.Background(), &middleware.Claims{UserID: tc.userID, Role: tc.role})
+		suite.Require().NoError(err, tc.role)
+		suite.Equal(tc.want, scope, "%s %s", tc.role, tc.userID)
+	}
+}
+
+// Unowned records are only in an unrestricted scope
+func (suite *RecordPolicyTestSuite) TestScope_Allows() {
+	owner := int32(11)
+	other := int32(13)
+
+	suite.True(authz.TenantScope().Allows(nil))
+	suite.True(authz.OwnerScope(10, 11).Allows(&owner))
+	suite.False(authz.OwnerScope(10, 11).Allows(&other))
+	suite.False(authz.OwnerScope(10, 11).Allows(nil))
+	suite.False(authz.OwnerScope().Allows(&owner))
+	suite.NotNil(authz.OwnerScope().Owners())
+}
+
+// The middleware stores the scope, and fails closed when it cannot be computed
+func (suite *RecordPolicyTestSuite) TestScopeMiddleware() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.DevelopmentAuthMiddleware())
+	router.Use(authz.ScopeMiddleware(suite.policy))
+	router.GET("/scope", func(c *gin.Context) {
+		scope, ok := authz.ScopeFromContext(c.Request.Context())
+		suite.True(ok)
+		c.JSON(http.StatusOK, scope)
+	})
+
+	cases := []struct {
+		userID, role string
+		status       int
+	}{
+		{"10", middleware.RoleManager, http.StatusOK},
+		{"-1", middleware.RoleManager, http.StatusServiceUnavailable},
+		{"11", middleware.RoleSalesRep, http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/scope", nil)
+		req.Header.Set("X-Tenant-ID", helpers.TestTenant1)
+		req.Header.Set("X-User-ID", tc.userID)
+		req.Header.Set("X-User-Role", tc.role)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		suite.Equal(tc.status, recorder.Code, "%s as %s: %s", tc.userID, tc.role, recorder.Body.String())
+	}
+}
+
+// Run the record policy test suite
+func TestRecordPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(RecordPolicyTestSuite))
+}
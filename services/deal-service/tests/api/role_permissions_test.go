@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"crm-platform/deal-service/tests/fixtures"
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+// RolePermissionsTestSuite checks every deal route against each built-in role
+type RolePermissionsTestSuite struct {
+	suite.Suite
+	db       *helpers.TestDatabase
+	server   *helpers.TestServer
+	fixtures *fixtures.DealFixtures
+	tenantID string
+}
+
+// SetupSuite connects to the test database and builds the production route table
+func (suite *RolePermissionsTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), suite.db)
+	suite.fixtures = fixtures.NewDealFixtures()
+	suite.tenantID = helpers.TestTenant1
+	suite.db.UsePredefinedTenant(suite.tenantID)
+}
+
+// TearDownSuite closes database connection
+func (suite *RolePermissionsTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// SetupTest starts each test with no deals
+func (suite *RolePermissionsTestSuite) SetupTest() {
+	if err := suite.db.CleanTenantData(suite.tenantID); err != nil {
+		suite.T().Logf("Warning: Failed to clean tenant data for %s: %v", suite.tenantID, err)
+	}
+}
+
+// Each role reaches exactly the routes its permissions cover
+func (suite *RolePermissionsTestSuite) TestDealRoutes_PerRole() {
+	routes := []struct {
+		method, path string
+		body         interface{}
+		permission   string
+	}{
+		{http.MethodGet, "/api/v1/deals", nil, "deals:read"},
+		{http.MethodGet, "/api/v1/deals/pipeline", nil, "deals:read"},
+		{http.MethodGet, "/api/v1/deals/owner/1", nil, "deals:read"},
+		{http.MethodGet, "/api/v1/deals/999999", nil, "deals:read"},
+		{http.MethodPost, "/api/v1/deals", suite.fixtures.ValidDeal(), "deals:write"},
+		{http.MethodPut, "/api/v1/deals/999999", suite.fixtures.UpdateRequest(), "deals:write"},
+		{http.MethodPut, "/api/v1/deals/999999/close", suite.fixtures.CloseWonRequest(), "deals:write"},
+		{http.MethodDelete, "/api/v1/deals/999999", nil, "deals:delete"},
+	}
+
+	allowed := map[string]map[string]bool{
+		middleware.RoleAdmin:    {"deals:read": true, "deals:write": true, "deals:delete": true},
+		middleware.RoleManager:  {"deals:read": true, "deals:write": true, "deals:delete": true},
+		middleware.RoleSalesRep: {"deals:read": true, "deals:write": true},
+		middleware.RoleViewer:   {"deals:read": true},
+		"unknown":               {},
+	}
+
+	for role, permissions := range allowed {
+		for _, route := range routes {
+			resp := helpers.NewRequest(suite.T(), route.method, route.path).
+				WithServer(suite.server).
+				WithTenant(suite.tenantID).
+				WithUser("1").
+				WithRole(role).
+				WithBody(route.body).
+				Execute()
+
+			name := fmt.Sprintf("%s %s %s", role, route.method, route.path)
+			if permissions[route.permission] {
+				suite.NotEqual(http.StatusForbidden, resp.StatusCode, "%s: %s", name, resp.RawBody)
+			} else {
+				suite.Equal(http.StatusForbidden, resp.StatusCode, "%s: %s", name, resp.RawBody)
+				suite.Contains(resp.RawBody, route.permission, name)
+			}
+		}
+	}
+}
+
+// Run the role permissions test suite
+func TestRolePermissionsTestSuite(t *testing.T) {
+	suite.Run(t, new(RolePermissionsTestSuite))
+}
+
+// staticRoleOverrides is a tenant.RoleOverrideSource backed by a map
+type staticRoleOverrides map[string]map[string][]string
+
+func (s staticRoleOverrides) RolePermissions(ctx context.Context, tenantID string) (map[string][]string, error) {
+	overrides, ok := s[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", tenant.ErrUnknownTenant, tenantID)
+	}
+	return overrides, nil
+}
+
+// PermissionPolicyTestSuite checks wildcards and per-tenant overrides without a database
+type PermissionPolicyTestSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+// SetupTest builds a router where tenant 2 restricts viewers and extends sales reps
+func (suite *PermissionPolicyTestSuite) SetupTest() {
+	overrides := staticRoleOverrides{
+		helpers.TestTenant1: {},
+		helpers.TestTenant2: {
+			middleware.RoleViewer:   {},
+			middleware.RoleSalesRep: {"deals:*"},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.Use(middleware.DevelopmentAuthMiddleware())
+	suite.router.Use(middleware.RolePermissionsMiddleware(middleware.NewPermissionPolicy(nil, overrides)))
+	for _, permission := range []string{"deals:read", "deals:delete", "contacts:write", "reports:read"} {
+		suite.router.GET("/"+strings.Replace(permission, ":", "/", 1), middleware.RequirePermission(permission), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+	}
+}
+
+// Wildcards cover every action on a resource, or everything
+func (suite *PermissionPolicyTestSuite) TestMatchPermission_Wildcards() {
+	suite.True(middleware.MatchPermission("*", "deals:delete"))
+	suite.True(middleware.MatchPermission("deals:*", "deals:delete"))
+	suite.True(middleware.MatchPermission("deals:read", "deals:read"))
+	suite.False(middleware.MatchPermission("deals:*", "contacts:read"))
+	suite.False(middleware.MatchPermission("deals:*", "dealsx:read"))
+	suite.False(middleware.MatchPermission("deals:read", "deals:write"))
+}
+
+// Tenants without overrides get the built-in roles
+func (suite *PermissionPolicyTestSuite) TestDefaultRoles() {
+	suite.expect(helpers.TestTenant1, middleware.RoleManager, "", "deals:delete", http.StatusNoContent)
+	suite.expect(helpers.TestTenant1, middleware.RoleManager, "", "contacts:write", http.StatusNoContent)
+	suite.expect(helpers.TestTenant1, middleware.RoleSalesRep, "", "deals:delete", http.StatusForbidden)
+	suite.expect(helpers.TestTenant1, middleware.RoleViewer, "", "deals:read", http.StatusNoContent)
+	suite.expect(helpers.TestTenant1, middleware.RoleViewer, "", "contacts:write", http.StatusForbidden)
+	suite.expect(helpers.TestTenant1, middleware.RoleAdmin, "", "reports:read", http.StatusNoContent)
+}
+
+// A tenant override replaces the role's built-in permissions
+func (suite *PermissionPolicyTestSuite) TestTenantOverrides() {
+	suite.expect(helpers.TestTenant2, middleware.RoleViewer, "", "deals:read", http.StatusForbidden)
+	suite.expect(helpers.TestTenant2, middleware.RoleSalesRep, "", "deals:delete", http.StatusNoContent)
+	suite.expect(helpers.TestTenant2, middleware.RoleSalesRep, "", "contacts:write", http.StatusForbidden)
+	suite.expect(helpers.TestTenant2, middleware.RoleManager, "", "deals:delete", http.StatusNoContent)
+}
+
+// Permissions listed in the token cannot extend the role or undo a tenant override
+func (suite *PermissionPolicyTestSuite) TestUserPermissions_IgnoredWithRole() {
+	suite.expect(helpers.TestTenant2, middleware.RoleViewer, "deals:read", "deals:read", http.StatusForbidden)
+	suite.expect(helpers.TestTenant1, middleware.RoleViewer, "contacts:*", "contacts:write", http.StatusForbidden)
+	suite.expect(helpers.TestTenant2, middleware.RoleSalesRep, "contacts:write", "contacts:write", http.StatusForbidden)
+}
+
+// Tokens without a role get no permissions, whatever they carry
+func (suite *PermissionPolicyTestSuite) TestUserPermissions_WithoutRole() {
+	policy := middleware.NewPermissionPolicy(nil, staticRoleOverrides{})
+	permissions, err := policy.Permissions(context.Background(), helpers.TestTenant1, &middleware.Claims{
+		Permissions: []string{"*", "deals:read"},
+	})
+	suite.Require().NoError(err)
+	suite.Empty(permissions)
+}
+
+// Unregistered tenants are rejected before any permission check
+func (suite *PermissionPolicyTestSuite) TestUnknownTenant_Forbidden() {
+	suite.expect(helpers.TestTenant3, middleware.RoleAdmin, "", "deals:read", http.StatusForbidden)
+}
+
+// expect calls the route guarded by permission as role in tenantID
+func (suite *PermissionPolicyTestSuite) expect(tenantID, role, userPermissions, permission string, wantStatus int) {
+	req := httptest.NewRequest(http.MethodGet, "/"+strings.Replace(permission, ":", "/", 1), nil)
+	req.Header.Set("X-Tenant-ID", tenantID)
+	req.Header.Set("X-User-Role", role)
+	req.Header.Set("X-User-Permissions", userPermissions)
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, req)
+
+	suite.Equal(wantStatus, recorder.Code, "%s as %s in %s: %s", permission, role, tenantID, recorder.Body.String())
+}
+
+// Run the permission policy test suite
+func TestPermissionPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionPolicyTestSuite))
+}
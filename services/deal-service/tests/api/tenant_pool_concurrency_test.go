@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/tenant"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/suite"
+)
+
+// TenantPoolConcurrencyTestSuite proves TenantPool isolation under concurrent multi-tenant load
+type TenantPoolConcurrencyTestSuite struct {
+	suite.Suite
+	db      *helpers.TestDatabase
+	tenants []string
+}
+
+// SetupSuite uses the predefined tenant schemas
+func (suite *TenantPoolConcurrencyTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.tenants = helpers.GetTestTenants()
+	for _, tenantID := range suite.tenants {
+		suite.db.UsePredefinedTenant(tenantID)
+	}
+}
+
+// TearDownSuite closes database connection
+func (suite *TenantPoolConcurrencyTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// SetupTest seeds each tenant with one deal titled after the tenant
+func (suite *TenantPoolConcurrencyTestSuite) SetupTest() {
+	for _, tenantID := range suite.tenants {
+		suite.Require().NoError(suite.db.CleanTenantData(tenantID))
+
+		ctx := suite.db.GetTenantContext(tenantID)
+		_, err := suite.db.TenantPool.Exec(ctx, "INSERT INTO deals (title, stage) VALUES ($1, 'Qualified')", tenantID)
+		suite.Require().NoError(err)
+	}
+}
+
+// TearDownTest removes the seeded deals
+func (suite *TenantPoolConcurrencyTestSuite) TearDownTest() {
+	for _, tenantID := range suite.tenants {
+		_ = suite.db.CleanTenantData(tenantID)
+	}
+}
+
+// Many goroutines per tenant mix Query, QueryRow and Exec on a shared pool;
+// every statement must see only its own tenant's schema and rows.
+func (suite *TenantPoolConcurrencyTestSuite) TestConcurrentOperations_NeverCrossTenants() {
+	const workersPerTenant = 25
+	const iterations = 40
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(suite.tenants)*workersPerTenant)
+
+	for _, tenantID := range suite.tenants {
+		ctx := suite.db.GetTenantContext(tenantID)
+		schemaName := tenant.GenerateSchemaName(tenantID)
+
+		for w := 0; w < workersPerTenant; w++ {
+			wg.Add(1)
+			go func(ctx context.Context, tenantID, schemaName string) {
+				defer wg.Done()
+				errs <- suite.runTenantWorkload(ctx, tenantID, schemaName, iterations)
+			}(ctx, tenantID, schemaName)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		suite.Require().NoError(err)
+	}
+}
+
+// Connections returned by TenantPool must not carry a tenant search path
+func (suite *TenantPoolConcurrencyTestSuite) TestReleasedConnections_HaveNoTenantSearchPath() {
+	for _, tenantID := range suite.tenants {
+		ctx := suite.db.GetTenantContext(tenantID)
+		suite.Require().NoError(suite.runTenantWorkload(ctx, tenantID, tenant.GenerateSchemaName(tenantID), 10))
+	}
+
+	// Hold every connection the pool can hand out at once and inspect each one
+	ctx := context.Background()
+	maxConns := int(suite.db.Pool.Config().MaxConns)
+	conns := make([]*pgxpool.Conn, 0, maxConns)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+
+	for i := 0; i < maxConns; i++ {
+		conn, err := suite.db.Pool.Acquire(ctx)
+		suite.Require().NoError(err)
+		conns = append(conns, conn)
+
+		var schema string
+		suite.Require().NoError(conn.QueryRow(ctx, "SELECT current_schema()").Scan(&schema))
+		suite.NotRegexp(`^tenant_[0-9A-Za-z]{26}$`, schema, "pooled connection still points at a tenant schema")
+	}
+}
+
+// runTenantWorkload checks schema and row visibility for one tenant
+func (suite *TenantPoolConcurrencyTestSuite) runTenantWorkload(ctx context.Context, tenantID, schemaName string, iterations int) error {
+	pool := suite.db.TenantPool
+
+	for i := 0; i < iterations; i++ {
+		// QueryRow: the statement runs in this tenant's schema
+		var current string
+		if err := pool.QueryRow(ctx, "SELECT current_schema()").Scan(&current); err != nil {
+			return fmt.Errorf("tenant %s: QueryRow failed: %w", tenantID, err)
+		}
+		if current != schemaName {
+			return fmt.Errorf("tenant %s: query ran in schema %s", tenantID, current)
+		}
+
+		// Query: only this tenant's deals are visible
+		rows, err := pool.Query(ctx, "SELECT title FROM deals")
+		if err != nil {
+			return fmt.Errorf("tenant %s: Query failed: %w", tenantID, err)
+		}
+		for rows.Next() {
+			var title string
+			if err := rows.Scan(&title); err != nil {
+				rows.Close()
+				return fmt.Errorf("tenant %s: scan failed: %w", tenantID, err)
+			}
+			if title != tenantID {
+				rows.Close()
+				return fmt.Errorf("tenant %s: saw deal belonging to %s", tenantID, title)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("tenant %s: rows failed: %w", tenantID, err)
+		}
+
+		// Exec: writes land in this tenant's schema only
+		tag, err := pool.Exec(ctx, "UPDATE deals SET probability = $1 WHERE title = $2", i%100, tenantID)
+		if err != nil {
+			return fmt.Errorf("tenant %s: Exec failed: %w", tenantID, err)
+		}
+		if tag.RowsAffected() != 1 {
+			return fmt.Errorf("tenant %s: Exec touched %d rows, want 1", tenantID, tag.RowsAffected())
+		}
+	}
+
+	return nil
+}
+
+// Run the concurrency test suite
+func TestTenantPoolConcurrencyTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantPoolConcurrencyTestSuite))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"crm-platform/deal-service/tests/helpers"
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/tenant"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TenantPoolTimeoutTestSuite checks query deadlines and tenant statement timeouts
+type TenantPoolTimeoutTestSuite struct {
+	suite.Suite
+	db       *helpers.TestDatabase
+	tenantID string
+}
+
+// SetupSuite uses the first predefined tenant schema
+func (suite *TenantPoolTimeoutTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.tenantID = helpers.GetTestTenants()[0]
+	suite.db.UsePredefinedTenant(suite.tenantID)
+}
+
+// TearDownSuite closes database connection
+func (suite *TenantPoolTimeoutTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// A per-context timeout stops the statement and is counted as a timeout
+func (suite *TenantPoolTimeoutTestSuite) TestQueryTimeout_CancelsStatement() {
+	ctx := database.WithQueryTimeout(suite.db.GetTenantContext(suite.tenantID), 200*time.Millisecond)
+	before := suite.db.Pool.GetMetrics().TimedOutQueries
+
+	start := time.Now()
+	_, err := suite.db.TenantPool.Exec(ctx, "SELECT pg_sleep(5)")
+
+	suite.Require().Error(err)
+	suite.True(database.IsQueryTimeout(err), "expected timeout error, got %v", err)
+	suite.Less(time.Since(start), 3*time.Second, "statement was not canceled at the deadline")
+	suite.Greater(suite.db.Pool.GetMetrics().TimedOutQueries, before)
+
+	// The connection is usable again once the server canceled the statement
+	var one int
+	suite.Require().NoError(suite.db.TenantPool.QueryRow(suite.db.GetTenantContext(suite.tenantID), "SELECT 1").Scan(&one))
+}
+
+// A tenant statement_timeout is enforced by the server and reset before the connection is reused
+func (suite *TenantPoolTimeoutTestSuite) TestTenantStatementTimeout_AppliesPerTenant() {
+	tenantPool := tenant.NewTenantPoolWithOptions(suite.db.Pool, tenant.TenantPoolOptions{
+		StatementTimeouts: &tenant.StatementTimeouts{
+			Overrides: map[string]time.Duration{suite.tenantID: 100 * time.Millisecond},
+		},
+	})
+	ctx := suite.db.GetTenantContext(suite.tenantID)
+
+	var timeout string
+	suite.Require().NoError(tenantPool.QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout))
+	suite.Equal("100ms", timeout)
+
+	_, err := tenantPool.Exec(ctx, "SELECT pg_sleep(1)")
+	suite.Require().Error(err)
+	suite.True(database.IsQueryTimeout(err), "expected statement_timeout error, got %v", err)
+
+	// Transactions get the same limit, scoped to the transaction
+	tx, err := tenantPool.Begin(ctx)
+	suite.Require().NoError(err)
+	suite.Require().NoError(tx.QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout))
+	suite.Equal("100ms", timeout)
+	suite.Require().NoError(tx.Rollback(ctx))
+
+	// Plain pool connections keep the server default
+	suite.Require().NoError(suite.db.Pool.QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout))
+	suite.NotEqual("100ms", timeout)
+}
+
+// Run the timeout test suite
+func TestTenantPoolTimeoutTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantPoolTimeoutTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	pkgconfig "crm-platform/pkg/config"
 	"crm-platform/pkg/database"
 	"crm-platform/pkg/tenant"
 
@@ -42,7 +43,7 @@ func SetupTestDatabase(t *testing.T) *TestDatabase {
 	health := pool.HealthCheck(ctx)
 	require.True(t, health.Healthy, "Test database is not healthy: %s", health.Error)
 
-	tenantPool := tenant.NewTenantPool(pool)
+	tenantPool := tenant.NewTenantPool(pool, pkgconfig.Defaults())
 
 	return &TestDatabase{
 		Pool:          pool,
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"crm-platform/deal-service/internal/handlers"
+	"crm-platform/pkg/authz"
 	"crm-platform/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -49,26 +50,18 @@ func SetupTestServer(t *testing.T, db *TestDatabase) *TestServer {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
-	// Add middleware in correct order (same as production)
-	router.Use(middleware.AuthMiddleware())
+	// Add middleware in correct order (same as production, with development header auth)
+	router.Use(middleware.DevelopmentAuthMiddleware())
 	router.Use(middleware.TenantMiddleware())
+	router.Use(middleware.RolePermissionsMiddleware(middleware.NewPermissionPolicy(nil, nil)))
+	router.Use(authz.ScopeMiddleware(authz.NewPolicy(authz.NewUserTeamLookup(db.TenantPool))))
 
 	// Create deal handler
 	dealHandler := handlers.NewDealHandlerWithTenantPool(db.TenantPool)
 
-	// Register ALL API routes (this was the missing piece!)
+	// Register ALL API routes with their permission requirements
 	v1 := router.Group("/api/v1")
-	deals := v1.Group("/deals")
-	{
-		deals.POST("", dealHandler.CreateDeal)           // POST /api/v1/deals
-		deals.GET("", dealHandler.ListDeals)             // GET /api/v1/deals
-		deals.GET("/pipeline", dealHandler.GetPipelineView) // GET /api/v1/deals/pipeline
-		deals.GET("/owner/:id", dealHandler.GetDealsByOwner) // GET /api/v1/deals/owner/:id
-		deals.GET("/:id", dealHandler.GetDeal)           // GET /api/v1/deals/:id
-		deals.PUT("/:id", dealHandler.UpdateDeal)        // PUT /api/v1/deals/:id
-		deals.PUT("/:id/close", dealHandler.CloseDeal)   // PUT /api/v1/deals/:id/close
-		deals.DELETE("/:id", dealHandler.DeleteDeal)     // DELETE /api/v1/deals/:id ← FIX: This was missing!
-	}
+	handlers.RegisterDealRoutes(v1.Group("/deals"), dealHandler)
 
 	return &TestServer{
 		Router:      router,
@@ -190,6 +183,12 @@ func (rb *RequestBuilder) WithUser(userID string) *RequestBuilder {
 	return rb
 }
 
+// WithRole sets the user role (development mode header; admin when unset)
+func (rb *RequestBuilder) WithRole(role string) *RequestBuilder {
+	rb.req.Headers["X-User-Role"] = role
+	return rb
+}
+
 // WithHeader adds a custom header
 func (rb *RequestBuilder) WithHeader(key, value string) *RequestBuilder {
 	rb.req.Headers[key] = value
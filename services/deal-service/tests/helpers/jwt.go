@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Token issuer defaults used by the JWT tests
+const (
+	TestJWTIssuer   = "https://auth.test.local"
+	TestJWTAudience = "crm-api"
+)
+
+// TestSigningKey is one key pair the test issuer can sign with
+type TestSigningKey struct {
+	Kid     string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+}
+
+// TestTokenIssuer stands in for auth-service: it signs tokens and publishes a JWKS file
+type TestTokenIssuer struct {
+	t        *testing.T
+	JWKSPath string
+}
+
+// NewTestTokenIssuer creates an issuer whose JWKS file lives in a temp dir
+func NewTestTokenIssuer(t *testing.T) *TestTokenIssuer {
+	return &TestTokenIssuer{t: t, JWKSPath: filepath.Join(t.TempDir(), "jwks.json")}
+}
+
+// NewRSAKey generates an RS256 signing key
+func (ti *TestTokenIssuer) NewRSAKey(kid string) TestSigningKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(ti.t, err)
+	return TestSigningKey{Kid: kid, Method: jwt.SigningMethodRS256, Private: key}
+}
+
+// NewECKey generates an ES256 signing key
+func (ti *TestTokenIssuer) NewECKey(kid string) TestSigningKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(ti.t, err)
+	return TestSigningKey{Kid: kid, Method: jwt.SigningMethodES256, Private: key}
+}
+
+// Publish replaces the JWKS file with the public halves of keys
+func (ti *TestTokenIssuer) Publish(keys ...TestSigningKey) {
+	jwks := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+
+	for _, key := range keys {
+		jwk := map[string]string{"kid": key.Kid, "use": "sig", "alg": key.Method.Alg()}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = encodeBigInt(pub.N)
+			jwk["e"] = encodeBigInt(big.NewInt(int64(pub.E)))
+		case *ecdsa.PublicKey:
+			jwk["kty"] = "EC"
+			jwk["crv"] = "P-256"
+			jwk["x"] = encodeBigInt(pub.X)
+			jwk["y"] = encodeBigInt(pub.Y)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	data, err := json.Marshal(jwks)
+	require.NoError(ti.t, err)
+	require.NoError(ti.t, os.WriteFile(ti.JWKSPath, data, 0o600))
+}
+
+// Claims returns valid registered claims for the test issuer and audience, expiring in an hour
+func (ti *TestTokenIssuer) Claims(userID, tenantID string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":       TestJWTIssuer,
+		"aud":       TestJWTAudience,
+		"sub":       userID,
+		"user_id":   userID,
+		"tenant_id": tenantID,
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+	}
+}
+
+// Sign signs claims with key, naming it in the kid header
+func (ti *TestTokenIssuer) Sign(key TestSigningKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+
+	signed, err := token.SignedString(key.Private)
+	require.NoError(ti.t, err)
+	return signed
+}
+
+// encodeBigInt encodes n as unpadded base64url, as JWK requires
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
@@ -6,7 +6,11 @@ import (
 	"os"
 	"time"
 
+	"crm-platform/pkg/config"
 	"crm-platform/pkg/database"
+	"crm-platform/pkg/health"
+	"crm-platform/pkg/middleware"
+	"crm-platform/pkg/tenant"
 	"crm-platform/tenant-service/internal/errors"
 	"crm-platform/tenant-service/internal/handlers"
 	"crm-platform/tenant-service/internal/services"
@@ -27,14 +31,30 @@ func getServerPort() string {
 	return port
 }
 
+// Load purge scheduler interval from environment with fallback
+func getPurgeInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("TENANT_PURGE_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return time.Hour
+}
+
+// Load expired invitation cleanup interval from environment with fallback
+func getInvitationCleanupInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("INVITATION_CLEANUP_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return time.Hour
+}
+
 // =============================================================================
 // SETUP FUNCTIONS
 // =============================================================================
 
-// Initialize database connection with retry logic
-func setupDatabase() (*database.Pool, error) {
-	// Load config from environment
-	config, err := database.LoadConfigFromEnv()
+// Initialize database connection with backoff retries (canceled by the startup timeout)
+func setupDatabase(cfg *config.Config) (*database.Pool, error) {
+	// Build pool config from the loaded settings
+	poolConfig, err := cfg.PoolConfig()
 	if err != nil {
 		return nil, errors.ErrDatabase("failed to load database config: " + err.Error())
 	}
@@ -43,36 +63,97 @@ func setupDatabase() (*database.Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	pool, err := database.NewPool(ctx, config)
+	pool, err := database.NewPool(ctx, poolConfig)
 	if err != nil {
 		return nil, errors.ErrDatabase("failed to create connection pool: " + err.Error())
 	}
 
-	// Test connection with ping
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, errors.ErrDatabase("failed to ping database: " + err.Error())
+	// Trace every statement as a child of the request span
+	pool.AddQueryObserver(database.NewTracingObserver(nil))
+
+	// With DB_BACKGROUND_CONNECT the service starts degraded and /health reports it
+	if pool.Degraded() {
+		log.Println("Database unavailable, starting degraded and reconnecting in background")
+		return pool, nil
 	}
 
 	log.Println("Database connection established successfully")
 	return pool, nil
 }
 
+// Bring the template schema to the latest migration before serving
+// Failures are logged, not fatal: readiness keeps reporting them until fixed.
+func setupTemplate(ctx context.Context, pool *database.Pool, migrationService *services.MigrationService) {
+	if pool.Degraded() {
+		log.Println("Database unavailable, skipping template migration")
+		return
+	}
+
+	exists, err := tenant.SchemaExists(ctx, pool, tenant.TemplateSchemaName)
+	if err != nil || !exists {
+		log.Printf("Template schema unavailable, skipping template migration (exists=%t, err=%v)", exists, err)
+		return
+	}
+
+	result, err := migrationService.MigrateTemplate(ctx)
+	if err != nil {
+		log.Printf("Template migration failed: %v", err)
+		return
+	}
+	log.Printf("Template schema at version %d (applied %v)", result.ToVersion, result.Applied)
+}
+
 // Initialize all handlers with database dependencies
-func setupHandlers(pool *database.Pool) (*handlers.TenantHandler, *handlers.HealthHandler) {
+func setupHandlers(ctx context.Context, cfg *config.Config, pool *database.Pool, migrationService *services.MigrationService) (*handlers.TenantHandler, *handlers.MigrationHandler, *handlers.HealthHandler, error) {
 	// Create service layer
-	tenantService := services.NewTenantService(pool)
+	tenantService := services.NewTenantService(pool, migrationService.Migrator(), tenant.IsolationModeFromEnv())
+
+	// Bulk test-tenant deletion is a development convenience
+	if cfg.IsDevelopment() {
+		tenantService.EnableTestTenantDeletion()
+	}
+
+	// Hard-purge soft-deleted tenants once their retention window has passed
+	tenantService.StartPurgeScheduler(ctx, getPurgeInterval())
+
+	// Delete pending invitations once they have expired
+	tenantService.StartInvitationCleanup(ctx, getInvitationCleanupInterval())
 
 	// Create handler instances
 	tenantHandler := handlers.NewTenantHandler(tenantService)
+	migrationHandler := handlers.NewMigrationHandler(migrationService)
 	healthHandler := handlers.NewHealthHandler(pool)
 
 	log.Println("Handlers initialized successfully")
-	return tenantHandler, healthHandler
+	return tenantHandler, migrationHandler, healthHandler, nil
+}
+
+// Expose Kubernetes probes: /livez for liveness, /readyz for readiness
+// Readiness results are cached briefly so frequent probes don't load the database.
+// Only this service checks migrations, since it is the one that applies them.
+func setupProbes(router *gin.Engine, cfg *config.Config, pool *database.Pool, migrator *tenant.Migrator) error {
+	checker, err := health.NewServiceChecker("tenant-service", pool, cfg)
+	if err != nil {
+		return errors.ErrHandler("failed to set up health checks: " + err.Error())
+	}
+	checker.Register("migrations", health.MigrationsCheck(migrator))
+	router.GET("/livez", gin.WrapH(checker.LivenessHandler()))  // GET /livez
+	router.GET("/readyz", gin.WrapH(checker.ReadinessHandler())) // GET /readyz
+	return nil
+}
+
+// Expose database metrics for Prometheus
+func setupMetrics(router *gin.Engine, pool *database.Pool) {
+	exporter := database.NewExporter(pool, database.ExporterOptions{
+		Service:      "tenant-service",
+		QueryLabels:  true,
+		TenantLabels: config.GetMetricsTenantLabels(),
+	})
+	router.GET("/metrics", gin.WrapH(exporter)) // GET /metrics
 }
 
 // Register all API routes
-func setupRoutes(router *gin.Engine, tenantHandler *handlers.TenantHandler, healthHandler *handlers.HealthHandler) {
+func setupRoutes(router *gin.Engine, tenantHandler *handlers.TenantHandler, migrationHandler *handlers.MigrationHandler, healthHandler *handlers.HealthHandler) {
 	// Register system endpoints (no auth required for internal service)
 	router.GET("/health", healthHandler.HealthCheck) // GET /health
 
@@ -84,10 +165,35 @@ func setupRoutes(router *gin.Engine, tenantHandler *handlers.TenantHandler, heal
 	{
 		tenants.POST("", tenantHandler.CreateTenant)                          // POST /internal/tenants
 		tenants.GET("", tenantHandler.ListTenants)                            // GET /internal/tenants
+		tenants.POST("/purge", tenantHandler.PurgeDueTenants)                 // POST /internal/tenants/purge
 		tenants.GET("/:id", tenantHandler.GetTenant)                          // GET /internal/tenants/:id
 		tenants.GET("/subdomain/:subdomain", tenantHandler.GetTenantBySubdomain) // GET /internal/tenants/subdomain/:subdomain
 		tenants.PUT("/:id", tenantHandler.UpdateTenant)                       // PUT /internal/tenants/:id
 		tenants.GET("/:id/health", tenantHandler.GetTenantHealth)             // GET /internal/tenants/:id/health
+		tenants.POST("/:id/provision", tenantHandler.RetryProvisioning)       // POST /internal/tenants/:id/provision
+		tenants.POST("/:id/suspend", tenantHandler.SuspendTenant)             // POST /internal/tenants/:id/suspend
+		tenants.POST("/:id/reactivate", tenantHandler.ReactivateTenant)       // POST /internal/tenants/:id/reactivate
+		tenants.DELETE("/:id", tenantHandler.DeleteTenant)                    // DELETE /internal/tenants/:id
+		tenants.POST("/:id/restore", tenantHandler.RestoreTenant)             // POST /internal/tenants/:id/restore
+		tenants.DELETE("/:id/purge", tenantHandler.PurgeTenant)               // DELETE /internal/tenants/:id/purge
+		tenants.GET("/:id/audit", tenantHandler.GetTenantAudit)               // GET /internal/tenants/:id/audit
+		tenants.POST("/:id/invitations", tenantHandler.CreateInvitation)      // POST /internal/tenants/:id/invitations
+		tenants.GET("/:id/invitations", tenantHandler.ListInvitations)        // GET /internal/tenants/:id/invitations
+		tenants.DELETE("/:id/invitations/:invitationId", tenantHandler.RevokeInvitation) // DELETE /internal/tenants/:id/invitations/:invitationId
+	}
+
+	// Register invitation endpoints that are not scoped to one tenant
+	invitations := internal.Group("/invitations")
+	{
+		invitations.POST("/accept", tenantHandler.AcceptInvitation)           // POST /internal/invitations/accept
+		invitations.POST("/cleanup", tenantHandler.CleanupExpiredInvitations) // POST /internal/invitations/cleanup
+	}
+
+	// Register tenant schema migration endpoints
+	migrations := internal.Group("/migrations")
+	{
+		migrations.GET("/status", migrationHandler.GetStatus)        // GET /internal/migrations/status
+		migrations.POST("/apply", migrationHandler.ApplyMigrations)  // POST /internal/migrations/apply
 	}
 
 	// Register test endpoints (for development/testing)
@@ -110,18 +216,45 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// Load and validate configuration (defaults, CONFIG_FILE, env, secret files)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// Setup database connection
-	pool, err := setupDatabase()
+	pool, err := setupDatabase(cfg)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 	defer pool.Close()
 
+	// Background work stops when main returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Migrate the template before tenants can be provisioned from it
+	migrationService, err := services.NewMigrationService(pool)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	setupTemplate(ctx, pool, migrationService)
+
 	// Setup handlers
-	tenantHandler, healthHandler := setupHandlers(pool)
+	tenantHandler, migrationHandler, healthHandler, err := setupHandlers(ctx, cfg, pool, migrationService)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Trace requests before routing
+	router.Use(middleware.TracingMiddleware("tenant-service"))
 
 	// Setup routes
-	setupRoutes(router, tenantHandler, healthHandler)
+	setupRoutes(router, tenantHandler, migrationHandler, healthHandler)
+	setupMetrics(router, pool)
+	if err := setupProbes(router, cfg, pool, migrationService.Migrator()); err != nil {
+		log.Fatal(err.Error())
+	}
 
 	// Get server port from environment
 	port := getServerPort()
@@ -0,0 +1,15 @@
+-- name: CreateTenantAuditEntry :exec
+INSERT INTO public.tenant_audit_log (tenant_id, action, actor, details)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListTenantAuditEntries :many
+SELECT id, tenant_id, action, actor, details, created_at
+FROM tenant_audit_log
+WHERE tenant_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: HasTenantAuditAction :one
+SELECT EXISTS(
+    SELECT 1 FROM tenant_audit_log
+    WHERE tenant_id = $1 AND action = $2
+);
\ No newline at end of file
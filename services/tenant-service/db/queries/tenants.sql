@@ -1,20 +1,20 @@
 -- name: CreateTenant :one
-INSERT INTO tenants (id, name, subdomain, schema_name)
-VALUES ($1, $2, $3, $4)
-RETURNING id, name, subdomain, schema_name, created_at;
+INSERT INTO tenants (id, name, subdomain, schema_name, status)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, subdomain, schema_name, status, created_at;
 
 -- name: GetTenantBySubdomain :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE subdomain = $1;
 
 -- name: GetTenantByID :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE id = $1;
 
 -- name: GetTenantBySchemaName :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE schema_name = $1;
 
@@ -26,6 +26,51 @@ UPDATE tenants
 SET name = $2, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
 
+-- name: UpdateTenantStatus :exec
+UPDATE tenants
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: LockTenantStatus :one
+SELECT status FROM tenants
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListTenantsByStatus :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE status = $1
+ORDER BY created_at;
+
+-- name: SoftDeleteTenant :one
+UPDATE tenants
+SET status_before_delete = status, status = 'suspended', deleted_at = CURRENT_TIMESTAMP, purge_after = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete;
+
+-- name: RestoreTenant :exec
+UPDATE tenants
+SET status = COALESCE(status_before_delete, 'active'), status_before_delete = NULL, deleted_at = NULL, purge_after = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: ListTenantsDueForPurge :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE deleted_at IS NOT NULL AND purge_after <= CURRENT_TIMESTAMP
+ORDER BY purge_after;
+
+-- name: ListTenantsBySubdomainPrefix :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE starts_with(subdomain, sqlc.arg(prefix)::text)
+ORDER BY subdomain;
+
+-- name: DeleteTenant :execrows
+DELETE FROM public.tenants
+WHERE id = sqlc.arg(id)
+  AND (NOT sqlc.arg(require_deleted)::bool OR deleted_at IS NOT NULL)
+  AND (NOT sqlc.arg(require_due)::bool OR purge_after <= CURRENT_TIMESTAMP);
+
 -- name: CheckSubdomainExists :one
 SELECT EXISTS(
     SELECT 1 FROM tenants 
@@ -39,7 +84,7 @@ SELECT EXISTS(
 );
 
 -- name: ListAllTenants :many
-SELECT id, name, subdomain, schema_name, created_at
+SELECT id, name, subdomain, schema_name, status, created_at
 FROM tenants
 ORDER BY name;
 
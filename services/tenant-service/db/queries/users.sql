@@ -0,0 +1,4 @@
+-- name: CreateInvitedUser :one
+INSERT INTO users (email, password_hash, first_name, last_name, role, status, active, email_verified, created_by)
+VALUES ($1, $2, $3, $4, $5, 'active', true, true, $6)
+RETURNING id, email, role, created_at;
@@ -4,17 +4,23 @@ CREATE TABLE tenants (
     name VARCHAR(255) NOT NULL,
     subdomain VARCHAR(63) UNIQUE NOT NULL,
     schema_name VARCHAR(63) UNIQUE NOT NULL,
+    status VARCHAR(50) DEFAULT 'active' NOT NULL,
     created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ, -- Soft-delete time; tenant is suspended until purged
+    purge_after TIMESTAMPTZ, -- Hard purge is due after this time
+    status_before_delete VARCHAR(50), -- Status a restore returns the tenant to
+
+    CONSTRAINT tenants_status_valid CHECK (status IN ('active', 'suspended', 'pending'))
 );
 
 -- User invitations (global table for cross-tenant invites)
 CREATE TABLE invitations (
     id TEXT PRIMARY KEY, -- ULID format (26 chars)
-    tenant_id TEXT REFERENCES tenants(id) ON DELETE CASCADE,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
     email VARCHAR(254) NOT NULL,
     role VARCHAR(20) CHECK (role IN ('admin', 'manager', 'sales_rep', 'viewer')) NOT NULL,
-    token VARCHAR(255) UNIQUE NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL, -- SHA-256 of the invitation token
     expires_at TIMESTAMPTZ NOT NULL,
     accepted_at TIMESTAMPTZ,
     invited_by INTEGER, -- References users table in tenant schema
@@ -22,14 +28,26 @@ CREATE TABLE invitations (
     created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Audit trail for tenant lifecycle changes (no FK: entries outlive purged tenants)
+CREATE TABLE tenant_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    details JSONB DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Performance indexes (beyond automatic UNIQUE indexes)
 CREATE INDEX idx_tenants_created_at ON tenants (created_at);
-CREATE INDEX idx_invitations_token ON invitations (token);
-CREATE INDEX idx_invitations_tenant_id ON invitations (tenant_id);
+CREATE INDEX idx_tenants_status ON tenants (status);
+CREATE INDEX idx_tenants_purge_after ON tenants (purge_after) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_tenant_audit_log_tenant_id ON tenant_audit_log (tenant_id, created_at);
+CREATE INDEX idx_invitations_tenant_id ON invitations (tenant_id, created_at);
 CREATE INDEX idx_invitations_email ON invitations (email);
-CREATE INDEX idx_invitations_expires_at ON invitations (expires_at);
+CREATE INDEX idx_invitations_expires_at ON invitations (expires_at) WHERE accepted_at IS NULL;
 
--- Unique constraint for active invitations
-CREATE UNIQUE INDEX idx_invitations_tenant_email_active 
-ON invitations (tenant_id, email) 
-WHERE accepted_at IS NULL AND expires_at > CURRENT_TIMESTAMP;
\ No newline at end of file
+-- Unique constraint for open invitations (expired ones count until cleanup)
+CREATE UNIQUE INDEX idx_invitations_tenant_email_pending
+ON invitations (tenant_id, email)
+WHERE accepted_at IS NULL;
\ No newline at end of file
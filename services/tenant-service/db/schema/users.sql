@@ -0,0 +1,22 @@
+-- Tenant users table (lives in each tenant_<id> schema, or tenant_shared in RLS mode)
+-- Mirrors tenant_template.users plus pkg/tenant/migrations; the service only inserts
+-- into it after scoping a transaction to the invitation's tenant.
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(254) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) CHECK (role IN ('admin', 'manager', 'sales_rep', 'viewer')) NOT NULL DEFAULT 'sales_rep',
+    status VARCHAR(20) CHECK (status IN ('active', 'inactive', 'pending')) NOT NULL DEFAULT 'pending',
+    email_verified BOOLEAN DEFAULT FALSE,
+    last_login_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES users(id),
+    permissions JSONB DEFAULT '{}',
+    active BOOLEAN DEFAULT true,
+    last_login TIMESTAMPTZ,
+    updated_by INTEGER REFERENCES users(id),
+    deleted_at TIMESTAMPTZ
+);
@@ -12,30 +12,33 @@ import (
 	"time"
 )
 
-const acceptInvitation = `-- name: AcceptInvitation :exec
+const acceptInvitation = `-- name: AcceptInvitation :execrows
 UPDATE invitations
 SET accepted_at = CURRENT_TIMESTAMP
-WHERE token = $1 AND accepted_at IS NULL
+WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > CURRENT_TIMESTAMP
 `
 
-func (q *Queries) AcceptInvitation(ctx context.Context, token string) error {
-	_, err := q.db.Exec(ctx, acceptInvitation, token)
-	return err
+func (q *Queries) AcceptInvitation(ctx context.Context, tokenHash string) (int64, error) {
+	result, err := q.db.Exec(ctx, acceptInvitation, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const checkPendingInvitation = `-- name: CheckPendingInvitation :one
 SELECT EXISTS(
-    SELECT 1 FROM invitations 
-    WHERE tenant_id = $1 
-      AND email = $2 
-      AND accepted_at IS NULL 
+    SELECT 1 FROM invitations
+    WHERE tenant_id = $1
+      AND email = $2
+      AND accepted_at IS NULL
       AND expires_at > CURRENT_TIMESTAMP
 )
 `
 
 type CheckPendingInvitationParams struct {
-	TenantID *string `json:"tenant_id"`
-	Email    string  `json:"email"`
+	TenantID string `json:"tenant_id"`
+	Email    string `json:"email"`
 }
 
 func (q *Queries) CheckPendingInvitation(ctx context.Context, arg CheckPendingInvitationParams) (bool, error) {
@@ -45,45 +48,53 @@ func (q *Queries) CheckPendingInvitation(ctx context.Context, arg CheckPendingIn
 	return exists, err
 }
 
-const cleanupExpiredInvitations = `-- name: CleanupExpiredInvitations :exec
+const cleanupExpiredInvitations = `-- name: CleanupExpiredInvitations :execrows
 DELETE FROM invitations
-WHERE expires_at < CURRENT_TIMESTAMP
+WHERE accepted_at IS NULL AND expires_at < CURRENT_TIMESTAMP
 `
 
-func (q *Queries) CleanupExpiredInvitations(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, cleanupExpiredInvitations)
-	return err
+func (q *Queries) CleanupExpiredInvitations(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, cleanupExpiredInvitations)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const createInvitation = `-- name: CreateInvitation :one
-INSERT INTO invitations (tenant_id, email, role, token, expires_at, invited_by, metadata)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, token, expires_at, created_at
+INSERT INTO invitations (id, tenant_id, email, role, token_hash, expires_at, invited_by, metadata)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, tenant_id, email, role, expires_at, accepted_at, created_at
 `
 
 type CreateInvitationParams struct {
-	TenantID  *string         `json:"tenant_id"`
+	ID        string          `json:"id"`
+	TenantID  string          `json:"tenant_id"`
 	Email     string          `json:"email"`
 	Role      string          `json:"role"`
-	Token     string          `json:"token"`
+	TokenHash string          `json:"token_hash"`
 	ExpiresAt time.Time       `json:"expires_at"`
 	InvitedBy *int32          `json:"invited_by"`
 	Metadata  json.RawMessage `json:"metadata"`
 }
 
 type CreateInvitationRow struct {
-	ID        string    `json:"id"`
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string       `json:"id"`
+	TenantID   string       `json:"tenant_id"`
+	Email      string       `json:"email"`
+	Role       string       `json:"role"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	AcceptedAt sql.NullTime `json:"accepted_at"`
+	CreatedAt  time.Time    `json:"created_at"`
 }
 
 func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (CreateInvitationRow, error) {
 	row := q.db.QueryRow(ctx, createInvitation,
+		arg.ID,
 		arg.TenantID,
 		arg.Email,
 		arg.Role,
-		arg.Token,
+		arg.TokenHash,
 		arg.ExpiresAt,
 		arg.InvitedBy,
 		arg.Metadata,
@@ -91,58 +102,89 @@ func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationPara
 	var i CreateInvitationRow
 	err := row.Scan(
 		&i.ID,
-		&i.Token,
+		&i.TenantID,
+		&i.Email,
+		&i.Role,
 		&i.ExpiresAt,
+		&i.AcceptedAt,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const deleteExpiredInvitation = `-- name: DeleteExpiredInvitation :exec
+DELETE FROM invitations
+WHERE tenant_id = $1
+  AND email = $2
+  AND accepted_at IS NULL
+  AND expires_at <= CURRENT_TIMESTAMP
+`
+
+type DeleteExpiredInvitationParams struct {
+	TenantID string `json:"tenant_id"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) DeleteExpiredInvitation(ctx context.Context, arg DeleteExpiredInvitationParams) error {
+	_, err := q.db.Exec(ctx, deleteExpiredInvitation, arg.TenantID, arg.Email)
+	return err
+}
+
+const deleteTenantInvitations = `-- name: DeleteTenantInvitations :exec
+DELETE FROM public.invitations
+WHERE tenant_id = $1
+`
+
+func (q *Queries) DeleteTenantInvitations(ctx context.Context, tenantID string) error {
+	_, err := q.db.Exec(ctx, deleteTenantInvitations, tenantID)
+	return err
+}
+
 const getInvitationByToken = `-- name: GetInvitationByToken :one
-SELECT i.id, i.tenant_id, i.email, i.role, i.token, i.expires_at, i.accepted_at, i.metadata,
-       t.name as tenant_name, t.subdomain as tenant_subdomain, t.schema_name as tenant_schema
+SELECT i.id, i.tenant_id, i.email, i.role, i.expires_at, i.invited_by,
+       t.name as tenant_name, t.subdomain as tenant_subdomain, t.schema_name as tenant_schema,
+       t.status as tenant_status
 FROM invitations i
 JOIN tenants t ON i.tenant_id = t.id
-WHERE i.token = $1 
-  AND i.accepted_at IS NULL 
+WHERE i.token_hash = $1
+  AND i.accepted_at IS NULL
   AND i.expires_at > CURRENT_TIMESTAMP
+  AND t.deleted_at IS NULL
 `
 
 type GetInvitationByTokenRow struct {
-	ID              string          `json:"id"`
-	TenantID        *string         `json:"tenant_id"`
-	Email           string          `json:"email"`
-	Role            string          `json:"role"`
-	Token           string          `json:"token"`
-	ExpiresAt       time.Time       `json:"expires_at"`
-	AcceptedAt      sql.NullTime    `json:"accepted_at"`
-	Metadata        json.RawMessage `json:"metadata"`
-	TenantName      string          `json:"tenant_name"`
-	TenantSubdomain string          `json:"tenant_subdomain"`
-	TenantSchema    string          `json:"tenant_schema"`
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	Email           string    `json:"email"`
+	Role            string    `json:"role"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	InvitedBy       *int32    `json:"invited_by"`
+	TenantName      string    `json:"tenant_name"`
+	TenantSubdomain string    `json:"tenant_subdomain"`
+	TenantSchema    string    `json:"tenant_schema"`
+	TenantStatus    string    `json:"tenant_status"`
 }
 
-func (q *Queries) GetInvitationByToken(ctx context.Context, token string) (GetInvitationByTokenRow, error) {
-	row := q.db.QueryRow(ctx, getInvitationByToken, token)
+func (q *Queries) GetInvitationByToken(ctx context.Context, tokenHash string) (GetInvitationByTokenRow, error) {
+	row := q.db.QueryRow(ctx, getInvitationByToken, tokenHash)
 	var i GetInvitationByTokenRow
 	err := row.Scan(
 		&i.ID,
 		&i.TenantID,
 		&i.Email,
 		&i.Role,
-		&i.Token,
 		&i.ExpiresAt,
-		&i.AcceptedAt,
-		&i.Metadata,
+		&i.InvitedBy,
 		&i.TenantName,
 		&i.TenantSubdomain,
 		&i.TenantSchema,
+		&i.TenantStatus,
 	)
 	return i, err
 }
 
 const getInvitationsByEmail = `-- name: GetInvitationsByEmail :many
-SELECT i.id, i.tenant_id, i.role, i.token, i.expires_at, i.accepted_at,
+SELECT i.id, i.tenant_id, i.role, i.expires_at, i.accepted_at,
        t.name as tenant_name, t.subdomain as tenant_subdomain
 FROM invitations i
 JOIN tenants t ON i.tenant_id = t.id
@@ -152,9 +194,8 @@ ORDER BY i.created_at DESC
 
 type GetInvitationsByEmailRow struct {
 	ID              string       `json:"id"`
-	TenantID        *string      `json:"tenant_id"`
+	TenantID        string       `json:"tenant_id"`
 	Role            string       `json:"role"`
-	Token           string       `json:"token"`
 	ExpiresAt       time.Time    `json:"expires_at"`
 	AcceptedAt      sql.NullTime `json:"accepted_at"`
 	TenantName      string       `json:"tenant_name"`
@@ -174,7 +215,6 @@ func (q *Queries) GetInvitationsByEmail(ctx context.Context, email string) ([]Ge
 			&i.ID,
 			&i.TenantID,
 			&i.Role,
-			&i.Token,
 			&i.ExpiresAt,
 			&i.AcceptedAt,
 			&i.TenantName,
@@ -195,7 +235,7 @@ SELECT i.id, i.email, i.role, i.expires_at, i.created_at,
        t.name as tenant_name, t.subdomain as tenant_subdomain
 FROM invitations i
 JOIN tenants t ON i.tenant_id = t.id
-WHERE i.accepted_at IS NULL 
+WHERE i.accepted_at IS NULL
   AND i.expires_at > CURRENT_TIMESTAMP
 ORDER BY i.created_at DESC
 `
@@ -239,7 +279,7 @@ func (q *Queries) ListPendingInvitations(ctx context.Context) ([]ListPendingInvi
 }
 
 const listTenantInvitations = `-- name: ListTenantInvitations :many
-SELECT id, email, role, token, expires_at, accepted_at, created_at
+SELECT id, tenant_id, email, role, expires_at, accepted_at, created_at
 FROM invitations
 WHERE tenant_id = $1
 ORDER BY created_at DESC
@@ -247,15 +287,15 @@ ORDER BY created_at DESC
 
 type ListTenantInvitationsRow struct {
 	ID         string       `json:"id"`
+	TenantID   string       `json:"tenant_id"`
 	Email      string       `json:"email"`
 	Role       string       `json:"role"`
-	Token      string       `json:"token"`
 	ExpiresAt  time.Time    `json:"expires_at"`
 	AcceptedAt sql.NullTime `json:"accepted_at"`
 	CreatedAt  time.Time    `json:"created_at"`
 }
 
-func (q *Queries) ListTenantInvitations(ctx context.Context, tenantID *string) ([]ListTenantInvitationsRow, error) {
+func (q *Queries) ListTenantInvitations(ctx context.Context, tenantID string) ([]ListTenantInvitationsRow, error) {
 	rows, err := q.db.Query(ctx, listTenantInvitations, tenantID)
 	if err != nil {
 		return nil, err
@@ -266,9 +306,9 @@ func (q *Queries) ListTenantInvitations(ctx context.Context, tenantID *string) (
 		var i ListTenantInvitationsRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.TenantID,
 			&i.Email,
 			&i.Role,
-			&i.Token,
 			&i.ExpiresAt,
 			&i.AcceptedAt,
 			&i.CreatedAt,
@@ -283,17 +323,20 @@ func (q *Queries) ListTenantInvitations(ctx context.Context, tenantID *string) (
 	return items, nil
 }
 
-const revokeInvitation = `-- name: RevokeInvitation :exec
+const revokeInvitation = `-- name: RevokeInvitation :execrows
 DELETE FROM invitations
 WHERE id = $1 AND tenant_id = $2 AND accepted_at IS NULL
 `
 
 type RevokeInvitationParams struct {
-	ID       string  `json:"id"`
-	TenantID *string `json:"tenant_id"`
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
 }
 
-func (q *Queries) RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) error {
-	_, err := q.db.Exec(ctx, revokeInvitation, arg.ID, arg.TenantID)
-	return err
+func (q *Queries) RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeInvitation, arg.ID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
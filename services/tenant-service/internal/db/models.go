@@ -12,10 +12,10 @@ import (
 
 type Invitation struct {
 	ID         string          `json:"id"`
-	TenantID   *string         `json:"tenant_id"`
+	TenantID   string          `json:"tenant_id"`
 	Email      string          `json:"email"`
 	Role       string          `json:"role"`
-	Token      string          `json:"token"`
+	TokenHash  string          `json:"token_hash"`
 	ExpiresAt  time.Time       `json:"expires_at"`
 	AcceptedAt sql.NullTime    `json:"accepted_at"`
 	InvitedBy  *int32          `json:"invited_by"`
@@ -24,10 +24,43 @@ type Invitation struct {
 }
 
 type Tenant struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Subdomain  string    `json:"subdomain"`
-	SchemaName string    `json:"schema_name"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                 string       `json:"id"`
+	Name               string       `json:"name"`
+	Subdomain          string       `json:"subdomain"`
+	SchemaName         string       `json:"schema_name"`
+	Status             string       `json:"status"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+	DeletedAt          sql.NullTime `json:"deleted_at"`
+	PurgeAfter         sql.NullTime `json:"purge_after"`
+	StatusBeforeDelete *string      `json:"status_before_delete"`
+}
+
+type TenantAuditLog struct {
+	ID        int64           `json:"id"`
+	TenantID  string          `json:"tenant_id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type User struct {
+	ID            int32           `json:"id"`
+	Email         string          `json:"email"`
+	PasswordHash  string          `json:"password_hash"`
+	FirstName     string          `json:"first_name"`
+	LastName      string          `json:"last_name"`
+	Role          string          `json:"role"`
+	Status        string          `json:"status"`
+	EmailVerified *bool           `json:"email_verified"`
+	LastLoginAt   sql.NullTime    `json:"last_login_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	CreatedBy     *int32          `json:"created_by"`
+	Permissions   json.RawMessage `json:"permissions"`
+	Active        *bool           `json:"active"`
+	LastLogin     sql.NullTime    `json:"last_login"`
+	UpdatedBy     *int32          `json:"updated_by"`
+	DeletedAt     sql.NullTime    `json:"deleted_at"`
 }
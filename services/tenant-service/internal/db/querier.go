@@ -9,26 +9,40 @@ import (
 )
 
 type Querier interface {
-	AcceptInvitation(ctx context.Context, token string) error
+	AcceptInvitation(ctx context.Context, tokenHash string) (int64, error)
 	CheckPendingInvitation(ctx context.Context, arg CheckPendingInvitationParams) (bool, error)
 	CheckSchemaNameExists(ctx context.Context, schemaName string) (bool, error)
 	CheckSubdomainExists(ctx context.Context, subdomain string) (bool, error)
-	CleanupExpiredInvitations(ctx context.Context) error
+	CleanupExpiredInvitations(ctx context.Context) (int64, error)
 	CountTenants(ctx context.Context) (int64, error)
 	CreateInvitation(ctx context.Context, arg CreateInvitationParams) (CreateInvitationRow, error)
+	CreateInvitedUser(ctx context.Context, arg CreateInvitedUserParams) (CreateInvitedUserRow, error)
 	CreateTenant(ctx context.Context, arg CreateTenantParams) (CreateTenantRow, error)
-	GetInvitationByToken(ctx context.Context, token string) (GetInvitationByTokenRow, error)
+	CreateTenantAuditEntry(ctx context.Context, arg CreateTenantAuditEntryParams) error
+	DeleteExpiredInvitation(ctx context.Context, arg DeleteExpiredInvitationParams) error
+	DeleteTenant(ctx context.Context, arg DeleteTenantParams) (int64, error)
+	DeleteTenantInvitations(ctx context.Context, tenantID string) error
+	GetInvitationByToken(ctx context.Context, tokenHash string) (GetInvitationByTokenRow, error)
 	GetInvitationsByEmail(ctx context.Context, email string) ([]GetInvitationsByEmailRow, error)
 	GetRecentTenants(ctx context.Context, limit int32) ([]GetRecentTenantsRow, error)
 	GetSchemaNameBySubdomain(ctx context.Context, subdomain string) (string, error)
 	GetTenantByID(ctx context.Context, id string) (Tenant, error)
 	GetTenantBySchemaName(ctx context.Context, schemaName string) (Tenant, error)
 	GetTenantBySubdomain(ctx context.Context, subdomain string) (Tenant, error)
+	HasTenantAuditAction(ctx context.Context, arg HasTenantAuditActionParams) (bool, error)
 	ListAllTenants(ctx context.Context) ([]ListAllTenantsRow, error)
 	ListPendingInvitations(ctx context.Context) ([]ListPendingInvitationsRow, error)
-	ListTenantInvitations(ctx context.Context, tenantID *string) ([]ListTenantInvitationsRow, error)
-	RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) error
+	ListTenantAuditEntries(ctx context.Context, tenantID string) ([]TenantAuditLog, error)
+	ListTenantInvitations(ctx context.Context, tenantID string) ([]ListTenantInvitationsRow, error)
+	ListTenantsByStatus(ctx context.Context, status string) ([]Tenant, error)
+	ListTenantsBySubdomainPrefix(ctx context.Context, prefix string) ([]Tenant, error)
+	ListTenantsDueForPurge(ctx context.Context) ([]Tenant, error)
+	LockTenantStatus(ctx context.Context, id string) (string, error)
+	RestoreTenant(ctx context.Context, id string) error
+	RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (int64, error)
+	SoftDeleteTenant(ctx context.Context, arg SoftDeleteTenantParams) (Tenant, error)
 	UpdateTenantName(ctx context.Context, arg UpdateTenantNameParams) error
+	UpdateTenantStatus(ctx context.Context, arg UpdateTenantStatusParams) error
 }
 
 var _ Querier = (*Queries)(nil)
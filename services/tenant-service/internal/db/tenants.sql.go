@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -50,9 +51,9 @@ func (q *Queries) CountTenants(ctx context.Context) (int64, error) {
 }
 
 const createTenant = `-- name: CreateTenant :one
-INSERT INTO tenants (id, name, subdomain, schema_name)
-VALUES ($1, $2, $3, $4)
-RETURNING id, name, subdomain, schema_name, created_at
+INSERT INTO tenants (id, name, subdomain, schema_name, status)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, subdomain, schema_name, status, created_at
 `
 
 type CreateTenantParams struct {
@@ -60,6 +61,7 @@ type CreateTenantParams struct {
 	Name       string `json:"name"`
 	Subdomain  string `json:"subdomain"`
 	SchemaName string `json:"schema_name"`
+	Status     string `json:"status"`
 }
 
 type CreateTenantRow struct {
@@ -67,6 +69,7 @@ type CreateTenantRow struct {
 	Name       string    `json:"name"`
 	Subdomain  string    `json:"subdomain"`
 	SchemaName string    `json:"schema_name"`
+	Status     string    `json:"status"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -76,6 +79,7 @@ func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Cre
 		arg.Name,
 		arg.Subdomain,
 		arg.SchemaName,
+		arg.Status,
 	)
 	var i CreateTenantRow
 	err := row.Scan(
@@ -83,11 +87,33 @@ func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Cre
 		&i.Name,
 		&i.Subdomain,
 		&i.SchemaName,
+		&i.Status,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const deleteTenant = `-- name: DeleteTenant :execrows
+DELETE FROM public.tenants
+WHERE id = $1
+  AND (NOT $2::bool OR deleted_at IS NOT NULL)
+  AND (NOT $3::bool OR purge_after <= CURRENT_TIMESTAMP)
+`
+
+type DeleteTenantParams struct {
+	ID             string `json:"id"`
+	RequireDeleted bool   `json:"require_deleted"`
+	RequireDue     bool   `json:"require_due"`
+}
+
+func (q *Queries) DeleteTenant(ctx context.Context, arg DeleteTenantParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTenant, arg.ID, arg.RequireDeleted, arg.RequireDue)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getRecentTenants = `-- name: GetRecentTenants :many
 SELECT id, name, subdomain, schema_name, created_at
 FROM tenants
@@ -141,7 +167,7 @@ func (q *Queries) GetSchemaNameBySubdomain(ctx context.Context, subdomain string
 }
 
 const getTenantByID = `-- name: GetTenantByID :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE id = $1
 `
@@ -154,14 +180,18 @@ func (q *Queries) GetTenantByID(ctx context.Context, id string) (Tenant, error)
 		&i.Name,
 		&i.Subdomain,
 		&i.SchemaName,
+		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PurgeAfter,
+		&i.StatusBeforeDelete,
 	)
 	return i, err
 }
 
 const getTenantBySchemaName = `-- name: GetTenantBySchemaName :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE schema_name = $1
 `
@@ -174,14 +204,18 @@ func (q *Queries) GetTenantBySchemaName(ctx context.Context, schemaName string)
 		&i.Name,
 		&i.Subdomain,
 		&i.SchemaName,
+		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PurgeAfter,
+		&i.StatusBeforeDelete,
 	)
 	return i, err
 }
 
 const getTenantBySubdomain = `-- name: GetTenantBySubdomain :one
-SELECT id, name, subdomain, schema_name, created_at, updated_at
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
 FROM tenants
 WHERE subdomain = $1
 `
@@ -194,14 +228,18 @@ func (q *Queries) GetTenantBySubdomain(ctx context.Context, subdomain string) (T
 		&i.Name,
 		&i.Subdomain,
 		&i.SchemaName,
+		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PurgeAfter,
+		&i.StatusBeforeDelete,
 	)
 	return i, err
 }
 
 const listAllTenants = `-- name: ListAllTenants :many
-SELECT id, name, subdomain, schema_name, created_at
+SELECT id, name, subdomain, schema_name, status, created_at
 FROM tenants
 ORDER BY name
 `
@@ -211,6 +249,7 @@ type ListAllTenantsRow struct {
 	Name       string    `json:"name"`
 	Subdomain  string    `json:"subdomain"`
 	SchemaName string    `json:"schema_name"`
+	Status     string    `json:"status"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -228,7 +267,122 @@ func (q *Queries) ListAllTenants(ctx context.Context) ([]ListAllTenantsRow, erro
 			&i.Name,
 			&i.Subdomain,
 			&i.SchemaName,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantsByStatus = `-- name: ListTenantsByStatus :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE status = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListTenantsByStatus(ctx context.Context, status string) ([]Tenant, error) {
+	rows, err := q.db.Query(ctx, listTenantsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tenant{}
+	for rows.Next() {
+		var i Tenant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Subdomain,
+			&i.SchemaName,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PurgeAfter,
+			&i.StatusBeforeDelete,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantsBySubdomainPrefix = `-- name: ListTenantsBySubdomainPrefix :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE starts_with(subdomain, $1::text)
+ORDER BY subdomain
+`
+
+func (q *Queries) ListTenantsBySubdomainPrefix(ctx context.Context, prefix string) ([]Tenant, error) {
+	rows, err := q.db.Query(ctx, listTenantsBySubdomainPrefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tenant{}
+	for rows.Next() {
+		var i Tenant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Subdomain,
+			&i.SchemaName,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PurgeAfter,
+			&i.StatusBeforeDelete,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantsDueForPurge = `-- name: ListTenantsDueForPurge :many
+SELECT id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+FROM tenants
+WHERE deleted_at IS NOT NULL AND purge_after <= CURRENT_TIMESTAMP
+ORDER BY purge_after
+`
+
+func (q *Queries) ListTenantsDueForPurge(ctx context.Context) ([]Tenant, error) {
+	rows, err := q.db.Query(ctx, listTenantsDueForPurge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tenant{}
+	for rows.Next() {
+		var i Tenant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Subdomain,
+			&i.SchemaName,
+			&i.Status,
 			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PurgeAfter,
+			&i.StatusBeforeDelete,
 		); err != nil {
 			return nil, err
 		}
@@ -240,6 +394,60 @@ func (q *Queries) ListAllTenants(ctx context.Context) ([]ListAllTenantsRow, erro
 	return items, nil
 }
 
+const lockTenantStatus = `-- name: LockTenantStatus :one
+SELECT status FROM tenants
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockTenantStatus(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRow(ctx, lockTenantStatus, id)
+	var status string
+	err := row.Scan(&status)
+	return status, err
+}
+
+const restoreTenant = `-- name: RestoreTenant :exec
+UPDATE tenants
+SET status = COALESCE(status_before_delete, 'active'), status_before_delete = NULL, deleted_at = NULL, purge_after = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreTenant(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, restoreTenant, id)
+	return err
+}
+
+const softDeleteTenant = `-- name: SoftDeleteTenant :one
+UPDATE tenants
+SET status_before_delete = status, status = 'suspended', deleted_at = CURRENT_TIMESTAMP, purge_after = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, subdomain, schema_name, status, created_at, updated_at, deleted_at, purge_after, status_before_delete
+`
+
+type SoftDeleteTenantParams struct {
+	ID         string       `json:"id"`
+	PurgeAfter sql.NullTime `json:"purge_after"`
+}
+
+func (q *Queries) SoftDeleteTenant(ctx context.Context, arg SoftDeleteTenantParams) (Tenant, error) {
+	row := q.db.QueryRow(ctx, softDeleteTenant, arg.ID, arg.PurgeAfter)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Subdomain,
+		&i.SchemaName,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PurgeAfter,
+		&i.StatusBeforeDelete,
+	)
+	return i, err
+}
+
 const updateTenantName = `-- name: UpdateTenantName :exec
 UPDATE tenants
 SET name = $2, updated_at = CURRENT_TIMESTAMP
@@ -255,3 +463,19 @@ func (q *Queries) UpdateTenantName(ctx context.Context, arg UpdateTenantNamePara
 	_, err := q.db.Exec(ctx, updateTenantName, arg.ID, arg.Name)
 	return err
 }
+
+const updateTenantStatus = `-- name: UpdateTenantStatus :exec
+UPDATE tenants
+SET status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateTenantStatusParams struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTenantStatus(ctx context.Context, arg UpdateTenantStatusParams) error {
+	_, err := q.db.Exec(ctx, updateTenantStatus, arg.ID, arg.Status)
+	return err
+}
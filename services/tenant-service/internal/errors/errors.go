@@ -34,6 +34,20 @@ var (
 		return fmt.Errorf("DUPLICATE ERROR: subdomain already exists")
 	}
 
+	ErrDuplicate = func(msg string) error {
+		return fmt.Errorf("DUPLICATE ERROR: %s", msg)
+	}
+
+	// Invalid lifecycle state errors
+	ErrInvalidState = func(msg string) error {
+		return fmt.Errorf("STATE ERROR: %s", msg)
+	}
+
+	// Operation not permitted in this environment
+	ErrForbidden = func(msg string) error {
+		return fmt.Errorf("FORBIDDEN: %s", msg)
+	}
+
 	// Not implemented errors
 	ErrNotImplemented = func(msg string) error {
 		return fmt.Errorf("NOT IMPLEMENTED: %s", msg)
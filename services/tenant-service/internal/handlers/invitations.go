@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"crm-platform/tenant-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInvitation handles POST /internal/tenants/:id/invitations
+// The response carries the invitation token once; the caller delivers it to the invitee.
+func (h *TenantHandler) CreateInvitation(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	invitation, err := h.tenantService.CreateInvitation(c.Request.Context(), tenantID, req, actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations handles GET /internal/tenants/:id/invitations
+func (h *TenantHandler) ListInvitations(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	invitations, err := h.tenantService.ListInvitations(c.Request.Context(), tenantID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation handles DELETE /internal/tenants/:id/invitations/:invitationId
+func (h *TenantHandler) RevokeInvitation(c *gin.Context) {
+	tenantID := c.Param("id")
+	invitationID := c.Param("invitationId")
+	if tenantID == "" || invitationID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID and invitation ID required",
+		})
+		return
+	}
+
+	if err := h.tenantService.RevokeInvitation(c.Request.Context(), tenantID, invitationID, actorFromRequest(c)); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// AcceptInvitation handles POST /internal/invitations/accept
+// Creates the invited user in the tenant; the token is in the body so it stays out of access logs.
+func (h *TenantHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request format: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.tenantService.AcceptInvitation(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// CleanupExpiredInvitations handles POST /internal/invitations/cleanup
+func (h *TenantHandler) CleanupExpiredInvitations(c *gin.Context) {
+	result, err := h.tenantService.CleanupExpiredInvitations(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"crm-platform/tenant-service/internal/models"
+	"crm-platform/tenant-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationHandler handles HTTP requests for tenant schema migrations
+type MigrationHandler struct {
+	migrationService *services.MigrationService
+}
+
+// NewMigrationHandler creates a new migration handler
+func NewMigrationHandler(migrationService *services.MigrationService) *MigrationHandler {
+	return &MigrationHandler{
+		migrationService: migrationService,
+	}
+}
+
+// GetStatus handles GET /internal/migrations/status
+func (h *MigrationHandler) GetStatus(c *gin.Context) {
+	status, err := h.migrationService.GetStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ApplyMigrations handles POST /internal/migrations/apply
+func (h *MigrationHandler) ApplyMigrations(c *gin.Context) {
+	var req models.ApplyMigrationsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	// Allow ?dry_run=true for quick checks without a body
+	if c.Query("dry_run") == "true" {
+		req.DryRun = true
+	}
+
+	report, err := h.migrationService.ApplyMigrations(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusMultiStatus // 207 when some schemas failed
+	}
+	c.JSON(status, report)
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"crm-platform/pkg/tenant"
 	"crm-platform/tenant-service/internal/models"
 	"crm-platform/tenant-service/internal/services"
 
@@ -79,9 +82,22 @@ func (h *TenantHandler) GetTenantBySubdomain(c *gin.Context) {
 	c.JSON(http.StatusOK, tenant)
 }
 
-// ListTenants handles GET /internal/tenants
+// ListTenants handles GET /internal/tenants (optionally ?status=pending|active|suspended)
 func (h *TenantHandler) ListTenants(c *gin.Context) {
-	tenants, err := h.tenantService.ListTenants(c.Request.Context())
+	var tenants []models.TenantResponse
+	var err error
+
+	switch status := c.Query("status"); status {
+	case "":
+		tenants, err = h.tenantService.ListTenants(c.Request.Context())
+	case tenant.StatusPending, tenant.StatusActive, tenant.StatusSuspended:
+		tenants, err = h.tenantService.ListTenantsByStatus(c.Request.Context(), status)
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid status filter: " + status,
+		})
+		return
+	}
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
@@ -140,6 +156,73 @@ func (h *TenantHandler) GetTenantHealth(c *gin.Context) {
 	}
 }
 
+// RetryProvisioning handles POST /internal/tenants/:id/provision
+func (h *TenantHandler) RetryProvisioning(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	tenant, err := h.tenantService.RetryProvisioning(c.Request.Context(), tenantID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// SuspendTenant handles POST /internal/tenants/:id/suspend
+func (h *TenantHandler) SuspendTenant(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	var req models.SuspendTenantRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	tenant, err := h.tenantService.SuspendTenant(c.Request.Context(), tenantID, req.Reason, actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// ReactivateTenant handles POST /internal/tenants/:id/reactivate
+func (h *TenantHandler) ReactivateTenant(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	tenant, err := h.tenantService.ReactivateTenant(c.Request.Context(), tenantID, actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
 // CreateTestTenants handles POST /internal/test-tenants
 func (h *TenantHandler) CreateTestTenants(c *gin.Context) {
 	var req models.BulkCreateTenantsRequest
@@ -166,6 +249,7 @@ func (h *TenantHandler) CreateTestTenants(c *gin.Context) {
 }
 
 // DeleteTestTenants handles DELETE /internal/test-tenants
+// Immediately purges every tenant whose subdomain starts with ?prefix= (required).
 func (h *TenantHandler) DeleteTestTenants(c *gin.Context) {
 	if c.Query("confirm") != "true" {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -174,15 +258,142 @@ func (h *TenantHandler) DeleteTestTenants(c *gin.Context) {
 		return
 	}
 
-	err := h.tenantService.DeleteTestTenants(c.Request.Context())
+	prefix := c.Query("prefix")
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Subdomain prefix required (?prefix=)",
+		})
+		return
+	}
+	result, err := h.tenantService.DeleteTestTenants(c.Request.Context(), prefix, actorFromRequest(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
+		h.handleServiceError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Failed) > 0 {
+		status = http.StatusMultiStatus // 207 for mixed results
+	}
+	c.JSON(status, result)
+}
+
+// DeleteTenant handles DELETE /internal/tenants/:id
+// Soft-deletes the tenant; ?retention_days= overrides the default purge window.
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	retention := services.DefaultRetentionPeriod
+	if days := c.Query("retention_days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid retention_days: " + days,
+			})
+			return
+		}
+		retention = time.Duration(n) * 24 * time.Hour
+	}
+
+	tenant, err := h.tenantService.DeleteTenant(c.Request.Context(), tenantID, retention, actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// RestoreTenant handles POST /internal/tenants/:id/restore
+func (h *TenantHandler) RestoreTenant(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	tenant, err := h.tenantService.RestoreTenant(c.Request.Context(), tenantID, actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// PurgeTenant handles DELETE /internal/tenants/:id/purge
+func (h *TenantHandler) PurgeTenant(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	if c.Query("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Add ?confirm=true to confirm purge",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Test tenants deleted"})
+	if err := h.tenantService.PurgeTenant(c.Request.Context(), tenantID, actorFromRequest(c)); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tenant purged"})
+}
+
+// PurgeDueTenants handles POST /internal/tenants/purge
+func (h *TenantHandler) PurgeDueTenants(c *gin.Context) {
+	result, err := h.tenantService.PurgeDueTenants(c.Request.Context(), actorFromRequest(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Failed) > 0 {
+		status = http.StatusMultiStatus // 207 for mixed results
+	}
+	c.JSON(status, result)
+}
+
+// GetTenantAudit handles GET /internal/tenants/:id/audit
+func (h *TenantHandler) GetTenantAudit(c *gin.Context) {
+	tenantID := c.Param("id")
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Tenant ID required",
+		})
+		return
+	}
+
+	entries, err := h.tenantService.GetTenantAudit(c.Request.Context(), tenantID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// actorFromRequest identifies the caller for audit entries
+func actorFromRequest(c *gin.Context) string {
+	if actor := c.GetHeader("X-Actor"); actor != "" {
+		return actor
+	}
+	return "internal-api"
 }
 
 // handleServiceError converts service errors to appropriate HTTP responses
@@ -193,8 +404,10 @@ func (h *TenantHandler) handleServiceError(c *gin.Context, err error) {
 	switch {
 	case strings.Contains(errMsg, "NOT FOUND"):
 		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: errMsg})
-	case strings.Contains(errMsg, "DUPLICATE ERROR"):
+	case strings.Contains(errMsg, "DUPLICATE ERROR"), strings.Contains(errMsg, "STATE ERROR"):
 		c.JSON(http.StatusConflict, models.ErrorResponse{Error: errMsg})
+	case strings.Contains(errMsg, "FORBIDDEN"):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: errMsg})
 	case strings.Contains(errMsg, "VALIDATION ERROR"):
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: errMsg})
 	case strings.Contains(errMsg, "NOT IMPLEMENTED"):
@@ -14,8 +14,10 @@ type UpdateTenantRequest struct {
 
 // CreateInvitationRequest represents a request to invite a user to a tenant
 type CreateInvitationRequest struct {
-	Email string `json:"email" binding:"required,email"`
-	Role  string `json:"role" binding:"required,oneof=admin member viewer"`
+	Email         string `json:"email" binding:"required,email,max=254"`
+	Role          string `json:"role" binding:"required,oneof=admin manager sales_rep viewer"`
+	InvitedBy     *int32 `json:"invited_by" binding:"omitempty,min=1"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=30"`
 }
 
 // BulkCreateTenantsRequest represents a request to create multiple test tenants
@@ -25,5 +27,20 @@ type BulkCreateTenantsRequest struct {
 
 // AcceptInvitationRequest represents a request to accept an invitation
 type AcceptInvitationRequest struct {
-	Token string `json:"token" binding:"required"`
+	Token     string `json:"token" binding:"required,max=128"`
+	FirstName string `json:"first_name" binding:"required,max=100"`
+	LastName  string `json:"last_name" binding:"required,max=100"`
+	Password  string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// ApplyMigrationsRequest represents a request to migrate tenant schemas
+type ApplyMigrationsRequest struct {
+	DryRun      bool     `json:"dry_run"`
+	Concurrency int      `json:"concurrency" binding:"omitempty,min=1,max=32"`
+	TenantIDs   []string `json:"tenant_ids" binding:"omitempty,dive,len=26"`
+}
+
+// SuspendTenantRequest represents a request to suspend a tenant
+type SuspendTenantRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
 }
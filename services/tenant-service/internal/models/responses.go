@@ -1,17 +1,23 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"crm-platform/pkg/tenant"
 )
 
 // TenantResponse represents a tenant with all details
 type TenantResponse struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Subdomain  string    `json:"subdomain"`
-	SchemaName string    `json:"schema_name"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Subdomain  string     `json:"subdomain"`
+	SchemaName string     `json:"schema_name"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	PurgeAfter *time.Time `json:"purge_after,omitempty"`
 }
 
 // TenantHealthResponse represents the health status of a tenant
@@ -25,7 +31,7 @@ type TenantHealthResponse struct {
 // InvitationResponse represents an invitation with details
 type InvitationResponse struct {
 	ID         string     `json:"id"`
-	TenantID   *string    `json:"tenant_id"`
+	TenantID   string     `json:"tenant_id"`
 	Email      string     `json:"email"`
 	Role       string     `json:"role"`
 	ExpiresAt  time.Time  `json:"expires_at"`
@@ -33,6 +39,27 @@ type InvitationResponse struct {
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
+// CreateInvitationResponse represents a new invitation
+// Token is only returned here; the registry stores its hash.
+type CreateInvitationResponse struct {
+	InvitationResponse
+	Token string `json:"token"`
+}
+
+// AcceptInvitationResponse represents the user created by accepting an invitation
+type AcceptInvitationResponse struct {
+	UserID          int32  `json:"user_id"`
+	TenantID        string `json:"tenant_id"`
+	TenantSubdomain string `json:"tenant_subdomain"`
+	Email           string `json:"email"`
+	Role            string `json:"role"`
+}
+
+// CleanupInvitationsResponse represents the result of an expired invitation cleanup
+type CleanupInvitationsResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
 // BulkCreateTenantsResponse represents the result of bulk tenant creation
 type BulkCreateTenantsResponse struct {
 	Created []TenantResponse       `json:"created"`
@@ -46,6 +73,36 @@ type BulkCreationFailure struct {
 	Error     string `json:"error"`
 }
 
+// MigrationStatusResponse represents the migration state of all tenant schemas
+type MigrationStatusResponse struct {
+	LatestVersion  int                   `json:"latest_version"`
+	PendingSchemas int                   `json:"pending_schemas"`
+	Schemas        []tenant.SchemaStatus `json:"schemas"`
+}
+
+// TenantAuditEntry represents one recorded tenant lifecycle action
+type TenantAuditEntry struct {
+	ID        int64           `json:"id"`
+	TenantID  string          `json:"tenant_id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Details   json.RawMessage `json:"details"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PurgeTenantsResponse represents the result of a hard purge run
+type PurgeTenantsResponse struct {
+	Purged []string             `json:"purged"`
+	Failed []TenantPurgeFailure `json:"failed,omitempty"`
+}
+
+// TenantPurgeFailure represents a tenant that could not be purged
+type TenantPurgeFailure struct {
+	TenantID  string `json:"tenant_id"`
+	Subdomain string `json:"subdomain"`
+	Error     string `json:"error"`
+}
+
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/db"
+	"crm-platform/tenant-service/internal/errors"
+	"crm-platform/tenant-service/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultInvitationTTL is how long an invitation can be accepted unless the request says otherwise
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// invitationBcryptCost matches the cost auth-service uses, so invited users'
+// hashes are indistinguishable from ones set through a password reset
+const invitationBcryptCost = 12
+
+// pgUniqueViolation is the PostgreSQL error code for a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// CreateInvitation invites email to a tenant with role
+// Expired invitations for the same address are cleared first; an open one is a
+// duplicate. The returned token is shown once: only its hash is stored.
+func (s *TenantService) CreateInvitation(ctx context.Context, tenantID string, req models.CreateInvitationRequest, actor string) (*models.CreateInvitationResponse, error) {
+	tenantRecord, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+	if tenantRecord.DeletedAt.Valid || tenantRecord.Status != tenant.StatusActive {
+		return nil, errors.ErrInvalidState(fmt.Sprintf("tenant is %s, only active tenants can invite users", tenantRecord.Status))
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	ttl := DefaultInvitationTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, errors.ErrHandler(fmt.Sprintf("failed to generate invitation token: %v", err))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	err = qtx.DeleteExpiredInvitation(ctx, db.DeleteExpiredInvitationParams{TenantID: tenantID, Email: email})
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to clear expired invitation: %v", err))
+	}
+
+	pending, err := qtx.CheckPendingInvitation(ctx, db.CheckPendingInvitationParams{TenantID: tenantID, Email: email})
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to check pending invitations: %v", err))
+	}
+	if pending {
+		return nil, errors.ErrDuplicate("a pending invitation already exists for this email")
+	}
+
+	invitation, err := qtx.CreateInvitation(ctx, db.CreateInvitationParams{
+		ID:        ulid.Make().String(),
+		TenantID:  tenantID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+		InvitedBy: req.InvitedBy,
+		Metadata:  json.RawMessage(`{}`),
+	})
+	if isUniqueViolation(err) {
+		return nil, errors.ErrDuplicate("a pending invitation already exists for this email")
+	}
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to create invitation: %v", err))
+	}
+
+	details := map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         email,
+		"role":          req.Role,
+	}
+	if err := recordAudit(ctx, qtx, tenantID, AuditActionInvite, actor, details); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to commit invitation: %v", err))
+	}
+
+	return &models.CreateInvitationResponse{
+		InvitationResponse: models.InvitationResponse{
+			ID:        invitation.ID,
+			TenantID:  invitation.TenantID,
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			ExpiresAt: invitation.ExpiresAt,
+			CreatedAt: invitation.CreatedAt,
+		},
+		Token: token,
+	}, nil
+}
+
+// AcceptInvitation redeems an invitation token and creates the invited user in
+// the tenant's schema (tenant_shared in RLS mode)
+// Marking the invitation accepted and inserting the user share one transaction,
+// so a token is redeemed exactly once and never without its user.
+func (s *TenantService) AcceptInvitation(ctx context.Context, req models.AcceptInvitationRequest) (*models.AcceptInvitationResponse, error) {
+	tokenHash := hashInvitationToken(req.Token)
+	invitation, err := s.queries.GetInvitationByToken(ctx, tokenHash)
+	if err == pgx.ErrNoRows {
+		return nil, errors.ErrNotFound("invitation not found or expired")
+	}
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to load invitation: %v", err))
+	}
+	if invitation.TenantStatus != tenant.StatusActive {
+		return nil, errors.ErrInvalidState(fmt.Sprintf("tenant is %s, invitations cannot be accepted", invitation.TenantStatus))
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), invitationBcryptCost)
+	if err != nil {
+		return nil, errors.ErrValidation(fmt.Sprintf("invalid password: %v", err))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	// Registry writes first, while the search path is still public
+	qtx := s.queries.WithTx(tx)
+	accepted, err := qtx.AcceptInvitation(ctx, tokenHash)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to accept invitation: %v", err))
+	}
+	if accepted == 0 {
+		return nil, errors.ErrNotFound("invitation not found or expired") // accepted concurrently
+	}
+
+	details := map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+	}
+	if err := recordAudit(ctx, qtx, invitation.TenantID, AuditActionAccept, invitation.Email, details); err != nil {
+		return nil, err
+	}
+
+	// Then scope the rest of the transaction to the invitation's tenant
+	if s.isolation == tenant.IsolationRLS {
+		err = tenant.SetTransactionTenant(ctx, tx, invitation.TenantID)
+	} else {
+		err = tenant.SetLocalSearchPath(ctx, tx, invitation.TenantSchema)
+	}
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to scope transaction to tenant: %v", err))
+	}
+
+	user, err := qtx.CreateInvitedUser(ctx, db.CreateInvitedUserParams{
+		Email:        invitation.Email,
+		PasswordHash: string(passwordHash),
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Role:         invitation.Role,
+		CreatedBy:    invitation.InvitedBy,
+	})
+	if isUniqueViolation(err) {
+		return nil, errors.ErrDuplicate("a user with this email already exists in the tenant")
+	}
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to create user: %v", err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to commit invitation acceptance: %v", err))
+	}
+
+	log.Printf("Invitation %s accepted: user %d created in tenant %s", invitation.ID, user.ID, invitation.TenantID)
+	return &models.AcceptInvitationResponse{
+		UserID:          user.ID,
+		TenantID:        invitation.TenantID,
+		TenantSubdomain: invitation.TenantSubdomain,
+		Email:           user.Email,
+		Role:            user.Role,
+	}, nil
+}
+
+// ListInvitations lists a tenant's invitations, newest first
+func (s *TenantService) ListInvitations(ctx context.Context, tenantID string) ([]models.InvitationResponse, error) {
+	if _, err := s.queries.GetTenantByID(ctx, tenantID); err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+
+	invitations, err := s.queries.ListTenantInvitations(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to list invitations: %v", err))
+	}
+
+	result := make([]models.InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		result[i] = models.InvitationResponse{
+			ID:        inv.ID,
+			TenantID:  inv.TenantID,
+			Email:     inv.Email,
+			Role:      inv.Role,
+			ExpiresAt: inv.ExpiresAt,
+			CreatedAt: inv.CreatedAt,
+		}
+		if inv.AcceptedAt.Valid {
+			result[i].AcceptedAt = &inv.AcceptedAt.Time
+		}
+	}
+
+	return result, nil
+}
+
+// RevokeInvitation deletes a tenant's pending invitation so its token stops working
+func (s *TenantService) RevokeInvitation(ctx context.Context, tenantID, invitationID, actor string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	revoked, err := qtx.RevokeInvitation(ctx, db.RevokeInvitationParams{ID: invitationID, TenantID: tenantID})
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to revoke invitation: %v", err))
+	}
+	if revoked == 0 {
+		return errors.ErrNotFound("pending invitation not found")
+	}
+
+	if err := recordAudit(ctx, qtx, tenantID, AuditActionRevoke, actor, map[string]any{"invitation_id": invitationID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to commit invitation revocation: %v", err))
+	}
+
+	return nil
+}
+
+// CleanupExpiredInvitations deletes pending invitations past their expiry
+// Accepted invitations are kept as a record of who joined through them.
+func (s *TenantService) CleanupExpiredInvitations(ctx context.Context) (*models.CleanupInvitationsResponse, error) {
+	deleted, err := s.queries.CleanupExpiredInvitations(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to clean up invitations: %v", err))
+	}
+
+	return &models.CleanupInvitationsResponse{Deleted: deleted}, nil
+}
+
+// StartInvitationCleanup runs CleanupExpiredInvitations every interval until ctx is cancelled
+func (s *TenantService) StartInvitationCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := s.CleanupExpiredInvitations(ctx)
+				if err != nil {
+					log.Printf("Scheduled invitation cleanup failed: %v", err)
+					continue
+				}
+				if result.Deleted > 0 {
+					log.Printf("Scheduled invitation cleanup: %d expired invitations deleted", result.Deleted)
+				}
+			}
+		}
+	}()
+}
+
+// newInvitationToken returns a random URL-safe token and the hash stored for it
+func newInvitationToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashInvitationToken(token), nil
+}
+
+// hashInvitationToken returns the hex SHA-256 of token (invitations.token_hash)
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/errors"
+	"crm-platform/tenant-service/internal/models"
+)
+
+// MigrationService handles tenant schema migrations
+type MigrationService struct {
+	migrator *tenant.Migrator
+}
+
+// NewMigrationService creates a new migration service using the embedded tenant migrations
+func NewMigrationService(pool *database.Pool) (*MigrationService, error) {
+	migrations, err := tenant.DefaultMigrations()
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to load tenant migrations: %v", err))
+	}
+
+	return &MigrationService{
+		migrator: tenant.NewMigrator(pool, migrations),
+	}, nil
+}
+
+// Migrator exposes the underlying migrator for provisioning
+func (s *MigrationService) Migrator() *tenant.Migrator {
+	return s.migrator
+}
+
+// MigrateTemplate applies pending migrations to the template schema
+// Runs at startup so new tenants are cloned from the latest version and readiness
+// (which checks the template version) does not wait for a manual apply.
+func (s *MigrationService) MigrateTemplate(ctx context.Context) (*tenant.SchemaResult, error) {
+	result := s.migrator.MigrateSchema(ctx, tenant.TemplateSchemaName)
+	if result.Error != "" {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to migrate template schema: %s", result.Error))
+	}
+	return &result, nil
+}
+
+// GetStatus reports current and pending migration versions for every tenant schema
+func (s *MigrationService) GetStatus(ctx context.Context) (*models.MigrationStatusResponse, error) {
+	statuses, err := s.migrator.Status(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to get migration status: %v", err))
+	}
+
+	response := &models.MigrationStatusResponse{
+		LatestVersion: s.migrator.LatestVersion(),
+		Schemas:       statuses,
+	}
+	for _, status := range statuses {
+		if len(status.Pending) > 0 {
+			response.PendingSchemas++
+		}
+	}
+
+	return response, nil
+}
+
+// ApplyMigrations migrates all (or the requested) tenant schemas
+func (s *MigrationService) ApplyMigrations(ctx context.Context, req models.ApplyMigrationsRequest) (*tenant.MigrationReport, error) {
+	schemas := make([]string, 0, len(req.TenantIDs))
+	for _, tenantID := range req.TenantIDs {
+		schemas = append(schemas, tenant.GenerateSchemaName(tenantID))
+	}
+
+	report, err := s.migrator.MigrateAll(ctx, tenant.MigrateOptions{
+		DryRun:      req.DryRun,
+		Concurrency: req.Concurrency,
+		Schemas:     schemas,
+	})
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to run migrations: %v", err))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/db"
+	"crm-platform/tenant-service/internal/errors"
+	"crm-platform/tenant-service/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultRetentionPeriod is how long a soft-deleted tenant is kept before hard purge
+const DefaultRetentionPeriod = 30 * 24 * time.Hour
+
+// Audit actions recorded in tenant_audit_log
+const (
+	AuditActionSoftDelete = "soft_delete"
+	AuditActionRestore    = "restore"
+	AuditActionPurge      = "purge"
+	AuditActionSuspend    = "suspend"
+	AuditActionReactivate = "reactivate"
+	AuditActionInvite     = "invite"
+	AuditActionAccept     = "accept_invitation"
+	AuditActionRevoke     = "revoke_invitation"
+)
+
+// DeleteTenant soft-deletes a tenant: access is suspended and a hard purge is
+// scheduled after the retention period. Deleting an already deleted tenant
+// returns its current state unchanged.
+func (s *TenantService) DeleteTenant(ctx context.Context, tenantID string, retention time.Duration, actor string) (*models.TenantResponse, error) {
+	if retention <= 0 {
+		retention = DefaultRetentionPeriod
+	}
+
+	existing, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+	if existing.DeletedAt.Valid {
+		return toTenantResponse(existing), nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	purgeAfter := time.Now().Add(retention)
+	deleted, err := qtx.SoftDeleteTenant(ctx, db.SoftDeleteTenantParams{
+		ID:         tenantID,
+		PurgeAfter: sql.NullTime{Time: purgeAfter, Valid: true},
+	})
+	if err == pgx.ErrNoRows {
+		// Deleted concurrently - report the current state
+		return s.GetTenant(ctx, tenantID)
+	}
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to delete tenant: %v", err))
+	}
+
+	details := map[string]any{
+		"previous_status": existing.Status,
+		"purge_after":     purgeAfter.UTC().Format(time.RFC3339),
+	}
+	if err := recordAudit(ctx, qtx, tenantID, AuditActionSoftDelete, actor, details); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to commit tenant deletion: %v", err))
+	}
+
+	return toTenantResponse(deleted), nil
+}
+
+// RestoreTenant reverses a soft delete before the tenant is purged
+func (s *TenantService) RestoreTenant(ctx context.Context, tenantID string, actor string) (*models.TenantResponse, error) {
+	existing, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+	if !existing.DeletedAt.Valid {
+		return toTenantResponse(existing), nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	if err := qtx.RestoreTenant(ctx, tenantID); err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to restore tenant: %v", err))
+	}
+
+	details := map[string]any{
+		"deleted_at": existing.DeletedAt.Time.UTC().Format(time.RFC3339),
+	}
+	if err := recordAudit(ctx, qtx, tenantID, AuditActionRestore, actor, details); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to commit tenant restore: %v", err))
+	}
+
+	return s.GetTenant(ctx, tenantID)
+}
+
+// PurgeTenant permanently removes a soft-deleted tenant: its schema, invitations
+// and registry row are dropped in one transaction. Purging a tenant that was
+// already purged succeeds without doing anything.
+func (s *TenantService) PurgeTenant(ctx context.Context, tenantID string, actor string) error {
+	existing, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err == pgx.ErrNoRows {
+		purged, auditErr := s.queries.HasTenantAuditAction(ctx, db.HasTenantAuditActionParams{
+			TenantID: tenantID,
+			Action:   AuditActionPurge,
+		})
+		if auditErr == nil && purged {
+			return nil
+		}
+		return errors.ErrNotFound("tenant not found")
+	}
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to get tenant: %v", err))
+	}
+
+	if !existing.DeletedAt.Valid {
+		return errors.ErrInvalidState("tenant must be deleted before it can be purged")
+	}
+
+	return s.purge(ctx, existing, actor, purgeIfDeleted)
+}
+
+// PurgeDueTenants hard-purges every soft-deleted tenant past its retention window
+func (s *TenantService) PurgeDueTenants(ctx context.Context, actor string) (*models.PurgeTenantsResponse, error) {
+	due, err := s.queries.ListTenantsDueForPurge(ctx)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to list tenants due for purge: %v", err))
+	}
+
+	return s.purgeAll(ctx, due, actor, purgeIfDue), nil
+}
+
+// DeleteTestTenants immediately purges all tenants whose subdomain starts with
+// prefix (development only, see EnableTestTenantDeletion)
+func (s *TenantService) DeleteTestTenants(ctx context.Context, prefix string, actor string) (*models.PurgeTenantsResponse, error) {
+	if !s.testTenantDeletion {
+		return nil, errors.ErrForbidden("bulk tenant deletion is only available in development")
+	}
+	if prefix == "" {
+		return nil, errors.ErrValidation("subdomain prefix is required")
+	}
+
+	tenants, err := s.queries.ListTenantsBySubdomainPrefix(ctx, prefix)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to list test tenants: %v", err))
+	}
+
+	return s.purgeAll(ctx, tenants, actor, purgeAlways), nil
+}
+
+// GetTenantAudit returns the lifecycle audit trail for a tenant (including purged tenants)
+func (s *TenantService) GetTenantAudit(ctx context.Context, tenantID string) ([]models.TenantAuditEntry, error) {
+	entries, err := s.queries.ListTenantAuditEntries(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to get audit log: %v", err))
+	}
+
+	result := make([]models.TenantAuditEntry, len(entries))
+	for i, e := range entries {
+		result[i] = models.TenantAuditEntry{
+			ID:        e.ID,
+			TenantID:  e.TenantID,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+// StartPurgeScheduler runs PurgeDueTenants every interval until ctx is cancelled
+func (s *TenantService) StartPurgeScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := s.PurgeDueTenants(ctx, "purge-scheduler")
+				if err != nil {
+					log.Printf("Scheduled tenant purge failed: %v", err)
+					continue
+				}
+				if len(result.Purged) > 0 || len(result.Failed) > 0 {
+					log.Printf("Scheduled tenant purge: %d purged, %d failed", len(result.Purged), len(result.Failed))
+				}
+			}
+		}
+	}()
+}
+
+// purgeAll purges each tenant independently so one failure does not stop the rest
+func (s *TenantService) purgeAll(ctx context.Context, tenants []db.Tenant, actor string, check purgeCheck) *models.PurgeTenantsResponse {
+	response := &models.PurgeTenantsResponse{
+		Purged: []string{},
+		Failed: []models.TenantPurgeFailure{},
+	}
+
+	for _, t := range tenants {
+		if err := s.purge(ctx, t, actor, check); err != nil {
+			response.Failed = append(response.Failed, models.TenantPurgeFailure{
+				TenantID:  t.ID,
+				Subdomain: t.Subdomain,
+				Error:     err.Error(),
+			})
+			continue
+		}
+		response.Purged = append(response.Purged, t.ID)
+	}
+
+	return response
+}
+
+// purgeCheck is the lifecycle state a tenant must still be in when its purge runs
+type purgeCheck int
+
+const (
+	purgeIfDue     purgeCheck = iota // soft-deleted and past purge_after (scheduled purge)
+	purgeIfDeleted                   // soft-deleted (purge on request)
+	purgeAlways                      // any state (development test tenants)
+)
+
+// purge drops the tenant schema (or its shared rows in RLS mode) and removes its
+// registry and invitation rows
+// The tenant data goes first because the tenant_shared rows reference the registry
+// row. The registry DELETE then locks the row and re-checks the state, so a tenant
+// restored since it was listed is left alone and the whole purge rolls back. The
+// registry queries are schema-qualified since the RLS tenant setting moves the
+// search path to tenant_shared.
+func (s *TenantService) purge(ctx context.Context, t db.Tenant, actor string, check purgeCheck) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	if s.isolation == tenant.IsolationRLS {
+		if err := tenant.DeleteSharedTenantData(ctx, tx, t.ID); err != nil {
+			return errors.ErrDatabase(fmt.Sprintf("failed to delete shared tenant data: %v", err))
+		}
+	} else if err := tenant.DropSchema(ctx, tx, t.SchemaName); err != nil {
+		return errors.ErrSchemaCreation(fmt.Sprintf("failed to drop schema %s: %v", t.SchemaName, err))
+	}
+
+	qtx := s.queries.WithTx(tx)
+	deleted, err := qtx.DeleteTenant(ctx, db.DeleteTenantParams{
+		ID:             t.ID,
+		RequireDeleted: check != purgeAlways,
+		RequireDue:     check == purgeIfDue,
+	})
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to delete tenant: %v", err))
+	}
+	if deleted == 0 {
+		return errors.ErrInvalidState("tenant was restored, purged or rescheduled concurrently")
+	}
+	if err := qtx.DeleteTenantInvitations(ctx, t.ID); err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to delete invitations: %v", err))
+	}
+
+	details := map[string]any{
+		"name":        t.Name,
+		"subdomain":   t.Subdomain,
+		"schema_name": t.SchemaName,
+	}
+	if err := recordAudit(ctx, qtx, t.ID, AuditActionPurge, actor, details); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to commit tenant purge: %v", err))
+	}
+
+	log.Printf("Purged tenant %s (%s)", t.ID, t.Subdomain)
+	return nil
+}
+
+// recordAudit writes a tenant lifecycle audit entry
+func recordAudit(ctx context.Context, q *db.Queries, tenantID, action, actor string, details map[string]any) error {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to encode audit details: %v", err))
+	}
+
+	err = q.CreateTenantAuditEntry(ctx, db.CreateTenantAuditEntryParams{
+		TenantID: tenantID,
+		Action:   action,
+		Actor:    actor,
+		Details:  payload,
+	})
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to record audit entry: %v", err))
+	}
+
+	return nil
+}
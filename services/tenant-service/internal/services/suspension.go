@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/db"
+	"crm-platform/tenant-service/internal/errors"
+	"crm-platform/tenant-service/internal/models"
+)
+
+// SuspendTenant blocks access to an active tenant while keeping its data
+// Suspending an already suspended tenant returns its current state unchanged.
+func (s *TenantService) SuspendTenant(ctx context.Context, tenantID string, reason string, actor string) (*models.TenantResponse, error) {
+	existing, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+
+	switch existing.Status {
+	case tenant.StatusSuspended:
+		return toTenantResponse(existing), nil
+	case tenant.StatusPending:
+		return nil, errors.ErrInvalidState("pending tenants cannot be suspended")
+	}
+
+	details := map[string]any{"reason": reason}
+	if err := s.changeStatus(ctx, tenantID, tenant.StatusSuspended, AuditActionSuspend, actor, details); err != nil {
+		return nil, err
+	}
+
+	return s.GetTenant(ctx, tenantID)
+}
+
+// ReactivateTenant restores access to a suspended tenant
+// Soft-deleted tenants must be restored with RestoreTenant instead.
+func (s *TenantService) ReactivateTenant(ctx context.Context, tenantID string, actor string) (*models.TenantResponse, error) {
+	existing, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+
+	if existing.DeletedAt.Valid {
+		return nil, errors.ErrInvalidState("tenant is deleted, restore it instead")
+	}
+
+	switch existing.Status {
+	case tenant.StatusActive:
+		return toTenantResponse(existing), nil
+	case tenant.StatusPending:
+		return nil, errors.ErrInvalidState("pending tenants must be provisioned, not reactivated")
+	}
+
+	if err := s.changeStatus(ctx, tenantID, tenant.StatusActive, AuditActionReactivate, actor, nil); err != nil {
+		return nil, err
+	}
+
+	return s.GetTenant(ctx, tenantID)
+}
+
+// changeStatus updates the tenant status and records the audit entry in one transaction
+func (s *TenantService) changeStatus(ctx context.Context, tenantID, status, action, actor string, details map[string]any) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	err = qtx.UpdateTenantStatus(ctx, db.UpdateTenantStatusParams{
+		ID:     tenantID,
+		Status: status,
+	})
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to update tenant status: %v", err))
+	}
+
+	if details == nil {
+		details = map[string]any{}
+	}
+	if err := recordAudit(ctx, qtx, tenantID, action, actor, details); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to commit status change: %v", err))
+	}
+
+	return nil
+}
@@ -10,24 +10,39 @@ import (
 	"crm-platform/tenant-service/internal/errors"
 	"crm-platform/tenant-service/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/oklog/ulid/v2"
 )
 
 // TenantService handles all tenant business logic
 type TenantService struct {
-	pool    *database.Pool
-	queries *db.Queries
+	pool      *database.Pool
+	queries   *db.Queries
+	migrator  *tenant.Migrator
+	isolation tenant.IsolationMode
+
+	testTenantDeletion bool // DeleteTestTenants is allowed (development only)
 }
 
 // NewTenantService creates a new tenant service instance
-func NewTenantService(pool *database.Pool) *TenantService {
+// In tenant.IsolationRLS mode tenants share tenant_shared and no per-tenant schema is built.
+func NewTenantService(pool *database.Pool, migrator *tenant.Migrator, isolation tenant.IsolationMode) *TenantService {
 	return &TenantService{
-		pool:    pool,
-		queries: db.New(pool),
+		pool:      pool,
+		queries:   db.New(pool),
+		migrator:  migrator,
+		isolation: isolation,
 	}
 }
 
+// EnableTestTenantDeletion allows DeleteTestTenants; only call it in development
+func (s *TenantService) EnableTestTenantDeletion() {
+	s.testTenantDeletion = true
+}
+
 // CreateTenant creates a new tenant with schema provisioning
+// The registry row is written as pending first; it only becomes active once the
+// schema is fully built, so a failure leaves a pending tenant that can be retried.
 func (s *TenantService) CreateTenant(ctx context.Context, req models.CreateTenantRequest) (*models.TenantResponse, error) {
 	// Check if subdomain already exists
 	exists, err := s.queries.CheckSubdomainExists(ctx, req.Subdomain)
@@ -44,42 +59,123 @@ func (s *TenantService) CreateTenant(ctx context.Context, req models.CreateTenan
 	// Generate schema name
 	schemaName := tenant.GenerateSchemaName(tenantID)
 
-	// Create tenant record in database
-	result, err := s.queries.CreateTenant(ctx, db.CreateTenantParams{
+	// Create pending tenant record in database
+	_, err = s.queries.CreateTenant(ctx, db.CreateTenantParams{
 		ID:         tenantID,
 		Name:       req.Name,
 		Subdomain:  req.Subdomain,
 		SchemaName: schemaName,
+		Status:     tenant.StatusPending,
 	})
 	if err != nil {
 		return nil, errors.ErrDatabase(fmt.Sprintf("failed to create tenant: %v", err))
 	}
 
-	// Create tenant schema
-	if err := tenant.CreateSchema(ctx, s.pool, schemaName); err != nil {
-		return nil, errors.ErrSchemaCreation(fmt.Sprintf("failed to create schema: %v", err))
+	// Build the schema and activate the tenant
+	if err := s.provisionTenant(ctx, tenantID, schemaName); err != nil {
+		return nil, err
+	}
+
+	return s.GetTenant(ctx, tenantID)
+}
+
+// RetryProvisioning rebuilds the schema for a tenant stuck in pending
+func (s *TenantService) RetryProvisioning(ctx context.Context, tenantID string) (*models.TenantResponse, error) {
+	tenantRecord, err := s.queries.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
+	}
+
+	if tenantRecord.Status != tenant.StatusPending {
+		return nil, errors.ErrInvalidState(fmt.Sprintf("tenant is %s, only pending tenants can be provisioned", tenantRecord.Status))
+	}
+
+	if err := s.provisionTenant(ctx, tenantRecord.ID, tenantRecord.SchemaName); err != nil {
+		return nil, err
 	}
 
-	// Copy template schema structure
-	templateSchema := "template"
-	if err := tenant.CopyTemplateSchema(ctx, s.pool, templateSchema, schemaName); err != nil {
-		return nil, errors.ErrSchemaCreation(fmt.Sprintf("failed to copy template: %v", err))
+	return s.GetTenant(ctx, tenantID)
+}
+
+// provisionTenant builds the tenant schema and marks the tenant active
+// The registry row stays locked until the tenant is activated, so concurrent calls
+// for one tenant run one at a time. The schema is built and migrated on the same
+// transaction that holds the lock, so provisioning needs one pool connection and a
+// failure at any step rolls back the whole schema with the activation.
+func (s *TenantService) provisionTenant(ctx context.Context, tenantID, schemaName string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to begin transaction: %v", err))
 	}
+	defer tx.Rollback(ctx)
 
-	// Get the full tenant record
-	fullTenant, err := s.queries.GetTenantByID(ctx, tenantID)
+	qtx := s.queries.WithTx(tx)
+	status, err := qtx.LockTenantStatus(ctx, tenantID)
+	if err == pgx.ErrNoRows {
+		return errors.ErrNotFound("tenant not found")
+	}
 	if err != nil {
-		return nil, errors.ErrDatabase(fmt.Sprintf("failed to retrieve tenant: %v", err))
+		return errors.ErrDatabase(fmt.Sprintf("failed to lock tenant: %v", err))
+	}
+	if status == tenant.StatusActive {
+		return nil // provisioned by a concurrent call while we waited for the lock
+	}
+	if status != tenant.StatusPending {
+		return errors.ErrInvalidState(fmt.Sprintf("tenant is %s, only pending tenants can be provisioned", status))
 	}
 
-	return &models.TenantResponse{
-		ID:         fullTenant.ID,
-		Name:       fullTenant.Name,
-		Subdomain:  fullTenant.Subdomain,
-		SchemaName: fullTenant.SchemaName,
-		CreatedAt:  result.CreatedAt,
-		UpdatedAt:  fullTenant.UpdatedAt,
-	}, nil
+	if s.isolation == tenant.IsolationRLS {
+		if err := s.checkSharedSchema(ctx, tx, tenantID); err != nil {
+			return err
+		}
+		return s.activateTenant(ctx, tx, qtx, tenantID)
+	}
+
+	// Clear leftovers from before provisioning was transactional
+	if err := tenant.DropSchema(ctx, tx, schemaName); err != nil {
+		return errors.ErrSchemaCreation(fmt.Sprintf("failed to clean up schema: %v", err))
+	}
+
+	// Create schema and copy template
+	if err := tenant.ProvisionSchemaTx(ctx, tx, tenant.TemplateSchemaName, schemaName); err != nil {
+		return errors.ErrSchemaCreation(fmt.Sprintf("failed to provision schema (tenant %s left pending): %v", tenantID, err))
+	}
+
+	// Apply any migrations the template has not picked up yet
+	if migration := s.migrator.MigrateSchemaTx(ctx, tx, schemaName); migration.Error != "" {
+		return errors.ErrSchemaCreation(fmt.Sprintf("failed to migrate schema (tenant %s left pending): %s", tenantID, migration.Error))
+	}
+
+	// Activate tenant only once the schema is complete
+	return s.activateTenant(ctx, tx, qtx, tenantID)
+}
+
+// checkSharedSchema verifies the shared RLS tables are present before a tenant is activated
+func (s *TenantService) checkSharedSchema(ctx context.Context, tx pgx.Tx, tenantID string) error {
+	exists, err := tenant.SchemaExists(ctx, tx, tenant.SharedSchemaName)
+	if err != nil {
+		return errors.ErrSchemaCreation(fmt.Sprintf("failed to check shared schema (tenant %s left pending): %v", tenantID, err))
+	}
+	if !exists {
+		return errors.ErrSchemaCreation(fmt.Sprintf("shared schema %s missing (tenant %s left pending)", tenant.SharedSchemaName, tenantID))
+	}
+	return nil
+}
+
+// activateTenant marks the locked tenant active and commits the schema with it
+func (s *TenantService) activateTenant(ctx context.Context, tx pgx.Tx, qtx *db.Queries, tenantID string) error {
+	err := qtx.UpdateTenantStatus(ctx, db.UpdateTenantStatusParams{
+		ID:     tenantID,
+		Status: tenant.StatusActive,
+	})
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		return errors.ErrDatabase(fmt.Sprintf("failed to activate tenant %s (left pending): %v", tenantID, err))
+	}
+
+	return nil
 }
 
 // GetTenant retrieves a tenant by ID
@@ -89,14 +185,7 @@ func (s *TenantService) GetTenant(ctx context.Context, tenantID string) (*models
 		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
 	}
 
-	return &models.TenantResponse{
-		ID:         tenant.ID,
-		Name:       tenant.Name,
-		Subdomain:  tenant.Subdomain,
-		SchemaName: tenant.SchemaName,
-		CreatedAt:  tenant.CreatedAt,
-		UpdatedAt:  tenant.UpdatedAt,
-	}, nil
+	return toTenantResponse(tenant), nil
 }
 
 // GetTenantBySubdomain retrieves a tenant by subdomain
@@ -106,14 +195,7 @@ func (s *TenantService) GetTenantBySubdomain(ctx context.Context, subdomain stri
 		return nil, errors.ErrNotFound(fmt.Sprintf("tenant not found: %v", err))
 	}
 
-	return &models.TenantResponse{
-		ID:         tenant.ID,
-		Name:       tenant.Name,
-		Subdomain:  tenant.Subdomain,
-		SchemaName: tenant.SchemaName,
-		CreatedAt:  tenant.CreatedAt,
-		UpdatedAt:  tenant.UpdatedAt,
-	}, nil
+	return toTenantResponse(tenant), nil
 }
 
 // ListTenants retrieves all tenants
@@ -130,6 +212,7 @@ func (s *TenantService) ListTenants(ctx context.Context) ([]models.TenantRespons
 			Name:       t.Name,
 			Subdomain:  t.Subdomain,
 			SchemaName: t.SchemaName,
+			Status:     t.Status,
 			CreatedAt:  t.CreatedAt,
 			UpdatedAt:  t.CreatedAt, // ListAllTenants doesn't return UpdatedAt
 		}
@@ -138,6 +221,21 @@ func (s *TenantService) ListTenants(ctx context.Context) ([]models.TenantRespons
 	return result, nil
 }
 
+// ListTenantsByStatus retrieves tenants in a given lifecycle state
+func (s *TenantService) ListTenantsByStatus(ctx context.Context, status string) ([]models.TenantResponse, error) {
+	tenants, err := s.queries.ListTenantsByStatus(ctx, status)
+	if err != nil {
+		return nil, errors.ErrDatabase(fmt.Sprintf("failed to list tenants: %v", err))
+	}
+
+	result := make([]models.TenantResponse, len(tenants))
+	for i, t := range tenants {
+		result[i] = *toTenantResponse(t)
+	}
+
+	return result, nil
+}
+
 // UpdateTenant updates tenant information
 func (s *TenantService) UpdateTenant(ctx context.Context, tenantID string, req models.UpdateTenantRequest) (*models.TenantResponse, error) {
 	// Check if tenant exists
@@ -173,12 +271,16 @@ func (s *TenantService) GetTenantHealth(ctx context.Context, tenantID string) (*
 		}, nil
 	}
 
-	// Check if schema exists
-	exists, err := tenant.SchemaExists(ctx, s.pool, tenantRecord.SchemaName)
+	// Check if schema exists (the shared schema in RLS mode)
+	schemaName := tenantRecord.SchemaName
+	if s.isolation == tenant.IsolationRLS {
+		schemaName = tenant.SharedSchemaName
+	}
+	exists, err := tenant.SchemaExists(ctx, s.pool, schemaName)
 	if err != nil {
 		return &models.TenantHealthResponse{
 			TenantID:   tenantID,
-			SchemaName: tenantRecord.SchemaName,
+			SchemaName: schemaName,
 			Healthy:    false,
 			Message:    fmt.Sprintf("failed to check schema: %v", err),
 		}, nil
@@ -187,7 +289,7 @@ func (s *TenantService) GetTenantHealth(ctx context.Context, tenantID string) (*
 	if !exists {
 		return &models.TenantHealthResponse{
 			TenantID:   tenantID,
-			SchemaName: tenantRecord.SchemaName,
+			SchemaName: schemaName,
 			Healthy:    false,
 			Message:    "schema does not exist",
 		}, nil
@@ -195,7 +297,7 @@ func (s *TenantService) GetTenantHealth(ctx context.Context, tenantID string) (*
 
 	return &models.TenantHealthResponse{
 		TenantID:   tenantID,
-		SchemaName: tenantRecord.SchemaName,
+		SchemaName: schemaName,
 		Healthy:    true,
 		Message:    "tenant is healthy",
 	}, nil
@@ -224,11 +326,22 @@ func (s *TenantService) CreateTestTenants(ctx context.Context, req models.BulkCr
 	return response, nil
 }
 
-// DeleteTestTenants deletes all test tenants (development only)
-func (s *TenantService) DeleteTestTenants(ctx context.Context) error {
-	// This is a placeholder - in production you'd want to:
-	// 1. List all tenants with test_ prefix
-	// 2. Delete their schemas
-	// 3. Delete their records
-	return errors.ErrNotImplemented("bulk tenant deletion not implemented")
+// toTenantResponse converts a tenant registry row to its API representation
+func toTenantResponse(t db.Tenant) *models.TenantResponse {
+	response := &models.TenantResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Subdomain:  t.Subdomain,
+		SchemaName: t.SchemaName,
+		Status:     t.Status,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}
+	if t.DeletedAt.Valid {
+		response.DeletedAt = &t.DeletedAt.Time
+	}
+	if t.PurgeAfter.Valid {
+		response.PurgeAfter = &t.PurgeAfter.Time
+	}
+	return response
 }
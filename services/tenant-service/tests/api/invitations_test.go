@@ -0,0 +1,156 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/tests/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// InvitationTestSuite covers inviting users and redeeming invitations
+// It runs once per isolation mode: accepted users land in the tenant's schema or in tenant_shared.
+type InvitationTestSuite struct {
+	suite.Suite
+	isolation tenant.IsolationMode
+	db        *helpers.TestDatabase
+	server    *helpers.TestServer
+	tenants   []string
+}
+
+// SetupSuite connects to the test database (skipped without DATABASE_URL)
+func (suite *InvitationTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), suite.db, suite.isolation)
+}
+
+// TearDownSuite closes the database connection
+func (suite *InvitationTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// TearDownTest removes the tenants the test created
+func (suite *InvitationTestSuite) TearDownTest() {
+	for _, id := range suite.tenants {
+		suite.db.RemoveTenant(id)
+	}
+	suite.tenants = nil
+}
+
+// createTenant creates an active tenant the test cleans up
+func (suite *InvitationTestSuite) createTenant() string {
+	id := suite.server.CreateTenant("Invitations")
+	suite.tenants = append(suite.tenants, id)
+	return id
+}
+
+// invite invites a unique address as a sales rep and returns the invitation ID, email and token
+func (suite *InvitationTestSuite) invite(tenantID string) (string, string, string) {
+	email := fmt.Sprintf("invitee%d@example.com", time.Now().UnixNano())
+	resp := suite.server.Post("/internal/tenants/"+tenantID+"/invitations", map[string]string{
+		"email": email,
+		"role":  "sales_rep",
+	})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, resp.Body)
+	suite.Require().NotEmpty(resp.Body["token"])
+	return resp.Body["id"].(string), email, resp.Body["token"].(string)
+}
+
+// accept redeems token with a valid password
+func (suite *InvitationTestSuite) accept(token string) *helpers.TestResponse {
+	return suite.server.Post("/internal/invitations/accept", map[string]string{
+		"token":      token,
+		"first_name": "Invited",
+		"last_name":  "User",
+		"password":   "correct-horse-battery",
+	})
+}
+
+// Creating an invitation stores it without the token, and a second open one for the same address conflicts
+func (suite *InvitationTestSuite) TestCreateInvitation() {
+	id := suite.createTenant()
+	invitationID, email, _ := suite.invite(id)
+
+	list := suite.server.Send(http.MethodGet, "/internal/tenants/"+id+"/invitations", nil)
+	suite.Require().Equal(http.StatusOK, list.StatusCode)
+	suite.Require().Len(list.List, 1)
+	listed := list.List[0].(map[string]interface{})
+	suite.Equal(invitationID, listed["id"])
+	suite.Equal(email, listed["email"])
+	suite.NotContains(listed, "token")
+	suite.NotContains(listed, "accepted_at")
+
+	resp := suite.server.Post("/internal/tenants/"+id+"/invitations", map[string]string{"email": email, "role": "viewer"})
+	suite.Equal(http.StatusConflict, resp.StatusCode, resp.Body)
+}
+
+// Accepting creates the user in the tenant with the invited role
+func (suite *InvitationTestSuite) TestAcceptInvitation() {
+	id := suite.createTenant()
+	_, email, token := suite.invite(id)
+
+	resp := suite.accept(token)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, resp.Body)
+	suite.Equal(id, resp.Body["tenant_id"])
+	suite.Equal(email, resp.Body["email"])
+	suite.Equal("sales_rep", resp.Body["role"])
+	suite.NotZero(resp.Body["user_id"])
+	suite.Equal(1, suite.db.CountUsers(id, suite.isolation, email))
+
+	list := suite.server.Send(http.MethodGet, "/internal/tenants/"+id+"/invitations", nil)
+	suite.Require().Len(list.List, 1)
+	suite.Contains(list.List[0], "accepted_at")
+}
+
+// A token is redeemed once; the second attempt creates no second user
+func (suite *InvitationTestSuite) TestAcceptInvitation_Twice() {
+	id := suite.createTenant()
+	_, email, token := suite.invite(id)
+
+	suite.Require().Equal(http.StatusCreated, suite.accept(token).StatusCode)
+	suite.Equal(http.StatusNotFound, suite.accept(token).StatusCode)
+	suite.Equal(1, suite.db.CountUsers(id, suite.isolation, email))
+}
+
+// An expired invitation cannot be accepted, and cleanup deletes it
+func (suite *InvitationTestSuite) TestAcceptInvitation_Expired() {
+	id := suite.createTenant()
+	invitationID, email, token := suite.invite(id)
+	suite.db.Exec("UPDATE invitations SET expires_at = now() - interval '1 minute' WHERE id = $1", invitationID)
+
+	suite.Equal(http.StatusNotFound, suite.accept(token).StatusCode)
+	suite.Equal(0, suite.db.CountUsers(id, suite.isolation, email))
+
+	resp := suite.server.Post("/internal/invitations/cleanup", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.GreaterOrEqual(resp.Body["deleted"], float64(1))
+	suite.Empty(suite.server.Send(http.MethodGet, "/internal/tenants/"+id+"/invitations", nil).List)
+}
+
+// A revoked invitation's token stops working; revoking it again, or from another tenant, is not found
+func (suite *InvitationTestSuite) TestRevokeInvitation() {
+	id := suite.createTenant()
+	other := suite.createTenant()
+	invitationID, email, token := suite.invite(id)
+
+	suite.Equal(http.StatusNotFound, suite.server.Send(http.MethodDelete, "/internal/tenants/"+other+"/invitations/"+invitationID, nil).StatusCode)
+	suite.Require().Equal(http.StatusOK, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id+"/invitations/"+invitationID, nil).StatusCode)
+	suite.Equal(http.StatusNotFound, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id+"/invitations/"+invitationID, nil).StatusCode)
+
+	suite.Equal(http.StatusNotFound, suite.accept(token).StatusCode)
+	suite.Equal(0, suite.db.CountUsers(id, suite.isolation, email))
+}
+
+func TestInvitationSchemaTestSuite(t *testing.T) {
+	suite.Run(t, &InvitationTestSuite{isolation: tenant.IsolationSchema})
+}
+
+func TestInvitationRLSTestSuite(t *testing.T) {
+	suite.Run(t, &InvitationTestSuite{isolation: tenant.IsolationRLS})
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/tests/helpers"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// OffboardingTestSuite covers soft delete, restore and purge
+// It runs once per isolation mode: a purge drops the tenant's schema or its tenant_shared rows.
+type OffboardingTestSuite struct {
+	suite.Suite
+	isolation tenant.IsolationMode
+	db        *helpers.TestDatabase
+	server    *helpers.TestServer
+	tenants   []string
+	users     map[string]string // tenant ID -> email of the user createTenant added
+}
+
+// SetupSuite connects to the test database (skipped without DATABASE_URL)
+func (suite *OffboardingTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), suite.db, suite.isolation)
+	suite.users = map[string]string{}
+}
+
+// TearDownSuite closes the database connection
+func (suite *OffboardingTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// TearDownTest removes the tenants the test created
+func (suite *OffboardingTestSuite) TearDownTest() {
+	for _, id := range suite.tenants {
+		suite.db.RemoveTenant(id)
+	}
+	suite.tenants = nil
+}
+
+// createTenant creates an active tenant with one user that the test cleans up
+// The user gives an RLS tenant rows in tenant_shared that reference its registry row.
+func (suite *OffboardingTestSuite) createTenant() string {
+	id := suite.server.CreateTenant("Offboarding")
+	suite.tenants = append(suite.tenants, id)
+
+	email := fmt.Sprintf("member%d@example.com", time.Now().UnixNano())
+	invitation := suite.server.Post("/internal/tenants/"+id+"/invitations", map[string]string{
+		"email": email,
+		"role":  "sales_rep",
+	})
+	suite.Require().Equal(http.StatusCreated, invitation.StatusCode, invitation.Body)
+	accepted := suite.server.Post("/internal/invitations/accept", map[string]string{
+		"token":      invitation.Body["token"].(string),
+		"first_name": "Tenant",
+		"last_name":  "Member",
+		"password":   "correct-horse-battery",
+	})
+	suite.Require().Equal(http.StatusCreated, accepted.StatusCode, accepted.Body)
+	suite.users[id] = email
+	return id
+}
+
+// hasData reports whether the tenant's schema (or its tenant_shared users under RLS) still exists
+func (suite *OffboardingTestSuite) hasData(id string) bool {
+	if suite.isolation == tenant.IsolationRLS {
+		return suite.db.CountUsers(id, suite.isolation, suite.users[id]) > 0
+	}
+	return suite.db.SchemaExists(id)
+}
+
+// status returns the tenant's current status
+func (suite *OffboardingTestSuite) status(id string) string {
+	resp := suite.server.Send(http.MethodGet, "/internal/tenants/"+id, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, resp.Body)
+	return resp.Body["status"].(string)
+}
+
+// Restoring returns a tenant to the status it had before the delete
+func (suite *OffboardingTestSuite) TestRestore_KeepsPreviousStatus() {
+	active := suite.createTenant()
+	suspended := suite.createTenant()
+	suite.Require().Equal(http.StatusOK, suite.server.Post("/internal/tenants/"+suspended+"/suspend", map[string]string{"reason": "billing"}).StatusCode)
+
+	for _, id := range []string{active, suspended} {
+		suite.Require().Equal(http.StatusOK, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id, nil).StatusCode)
+		suite.Equal(tenant.StatusSuspended, suite.status(id))
+		suite.Require().Equal(http.StatusOK, suite.server.Post("/internal/tenants/"+id+"/restore", nil).StatusCode)
+	}
+
+	suite.Equal(tenant.StatusActive, suite.status(active))
+	suite.Equal(tenant.StatusSuspended, suite.status(suspended), "a suspended tenant is not activated by a restore")
+}
+
+// The scheduled purge only removes deleted tenants past purge_after
+func (suite *OffboardingTestSuite) TestPurgeDue_OnlyDueTenants() {
+	due := suite.createTenant()
+	notDue := suite.createTenant()
+	restored := suite.createTenant()
+	for _, id := range []string{due, notDue, restored} {
+		suite.Require().Equal(http.StatusOK, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id, nil).StatusCode)
+	}
+	suite.db.Exec("UPDATE tenants SET purge_after = now() - interval '1 minute' WHERE id = ANY($1)", []string{due, restored})
+	suite.Require().Equal(http.StatusOK, suite.server.Post("/internal/tenants/"+restored+"/restore", nil).StatusCode)
+
+	resp := suite.server.Post("/internal/tenants/purge", nil)
+	suite.Require().Contains([]int{http.StatusOK, http.StatusMultiStatus}, resp.StatusCode)
+	suite.Contains(resp.Body["purged"], due)
+	suite.NotContains(resp.Body["purged"], notDue)
+	suite.NotContains(resp.Body["purged"], restored)
+
+	suite.Equal(http.StatusNotFound, suite.server.Send(http.MethodGet, "/internal/tenants/"+due, nil).StatusCode)
+	suite.False(suite.hasData(due))
+	suite.True(suite.hasData(notDue))
+	suite.Equal(tenant.StatusActive, suite.status(restored))
+	suite.True(suite.hasData(restored))
+}
+
+// Purging on request needs a soft delete first
+func (suite *OffboardingTestSuite) TestPurge_RequiresSoftDelete() {
+	id := suite.createTenant()
+	suite.Equal(http.StatusConflict, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id+"/purge?confirm=true", nil).StatusCode)
+
+	suite.Require().Equal(http.StatusOK, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id, nil).StatusCode)
+	suite.Equal(http.StatusOK, suite.server.Send(http.MethodDelete, "/internal/tenants/"+id+"/purge?confirm=true", nil).StatusCode)
+	suite.False(suite.hasData(id))
+}
+
+// Bulk test-tenant deletion needs an explicit prefix
+func (suite *OffboardingTestSuite) TestDeleteTestTenants_RequiresPrefix() {
+	suite.Equal(http.StatusBadRequest, suite.server.Send(http.MethodDelete, "/internal/test-tenants?confirm=true", nil).StatusCode)
+}
+
+func TestOffboardingSchemaTestSuite(t *testing.T) {
+	suite.Run(t, &OffboardingTestSuite{isolation: tenant.IsolationSchema})
+}
+
+func TestOffboardingRLSTestSuite(t *testing.T) {
+	suite.Run(t, &OffboardingTestSuite{isolation: tenant.IsolationRLS})
+}
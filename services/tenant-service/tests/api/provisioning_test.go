@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/db"
+	"crm-platform/tenant-service/internal/models"
+	"crm-platform/tenant-service/internal/services"
+	"crm-platform/tenant-service/tests/helpers"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+// ProvisioningTestSuite covers the pending → active lifecycle and provisioning retries
+type ProvisioningTestSuite struct {
+	suite.Suite
+	db      *helpers.TestDatabase
+	server  *helpers.TestServer
+	tenants []string
+}
+
+// SetupSuite connects to the test database (skipped without DATABASE_URL)
+func (suite *ProvisioningTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+	suite.server = helpers.SetupTestServer(suite.T(), suite.db, tenant.IsolationSchema)
+}
+
+// TearDownSuite closes the database connection
+func (suite *ProvisioningTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// TearDownTest removes the tenants the test created
+func (suite *ProvisioningTestSuite) TearDownTest() {
+	for _, id := range suite.tenants {
+		suite.db.RemoveTenant(id)
+	}
+	suite.tenants = nil
+}
+
+// pendingTenant registers a tenant stuck in pending with a half-built schema,
+// as a crash during provisioning leaves it
+func (suite *ProvisioningTestSuite) pendingTenant() string {
+	id := ulid.Make().String()
+	suite.tenants = append(suite.tenants, id)
+	_, err := db.New(suite.db.Pool).CreateTenant(context.Background(), db.CreateTenantParams{
+		ID:         id,
+		Name:       "Pending Tenant",
+		Subdomain:  fmt.Sprintf("pending%d", time.Now().UnixNano()),
+		SchemaName: tenant.GenerateSchemaName(id),
+		Status:     tenant.StatusPending,
+	})
+	suite.Require().NoError(err)
+	suite.db.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, tenant.GenerateSchemaName(id)))
+	suite.db.Exec(fmt.Sprintf(`CREATE TABLE "%s".leftover (id INT)`, tenant.GenerateSchemaName(id)))
+	return id
+}
+
+// A new tenant is active with its schema built
+func (suite *ProvisioningTestSuite) TestCreateTenant_Active() {
+	id := suite.server.CreateTenant("Acme")
+	suite.tenants = append(suite.tenants, id)
+
+	resp := suite.server.Send(http.MethodGet, "/internal/tenants/"+id, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal(tenant.StatusActive, resp.Body["status"])
+	suite.True(suite.db.SchemaExists(id))
+}
+
+// Retrying a pending tenant rebuilds its schema from scratch and activates it
+func (suite *ProvisioningTestSuite) TestRetryProvisioning_ActivatesPendingTenant() {
+	id := suite.pendingTenant()
+
+	resp := suite.server.Post("/internal/tenants/"+id+"/provision", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, resp.Body)
+	suite.Equal(tenant.StatusActive, resp.Body["status"])
+
+	var leftover bool
+	err := suite.db.Pool.QueryRow(context.Background(),
+		"SELECT EXISTS(SELECT 1 FROM information_schema.tables WHERE table_schema = $1 AND table_name = 'leftover')",
+		tenant.GenerateSchemaName(id)).Scan(&leftover)
+	suite.Require().NoError(err)
+	suite.False(leftover, "the half-built schema is dropped first")
+	suite.True(suite.db.SchemaExists(id))
+}
+
+// Only pending tenants can be provisioned again
+func (suite *ProvisioningTestSuite) TestRetryProvisioning_RejectsActiveTenant() {
+	id := suite.server.CreateTenant("Acme")
+	suite.tenants = append(suite.tenants, id)
+
+	suite.Equal(http.StatusConflict, suite.server.Post("/internal/tenants/"+id+"/provision", nil).StatusCode)
+	suite.Equal(http.StatusNotFound, suite.server.Post("/internal/tenants/"+ulid.Make().String()+"/provision", nil).StatusCode)
+	suite.True(suite.db.SchemaExists(id))
+}
+
+// Concurrent retries take turns on the tenant row: one builds the schema, the
+// others find the tenant active and leave the schema alone
+func (suite *ProvisioningTestSuite) TestRetryProvisioning_Concurrent() {
+	id := suite.pendingTenant()
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 4)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = suite.server.Post("/internal/tenants/"+id+"/provision", nil).StatusCode
+		}(i)
+	}
+	wg.Wait()
+
+	for _, status := range statuses {
+		suite.Contains([]int{http.StatusOK, http.StatusConflict}, status)
+	}
+	suite.Contains(statuses, http.StatusOK)
+
+	resp := suite.server.Send(http.MethodGet, "/internal/tenants/"+id, nil)
+	suite.Equal(tenant.StatusActive, resp.Body["status"])
+	suite.True(suite.db.SchemaExists(id))
+
+	var tables int
+	err := suite.db.Pool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = $1 AND table_name = 'users'",
+		tenant.GenerateSchemaName(id)).Scan(&tables)
+	suite.Require().NoError(err)
+	suite.Equal(1, tables, "the schema survived the other retries")
+}
+
+// Provisioning needs a single connection, so concurrent creates finish on a
+// one-connection pool instead of each holding a connection while waiting for another
+func (suite *ProvisioningTestSuite) TestCreateTenant_ConcurrentOnSingleConnection() {
+	config, err := database.LoadConfigFromEnv()
+	suite.Require().NoError(err)
+	config.MaxConns = 1
+	config.MinConns = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	pool, err := database.NewPool(ctx, config)
+	suite.Require().NoError(err)
+	defer pool.Close()
+
+	migrationService, err := services.NewMigrationService(pool)
+	suite.Require().NoError(err)
+	service := services.NewTenantService(pool, migrationService.Migrator(), tenant.IsolationSchema)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			created, err := service.CreateTenant(ctx, models.CreateTenantRequest{
+				Name:      "Single Connection",
+				Subdomain: fmt.Sprintf("single%d%d", i, time.Now().UnixNano()),
+			})
+			errs[i] = err
+			if err == nil {
+				mu.Lock()
+				suite.tenants = append(suite.tenants, created.ID)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		suite.NoError(err)
+	}
+	suite.NoError(ctx.Err(), "provisioning did not wait on the pool")
+}
+
+func TestProvisioningTestSuite(t *testing.T) {
+	suite.Run(t, new(ProvisioningTestSuite))
+}
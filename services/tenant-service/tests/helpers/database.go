@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/database"
+	"crm-platform/pkg/tenant"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDatabase is the registry database the tenant-service tests run against
+type TestDatabase struct {
+	Pool *database.Pool
+	t    *testing.T
+}
+
+// SetupTestDatabase connects to DATABASE_URL, skipping the test when it is not set
+// The database needs the registry tables, the template schema and tenant_shared
+// (run the migrations in ../../migrations first).
+func SetupTestDatabase(t *testing.T) *TestDatabase {
+	if os.Getenv("DATABASE_URL") == "" {
+		t.Skip("DATABASE_URL not set, skipping tenant-service database tests")
+	}
+
+	config, err := database.LoadConfigFromEnv()
+	require.NoError(t, err, "Failed to load database config for tests")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	pool, err := database.NewPool(ctx, config)
+	require.NoError(t, err, "Failed to create test database pool")
+
+	health := pool.HealthCheck(ctx)
+	require.True(t, health.Healthy, "Test database is not healthy: %s", health.Error)
+
+	return &TestDatabase{Pool: pool, t: t}
+}
+
+// Close closes the pool
+func (td *TestDatabase) Close() {
+	if td.Pool != nil {
+		td.Pool.Close()
+	}
+}
+
+// Exec runs sql against the registry, requiring success
+func (td *TestDatabase) Exec(sql string, args ...interface{}) {
+	_, err := td.Pool.Exec(context.Background(), sql, args...)
+	require.NoError(td.t, err, "Failed to run %q", sql)
+}
+
+// RemoveTenant drops everything a test tenant left behind: schema, shared rows,
+// invitations, registry row and audit trail
+func (td *TestDatabase) RemoveTenant(tenantID string) {
+	ctx := context.Background()
+	if err := tenant.DropSchema(ctx, td.Pool, tenant.GenerateSchemaName(tenantID)); err != nil {
+		td.t.Logf("Warning: failed to drop schema of %s: %v", tenantID, err)
+	}
+	td.deleteSharedRows(tenantID)
+	td.Exec("DELETE FROM invitations WHERE tenant_id = $1", tenantID)
+	td.Exec("DELETE FROM tenants WHERE id = $1", tenantID)
+	td.Exec("DELETE FROM tenant_audit_log WHERE tenant_id = $1", tenantID)
+}
+
+// deleteSharedRows removes the tenant's tenant_shared rows; the RLS tenant setting needs a transaction
+func (td *TestDatabase) deleteSharedRows(tenantID string) {
+	ctx := context.Background()
+	tx, err := td.Pool.Begin(ctx)
+	require.NoError(td.t, err)
+	defer tx.Rollback(ctx)
+
+	if err := tenant.DeleteSharedTenantData(ctx, tx, tenantID); err != nil {
+		td.t.Logf("Warning: failed to delete shared rows of %s: %v", tenantID, err)
+		return
+	}
+	require.NoError(td.t, tx.Commit(ctx))
+}
+
+// SchemaExists reports whether a tenant's own schema exists
+func (td *TestDatabase) SchemaExists(tenantID string) bool {
+	exists, err := tenant.SchemaExists(context.Background(), td.Pool, tenant.GenerateSchemaName(tenantID))
+	require.NoError(td.t, err)
+	return exists
+}
+
+// CountUsers counts the users with email a tenant has, in its schema or in
+// tenant_shared under RLS depending on isolation
+func (td *TestDatabase) CountUsers(tenantID string, isolation tenant.IsolationMode, email string) int {
+	ctx := context.Background()
+	tx, err := td.Pool.Begin(ctx)
+	require.NoError(td.t, err)
+	defer tx.Rollback(ctx)
+
+	if isolation == tenant.IsolationRLS {
+		err = tenant.SetTransactionTenant(ctx, tx, tenantID)
+	} else {
+		err = tenant.SetLocalSearchPath(ctx, tx, tenant.GenerateSchemaName(tenantID))
+	}
+	require.NoError(td.t, err)
+
+	var count int
+	require.NoError(td.t, tx.QueryRow(ctx, "SELECT count(*) FROM users WHERE email = $1", email).Scan(&count))
+	return count
+}
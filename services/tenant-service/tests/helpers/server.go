@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"crm-platform/pkg/tenant"
+	"crm-platform/tenant-service/internal/handlers"
+	"crm-platform/tenant-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServer serves the internal tenant routes over the test database
+type TestServer struct {
+	Router  *gin.Engine
+	Service *services.TenantService
+	t       *testing.T
+}
+
+// TestResponse is a recorded response with its JSON body decoded
+// Array bodies are in List, object bodies in Body.
+type TestResponse struct {
+	StatusCode int
+	Body       map[string]interface{}
+	List       []interface{}
+}
+
+// SetupTestServer creates a server whose tenants use isolation
+func SetupTestServer(t *testing.T, db *TestDatabase, isolation tenant.IsolationMode) *TestServer {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	migrationService, err := services.NewMigrationService(db.Pool)
+	require.NoError(t, err)
+	service := services.NewTenantService(db.Pool, migrationService.Migrator(), isolation)
+	service.EnableTestTenantDeletion()
+	tenantHandler := handlers.NewTenantHandler(service)
+
+	// Same paths as cmd/server
+	tenants := router.Group("/internal/tenants")
+	tenants.POST("", tenantHandler.CreateTenant)
+	tenants.POST("/purge", tenantHandler.PurgeDueTenants)
+	tenants.GET("/:id", tenantHandler.GetTenant)
+	tenants.POST("/:id/provision", tenantHandler.RetryProvisioning)
+	tenants.DELETE("/:id", tenantHandler.DeleteTenant)
+	tenants.POST("/:id/restore", tenantHandler.RestoreTenant)
+	tenants.DELETE("/:id/purge", tenantHandler.PurgeTenant)
+	tenants.POST("/:id/suspend", tenantHandler.SuspendTenant)
+	tenants.POST("/:id/invitations", tenantHandler.CreateInvitation)
+	tenants.GET("/:id/invitations", tenantHandler.ListInvitations)
+	tenants.DELETE("/:id/invitations/:invitationId", tenantHandler.RevokeInvitation)
+	router.POST("/internal/invitations/accept", tenantHandler.AcceptInvitation)
+	router.POST("/internal/invitations/cleanup", tenantHandler.CleanupExpiredInvitations)
+	router.DELETE("/internal/test-tenants", tenantHandler.DeleteTestTenants)
+
+	return &TestServer{Router: router, Service: service, t: t}
+}
+
+// Send sends a request to path with body as JSON unless nil
+func (ts *TestServer) Send(method, path string, body interface{}) *TestResponse {
+	req := httptest.NewRequest(method, path, nil)
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(ts.t, err)
+		req = httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	recorder := httptest.NewRecorder()
+	ts.Router.ServeHTTP(recorder, req)
+
+	resp := &TestResponse{StatusCode: recorder.Code}
+	if recorder.Body.Len() > 0 && recorder.Body.Bytes()[0] == '[' {
+		require.NoError(ts.t, json.Unmarshal(recorder.Body.Bytes(), &resp.List))
+	} else if recorder.Body.Len() > 0 {
+		require.NoError(ts.t, json.Unmarshal(recorder.Body.Bytes(), &resp.Body))
+	}
+	return resp
+}
+
+// Post sends body as JSON to path
+func (ts *TestServer) Post(path string, body interface{}) *TestResponse {
+	return ts.Send(http.MethodPost, path, body)
+}
+
+// CreateTenant creates an active tenant with a unique subdomain and returns its ID
+func (ts *TestServer) CreateTenant(name string) string {
+	subdomain := fmt.Sprintf("test%d", time.Now().UnixNano())
+	resp := ts.Post("/internal/tenants", map[string]string{"name": name, "subdomain": subdomain})
+	require.Equal(ts.t, http.StatusCreated, resp.StatusCode, resp.Body)
+	return resp.Body["id"].(string)
+}